@@ -7,7 +7,22 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// passwordCost is the bcrypt cost passwords are hashed with.
+const passwordCost = 12
+
+// missingPasswordHash is a bcrypt hash, at passwordCost, compared against
+// when there is no stored password.
+const missingPasswordHash = "$2a$12$aqSKUHo/tPA7hj./Fc0sfOnCECDK9dXFmerjSZ3jSPiXD76XQb.3y"
+
+// ComparePasswords reports whether providedPassword matches storedPassword.
+// An empty storedPassword, for an account without a password or no account
+// at all, never matches but takes as long to refuse as a wrong password, so
+// response times do not reveal which emails have accounts.
 func ComparePasswords(providedPassword, storedPassword string) bool {
+	if storedPassword == "" {
+		bcrypt.CompareHashAndPassword([]byte(missingPasswordHash), []byte(providedPassword))
+		return false
+	}
 	err := bcrypt.CompareHashAndPassword([]byte(storedPassword), []byte(providedPassword))
 	return err == nil
 }
@@ -32,7 +47,7 @@ func HashPassword(providedPassword *string) error {
 		return errors.New("missing special character")
 	}
 	password := []byte(*providedPassword)
-	hashedPassword, err := bcrypt.GenerateFromPassword(password, 12)
+	hashedPassword, err := bcrypt.GenerateFromPassword(password, passwordCost)
 	if err != nil {
 		return err
 	}
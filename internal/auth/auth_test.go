@@ -3,6 +3,11 @@ package auth
 import (
 	"errors"
 	"testing"
+	"time"
+
+	"github.com/Leagueify/api/internal/model"
+	"github.com/lib/pq"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestComparePasswords(t *testing.T) {
@@ -56,6 +61,18 @@ func TestComparePasswords(t *testing.T) {
 	}
 }
 
+func TestMissingPasswordHashCost(t *testing.T) {
+	// refusing a missing password only takes as long as a wrong one when
+	// the hash compared against has the cost passwords are hashed with
+	cost, err := bcrypt.Cost([]byte(missingPasswordHash))
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected for the missing password hash", err)
+	}
+	if cost != passwordCost {
+		t.Errorf("Expected cost %v received %v", passwordCost, cost)
+	}
+}
+
 func TestHashPassword(t *testing.T) {
 	testCases := []struct {
 		Description       string
@@ -109,3 +126,131 @@ func TestHashPassword(t *testing.T) {
 		}
 	}
 }
+
+func TestHashToken(t *testing.T) {
+	testCases := []struct {
+		Description    string
+		Token          string
+		ExpectedResult string
+	}{
+		{
+			Description:    "Valid Token",
+			Token:          "KJV1XK3",
+			ExpectedResult: "bad0d3624f2cd1e37737c26dc6ac3c63f030c1c58026f2502d016fd5b0741676",
+		},
+		{
+			Description:    "Empty Token",
+			Token:          "",
+			ExpectedResult: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		},
+	}
+
+	for _, test := range testCases {
+		result := HashToken(test.Token)
+		if result != test.ExpectedResult {
+			t.Errorf(
+				"%v: Expected %v received %v for %v",
+				test.Description, test.ExpectedResult, result, test.Token,
+			)
+		}
+	}
+}
+
+func TestHasPermission(t *testing.T) {
+	testCases := []struct {
+		Description    string
+		Account        model.Account
+		Permission     Permission
+		ExpectedResult bool
+	}{
+		{
+			Description:    "Administrator Without Roles",
+			Account:        model.Account{IsAdmin: true},
+			Permission:     PermissionManageSeasons,
+			ExpectedResult: true,
+		},
+		{
+			Description:    "Role Grants Permission",
+			Account:        model.Account{Roles: pq.StringArray{"registrar"}},
+			Permission:     PermissionViewRegistrations,
+			ExpectedResult: true,
+		},
+		{
+			Description:    "Role Lacks Permission",
+			Account:        model.Account{Roles: pq.StringArray{"coach"}},
+			Permission:     PermissionManageSeasons,
+			ExpectedResult: false,
+		},
+		{
+			Description:    "Unknown Role",
+			Account:        model.Account{Roles: pq.StringArray{"superuser"}},
+			Permission:     PermissionManageSeasons,
+			ExpectedResult: false,
+		},
+		{
+			Description:    "Coach Flag Is Not A Role",
+			Account:        model.Account{Coach: true},
+			Permission:     PermissionViewRosters,
+			ExpectedResult: false,
+		},
+	}
+
+	for _, test := range testCases {
+		result := HasPermission(test.Account, test.Permission)
+		if result != test.ExpectedResult {
+			t.Errorf(
+				"%v: Expected %v received %v",
+				test.Description, test.ExpectedResult, result,
+			)
+		}
+	}
+}
+
+func TestLoginBackoff(t *testing.T) {
+	testCases := []struct {
+		Description    string
+		Failures       int
+		ExpectedResult time.Duration
+	}{
+		{
+			Description:    "No Failures",
+			Failures:       0,
+			ExpectedResult: 0,
+		},
+		{
+			Description:    "Failures Before Backoff",
+			Failures:       LoginBackoffFailures - 1,
+			ExpectedResult: 0,
+		},
+		{
+			Description:    "First Backoff",
+			Failures:       LoginBackoffFailures,
+			ExpectedResult: time.Second,
+		},
+		{
+			Description:    "Backoff Doubles",
+			Failures:       LoginBackoffFailures + 3,
+			ExpectedResult: 8 * time.Second,
+		},
+		{
+			Description:    "Lockout Threshold",
+			Failures:       LoginLockoutThreshold,
+			ExpectedResult: LoginLockoutDuration,
+		},
+		{
+			Description:    "Beyond Lockout Threshold",
+			Failures:       LoginLockoutThreshold + 25,
+			ExpectedResult: LoginLockoutDuration,
+		},
+	}
+
+	for _, test := range testCases {
+		result := LoginBackoff(test.Failures)
+		if result != test.ExpectedResult {
+			t.Errorf(
+				"%v: Expected %v received %v",
+				test.Description, test.ExpectedResult, result,
+			)
+		}
+	}
+}
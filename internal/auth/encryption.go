@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"errors"
+)
+
+// EncryptionKeySize is the length of the AES-256 key used for data
+// encrypted at rest.
+const EncryptionKeySize = 32
+
+// Cipher encrypts data stored at rest with AES-256-GCM.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher returns a Cipher using key, which must be EncryptionKeySize bytes.
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != EncryptionKeySize {
+		return nil, errors.New("encryption key must be 32 bytes")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// Encrypt seals plaintext and returns it base64 encoded with its nonce.
+// The same associatedData must be given to Decrypt, binding the ciphertext
+// to the record it was written for.
+func (c *Cipher) Encrypt(plaintext, associatedData []byte) string {
+	nonce := randomBytes(c.aead.NonceSize())
+	sealed := c.aead.Seal(nonce, nonce, plaintext, associatedData)
+	return base64.StdEncoding.EncodeToString(sealed)
+}
+
+// Decrypt opens ciphertext produced by Encrypt.
+func (c *Cipher) Decrypt(ciphertext string, associatedData []byte) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < c.aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, sealed := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	return c.aead.Open(nil, nonce, sealed, associatedData)
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCipher(t *testing.T) {
+	cipher, err := NewCipher(bytes.Repeat([]byte("k"), EncryptionKeySize))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	plaintext := []byte(`{"allergies":"peanuts"}`)
+	ciphertext := cipher.Encrypt(plaintext, []byte("49QRBF09Y"))
+	if bytes.Contains([]byte(ciphertext), []byte("peanuts")) {
+		t.Errorf("Expected ciphertext to hide the plaintext, received %v", ciphertext)
+	}
+	if cipher.Encrypt(plaintext, []byte("49QRBF09Y")) == ciphertext {
+		t.Errorf("Expected a new nonce for every encryption")
+	}
+	tampered := []byte(ciphertext)
+	tampered[len(tampered)/2] ^= 'A' ^ 'B'
+
+	testCases := []struct {
+		Description    string
+		Ciphertext     string
+		AssociatedData string
+		ExpectedError  bool
+	}{
+		{Description: "Matching Record", Ciphertext: ciphertext, AssociatedData: "49QRBF09Y"},
+		{Description: "Different Record", Ciphertext: ciphertext, AssociatedData: "QP4RD39CE", ExpectedError: true},
+		{Description: "Tampered Ciphertext", Ciphertext: string(tampered), AssociatedData: "49QRBF09Y", ExpectedError: true},
+		{Description: "Invalid Encoding", Ciphertext: "not base64!", AssociatedData: "49QRBF09Y", ExpectedError: true},
+		{Description: "Too Short", Ciphertext: "AAAA", AssociatedData: "49QRBF09Y", ExpectedError: true},
+	}
+
+	for _, test := range testCases {
+		decrypted, err := cipher.Decrypt(test.Ciphertext, []byte(test.AssociatedData))
+		if test.ExpectedError {
+			if err == nil {
+				t.Errorf("%v: Expected an error", test.Description)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%v: unexpected error %v", test.Description, err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Errorf("%v: Expected %s received %s", test.Description, plaintext, decrypted)
+		}
+	}
+
+	if _, err := NewCipher([]byte("short")); err == nil {
+		t.Errorf("Expected an error for a short key")
+	}
+}
@@ -0,0 +1,40 @@
+package auth
+
+// GuardianPermission is what a guardian may do for a player. Every player
+// has at least one primary guardian.
+type GuardianPermission string
+
+const (
+	// GuardianPrimary manages the player, including deleting it and
+	// inviting or removing other guardians.
+	GuardianPrimary GuardianPermission = "primary"
+	// GuardianSecondary views, updates and registers the player.
+	GuardianSecondary GuardianPermission = "secondary"
+	// GuardianViewOnly views the player.
+	GuardianViewOnly GuardianPermission = "view"
+)
+
+// IsGuardianPermission reports whether permission is a known guardian permission.
+func IsGuardianPermission(permission string) bool {
+	switch GuardianPermission(permission) {
+	case GuardianPrimary, GuardianSecondary, GuardianViewOnly:
+		return true
+	}
+	return false
+}
+
+// CanManage reports whether the guardian may delete the player and manage
+// its guardians.
+func (p GuardianPermission) CanManage() bool {
+	return p == GuardianPrimary
+}
+
+// CanUpdate reports whether the guardian may update the player's details.
+func (p GuardianPermission) CanUpdate() bool {
+	return p == GuardianPrimary || p == GuardianSecondary
+}
+
+// CanRegister reports whether the guardian may register the player.
+func (p GuardianPermission) CanRegister() bool {
+	return p == GuardianPrimary || p == GuardianSecondary
+}
@@ -0,0 +1,38 @@
+package auth
+
+import "time"
+
+const (
+	// LoginAttemptWindow is how far back failed logins from an IP address are counted.
+	LoginAttemptWindow = 15 * time.Minute
+	// LoginBackoffFailures is the number of consecutive failures allowed before
+	// an account must wait between attempts.
+	LoginBackoffFailures = 3
+	// LoginLockoutThreshold is the number of consecutive failures that locks an account.
+	LoginLockoutThreshold = 10
+	// LoginLockoutDuration is how long a locked account refuses logins.
+	LoginLockoutDuration = 30 * time.Minute
+	// LoginIPThreshold is the number of failed logins from one IP address
+	// within LoginAttemptWindow before the address is refused.
+	LoginIPThreshold = 50
+)
+
+// Lockout events recorded for investigation.
+const (
+	LockoutEventAccountLocked   = "account_locked"
+	LockoutEventAccountUnlocked = "account_unlocked"
+	LockoutEventIPBlocked       = "ip_blocked"
+)
+
+// LoginBackoff returns how long an account must wait before the next login
+// attempt after failures consecutive failures. The wait doubles with every
+// failure past LoginBackoffFailures until the account is locked.
+func LoginBackoff(failures int) time.Duration {
+	if failures < LoginBackoffFailures {
+		return 0
+	}
+	if failures >= LoginLockoutThreshold {
+		return LoginLockoutDuration
+	}
+	return time.Second << (failures - LoginBackoffFailures)
+}
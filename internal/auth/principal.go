@@ -0,0 +1,20 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/Leagueify/api/internal/model"
+)
+
+type accountKey struct{}
+
+// WithAccount returns a copy of ctx carrying the authenticated account.
+func WithAccount(ctx context.Context, account model.Account) context.Context {
+	return context.WithValue(ctx, accountKey{}, account)
+}
+
+// AccountFromContext returns the authenticated account stored on ctx.
+func AccountFromContext(ctx context.Context) (model.Account, bool) {
+	account, ok := ctx.Value(accountKey{}).(model.Account)
+	return account, ok
+}
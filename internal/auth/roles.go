@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"slices"
+
+	"github.com/Leagueify/api/internal/model"
+)
+
+// Role is a named set of permissions that can be granted to an account.
+type Role string
+
+// Permission is checked by routes that do not require a full administrator.
+type Permission string
+
+const (
+	RoleLeagueAdmin Role = "league_admin"
+	RoleRegistrar   Role = "registrar"
+	RoleCoach       Role = "coach"
+	RoleReferee     Role = "referee"
+	RoleVolunteer   Role = "volunteer"
+	RoleParent      Role = "parent"
+)
+
+const (
+	PermissionManageAccounts      Permission = "accounts:manage"
+	PermissionViewAccounts        Permission = "accounts:view"
+	PermissionManageLeague        Permission = "league:manage"
+	PermissionManageRegistrations Permission = "registrations:manage"
+	PermissionViewRegistrations   Permission = "registrations:view"
+	PermissionViewRosters         Permission = "rosters:view"
+	PermissionManageSeasons       Permission = "seasons:manage"
+)
+
+// rolePermissions maps each role to the permissions it grants. Access to an
+// account's own players is checked per player and is not a permission.
+var rolePermissions = map[Role][]Permission{
+	RoleLeagueAdmin: {
+		PermissionManageAccounts,
+		PermissionViewAccounts,
+		PermissionManageLeague,
+		PermissionManageRegistrations,
+		PermissionViewRegistrations,
+		PermissionViewRosters,
+		PermissionManageSeasons,
+	},
+	RoleRegistrar: {
+		PermissionViewAccounts,
+		PermissionManageRegistrations,
+		PermissionViewRegistrations,
+	},
+	RoleCoach: {
+		PermissionViewRosters,
+	},
+	RoleReferee: {
+		PermissionViewRosters,
+	},
+	RoleVolunteer: {},
+	RoleParent:    {},
+}
+
+// Roles returns every grantable role and its permissions.
+func Roles() map[Role][]Permission {
+	return rolePermissions
+}
+
+// ValidRole reports whether role names a grantable role.
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[Role(role)]
+	return ok
+}
+
+// HasPermission reports whether the account holds permission through one of
+// its roles. Administrators hold every permission.
+func HasPermission(account model.Account, permission Permission) bool {
+	if account.IsAdmin {
+		return true
+	}
+	for _, role := range account.Roles {
+		if slices.Contains(rolePermissions[Role(role)], permission) {
+			return true
+		}
+	}
+	return false
+}
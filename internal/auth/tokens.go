@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+const (
+	// AccountVerificationTTL is how long an emailed verification link remains valid.
+	AccountVerificationTTL = 24 * time.Hour
+	// GuardianInviteTTL is how long an emailed guardian invite remains valid.
+	GuardianInviteTTL = 7 * 24 * time.Hour
+	// OIDCSignupTTL is how long a new account holder has to confirm their
+	// details after signing in with an identity provider.
+	OIDCSignupTTL = 30 * time.Minute
+	// OIDCStateTTL is how long an identity provider authorization remains valid.
+	OIDCStateTTL = 10 * time.Minute
+	// PasswordResetTTL is how long an emailed password reset token remains valid.
+	PasswordResetTTL = 30 * time.Minute
+	// SessionTTL is how long an API key remains valid after login.
+	SessionTTL = 30 * 24 * time.Hour
+)
+
+// HashToken returns the digest stored in place of a single-use token.
+func HashToken(token string) string {
+	digest := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(digest[:])
+}
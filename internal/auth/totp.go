@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// TOTPDigits is the length of an authenticator code.
+	TOTPDigits = 6
+	// TOTPPeriod is how long each authenticator code is valid.
+	TOTPPeriod = 30 * time.Second
+	// TOTPSkew is the number of periods either side of the current one that
+	// are accepted to allow for clock drift.
+	TOTPSkew = 1
+	// TOTPIssuer names the service in authenticator apps.
+	TOTPIssuer = "Leagueify"
+	// RecoveryCodeCount is the number of recovery codes issued at a time.
+	RecoveryCodeCount = 10
+	// TwoFactorChallengeTTL is how long a login waits for a two-factor code.
+	TwoFactorChallengeTTL = 5 * time.Minute
+)
+
+// totpModulus truncates an HOTP value to TOTPDigits digits.
+const totpModulus = 1000000
+
+var secretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret returns a new base32 encoded 160-bit shared secret.
+func GenerateTOTPSecret() string {
+	return secretEncoding.EncodeToString(randomBytes(20))
+}
+
+// GenerateRecoveryCodes returns RecoveryCodeCount single-use codes formatted
+// as four groups of four characters.
+func GenerateRecoveryCodes() []string {
+	codes := make([]string, RecoveryCodeCount)
+	for index := range codes {
+		code := secretEncoding.EncodeToString(randomBytes(10))
+		codes[index] = strings.Join(
+			[]string{code[0:4], code[4:8], code[8:12], code[12:16]}, "-",
+		)
+	}
+	return codes
+}
+
+// NormalizeRecoveryCode removes separators and case from a recovery code so
+// it can be hashed and compared.
+func NormalizeRecoveryCode(code string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.ToUpper(code))
+}
+
+// TOTPCode returns the RFC 6238 authenticator code for secret at time t.
+func TOTPCode(secret string, t time.Time) (string, error) {
+	key, err := secretEncoding.DecodeString(secret)
+	if err != nil {
+		return "", err
+	}
+	return hotp(key, totpCounter(t)), nil
+}
+
+// TOTPProvisioningURI returns the otpauth URI rendered as a QR code for
+// authenticator apps to enroll secret for accountName.
+func TOTPProvisioningURI(secret, accountName string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", TOTPIssuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", strconv.Itoa(TOTPDigits))
+	query.Set("period", strconv.Itoa(int(TOTPPeriod.Seconds())))
+	return fmt.Sprintf(
+		"otpauth://totp/%s?%s",
+		url.PathEscape(TOTPIssuer+":"+accountName), query.Encode(),
+	)
+}
+
+// ValidateTOTP checks code against secret at time t and returns the matching
+// time step. Steps at or before lastCounter are refused so a code cannot be
+// replayed.
+func ValidateTOTP(secret, code string, t time.Time, lastCounter int64) (int64, bool) {
+	key, err := secretEncoding.DecodeString(secret)
+	if err != nil || len(code) != TOTPDigits {
+		return 0, false
+	}
+	current := int64(totpCounter(t))
+	for counter := current - TOTPSkew; counter <= current+TOTPSkew; counter++ {
+		if counter <= lastCounter {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(hotp(key, uint64(counter))), []byte(code)) == 1 {
+			return counter, true
+		}
+	}
+	return 0, false
+}
+
+// hotp returns the RFC 4226 code for key at counter.
+func hotp(key []byte, counter uint64) string {
+	message := make([]byte, 8)
+	binary.BigEndian.PutUint64(message, counter)
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", TOTPDigits, value%totpModulus)
+}
+
+func randomBytes(length int) []byte {
+	bytes := make([]byte, length)
+	if _, err := rand.Read(bytes); err != nil {
+		panic(err)
+	}
+	return bytes
+}
+
+func totpCounter(t time.Time) uint64 {
+	return uint64(t.Unix() / int64(TOTPPeriod/time.Second))
+}
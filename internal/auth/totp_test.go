@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the base32 encoding of the RFC 6238 SHA1 test key
+// "12345678901234567890".
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 Appendix B test vectors truncated to six digits
+	testCases := []struct {
+		Description  string
+		Time         int64
+		ExpectedCode string
+	}{
+		{Description: "1970-01-01 00:00:59", Time: 59, ExpectedCode: "287082"},
+		{Description: "2005-03-18 01:58:29", Time: 1111111109, ExpectedCode: "081804"},
+		{Description: "2005-03-18 01:58:31", Time: 1111111111, ExpectedCode: "050471"},
+		{Description: "2009-02-13 23:31:30", Time: 1234567890, ExpectedCode: "005924"},
+		{Description: "2033-05-18 03:33:20", Time: 2000000000, ExpectedCode: "279037"},
+		{Description: "2603-10-11 11:33:20", Time: 20000000000, ExpectedCode: "353130"},
+	}
+
+	for _, test := range testCases {
+		code, err := TOTPCode(rfcSecret, time.Unix(test.Time, 0))
+		if err != nil {
+			t.Fatalf("%v: unexpected error %v", test.Description, err)
+		}
+		if code != test.ExpectedCode {
+			t.Errorf(
+				"%v: Expected %v received %v",
+				test.Description, test.ExpectedCode, code,
+			)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := now.Unix() / 30
+	testCases := []struct {
+		Description     string
+		Secret          string
+		Code            string
+		LastCounter     int64
+		ExpectedCounter int64
+		ExpectedResult  bool
+	}{
+		{
+			Description:     "Current Code",
+			Secret:          rfcSecret,
+			Code:            "050471",
+			ExpectedCounter: current,
+			ExpectedResult:  true,
+		},
+		{
+			Description:     "Previous Code Within Skew",
+			Secret:          rfcSecret,
+			Code:            "081804",
+			ExpectedCounter: current - 1,
+			ExpectedResult:  true,
+		},
+		{
+			Description:    "Replayed Code",
+			Secret:         rfcSecret,
+			Code:           "050471",
+			LastCounter:    current,
+			ExpectedResult: false,
+		},
+		{
+			Description:    "Expired Code",
+			Secret:         rfcSecret,
+			Code:           "287082",
+			ExpectedResult: false,
+		},
+		{
+			Description:    "Short Code",
+			Secret:         rfcSecret,
+			Code:           "50471",
+			ExpectedResult: false,
+		},
+		{
+			Description:    "Invalid Secret",
+			Secret:         "not base32!",
+			Code:           "050471",
+			ExpectedResult: false,
+		},
+	}
+
+	for _, test := range testCases {
+		counter, result := ValidateTOTP(test.Secret, test.Code, now, test.LastCounter)
+		if result != test.ExpectedResult || counter != test.ExpectedCounter {
+			t.Errorf(
+				"%v: Expected %v at %v received %v at %v",
+				test.Description, test.ExpectedResult, test.ExpectedCounter, result, counter,
+			)
+		}
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI(rfcSecret, "test@leagueify.org")
+	expected := "otpauth://totp/Leagueify:test@leagueify.org?algorithm=SHA1&digits=6&issuer=Leagueify&period=30&secret=" + rfcSecret
+	if uri != expected {
+		t.Errorf("Expected %v received %v", expected, uri)
+	}
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes := GenerateRecoveryCodes()
+	if len(codes) != RecoveryCodeCount {
+		t.Fatalf("Expected %v codes received %v", RecoveryCodeCount, len(codes))
+	}
+	format := regexp.MustCompile(`^[A-Z2-7]{4}-[A-Z2-7]{4}-[A-Z2-7]{4}-[A-Z2-7]{4}$`)
+	seen := map[string]bool{}
+	for _, code := range codes {
+		if !format.MatchString(code) {
+			t.Errorf("Unexpected recovery code format %v", code)
+		}
+		if seen[code] {
+			t.Errorf("Duplicate recovery code %v", code)
+		}
+		seen[code] = true
+		if normalized := NormalizeRecoveryCode(" " + code + " "); len(normalized) != 16 {
+			t.Errorf("Expected 16 characters received %v", normalized)
+		}
+	}
+}
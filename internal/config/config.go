@@ -1,17 +1,38 @@
 package config
 
 import (
+	"encoding/base64"
+	"fmt"
 	"os"
 	"strconv"
 	"strings"
 )
 
+// OIDCProvider is an OpenID Connect identity provider account holders can
+// sign in with.
+type OIDCProvider struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+}
+
 type configuration struct {
-	DB        string
-	DBConnStr string
-	Sentry    bool
-	SentryDSN string
-	SentryTSR float64
+	BaseURL               string
+	DB                    string
+	DBAutoMigrate         bool
+	DBConnStr             string
+	EncryptionKey         []byte
+	OIDCProviders         []OIDCProvider
+	PaymentsCurrency      string
+	PaymentsProvider      string
+	PaymentsWebhookSecret string
+	RequireAdmin2FA       bool
+	Sentry                bool
+	SentryDSN             string
+	SentryTSR             float64
+	StripeAPIURL          string
+	StripeSecretKey       string
 }
 
 func LoadConfig() *configuration {
@@ -22,6 +43,10 @@ func LoadConfig() *configuration {
 }
 
 func (c *configuration) loadFromEnv() {
+	// Public URL used when building links sent to users
+	if baseURL := os.Getenv("BASE_URL"); baseURL != "" {
+		c.BaseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	}
 	// Database Configuration
 	// Database Service
 	if db := os.Getenv("DATABASE"); db != "" {
@@ -31,6 +56,69 @@ func (c *configuration) loadFromEnv() {
 	if dbConnStr := os.Getenv("DB_CONN_STR"); dbConnStr != "" {
 		c.DBConnStr = strings.TrimSpace(dbConnStr)
 	}
+	// Database Migrations on Startup
+	if dbAutoMigrate := os.Getenv("DB_AUTO_MIGRATE"); dbAutoMigrate != "" {
+		b, err := strconv.ParseBool(strings.TrimSpace(dbAutoMigrate))
+		if err != nil {
+			panic("Invalid DB_AUTO_MIGRATE Environment Variable")
+		}
+		c.DBAutoMigrate = b
+	}
+	// Key Encrypting Sensitive Data at Rest
+	if encryptionKey := os.Getenv("ENCRYPTION_KEY"); encryptionKey != "" {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encryptionKey))
+		if err != nil || len(key) != 32 {
+			panic("Invalid ENCRYPTION_KEY Environment Variable")
+		}
+		c.EncryptionKey = key
+	}
+	// OpenID Connect Identity Providers
+	if providers := os.Getenv("OIDC_PROVIDERS"); providers != "" {
+		for _, name := range strings.Split(providers, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			c.OIDCProviders = append(c.OIDCProviders, loadOIDCProvider(name))
+		}
+	}
+	// Payment Provider Collecting Registration Fees
+	if provider := os.Getenv("PAYMENTS_PROVIDER"); provider != "" {
+		c.PaymentsProvider = strings.ToLower(strings.TrimSpace(provider))
+		if c.PaymentsProvider != "fake" && c.PaymentsProvider != "stripe" {
+			panic("Invalid PAYMENTS_PROVIDER Environment Variable")
+		}
+	}
+	// Currency Registration Fees Are Charged In
+	if currency := os.Getenv("PAYMENTS_CURRENCY"); currency != "" {
+		c.PaymentsCurrency = strings.ToLower(strings.TrimSpace(currency))
+	}
+	// Secret Payment Provider Webhooks Are Signed With
+	if webhookSecret := os.Getenv("PAYMENTS_WEBHOOK_SECRET"); webhookSecret != "" {
+		c.PaymentsWebhookSecret = strings.TrimSpace(webhookSecret)
+	}
+	if c.PaymentsProvider != "" && c.PaymentsWebhookSecret == "" {
+		panic("Invalid PAYMENTS_WEBHOOK_SECRET Environment Variable")
+	}
+	// Stripe Compatible API
+	if stripeAPIURL := os.Getenv("STRIPE_API_URL"); stripeAPIURL != "" {
+		c.StripeAPIURL = strings.TrimRight(strings.TrimSpace(stripeAPIURL), "/")
+	}
+	// Stripe Secret API Key
+	if stripeSecretKey := os.Getenv("STRIPE_SECRET_KEY"); stripeSecretKey != "" {
+		c.StripeSecretKey = strings.TrimSpace(stripeSecretKey)
+	}
+	if c.PaymentsProvider == "stripe" && c.StripeSecretKey == "" {
+		panic("Invalid STRIPE_SECRET_KEY Environment Variable")
+	}
+	// Two-Factor Authentication Required for Administrators
+	if requireAdmin2FA := os.Getenv("REQUIRE_ADMIN_2FA"); requireAdmin2FA != "" {
+		b, err := strconv.ParseBool(strings.TrimSpace(requireAdmin2FA))
+		if err != nil {
+			panic("Invalid REQUIRE_ADMIN_2FA Environment Variable")
+		}
+		c.RequireAdmin2FA = b
+	}
 	// Sentry Configuration
 	// Sentry
 	if sentry := os.Getenv("SENTRY"); sentry != "" {
@@ -55,10 +143,35 @@ func (c *configuration) loadFromEnv() {
 }
 
 func (c *configuration) setDefaults() {
+	// Base URL
+	c.BaseURL = "http://localhost"
 	// Database
 	c.DB = "postgres"
+	c.DBAutoMigrate = false
+	// Payments
+	c.PaymentsCurrency = "usd"
+	c.StripeAPIURL = "https://api.stripe.com"
+	// Two-Factor Authentication
+	c.RequireAdmin2FA = false
 	// Sentry
 	c.Sentry = true
 	c.SentryDSN = "https://e7e4580a95ed8183cdf475d2fc826255@o4504687817261056.ingest.us.sentry.io/4506582744956928"
 	c.SentryTSR = 1.0
 }
+
+// loadOIDCProvider reads the OIDC_<NAME>_* settings for the named provider.
+func loadOIDCProvider(name string) OIDCProvider {
+	provider := OIDCProvider{Name: name}
+	prefix := "OIDC_" + strings.ToUpper(name) + "_"
+	for key, value := range map[string]*string{
+		"ISSUER":        &provider.Issuer,
+		"CLIENT_ID":     &provider.ClientID,
+		"CLIENT_SECRET": &provider.ClientSecret,
+	} {
+		*value = strings.TrimSpace(os.Getenv(prefix + key))
+		if *value == "" {
+			panic(fmt.Sprintf("Invalid %s%s Environment Variable", prefix, key))
+		}
+	}
+	return provider
+}
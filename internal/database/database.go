@@ -3,54 +3,198 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/Leagueify/api/internal/config"
 	"github.com/Leagueify/api/internal/database/postgres"
 	"github.com/Leagueify/api/internal/model"
-	"github.com/lib/pq"
 )
 
 type Database interface {
 	// account functions
-	ActivateAccount(accountID, apikey string) error
+	ActivateAccount(tx *sql.Tx, accountID string) error
+	ConsumeAccountVerification(tx *sql.Tx, accountID, tokenHash string) error
 	CreateAccount(account model.AccountCreation) error
-	GetAccountByAPIKey(apikey string) (model.Account, error)
+	CreateAccountVerification(verification model.AccountVerificationToken) error
+	DeleteAccount(tx *sql.Tx, accountID string) error
+	GetAccount(accountID string) (model.Account, error)
+	GetAccountByAPIKey(keyHash string) (model.Account, error)
 	GetAccountByEmail(email string) (model.Account, error)
 	GetTotalAccounts() (int, error)
-	SetAPIKey(apikey, accountID string) error
-	SetPlayerIDs(playerIDs *pq.StringArray, accountID string, tx *sql.Tx) error
-	SetRegistrationCode(tx *sql.Tx, code, accountID string) error
-	UnsetAPIKey(accountID string) error
+	GetTotalAdmins(tx *sql.Tx) (int, error)
+	ListAccounts(filter model.AccountFilter) ([]model.Account, error)
+	SetAccountAccess(tx *sql.Tx, accountID string, isActive, isAdmin bool) error
+	UpdateAccount(tx *sql.Tx, account model.Account) error
+	UpdatePassword(tx *sql.Tx, accountID, password string) error
+	// coupon functions
+	CouponUsedByAccount(tx *sql.Tx, couponID, accountID string) (bool, error)
+	CreateCoupon(coupon model.Coupon) error
+	DeleteCoupon(couponID string) error
+	GetCouponByCode(tx *sql.Tx, code string) (model.Coupon, error)
+	ListCoupons() ([]model.Coupon, error)
+	UpdateCoupon(coupon model.Coupon) error
 	// email functions
+	ClaimOutboundEmails(limit int, lease time.Duration) ([]model.OutboundEmail, error)
 	CreateEmailConfig(emailConfig model.EmailConfig) error
+	CreateOutboundEmail(email model.OutboundEmail) error
+	GetEmailConfig() (model.EmailConfig, error)
 	GetTotalEmailConfigs() (int, error)
+	MarkOutboundEmailFailed(emailID, lastError string, nextAttemptAt time.Time, permanent bool) error
+	MarkOutboundEmailSent(emailID string) error
+	SetEmailConfigError(emailConfigID string, hasError bool) error
+	// fee functions
+	CreateFeeSchedule(schedule model.FeeSchedule) error
+	DeleteFeeSchedule(seasonID, scheduleID string) error
+	ListFeeSchedules(seasonID string) ([]model.FeeSchedule, error)
+	UpdateFeeSchedule(schedule model.FeeSchedule) error
+	// financial aid functions
+	CreateFinancialAid(aid model.FinancialAid) (bool, error)
+	GetFinancialAid(aidID string) (model.FinancialAid, error)
+	ListFinancialAid(filter model.FinancialAidFilter) ([]model.FinancialAid, error)
+	ListRegistrationFinancialAid(registrationID string) ([]model.FinancialAid, error)
+	ReviewFinancialAid(tx *sql.Tx, aid model.FinancialAid) (bool, error)
+	// guardian functions
+	ConsumeGuardianInvite(tx *sql.Tx, tokenHash string) (model.GuardianInvite, error)
+	CreateGuardian(tx *sql.Tx, guardian model.Guardian) error
+	CreateGuardianInvite(invite model.GuardianInvite) error
+	DeleteGuardian(tx *sql.Tx, accountID, playerID string) error
+	GetGuardian(accountID, playerID string) (model.Guardian, error)
+	GetTotalPrimaryGuardians(tx *sql.Tx, playerID string) (int, error)
+	ListGuardians(playerID string) ([]model.Guardian, error)
+	ReleasePlayers(tx *sql.Tx, accountID string) error
+	// installment functions
+	CreateInstallmentPlan(plan model.InstallmentPlan) error
+	CreateInstallments(tx *sql.Tx, installments []model.Installment) error
+	DeleteInstallmentPlan(seasonID, planID string) error
+	GetInstallmentPlan(seasonID, planID string) (model.InstallmentPlan, error)
+	ListInstallmentPlans(seasonID string) ([]model.InstallmentPlan, error)
+	ListInstallments(registrationID string) ([]model.Installment, error)
+	ListOverdueInstallments(today string, remindedBefore time.Time) ([]model.InstallmentReminder, error)
+	MarkInstallmentsReminded(registrationID string, number int) error
+	UpdateInstallmentPlan(plan model.InstallmentPlan) error
 	// league functions
 	CreateLeague(league model.LeagueCreation) error
+	GetLeague() (model.League, error)
 	GetTotalLeagues() (int, error)
+	// lockout functions
+	CreateLockoutEvent(event model.LockoutEvent) error
+	GetFailedLoginsByIP(ipAddress string, since time.Time) (int, error)
+	GetLoginLockout(accountID string) (model.LoginLockout, error)
+	ListLockoutEvents(limit int) ([]model.LockoutEvent, error)
+	LockAccount(accountID string, until time.Time) error
+	RecordLoginAttempt(attempt model.LoginAttempt) error
+	RecordLoginFailure(accountID string) (int, error)
+	ResetLoginFailures(accountID string) error
+	// medical functions
+	CreateEmergencyContact(contact model.EmergencyContact) error
+	CreateMedicalAccessEvent(event model.MedicalAccessEvent) error
+	DeleteEmergencyContact(playerID, contactID string) error
+	GetMedicalRecord(playerID string) (model.MedicalRecord, error)
+	ListEmergencyContacts(playerID string) ([]model.EmergencyContact, error)
+	ListMedicalAccessEvents(playerID string, limit int) ([]model.MedicalAccessEvent, error)
+	SetMedicalRecord(record model.MedicalRecord) error
+	UnlockAccount(accountID string) error
+	// oidc functions
+	ConsumeOIDCSignup(tx *sql.Tx, tokenHash string) (model.OIDCSignup, error)
+	ConsumeOIDCState(stateHash, provider string) (model.OIDCState, error)
+	CreateOIDCAccount(tx *sql.Tx, account model.AccountCreation, identity model.OIDCIdentity) error
+	CreateOIDCIdentity(identity model.OIDCIdentity) error
+	CreateOIDCSignup(signup model.OIDCSignup) error
+	CreateOIDCState(state model.OIDCState) error
+	GetOIDCIdentity(provider, subject string) (model.OIDCIdentity, error)
+	// password reset functions
+	ConsumePasswordReset(tx *sql.Tx, tokenHash string) (string, error)
+	CreatePasswordReset(reset model.PasswordResetToken) error
+	// payment functions
+	CreatePayment(tx *sql.Tx, payment model.Payment) (bool, error)
+	GetPayment(paymentID string) (model.Payment, error)
+	GetPaymentByReference(provider, reference string) (model.Payment, error)
+	ListPayments(registrationID string) ([]model.Payment, error)
 	// player function
 	CreatePlayer(player model.Player, tx *sql.Tx) error
 	DeletePlayer(playerID string, tx *sql.Tx) error
 	GetPlayer(playerID string) (model.Player, error)
-	RegisterPlayer(tx *sql.Tx, playerID string) error
+	ListPlayers(accountID string) ([]model.Player, error)
+	SetPlayerDivision(playerID, division string) (bool, error)
+	UpdatePlayer(tx *sql.Tx, player model.Player) error
 	// position functions
 	CreatePositions(positions model.PositionCreation) error
 	GetAllPositions() ([]model.Position, error)
 	GetTotalPositions() (int, error)
 	// registration functions
+	AddRegistrationPlayer(tx *sql.Tx, player model.RegistrationPlayer) (bool, error)
 	CreateRegistration(tx *sql.Tx, registration model.Registration) error
-	GetRegistration(tx *sql.Tx, registrationID string) (pq.StringArray, error)
-	SetRegistration(tx *sql.Tx, playerIDs pq.StringArray, registrationID string) error
+	CreateRegistrationAdjustment(tx *sql.Tx, adjustment model.RegistrationAdjustment) (bool, error)
+	GetRegistration(registrationID string) (model.Registration, error)
+	GetRegistrationForUpdate(tx *sql.Tx, registrationID string) (model.Registration, error)
+	GetSeasonRegistration(tx *sql.Tx, accountID, seasonID string) (model.Registration, error)
+	ListAccountRegistrations(accountID string) ([]model.Registration, error)
+	ListRegistrationAdjustments(registrationID string) ([]model.RegistrationAdjustment, error)
+	ListRegistrationPlayers(registrationID string) ([]model.RegistrationPlayer, error)
+	ListRegistrations(filter model.RegistrationFilter) ([]model.Registration, error)
+	SetRegistrationAmountDue(tx *sql.Tx, registrationID string, amountDue int) error
+	WithdrawRegistrationPlayer(tx *sql.Tx, player model.RegistrationPlayer) (bool, error)
+	// role functions
+	GrantRole(accountID, role, grantedBy string) error
+	ListAccountRoles(accountID string) ([]model.AccountRole, error)
+	RevokeRole(accountID, role string) error
 	// season functions
 	CreateSeason(season model.Season) error
 	GetSeason(seasonID string) (model.Season, error)
 	ListSeasons() ([]model.SeasonList, error)
 	UpdateSeason(season model.Season) error
+	// session functions
+	CreateSession(session model.Session) error
+	DeleteSession(accountID, sessionID string) error
+	DeleteSessions(tx *sql.Tx, accountID string) error
+	ListSessions(accountID string) ([]model.Session, error)
 	// sport functions
 	GetSports() ([]model.Sport, error)
 	GetSportByID(sportID string) (model.Sport, error)
+	// team functions
+	AssignTeamCoach(team, accountID, assignedBy string) error
+	AssignTeamPlayer(team, playerID string) error
+	IsTeamCoach(accountID, team string) (bool, error)
+	ListTeamCoaches(team string) ([]model.TeamCoach, error)
+	RemoveTeamCoach(team, accountID string) error
+	RemoveTeamPlayer(team, playerID string) error
+	// two-factor functions
+	CreateLoginChallenge(challenge model.LoginChallenge) error
+	CreateTwoFactor(accountID, secret string) error
+	DeleteLoginChallenge(challengeID string) error
+	DeleteTwoFactor(tx *sql.Tx, accountID string) error
+	EnableTwoFactor(tx *sql.Tx, accountID string, counter int64) error
+	GetLoginChallenge(tokenHash string) (model.LoginChallenge, error)
+	GetTwoFactor(accountID string) (model.TwoFactor, error)
+	ReplaceRecoveryCodes(tx *sql.Tx, accountID string, codeHashes []string) error
+	UpdateTwoFactorCounter(accountID string, counter int64) error
+	UseRecoveryCode(accountID, codeHash string) error
+	// waitlist functions
+	AcceptWaitlistOffer(tx *sql.Tx, registrationID, playerID string) (bool, error)
+	DeclineWaitlistPlace(tx *sql.Tx, registrationID, playerID string) (bool, error)
+	GetDivisionCapacityForUpdate(tx *sql.Tx, seasonID, division string) (model.DivisionCapacity, error)
+	ListDivisionCapacities(seasonID string) ([]model.DivisionCapacity, error)
+	ListWaitlist(seasonID string, filter model.WaitlistFilter) ([]model.WaitlistEntry, error)
+	MoveWaitlistEntry(tx *sql.Tx, entry model.WaitlistEntry, position int) (bool, error)
+	OfferWaitlistPlaces(tx *sql.Tx, seasonID, division string) ([]model.WaitlistOffer, error)
+	PromoteWaitlistedPlayer(tx *sql.Tx, registrationID, playerID string) (bool, error)
+	RefreshWaitlists() ([]model.WaitlistOffer, error)
+	SetDivisionCapacity(tx *sql.Tx, capacity model.DivisionCapacity) error
+	// withdrawal functions
+	CreateWithdrawal(withdrawal model.Withdrawal) (bool, error)
+	GetRefundPolicy(seasonID string) (model.RefundPolicy, error)
+	GetWithdrawal(withdrawalID string) (model.Withdrawal, error)
+	ListRegistrationWithdrawals(registrationID string) ([]model.Withdrawal, error)
+	ListWithdrawals(filter model.WithdrawalFilter) ([]model.Withdrawal, error)
+	ReviewWithdrawal(tx *sql.Tx, withdrawal model.Withdrawal) (bool, error)
+	SetRefundPolicy(policy model.RefundPolicy) error
 	// database functions
 	BeginTransaction() (*sql.Tx, error)
-	InitializeDatabase() error
+	// migration functions
+	MigrateDown(steps int) (int, error)
+	MigrateUp() (int, error)
+	MigrationStatus() ([]model.Migration, error)
+	PendingMigrations() (int, error)
 }
 
 func GetDatabase() (Database, error) {
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS sports;
+DROP TABLE IF EXISTS seasons;
+DROP TABLE IF EXISTS registrations;
+DROP TABLE IF EXISTS positions;
+DROP TABLE IF EXISTS players;
+DROP TABLE IF EXISTS leagues;
+DROP TABLE IF EXISTS email;
+DROP TABLE IF EXISTS accounts;
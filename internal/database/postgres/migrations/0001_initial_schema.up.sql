@@ -0,0 +1,71 @@
+CREATE TABLE IF NOT EXISTS accounts (
+	id TEXT PRIMARY KEY,
+	first_name TEXT NOT NULL,
+	last_name TEXT NOT NULL,
+	email TEXT NOT NULL UNIQUE,
+	password TEXT NOT NULL,
+	phone TEXT NOT NULL UNIQUE,
+	date_of_birth TEXT NOT NULL,
+	registration_code TEXT NOT NULL,
+	player_ids TEXT[] NOT NULL,
+	coach BOOLEAN DEFAULT false,
+	volunteer BOOLEAN DEFAULT false,
+	apikey TEXT NOT NULL,
+	is_active BOOLEAN DEFAULT false,
+	is_admin BOOLEAN DEFAULT false
+);
+
+CREATE TABLE IF NOT EXISTS email (
+	id TEXT PRIMARY KEY,
+	email TEXT NOT NULL UNIQUE,
+	smtp_host TEXT NOT NULL,
+	smtp_port INTEGER NOT NULL,
+	smtp_user TEXT NOT NULL,
+	smtp_pass TEXT NOT NULL,
+	is_active BOOLEAN DEFAULT false,
+	has_error BOOLEAN DEFAULT true
+);
+
+CREATE TABLE IF NOT EXISTS leagues (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	sport_id TEXT NOT NULL,
+	master_admin TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS players (
+	id TEXT PRIMARY KEY,
+	first_name TEXT NOT NULL,
+	last_name TEXT NOT NULL,
+	date_of_birth TEXT NOT NULL,
+	position TEXT NOT NULL,
+	team TEXT NOT NULL,
+	division TEXT NOT NULL,
+	is_registered BOOLEAN DEFAULT false
+);
+
+CREATE TABLE IF NOT EXISTS positions (
+	id TEXT PRIMARY KEY,
+	name TEXT UNIQUE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS registrations (
+	id TEXT PRIMARY KEY,
+	player_ids TEXT[] NOT NULL,
+	amount_due INTEGER NOT NULL,
+	amount_paid INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS seasons (
+	id TEXT PRIMARY KEY,
+	name TEXT UNIQUE NOT NULL,
+	start_date TEXT NOT NULL,
+	end_date TEXT NOT NULL,
+	registration_opens TEXT NOT NULL,
+	registration_closes TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS sports (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL UNIQUE
+);
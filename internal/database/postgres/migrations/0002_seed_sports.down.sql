@@ -0,0 +1,2 @@
+DELETE FROM sports
+WHERE id IN ('BSB', 'BKB', 'FTB', 'HKY', 'QDC', 'RGB', 'SCR', 'SFB', 'VYB');
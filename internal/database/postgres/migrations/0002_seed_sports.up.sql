@@ -0,0 +1,12 @@
+-- sports keep any ID assigned by a previous boot; only missing names are added
+INSERT INTO sports (id, name) VALUES
+	('BSB', 'baseball'),
+	('BKB', 'basketball'),
+	('FTB', 'football'),
+	('HKY', 'hockey'),
+	('QDC', 'quidditch'),
+	('RGB', 'rugby'),
+	('SCR', 'soccer'),
+	('SFB', 'softball'),
+	('VYB', 'volleyball')
+ON CONFLICT DO NOTHING;
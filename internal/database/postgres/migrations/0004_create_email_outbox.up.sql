@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS email_outbox (
+	id TEXT PRIMARY KEY,
+	recipient TEXT NOT NULL,
+	subject TEXT NOT NULL,
+	text_body TEXT NOT NULL,
+	html_body TEXT NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	last_error TEXT NOT NULL DEFAULT '',
+	sent_at TIMESTAMPTZ,
+	failed_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS email_outbox_pending_idx
+	ON email_outbox (next_attempt_at)
+	WHERE sent_at IS NULL AND failed_at IS NULL;
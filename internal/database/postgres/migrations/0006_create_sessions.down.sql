@@ -0,0 +1,3 @@
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS apikey TEXT NOT NULL DEFAULT '';
+
+DROP TABLE IF EXISTS sessions;
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS account_roles (
+	account_id TEXT NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+	role TEXT NOT NULL,
+	granted_by TEXT NOT NULL DEFAULT '',
+	granted_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (account_id, role)
+);
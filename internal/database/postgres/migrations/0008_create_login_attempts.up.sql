@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS login_attempts (
+	id BIGSERIAL PRIMARY KEY,
+	account_id TEXT NOT NULL DEFAULT '',
+	email TEXT NOT NULL,
+	ip_address TEXT NOT NULL,
+	succeeded BOOLEAN NOT NULL,
+	attempted_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS login_attempts_ip_address_idx ON login_attempts (ip_address, attempted_at);
+
+CREATE TABLE IF NOT EXISTS account_lockouts (
+	account_id TEXT PRIMARY KEY REFERENCES accounts (id) ON DELETE CASCADE,
+	failed_attempts INTEGER NOT NULL DEFAULT 0,
+	locked_until TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS lockout_events (
+	id BIGSERIAL PRIMARY KEY,
+	account_id TEXT NOT NULL DEFAULT '',
+	ip_address TEXT NOT NULL DEFAULT '',
+	event TEXT NOT NULL,
+	actor TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS lockout_events_created_at_idx ON lockout_events (created_at);
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS two_factor (
+	account_id TEXT PRIMARY KEY REFERENCES accounts (id) ON DELETE CASCADE,
+	secret TEXT NOT NULL,
+	enabled BOOLEAN NOT NULL DEFAULT false,
+	last_counter BIGINT NOT NULL DEFAULT 0,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	enabled_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS recovery_codes (
+	account_id TEXT NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+	code_hash TEXT NOT NULL,
+	used_at TIMESTAMPTZ,
+	PRIMARY KEY (account_id, code_hash)
+);
+
+CREATE TABLE IF NOT EXISTS login_challenges (
+	id TEXT PRIMARY KEY,
+	account_id TEXT NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+	token_hash TEXT NOT NULL UNIQUE,
+	label TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	expires_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS oidc_identities (
+	provider TEXT NOT NULL,
+	subject TEXT NOT NULL,
+	account_id TEXT NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+	email TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (provider, subject)
+);
+
+CREATE INDEX IF NOT EXISTS oidc_identities_account_id_idx ON oidc_identities (account_id);
+
+CREATE TABLE IF NOT EXISTS oidc_states (
+	state_hash TEXT PRIMARY KEY,
+	provider TEXT NOT NULL,
+	nonce TEXT NOT NULL,
+	code_verifier TEXT NOT NULL,
+	label TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS oidc_signups (
+	token_hash TEXT PRIMARY KEY,
+	provider TEXT NOT NULL,
+	subject TEXT NOT NULL,
+	email TEXT NOT NULL,
+	email_verified BOOLEAN NOT NULL DEFAULT false,
+	first_name TEXT NOT NULL DEFAULT '',
+	last_name TEXT NOT NULL DEFAULT '',
+	phone TEXT NOT NULL DEFAULT '',
+	date_of_birth TEXT NOT NULL DEFAULT '',
+	label TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	expires_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS guardian_invites;
+
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS player_ids TEXT[] NOT NULL DEFAULT '{}';
+
+UPDATE accounts SET player_ids = ARRAY(
+	SELECT player_id FROM guardians
+	WHERE guardians.account_id = accounts.id ORDER BY created_at, player_id
+);
+
+DROP TABLE IF EXISTS guardians;
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS guardians (
+	account_id TEXT NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+	player_id TEXT NOT NULL REFERENCES players (id) ON DELETE CASCADE,
+	permission TEXT NOT NULL CHECK (permission IN ('primary', 'secondary', 'view')),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (account_id, player_id)
+);
+
+CREATE INDEX IF NOT EXISTS guardians_player_id_idx ON guardians (player_id);
+
+-- accounts become the primary guardian of the players they created
+INSERT INTO guardians (account_id, player_id, permission)
+SELECT accounts.id, players.id, 'primary'
+FROM accounts
+JOIN players ON players.id = ANY (accounts.player_ids)
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE accounts DROP COLUMN IF EXISTS player_ids;
+
+CREATE TABLE IF NOT EXISTS guardian_invites (
+	id TEXT PRIMARY KEY,
+	player_id TEXT NOT NULL REFERENCES players (id) ON DELETE CASCADE,
+	invited_by TEXT NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+	email TEXT NOT NULL,
+	permission TEXT NOT NULL CHECK (permission IN ('primary', 'secondary', 'view')),
+	token_hash TEXT NOT NULL UNIQUE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	expires_at TIMESTAMPTZ NOT NULL,
+	accepted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS guardian_invites_player_id_idx ON guardian_invites (player_id);
@@ -0,0 +1,6 @@
+ALTER TABLE players
+	DROP COLUMN IF EXISTS gender,
+	DROP COLUMN IF EXISTS jersey_size,
+	DROP COLUMN IF EXISTS jersey_number,
+	DROP COLUMN IF EXISTS school,
+	DROP COLUMN IF EXISTS grade;
@@ -0,0 +1,6 @@
+ALTER TABLE players
+	ADD COLUMN IF NOT EXISTS gender TEXT NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS jersey_size TEXT NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS jersey_number INTEGER,
+	ADD COLUMN IF NOT EXISTS school TEXT NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS grade TEXT NOT NULL DEFAULT '';
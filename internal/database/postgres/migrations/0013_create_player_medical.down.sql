@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS medical_access_events;
+DROP TABLE IF EXISTS medical_records;
+DROP TABLE IF EXISTS emergency_contacts;
+DROP TABLE IF EXISTS team_coaches;
@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS team_coaches (
+	team TEXT NOT NULL,
+	account_id TEXT NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+	assigned_by TEXT NOT NULL DEFAULT '',
+	assigned_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (team, account_id)
+);
+
+CREATE TABLE IF NOT EXISTS emergency_contacts (
+	id TEXT PRIMARY KEY,
+	player_id TEXT NOT NULL REFERENCES players (id) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	relationship TEXT NOT NULL,
+	phone TEXT NOT NULL,
+	email TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS emergency_contacts_player_id_idx ON emergency_contacts (player_id);
+
+-- medical information is stored encrypted with ENCRYPTION_KEY
+CREATE TABLE IF NOT EXISTS medical_records (
+	player_id TEXT PRIMARY KEY REFERENCES players (id) ON DELETE CASCADE,
+	data TEXT NOT NULL,
+	updated_by TEXT NOT NULL DEFAULT '',
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- access events outlive the players and accounts they refer to
+CREATE TABLE IF NOT EXISTS medical_access_events (
+	id BIGSERIAL PRIMARY KEY,
+	player_id TEXT NOT NULL,
+	account_id TEXT NOT NULL,
+	record TEXT NOT NULL,
+	ip_address TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS medical_access_events_player_id_idx ON medical_access_events (player_id, created_at);
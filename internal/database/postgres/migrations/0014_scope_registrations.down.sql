@@ -0,0 +1,31 @@
+ALTER TABLE players ADD COLUMN IF NOT EXISTS is_registered BOOLEAN DEFAULT false;
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS registration_code TEXT NOT NULL DEFAULT '';
+ALTER TABLE registrations ADD COLUMN IF NOT EXISTS player_ids TEXT[] NOT NULL DEFAULT '{}';
+
+UPDATE registrations SET player_ids = ARRAY(
+	SELECT player_id FROM registration_players
+	WHERE registration_players.registration_id = registrations.id
+	ORDER BY created_at, player_id
+);
+
+UPDATE players SET is_registered = true
+WHERE id IN (
+	SELECT player_id FROM registration_players
+	WHERE status IN ('pending', 'confirmed', 'waitlisted')
+);
+
+-- accounts keep their most recent registration
+UPDATE accounts SET registration_code = latest.id
+FROM (
+	SELECT DISTINCT ON (account_id) account_id, id FROM registrations
+	WHERE account_id IS NOT NULL
+	ORDER BY account_id, created_at DESC
+) AS latest
+WHERE latest.account_id = accounts.id;
+
+DROP TABLE IF EXISTS registration_players;
+DROP INDEX IF EXISTS registrations_account_season_idx;
+ALTER TABLE registrations
+	DROP COLUMN IF EXISTS account_id,
+	DROP COLUMN IF EXISTS season_id,
+	DROP COLUMN IF EXISTS created_at;
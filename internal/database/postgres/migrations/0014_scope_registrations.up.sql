@@ -0,0 +1,35 @@
+-- registrations belong to an account for a season, registrations made before
+-- seasons were tracked keep a NULL season
+ALTER TABLE registrations
+	ADD COLUMN IF NOT EXISTS account_id TEXT REFERENCES accounts (id) ON DELETE SET NULL,
+	ADD COLUMN IF NOT EXISTS season_id TEXT REFERENCES seasons (id),
+	ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+UPDATE registrations SET account_id = accounts.id
+FROM accounts WHERE accounts.registration_code = registrations.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS registrations_account_season_idx ON registrations (account_id, season_id);
+
+CREATE TABLE IF NOT EXISTS registration_players (
+	registration_id TEXT NOT NULL REFERENCES registrations (id) ON DELETE CASCADE,
+	player_id TEXT NOT NULL REFERENCES players (id) ON DELETE CASCADE,
+	season_id TEXT REFERENCES seasons (id),
+	status TEXT NOT NULL DEFAULT 'pending'
+		CHECK (status IN ('pending', 'confirmed', 'waitlisted', 'withdrawn', 'refunded')),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (registration_id, player_id)
+);
+
+-- a player is registered at most once per season
+CREATE UNIQUE INDEX IF NOT EXISTS registration_players_season_idx ON registration_players (player_id, season_id);
+
+INSERT INTO registration_players (registration_id, player_id, status)
+SELECT DISTINCT registrations.id, players.id, 'confirmed'
+FROM registrations
+JOIN players ON players.id = ANY (registrations.player_ids)
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE registrations DROP COLUMN IF EXISTS player_ids;
+ALTER TABLE accounts DROP COLUMN IF EXISTS registration_code;
+ALTER TABLE players DROP COLUMN IF EXISTS is_registered;
@@ -0,0 +1,2 @@
+ALTER TABLE registration_players DROP COLUMN IF EXISTS amount;
+DROP TABLE IF EXISTS fee_schedules;
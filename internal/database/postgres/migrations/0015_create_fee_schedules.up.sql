@@ -0,0 +1,20 @@
+-- fees are stored in cents, schedules with an empty division apply to players
+-- in divisions without a schedule of their own
+CREATE TABLE IF NOT EXISTS fee_schedules (
+	id TEXT PRIMARY KEY,
+	season_id TEXT NOT NULL REFERENCES seasons (id) ON DELETE CASCADE,
+	division TEXT NOT NULL DEFAULT '',
+	base_fee INTEGER NOT NULL CHECK (base_fee >= 0),
+	early_bird_fee INTEGER CHECK (early_bird_fee >= 0),
+	early_bird_ends TEXT NOT NULL DEFAULT '',
+	late_fee INTEGER NOT NULL DEFAULT 0 CHECK (late_fee >= 0),
+	late_fee_starts TEXT NOT NULL DEFAULT '',
+	sibling_discount INTEGER NOT NULL DEFAULT 0 CHECK (sibling_discount BETWEEN 0 AND 100),
+	family_max INTEGER NOT NULL DEFAULT 0 CHECK (family_max >= 0),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	UNIQUE (season_id, division)
+);
+
+-- the amount charged for each player, registrations.amount_due is their sum
+ALTER TABLE registration_players ADD COLUMN IF NOT EXISTS amount INTEGER NOT NULL DEFAULT 0;
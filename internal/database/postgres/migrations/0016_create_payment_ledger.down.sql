@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS payment_ledger;
+DROP FUNCTION IF EXISTS payment_ledger_immutable();
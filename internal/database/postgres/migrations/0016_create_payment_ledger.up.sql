@@ -0,0 +1,34 @@
+-- the ledger is append only, amounts are in cents and refunds reference the
+-- payment they return
+CREATE TABLE IF NOT EXISTS payment_ledger (
+	id TEXT PRIMARY KEY,
+	registration_id TEXT NOT NULL REFERENCES registrations (id),
+	kind TEXT NOT NULL CHECK (kind IN ('payment', 'refund')),
+	amount INTEGER NOT NULL CHECK (amount > 0),
+	provider TEXT NOT NULL,
+	reference TEXT NOT NULL,
+	payment_id TEXT REFERENCES payment_ledger (id),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	UNIQUE (provider, reference),
+	CHECK ((kind = 'refund') = (payment_id IS NOT NULL))
+);
+
+CREATE INDEX IF NOT EXISTS payment_ledger_registration_id_idx ON payment_ledger (registration_id);
+
+CREATE OR REPLACE FUNCTION payment_ledger_immutable() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'payment ledger entries cannot be changed';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER payment_ledger_immutable
+	BEFORE UPDATE OR DELETE ON payment_ledger
+	FOR EACH ROW EXECUTE FUNCTION payment_ledger_immutable();
+
+-- amounts paid before the ledger existed are carried over so amount_paid
+-- always matches the ledger
+INSERT INTO payment_ledger (id, registration_id, kind, amount, provider, reference)
+SELECT id, id, 'payment', amount_paid, 'legacy', id
+FROM registrations
+WHERE amount_paid > 0
+ON CONFLICT DO NOTHING;
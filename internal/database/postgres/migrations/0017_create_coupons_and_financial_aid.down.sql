@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS registration_adjustments;
+DROP TABLE IF EXISTS financial_aid;
+DROP TABLE IF EXISTS coupons;
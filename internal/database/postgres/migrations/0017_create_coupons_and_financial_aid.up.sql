@@ -0,0 +1,49 @@
+-- coupon codes are stored upper case, percent coupons take value percent off
+-- and fixed coupons take value cents off
+CREATE TABLE IF NOT EXISTS coupons (
+	id TEXT PRIMARY KEY,
+	code TEXT NOT NULL UNIQUE,
+	kind TEXT NOT NULL CHECK (kind IN ('percent', 'fixed')),
+	value INTEGER NOT NULL CHECK (value > 0),
+	season_id TEXT REFERENCES seasons (id) ON DELETE CASCADE,
+	max_uses INTEGER NOT NULL DEFAULT 0 CHECK (max_uses >= 0),
+	once_per_family BOOLEAN NOT NULL DEFAULT false,
+	expires_on TEXT NOT NULL DEFAULT '',
+	disabled BOOLEAN NOT NULL DEFAULT false,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	CHECK (kind = 'fixed' OR value <= 100)
+);
+
+CREATE TABLE IF NOT EXISTS financial_aid (
+	id TEXT PRIMARY KEY,
+	registration_id TEXT NOT NULL REFERENCES registrations (id) ON DELETE CASCADE,
+	requested_amount INTEGER NOT NULL CHECK (requested_amount > 0),
+	reason TEXT NOT NULL,
+	status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'denied')),
+	awarded_amount INTEGER NOT NULL DEFAULT 0 CHECK (awarded_amount >= 0),
+	note TEXT NOT NULL DEFAULT '',
+	reviewed_by TEXT NOT NULL DEFAULT '',
+	reviewed_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- a registration has at most one application awaiting review
+CREATE UNIQUE INDEX IF NOT EXISTS financial_aid_pending_idx ON financial_aid (registration_id) WHERE status = 'pending';
+
+-- coupons and financial aid taken off a registration, amount_due is the sum of
+-- its players' amounts less these adjustments
+CREATE TABLE IF NOT EXISTS registration_adjustments (
+	id TEXT PRIMARY KEY,
+	registration_id TEXT NOT NULL REFERENCES registrations (id) ON DELETE CASCADE,
+	kind TEXT NOT NULL CHECK (kind IN ('coupon', 'aid')),
+	coupon_id TEXT REFERENCES coupons (id) ON DELETE SET NULL,
+	financial_aid_id TEXT REFERENCES financial_aid (id) ON DELETE CASCADE,
+	description TEXT NOT NULL,
+	amount INTEGER NOT NULL CHECK (amount > 0),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	UNIQUE (registration_id, coupon_id)
+);
+
+CREATE INDEX IF NOT EXISTS registration_adjustments_coupon_id_idx ON registration_adjustments (coupon_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS registration_installments;
+DROP TABLE IF EXISTS installment_plans;
@@ -0,0 +1,30 @@
+-- installment plans split a registration's amount due into equal payments
+-- interval_days apart, the first due the day the registration joins the plan
+CREATE TABLE IF NOT EXISTS installment_plans (
+	id TEXT PRIMARY KEY,
+	season_id TEXT NOT NULL REFERENCES seasons (id) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	installments INTEGER NOT NULL CHECK (installments BETWEEN 2 AND 12),
+	interval_days INTEGER NOT NULL CHECK (interval_days BETWEEN 1 AND 365),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	UNIQUE (season_id, name)
+);
+
+-- the due dates of a registration on a plan, copied from the plan so later
+-- edits to it leave the schedule alone. Amounts are not stored: installment n
+-- of a schedule of count is paid once amount_paid covers
+-- amount_due * n / count
+CREATE TABLE IF NOT EXISTS registration_installments (
+	id TEXT PRIMARY KEY,
+	registration_id TEXT NOT NULL REFERENCES registrations (id) ON DELETE CASCADE,
+	plan_id TEXT REFERENCES installment_plans (id) ON DELETE SET NULL,
+	number INTEGER NOT NULL CHECK (number >= 1),
+	due_on TEXT NOT NULL,
+	overdue_at TIMESTAMPTZ,
+	reminded_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	UNIQUE (registration_id, number)
+);
+
+CREATE INDEX IF NOT EXISTS registration_installments_due_on_idx ON registration_installments (due_on);
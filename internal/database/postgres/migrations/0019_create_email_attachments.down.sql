@@ -0,0 +1 @@
+DROP TABLE IF EXISTS email_outbox_attachments;
@@ -0,0 +1,9 @@
+-- files sent with an outbound email, in the order they are attached
+CREATE TABLE IF NOT EXISTS email_outbox_attachments (
+	email_id TEXT NOT NULL REFERENCES email_outbox (id) ON DELETE CASCADE,
+	position INTEGER NOT NULL,
+	filename TEXT NOT NULL,
+	content_type TEXT NOT NULL,
+	content BYTEA NOT NULL,
+	PRIMARY KEY (email_id, position)
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS withdrawals;
+ALTER TABLE registration_players DROP COLUMN IF EXISTS retained;
+DROP TABLE IF EXISTS refund_policies;
@@ -0,0 +1,37 @@
+-- players withdrawn on or before full_refund_until are refunded their whole
+-- fee, on or before partial_refund_until partial_refund_percent of it, and
+-- after that nothing. Dates are YYYY-MM-DD, seasons without a policy refund
+-- nothing
+CREATE TABLE IF NOT EXISTS refund_policies (
+	season_id TEXT PRIMARY KEY REFERENCES seasons (id) ON DELETE CASCADE,
+	full_refund_until TEXT NOT NULL DEFAULT '',
+	partial_refund_until TEXT NOT NULL DEFAULT '',
+	partial_refund_percent INTEGER NOT NULL DEFAULT 0 CHECK (partial_refund_percent BETWEEN 0 AND 100),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- the part of a withdrawn player's fee kept under the refund policy, it stays
+-- in the registration's amount due
+ALTER TABLE registration_players ADD COLUMN IF NOT EXISTS retained INTEGER NOT NULL DEFAULT 0 CHECK (retained >= 0);
+
+-- refund_amount is the part of the player's fee refunded, refunded is how much
+-- of it was returned through the payment provider. The rest is taken off what
+-- the registration still owes
+CREATE TABLE IF NOT EXISTS withdrawals (
+	id TEXT PRIMARY KEY,
+	registration_id TEXT NOT NULL REFERENCES registrations (id) ON DELETE CASCADE,
+	player_id TEXT NOT NULL REFERENCES players (id) ON DELETE CASCADE,
+	reason TEXT NOT NULL DEFAULT '',
+	status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'denied')),
+	refund_amount INTEGER NOT NULL CHECK (refund_amount >= 0),
+	refunded INTEGER NOT NULL DEFAULT 0 CHECK (refunded >= 0),
+	note TEXT NOT NULL DEFAULT '',
+	reviewed_by TEXT NOT NULL DEFAULT '',
+	reviewed_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- a player has at most one withdrawal awaiting review
+CREATE UNIQUE INDEX IF NOT EXISTS withdrawals_pending_idx ON withdrawals (registration_id, player_id) WHERE status = 'pending';
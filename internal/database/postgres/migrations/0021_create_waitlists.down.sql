@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS registration_players_waitlist_idx;
+ALTER TABLE registration_players DROP COLUMN IF EXISTS offer_expires_at;
+ALTER TABLE registration_players DROP COLUMN IF EXISTS waitlist_position;
+DROP SEQUENCE IF EXISTS waitlist_positions;
+DROP TABLE IF EXISTS division_capacities;
@@ -0,0 +1,34 @@
+-- capacity is how many players a division of a season holds, players
+-- registered once it is full are waitlisted. offer_hours is how long a
+-- waitlisted family has to accept a place that opens up. Divisions without a
+-- capacity are unlimited
+CREATE TABLE IF NOT EXISTS division_capacities (
+	season_id TEXT NOT NULL REFERENCES seasons (id) ON DELETE CASCADE,
+	division TEXT NOT NULL,
+	capacity INTEGER NOT NULL CHECK (capacity >= 0),
+	offer_hours INTEGER NOT NULL DEFAULT 48 CHECK (offer_hours > 0),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (season_id, division)
+);
+
+-- waitlisted players are offered places in waitlist_position order, drawn from
+-- a sequence so players waitlisted later always queue behind those before
+-- them. An offer holds a place until offer_expires_at
+CREATE SEQUENCE IF NOT EXISTS waitlist_positions;
+ALTER TABLE registration_players ADD COLUMN IF NOT EXISTS waitlist_position BIGINT;
+ALTER TABLE registration_players ADD COLUMN IF NOT EXISTS offer_expires_at TIMESTAMPTZ;
+-- players waitlisted before now queue in the order they registered
+UPDATE registration_players SET waitlist_position = queued.position
+FROM (
+	SELECT registration_id, player_id, nextval('waitlist_positions') AS position
+	FROM (
+		SELECT registration_id, player_id FROM registration_players
+		WHERE status = 'waitlisted' AND waitlist_position IS NULL
+		ORDER BY created_at, player_id
+	) waiting
+) queued
+WHERE registration_players.registration_id = queued.registration_id
+AND registration_players.player_id = queued.player_id;
+
+CREATE INDEX IF NOT EXISTS registration_players_waitlist_idx ON registration_players (season_id, waitlist_position) WHERE status = 'waitlisted';
@@ -3,17 +3,26 @@ package postgres
 import (
 	"database/sql"
 	"errors"
-	"fmt"
 
 	"github.com/Leagueify/api/internal/model"
-	"github.com/lib/pq"
 )
 
-func (p Postgres) ActivateAccount(accountID, apikey string) error {
-	results, err := p.DB.Exec(`
-		UPDATE accounts SET apikey = $1, is_active = true
-		WHERE id = $2 AND is_active = false
-	`, apikey[:len(apikey)-1], accountID[:len(accountID)-1])
+// accountColumns selects an account in the order model.Account is scanned.
+// Players are the players the account is a guardian of.
+const accountColumns = `
+	accounts.id, accounts.first_name, accounts.last_name, accounts.email,
+	accounts.password, accounts.phone, accounts.date_of_birth, ARRAY(
+		SELECT player_id FROM guardians
+		WHERE guardians.account_id = accounts.id
+		ORDER BY guardians.created_at, guardians.player_id
+	), accounts.coach, accounts.volunteer, accounts.is_active,
+	accounts.is_admin`
+
+func (p Postgres) ActivateAccount(tx *sql.Tx, accountID string) error {
+	results, err := tx.Exec(`
+		UPDATE accounts SET is_active = true
+		WHERE id = $1 AND is_active = false
+	`, accountID)
 	if err != nil {
 		return err
 	}
@@ -30,32 +39,48 @@ func (p Postgres) ActivateAccount(accountID, apikey string) error {
 }
 
 func (p Postgres) CreateAccount(account model.AccountCreation) error {
-	if _, err := p.DB.Exec(`
-		INSERT INTO accounts (
-			id, first_name, last_name, email, password, phone,
-			date_of_birth, registration_code, player_ids, coach,
-			volunteer, apikey, is_active, is_admin
-		)
-		VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
-		)`,
-		account.ID[:len(account.ID)-1], account.FirstName,
-		account.LastName, account.Email, account.Password,
-		account.Phone, account.DateOfBirth, "", "{}", account.Coach,
-		account.Volunteer, "", account.IsActive, account.IsAdmin,
-	); err != nil {
+	return insertAccount(p.DB, account)
+}
+
+// DeleteAccount removes the account. Sessions, roles and outstanding tokens
+// are removed by their foreign keys.
+func (p Postgres) DeleteAccount(tx *sql.Tx, accountID string) error {
+	results, err := tx.Exec(`
+		DELETE FROM accounts WHERE id = $1
+	`, accountID)
+	if err != nil {
 		return err
 	}
 
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.New("Account not found")
+	}
+
 	return nil
 }
 
-func (p Postgres) GetAccountByAPIKey(apikey string) (model.Account, error) {
+// GetAccountByAPIKey returns the account owning an unexpired session key and
+// records the session as used. SessionID is set to the matched session and
+// Roles to the roles granted to the account.
+func (p Postgres) GetAccountByAPIKey(keyHash string) (model.Account, error) {
 	account := model.Account{}
 
 	if err := p.DB.QueryRow(`
-		SELECT * FROM accounts WHERE apikey = $1
-	`, apikey[:len(apikey)-1]).Scan(
+		WITH session AS (
+			UPDATE sessions SET last_used_at = now()
+			WHERE key_hash = $1 AND expires_at > now()
+			RETURNING id, account_id
+		)
+		SELECT `+accountColumns+`, session.id, ARRAY(
+			SELECT role FROM account_roles
+			WHERE account_id = accounts.id ORDER BY role
+		) FROM accounts
+		JOIN session ON session.account_id = accounts.id
+	`, keyHash).Scan(
 		&account.ID,
 		&account.FirstName,
 		&account.LastName,
@@ -63,13 +88,42 @@ func (p Postgres) GetAccountByAPIKey(apikey string) (model.Account, error) {
 		&account.Password,
 		&account.Phone,
 		&account.DateOfBirth,
-		&account.RegistrationCode,
 		&account.Players,
 		&account.Coach,
 		&account.Volunteer,
-		&account.APIKey,
 		&account.IsActive,
 		&account.IsAdmin,
+		&account.SessionID,
+		&account.Roles,
+	); err != nil {
+		return account, err
+	}
+
+	return account, nil
+}
+
+func (p Postgres) GetAccount(accountID string) (model.Account, error) {
+	account := model.Account{}
+
+	if err := p.DB.QueryRow(`
+		SELECT `+accountColumns+`, ARRAY(
+			SELECT role FROM account_roles
+			WHERE account_id = accounts.id ORDER BY role
+		) FROM accounts WHERE id = $1
+	`, accountID).Scan(
+		&account.ID,
+		&account.FirstName,
+		&account.LastName,
+		&account.Email,
+		&account.Password,
+		&account.Phone,
+		&account.DateOfBirth,
+		&account.Players,
+		&account.Coach,
+		&account.Volunteer,
+		&account.IsActive,
+		&account.IsAdmin,
+		&account.Roles,
 	); err != nil {
 		return account, err
 	}
@@ -81,7 +135,7 @@ func (p Postgres) GetAccountByEmail(email string) (model.Account, error) {
 	account := model.Account{}
 
 	if err := p.DB.QueryRow(`
-		SELECT * FROM accounts WHERE email = $1
+		SELECT `+accountColumns+` FROM accounts WHERE email = $1
 	`, email).Scan(
 		&account.ID,
 		&account.FirstName,
@@ -90,11 +144,9 @@ func (p Postgres) GetAccountByEmail(email string) (model.Account, error) {
 		&account.Password,
 		&account.Phone,
 		&account.DateOfBirth,
-		&account.RegistrationCode,
 		&account.Players,
 		&account.Coach,
 		&account.Volunteer,
-		&account.APIKey,
 		&account.IsActive,
 		&account.IsAdmin,
 	); err != nil {
@@ -115,39 +167,160 @@ func (p Postgres) GetTotalAccounts() (int, error) {
 	return totalAccounts, nil
 }
 
-func (p Postgres) SetAPIKey(apikey, accountID string) error {
-	if _, err := p.DB.Exec(`
-		UPDATE accounts SET apikey = $1 WHERE id = $2
-	`, apikey[:len(apikey)-1], accountID); err != nil {
-		fmt.Println(err)
+// GetTotalAdmins returns the number of active administrators, locking their
+// rows so concurrent demotions cannot remove the last administrator.
+func (p Postgres) GetTotalAdmins(tx *sql.Tx) (int, error) {
+	var totalAdmins int
+
+	row := tx.QueryRow(`
+		SELECT COUNT(*) FROM (
+			SELECT id FROM accounts
+			WHERE is_admin = true AND is_active = true FOR UPDATE
+		) AS admins
+	`)
+	if err := row.Scan(&totalAdmins); err != nil {
+		return 0, err
+	}
+
+	return totalAdmins, nil
+}
+
+func (p Postgres) ListAccounts(filter model.AccountFilter) ([]model.Account, error) {
+	accounts := []model.Account{}
+
+	rows, err := p.DB.Query(`
+		SELECT `+accountColumns+`, ARRAY(
+			SELECT role FROM account_roles
+			WHERE account_id = accounts.id ORDER BY role
+		) FROM accounts
+		WHERE ($1::boolean IS NULL OR is_active = $1)
+		AND ($2::boolean IS NULL OR is_admin = $2)
+		AND ($3::boolean IS NULL OR coach = $3)
+		AND ($4::boolean IS NULL OR volunteer = $4)
+		AND (
+			$5 = '' OR first_name ILIKE '%' || $5 || '%'
+			OR last_name ILIKE '%' || $5 || '%' OR email ILIKE '%' || $5 || '%'
+		)
+		ORDER BY last_name, first_name, id
+		LIMIT $6 OFFSET $7
+	`,
+		filter.IsActive, filter.IsAdmin, filter.Coach, filter.Volunteer,
+		filter.Search, filter.Limit, filter.Offset,
+	)
+	if err != nil {
+		return accounts, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var account model.Account
+		if err := rows.Scan(
+			&account.ID,
+			&account.FirstName,
+			&account.LastName,
+			&account.Email,
+			&account.Password,
+			&account.Phone,
+			&account.DateOfBirth,
+			&account.Players,
+			&account.Coach,
+			&account.Volunteer,
+			&account.IsActive,
+			&account.IsAdmin,
+			&account.Roles,
+		); err != nil {
+			return accounts, err
+		}
+		accounts = append(accounts, account)
+	}
+
+	return accounts, rows.Err()
+}
+
+func (p Postgres) SetAccountAccess(tx *sql.Tx, accountID string, isActive, isAdmin bool) error {
+	results, err := tx.Exec(`
+		UPDATE accounts SET is_active = $1, is_admin = $2 WHERE id = $3
+	`, isActive, isAdmin, accountID)
+	if err != nil {
 		return err
 	}
 
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.New("Account not found")
+	}
+
 	return nil
 }
 
-func (p Postgres) SetPlayerIDs(playerIDs *pq.StringArray, accountID string, tx *sql.Tx) error {
-	if _, err := tx.Exec(`
-		UPDATE accounts SET player_ids = $1 WHERE id = $2
-	`, playerIDs, accountID); err != nil {
+func (p Postgres) UpdateAccount(tx *sql.Tx, account model.Account) error {
+	results, err := tx.Exec(`
+		UPDATE accounts SET
+			first_name = $1, last_name = $2, email = $3, phone = $4,
+			coach = $5, volunteer = $6, is_active = $7
+		WHERE id = $8
+	`,
+		account.FirstName, account.LastName, account.Email, account.Phone,
+		account.Coach, account.Volunteer, account.IsActive, account.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
 		return err
 	}
+	if rows != 1 {
+		return errors.New("Account update failed")
+	}
+
 	return nil
 }
 
-func (p Postgres) SetRegistrationCode(tx *sql.Tx, code, accountID string) error {
+func (p Postgres) UpdatePassword(tx *sql.Tx, accountID, password string) error {
+	results, err := tx.Exec(`
+		UPDATE accounts SET password = $1 WHERE id = $2
+	`, password, accountID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.New("Account update failed")
+	}
+
+	// a new password logs out every session
 	if _, err := tx.Exec(`
-		UPDATE accounts SET registration_code = $1 WHERE id = $2
-	`, code, accountID); err != nil {
+		DELETE FROM sessions WHERE account_id = $1
+	`, accountID); err != nil {
 		return err
 	}
+
 	return nil
 }
 
-func (p Postgres) UnsetAPIKey(accountID string) error {
-	if _, err := p.DB.Exec(`
-		UPDATE accounts SET apikey = "" WHERE id = $1
-	`, accountID); err != nil {
+// insertAccount creates the account through db, which may be a transaction.
+func insertAccount(db execer, account model.AccountCreation) error {
+	if _, err := db.Exec(`
+		INSERT INTO accounts (
+			id, first_name, last_name, email, password, phone,
+			date_of_birth, coach, volunteer, is_active, is_admin
+		)
+		VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+		)`,
+		account.ID[:len(account.ID)-1], account.FirstName,
+		account.LastName, account.Email, account.Password,
+		account.Phone, account.DateOfBirth, account.Coach,
+		account.Volunteer, account.IsActive, account.IsAdmin,
+	); err != nil {
 		return err
 	}
 
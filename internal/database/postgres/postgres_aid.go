@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"database/sql"
+
+	"github.com/Leagueify/api/internal/model"
+)
+
+const financialAidColumns = `
+	id, registration_id, requested_amount, reason, status, awarded_amount,
+	note, reviewed_by, reviewed_at, created_at
+`
+
+// CreateFinancialAid records an application. A registration may only have one
+// application awaiting review, so false is returned when it already has one.
+func (p Postgres) CreateFinancialAid(aid model.FinancialAid) (bool, error) {
+	results, err := p.DB.Exec(`
+		INSERT INTO financial_aid (
+			id, registration_id, requested_amount, reason
+		)
+		VALUES (
+			$1, $2, $3, $4
+		)
+		ON CONFLICT (registration_id) WHERE status = 'pending' DO NOTHING
+	`, aid.ID, aid.RegistrationID, aid.RequestedAmount, aid.Reason)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
+
+func (p Postgres) GetFinancialAid(aidID string) (model.FinancialAid, error) {
+	var aid model.FinancialAid
+
+	if err := scanFinancialAid(p.DB.QueryRow(`
+		SELECT `+financialAidColumns+` FROM financial_aid WHERE id = $1
+	`, aidID), &aid); err != nil {
+		return aid, err
+	}
+
+	return aid, nil
+}
+
+// ListFinancialAid returns applications with the status, or every application
+// when no status is given, oldest first.
+func (p Postgres) ListFinancialAid(filter model.FinancialAidFilter) ([]model.FinancialAid, error) {
+	return p.listFinancialAid(`
+		SELECT `+financialAidColumns+` FROM financial_aid
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at, id
+	`, filter.Status)
+}
+
+// ListRegistrationFinancialAid returns the registration's applications,
+// oldest first.
+func (p Postgres) ListRegistrationFinancialAid(registrationID string) ([]model.FinancialAid, error) {
+	return p.listFinancialAid(`
+		SELECT `+financialAidColumns+` FROM financial_aid
+		WHERE registration_id = $1
+		ORDER BY created_at, id
+	`, registrationID)
+}
+
+// ReviewFinancialAid records the decision on an application. Applications are
+// only reviewed once, so false is returned when it is no longer pending.
+func (p Postgres) ReviewFinancialAid(tx *sql.Tx, aid model.FinancialAid) (bool, error) {
+	results, err := tx.Exec(`
+		UPDATE financial_aid SET
+			status = $1, awarded_amount = $2, note = $3, reviewed_by = $4,
+			reviewed_at = now(), updated_at = now()
+		WHERE id = $5 AND status = 'pending'
+	`, aid.Status, aid.AwardedAmount, aid.Note, aid.ReviewedBy, aid.ID)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
+
+func (p Postgres) listFinancialAid(query string, args ...any) ([]model.FinancialAid, error) {
+	applications := []model.FinancialAid{}
+
+	rows, err := p.DB.Query(query, args...)
+	if err != nil {
+		return applications, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var aid model.FinancialAid
+		if err := scanFinancialAid(rows, &aid); err != nil {
+			return applications, err
+		}
+		applications = append(applications, aid)
+	}
+
+	return applications, rows.Err()
+}
+
+func scanFinancialAid(row scanner, aid *model.FinancialAid) error {
+	return row.Scan(
+		&aid.ID,
+		&aid.RegistrationID,
+		&aid.RequestedAmount,
+		&aid.Reason,
+		&aid.Status,
+		&aid.AwardedAmount,
+		&aid.Note,
+		&aid.ReviewedBy,
+		&aid.ReviewedAt,
+		&aid.CreatedAt,
+	)
+}
@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/Leagueify/api/internal/model"
+)
+
+const couponColumns = `
+	coupons.id, coupons.code, coupons.kind, coupons.value,
+	COALESCE(coupons.season_id, ''), coupons.max_uses, coupons.once_per_family,
+	coupons.expires_on, coupons.disabled, (
+		SELECT count(*) FROM registration_adjustments
+		WHERE registration_adjustments.coupon_id = coupons.id
+	) AS uses, coupons.created_at
+`
+
+// CouponUsedByAccount reports whether the coupon has been applied to any of
+// the account's registrations.
+func (p Postgres) CouponUsedByAccount(tx *sql.Tx, couponID, accountID string) (bool, error) {
+	var used bool
+
+	if err := tx.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM registration_adjustments
+			JOIN registrations ON registrations.id = registration_adjustments.registration_id
+			WHERE registration_adjustments.coupon_id = $1
+			AND registrations.account_id = $2
+		)
+	`, couponID, accountID).Scan(&used); err != nil {
+		return false, err
+	}
+
+	return used, nil
+}
+
+func (p Postgres) CreateCoupon(coupon model.Coupon) error {
+	if _, err := p.DB.Exec(`
+		INSERT INTO coupons (
+			id, code, kind, value, season_id, max_uses, once_per_family,
+			expires_on, disabled
+		)
+		VALUES (
+			$1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, $9
+		)`,
+		coupon.ID, coupon.Code, coupon.Kind, coupon.Value, coupon.SeasonID,
+		coupon.MaxUses, coupon.OncePerFamily, coupon.ExpiresOn, coupon.Disabled,
+	); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (p Postgres) DeleteCoupon(couponID string) error {
+	results, err := p.DB.Exec(`
+		DELETE FROM coupons WHERE id = $1
+	`, couponID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.New("Coupon deletion failed")
+	}
+
+	return nil
+}
+
+// GetCouponByCode returns the coupon with the code, locking it until the
+// transaction ends so concurrent redemptions cannot exceed its uses.
+func (p Postgres) GetCouponByCode(tx *sql.Tx, code string) (model.Coupon, error) {
+	var coupon model.Coupon
+
+	// the lock is taken before counting so the uses include the redemptions
+	// made by whoever held it before
+	if err := tx.QueryRow(`
+		SELECT id FROM coupons WHERE code = $1
+		FOR UPDATE
+	`, code).Scan(&coupon.ID); err != nil {
+		return coupon, err
+	}
+
+	if err := scanCoupon(tx.QueryRow(`
+		SELECT `+couponColumns+` FROM coupons WHERE id = $1
+	`, coupon.ID), &coupon); err != nil {
+		return coupon, err
+	}
+
+	return coupon, nil
+}
+
+func (p Postgres) ListCoupons() ([]model.Coupon, error) {
+	coupons := []model.Coupon{}
+
+	rows, err := p.DB.Query(`
+		SELECT ` + couponColumns + ` FROM coupons ORDER BY code
+	`)
+	if err != nil {
+		return coupons, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var coupon model.Coupon
+		if err := scanCoupon(rows, &coupon); err != nil {
+			return coupons, err
+		}
+		coupons = append(coupons, coupon)
+	}
+
+	return coupons, rows.Err()
+}
+
+func (p Postgres) UpdateCoupon(coupon model.Coupon) error {
+	results, err := p.DB.Exec(`
+		UPDATE coupons SET
+			code = $1, kind = $2, value = $3, season_id = NULLIF($4, ''),
+			max_uses = $5, once_per_family = $6, expires_on = $7, disabled = $8,
+			updated_at = now()
+		WHERE id = $9
+	`,
+		coupon.Code, coupon.Kind, coupon.Value, coupon.SeasonID, coupon.MaxUses,
+		coupon.OncePerFamily, coupon.ExpiresOn, coupon.Disabled, coupon.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.New("Coupon update failed")
+	}
+
+	return nil
+}
+
+func scanCoupon(row scanner, coupon *model.Coupon) error {
+	return row.Scan(
+		&coupon.ID,
+		&coupon.Code,
+		&coupon.Kind,
+		&coupon.Value,
+		&coupon.SeasonID,
+		&coupon.MaxUses,
+		&coupon.OncePerFamily,
+		&coupon.ExpiresOn,
+		&coupon.Disabled,
+		&coupon.Uses,
+		&coupon.CreatedAt,
+	)
+}
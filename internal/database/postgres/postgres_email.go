@@ -1,6 +1,56 @@
 package postgres
 
-import "github.com/Leagueify/api/internal/model"
+import (
+	"fmt"
+	"time"
+
+	"github.com/Leagueify/api/internal/model"
+	"github.com/lib/pq"
+)
+
+func (p Postgres) ClaimOutboundEmails(limit int, lease time.Duration) ([]model.OutboundEmail, error) {
+	emails := []model.OutboundEmail{}
+
+	// leased emails become claimable again if the sender never reports back
+	rows, err := p.DB.Query(`
+		UPDATE email_outbox SET next_attempt_at = now() + $2::interval
+		WHERE id IN (
+			SELECT id FROM email_outbox
+			WHERE sent_at IS NULL AND failed_at IS NULL
+				AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, recipient, subject, text_body, html_body, attempts,
+			next_attempt_at, last_error
+	`, limit, fmt.Sprintf("%d milliseconds", lease.Milliseconds()))
+	if err != nil {
+		return emails, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var email model.OutboundEmail
+		if err := rows.Scan(
+			&email.ID,
+			&email.Recipient,
+			&email.Subject,
+			&email.TextBody,
+			&email.HTMLBody,
+			&email.Attempts,
+			&email.NextAttemptAt,
+			&email.LastError,
+		); err != nil {
+			return emails, err
+		}
+		emails = append(emails, email)
+	}
+	if err := rows.Err(); err != nil {
+		return emails, err
+	}
+
+	return emails, p.loadEmailAttachments(emails)
+}
 
 func (p Postgres) CreateEmailConfig(emailConfig model.EmailConfig) error {
 	if _, err := p.DB.Exec(`
@@ -30,3 +80,143 @@ func (p Postgres) GetTotalEmailConfigs() (int, error) {
 
 	return totalEmailConfigs, nil
 }
+
+func (p Postgres) GetEmailConfig() (model.EmailConfig, error) {
+	var emailConfig model.EmailConfig
+
+	if err := p.DB.QueryRow(`
+		SELECT * FROM email WHERE is_active = true LIMIT 1
+	`).Scan(
+		&emailConfig.ID,
+		&emailConfig.Email,
+		&emailConfig.SMTPHost,
+		&emailConfig.SMTPPort,
+		&emailConfig.SMTPUser,
+		&emailConfig.SMTPPass,
+		&emailConfig.IsEnabled,
+		&emailConfig.HasError,
+	); err != nil {
+		return emailConfig, err
+	}
+
+	return emailConfig, nil
+}
+
+// CreateOutboundEmail queues an email for delivery. Emails with attachments
+// are queued with them in a single transaction so they are never delivered
+// without them.
+func (p Postgres) CreateOutboundEmail(email model.OutboundEmail) error {
+	if len(email.Attachments) == 0 {
+		return insertOutboundEmail(p.DB, email)
+	}
+	tx, err := p.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := insertOutboundEmail(tx, email); err != nil {
+		return err
+	}
+	for position, attachment := range email.Attachments {
+		if _, err := tx.Exec(`
+			INSERT INTO email_outbox_attachments (
+				email_id, position, filename, content_type, content
+			)
+			VALUES (
+				$1, $2, $3, $4, $5
+			)
+			`, email.ID[:len(email.ID)-1], position, attachment.Filename,
+			attachment.ContentType, attachment.Content,
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (p Postgres) MarkOutboundEmailFailed(emailID, lastError string, nextAttemptAt time.Time, permanent bool) error {
+	if _, err := p.DB.Exec(`
+		UPDATE email_outbox
+		SET attempts = attempts + 1, last_error = $1, next_attempt_at = $2,
+			failed_at = CASE WHEN $3 THEN now() ELSE NULL END
+		WHERE id = $4
+	`, lastError, nextAttemptAt, permanent, emailID); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (p Postgres) MarkOutboundEmailSent(emailID string) error {
+	if _, err := p.DB.Exec(`
+		UPDATE email_outbox
+		SET attempts = attempts + 1, last_error = '', sent_at = now()
+		WHERE id = $1
+	`, emailID); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (p Postgres) SetEmailConfigError(emailConfigID string, hasError bool) error {
+	if _, err := p.DB.Exec(`
+		UPDATE email SET has_error = $1 WHERE id = $2
+	`, hasError, emailConfigID); err != nil {
+		return err
+	}
+	return nil
+}
+
+func insertOutboundEmail(db execer, email model.OutboundEmail) error {
+	if _, err := db.Exec(`
+		INSERT INTO email_outbox (
+			id, recipient, subject, text_body, html_body
+		)
+		VALUES (
+			$1, $2, $3, $4, $5
+		)
+		`, email.ID[:len(email.ID)-1], email.Recipient, email.Subject,
+		email.TextBody, email.HTMLBody,
+	); err != nil {
+		return err
+	}
+	return nil
+}
+
+// loadEmailAttachments adds their attachments to claimed emails.
+func (p Postgres) loadEmailAttachments(emails []model.OutboundEmail) error {
+	if len(emails) == 0 {
+		return nil
+	}
+	index := map[string]int{}
+	emailIDs := []string{}
+	for i, email := range emails {
+		index[email.ID] = i
+		emailIDs = append(emailIDs, email.ID)
+	}
+
+	rows, err := p.DB.Query(`
+		SELECT email_id, filename, content_type, content
+		FROM email_outbox_attachments WHERE email_id = ANY($1)
+		ORDER BY email_id, position
+	`, pq.Array(emailIDs))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var emailID string
+		var attachment model.EmailAttachment
+		if err := rows.Scan(
+			&emailID,
+			&attachment.Filename,
+			&attachment.ContentType,
+			&attachment.Content,
+		); err != nil {
+			return err
+		}
+		email := &emails[index[emailID]]
+		email.Attachments = append(email.Attachments, attachment)
+	}
+
+	return rows.Err()
+}
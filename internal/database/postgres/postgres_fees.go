@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"errors"
+
+	"github.com/Leagueify/api/internal/model"
+)
+
+func (p Postgres) CreateFeeSchedule(schedule model.FeeSchedule) error {
+	if _, err := p.DB.Exec(`
+		INSERT INTO fee_schedules (
+			id, season_id, division, base_fee, early_bird_fee, early_bird_ends,
+			late_fee, late_fee_starts, sibling_discount, family_max
+		)
+		VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+		)`,
+		schedule.ID, schedule.SeasonID, schedule.Division, schedule.BaseFee,
+		schedule.EarlyBirdFee, schedule.EarlyBirdEnds, schedule.LateFee,
+		schedule.LateFeeStarts, schedule.SiblingDiscount, schedule.FamilyMax,
+	); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (p Postgres) DeleteFeeSchedule(seasonID, scheduleID string) error {
+	results, err := p.DB.Exec(`
+		DELETE FROM fee_schedules WHERE season_id = $1 AND id = $2
+	`, seasonID, scheduleID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.New("Fee schedule deletion failed")
+	}
+
+	return nil
+}
+
+// ListFeeSchedules returns the season's fee schedules, the schedule without a
+// division first.
+func (p Postgres) ListFeeSchedules(seasonID string) ([]model.FeeSchedule, error) {
+	schedules := []model.FeeSchedule{}
+
+	rows, err := p.DB.Query(`
+		SELECT
+			id, season_id, division, base_fee, early_bird_fee, early_bird_ends,
+			late_fee, late_fee_starts, sibling_discount, family_max
+		FROM fee_schedules WHERE season_id = $1
+		ORDER BY division
+	`, seasonID)
+	if err != nil {
+		return schedules, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var schedule model.FeeSchedule
+		if err := rows.Scan(
+			&schedule.ID,
+			&schedule.SeasonID,
+			&schedule.Division,
+			&schedule.BaseFee,
+			&schedule.EarlyBirdFee,
+			&schedule.EarlyBirdEnds,
+			&schedule.LateFee,
+			&schedule.LateFeeStarts,
+			&schedule.SiblingDiscount,
+			&schedule.FamilyMax,
+		); err != nil {
+			return schedules, err
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, rows.Err()
+}
+
+func (p Postgres) UpdateFeeSchedule(schedule model.FeeSchedule) error {
+	results, err := p.DB.Exec(`
+		UPDATE fee_schedules SET
+			division = $1, base_fee = $2, early_bird_fee = $3,
+			early_bird_ends = $4, late_fee = $5, late_fee_starts = $6,
+			sibling_discount = $7, family_max = $8, updated_at = now()
+		WHERE season_id = $9 AND id = $10
+	`,
+		schedule.Division, schedule.BaseFee, schedule.EarlyBirdFee,
+		schedule.EarlyBirdEnds, schedule.LateFee, schedule.LateFeeStarts,
+		schedule.SiblingDiscount, schedule.FamilyMax, schedule.SeasonID,
+		schedule.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.New("Fee schedule update failed")
+	}
+
+	return nil
+}
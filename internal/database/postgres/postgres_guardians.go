@@ -0,0 +1,202 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+)
+
+// ConsumeGuardianInvite spends the unexpired invite issued for tokenHash.
+func (p Postgres) ConsumeGuardianInvite(tx *sql.Tx, tokenHash string) (model.GuardianInvite, error) {
+	var invite model.GuardianInvite
+
+	if err := tx.QueryRow(`
+		UPDATE guardian_invites SET accepted_at = now()
+		WHERE token_hash = $1 AND accepted_at IS NULL AND expires_at > now()
+		RETURNING id, player_id, invited_by, email, permission, expires_at
+	`, tokenHash).Scan(
+		&invite.ID,
+		&invite.PlayerID,
+		&invite.InvitedBy,
+		&invite.Email,
+		&invite.Permission,
+		&invite.ExpiresAt,
+	); err != nil {
+		return invite, err
+	}
+
+	return invite, nil
+}
+
+// CreateGuardian makes the account a guardian of the player. An existing
+// guardian takes the new permission unless they are a primary guardian.
+func (p Postgres) CreateGuardian(tx *sql.Tx, guardian model.Guardian) error {
+	if _, err := tx.Exec(`
+		INSERT INTO guardians (account_id, player_id, permission)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (account_id, player_id) DO UPDATE
+		SET permission = EXCLUDED.permission
+		WHERE guardians.permission <> 'primary'
+	`, guardian.AccountID, guardian.PlayerID, guardian.Permission); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (p Postgres) CreateGuardianInvite(invite model.GuardianInvite) error {
+	if _, err := p.DB.Exec(`
+		INSERT INTO guardian_invites (
+			id, player_id, invited_by, email, permission, token_hash,
+			expires_at
+		)
+		VALUES (
+			$1, $2, $3, $4, $5, $6, $7
+		)`,
+		invite.ID[:len(invite.ID)-1], invite.PlayerID, invite.InvitedBy,
+		invite.Email, invite.Permission, invite.TokenHash, invite.ExpiresAt,
+	); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (p Postgres) DeleteGuardian(tx *sql.Tx, accountID, playerID string) error {
+	results, err := tx.Exec(`
+		DELETE FROM guardians WHERE account_id = $1 AND player_id = $2
+	`, accountID, playerID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.New("Guardian not found")
+	}
+
+	return nil
+}
+
+func (p Postgres) GetGuardian(accountID, playerID string) (model.Guardian, error) {
+	var guardian model.Guardian
+
+	if err := p.DB.QueryRow(`
+		SELECT account_id, player_id, permission, created_at
+		FROM guardians WHERE account_id = $1 AND player_id = $2
+	`, accountID, playerID).Scan(
+		&guardian.AccountID,
+		&guardian.PlayerID,
+		&guardian.Permission,
+		&guardian.CreatedAt,
+	); err != nil {
+		return guardian, err
+	}
+
+	return guardian, nil
+}
+
+// GetTotalPrimaryGuardians returns the number of primary guardians of the
+// player, locking their rows so concurrent removals cannot leave the player
+// without one.
+func (p Postgres) GetTotalPrimaryGuardians(tx *sql.Tx, playerID string) (int, error) {
+	var totalGuardians int
+
+	row := tx.QueryRow(`
+		SELECT COUNT(*) FROM (
+			SELECT account_id FROM guardians
+			WHERE player_id = $1 AND permission = 'primary' FOR UPDATE
+		) AS primary_guardians
+	`, playerID)
+	if err := row.Scan(&totalGuardians); err != nil {
+		return 0, err
+	}
+
+	return totalGuardians, nil
+}
+
+func (p Postgres) ListGuardians(playerID string) ([]model.Guardian, error) {
+	guardians := []model.Guardian{}
+
+	rows, err := p.DB.Query(`
+		SELECT guardians.account_id, guardians.player_id,
+			guardians.permission, accounts.first_name, accounts.last_name,
+			accounts.email, guardians.created_at
+		FROM guardians JOIN accounts ON accounts.id = guardians.account_id
+		WHERE guardians.player_id = $1
+		ORDER BY guardians.created_at, guardians.account_id
+	`, playerID)
+	if err != nil {
+		return guardians, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var guardian model.Guardian
+		if err := rows.Scan(
+			&guardian.AccountID,
+			&guardian.PlayerID,
+			&guardian.Permission,
+			&guardian.FirstName,
+			&guardian.LastName,
+			&guardian.Email,
+			&guardian.CreatedAt,
+		); err != nil {
+			return guardians, err
+		}
+		guardian.AccountID = util.ReturnSignedToken(guardian.AccountID)
+		guardians = append(guardians, guardian)
+	}
+
+	return guardians, rows.Err()
+}
+
+// ReleasePlayers removes the account as a guardian ahead of deleting it.
+// Players with no other guardian are deleted unless they have ever been
+// registered, in which case they are kept without a guardian so their
+// registration history remains. Where the account was the only primary
+// guardian the longest standing remaining guardian becomes primary.
+func (p Postgres) ReleasePlayers(tx *sql.Tx, accountID string) error {
+	if _, err := tx.Exec(`
+		DELETE FROM players WHERE id IN (
+			SELECT player_id FROM guardians AS released
+			WHERE released.account_id = $1 AND NOT EXISTS (
+				SELECT 1 FROM guardians AS others
+				WHERE others.player_id = released.player_id
+				AND others.account_id <> $1
+			) AND NOT EXISTS (
+				SELECT 1 FROM registration_players
+				WHERE registration_players.player_id = released.player_id
+			)
+		)
+	`, accountID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		UPDATE guardians SET permission = 'primary'
+		WHERE (player_id, account_id) IN (
+			SELECT DISTINCT ON (player_id) player_id, account_id
+			FROM guardians AS others
+			WHERE others.account_id <> $1 AND others.player_id IN (
+				SELECT player_id FROM guardians
+				WHERE account_id = $1 AND permission = 'primary'
+			) AND NOT EXISTS (
+				SELECT 1 FROM guardians AS remaining
+				WHERE remaining.player_id = others.player_id
+				AND remaining.account_id <> $1
+				AND remaining.permission = 'primary'
+			)
+			ORDER BY player_id, permission = 'secondary' DESC, created_at
+		)
+	`, accountID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM guardians WHERE account_id = $1
+	`, accountID); err != nil {
+		return err
+	}
+	return nil
+}
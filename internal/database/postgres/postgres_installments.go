@@ -0,0 +1,228 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Leagueify/api/internal/model"
+)
+
+func (p Postgres) CreateInstallmentPlan(plan model.InstallmentPlan) error {
+	if _, err := p.DB.Exec(`
+		INSERT INTO installment_plans (
+			id, season_id, name, installments, interval_days
+		)
+		VALUES (
+			$1, $2, $3, $4, $5
+		)`,
+		plan.ID, plan.SeasonID, plan.Name, plan.Installments, plan.IntervalDays,
+	); err != nil {
+		return err
+	}
+	return nil
+}
+
+// CreateInstallments records the schedule of a registration joining a plan.
+func (p Postgres) CreateInstallments(tx *sql.Tx, installments []model.Installment) error {
+	for _, installment := range installments {
+		if _, err := tx.Exec(`
+			INSERT INTO registration_installments (
+				id, registration_id, plan_id, number, due_on
+			)
+			VALUES (
+				$1, $2, $3, $4, $5
+			)`,
+			installment.ID, installment.RegistrationID, installment.PlanID,
+			installment.Number, installment.DueOn,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p Postgres) DeleteInstallmentPlan(seasonID, planID string) error {
+	results, err := p.DB.Exec(`
+		DELETE FROM installment_plans WHERE season_id = $1 AND id = $2
+	`, seasonID, planID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.New("Installment plan deletion failed")
+	}
+
+	return nil
+}
+
+func (p Postgres) GetInstallmentPlan(seasonID, planID string) (model.InstallmentPlan, error) {
+	var plan model.InstallmentPlan
+
+	if err := p.DB.QueryRow(`
+		SELECT id, season_id, name, installments, interval_days
+		FROM installment_plans WHERE season_id = $1 AND id = $2
+	`, seasonID, planID).Scan(
+		&plan.ID,
+		&plan.SeasonID,
+		&plan.Name,
+		&plan.Installments,
+		&plan.IntervalDays,
+	); err != nil {
+		return plan, err
+	}
+
+	return plan, nil
+}
+
+func (p Postgres) ListInstallmentPlans(seasonID string) ([]model.InstallmentPlan, error) {
+	plans := []model.InstallmentPlan{}
+
+	rows, err := p.DB.Query(`
+		SELECT id, season_id, name, installments, interval_days
+		FROM installment_plans WHERE season_id = $1
+		ORDER BY installments, name
+	`, seasonID)
+	if err != nil {
+		return plans, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var plan model.InstallmentPlan
+		if err := rows.Scan(
+			&plan.ID,
+			&plan.SeasonID,
+			&plan.Name,
+			&plan.Installments,
+			&plan.IntervalDays,
+		); err != nil {
+			return plans, err
+		}
+		plans = append(plans, plan)
+	}
+
+	return plans, rows.Err()
+}
+
+// ListInstallments returns the registration's schedule in due date order, or
+// an empty schedule when it is not on a plan.
+func (p Postgres) ListInstallments(registrationID string) ([]model.Installment, error) {
+	installments := []model.Installment{}
+
+	rows, err := p.DB.Query(`
+		SELECT
+			id, registration_id, COALESCE(plan_id, ''), number, due_on,
+			overdue_at, reminded_at
+		FROM registration_installments WHERE registration_id = $1
+		ORDER BY number
+	`, registrationID)
+	if err != nil {
+		return installments, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var installment model.Installment
+		if err := rows.Scan(
+			&installment.ID,
+			&installment.RegistrationID,
+			&installment.PlanID,
+			&installment.Number,
+			&installment.DueOn,
+			&installment.OverdueAt,
+			&installment.RemindedAt,
+		); err != nil {
+			return installments, err
+		}
+		installments = append(installments, installment)
+	}
+
+	return installments, rows.Err()
+}
+
+// ListOverdueInstallments returns the latest unpaid installment due before
+// today of each registration, unless the registration was reminded of its
+// overdue installments since remindedBefore.
+func (p Postgres) ListOverdueInstallments(today string, remindedBefore time.Time) ([]model.InstallmentReminder, error) {
+	reminders := []model.InstallmentReminder{}
+
+	rows, err := p.DB.Query(`
+		SELECT DISTINCT ON (i.registration_id)
+			i.id, i.registration_id, i.number, s.installments, i.due_on,
+			r.amount_due, r.amount_paid, a.email, a.first_name, se.name
+		FROM registration_installments i
+		JOIN (
+			SELECT registration_id, count(*) AS installments
+			FROM registration_installments GROUP BY registration_id
+		) s ON s.registration_id = i.registration_id
+		JOIN registrations r ON r.id = i.registration_id
+		JOIN accounts a ON a.id = r.account_id
+		JOIN seasons se ON se.id = r.season_id
+		WHERE i.due_on < $1
+		AND r.amount_paid < r.amount_due * i.number / s.installments
+		AND (i.reminded_at IS NULL OR i.reminded_at < $2)
+		ORDER BY i.registration_id, i.number DESC
+	`, today, remindedBefore)
+	if err != nil {
+		return reminders, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var reminder model.InstallmentReminder
+		if err := rows.Scan(
+			&reminder.InstallmentID,
+			&reminder.RegistrationID,
+			&reminder.Number,
+			&reminder.Installments,
+			&reminder.DueOn,
+			&reminder.AmountDue,
+			&reminder.AmountPaid,
+			&reminder.Email,
+			&reminder.FirstName,
+			&reminder.SeasonName,
+		); err != nil {
+			return reminders, err
+		}
+		reminders = append(reminders, reminder)
+	}
+
+	return reminders, rows.Err()
+}
+
+// MarkInstallmentsReminded flags the registration's installments up to number
+// as overdue and records that the account holder was reminded of them.
+func (p Postgres) MarkInstallmentsReminded(registrationID string, number int) error {
+	if _, err := p.DB.Exec(`
+		UPDATE registration_installments SET
+			overdue_at = COALESCE(overdue_at, now()), reminded_at = now()
+		WHERE registration_id = $1 AND number <= $2
+	`, registrationID, number); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (p Postgres) UpdateInstallmentPlan(plan model.InstallmentPlan) error {
+	results, err := p.DB.Exec(`
+		UPDATE installment_plans SET
+			name = $1, installments = $2, interval_days = $3, updated_at = now()
+		WHERE season_id = $4 AND id = $5
+	`, plan.Name, plan.Installments, plan.IntervalDays, plan.SeasonID, plan.ID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.New("Installment plan update failed")
+	}
+
+	return nil
+}
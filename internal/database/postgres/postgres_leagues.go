@@ -14,6 +14,22 @@ func (p Postgres) CreateLeague(league model.LeagueCreation) error {
 	return nil
 }
 
+// GetLeague returns the league hosted by this instance.
+func (p Postgres) GetLeague() (model.League, error) {
+	var league model.League
+
+	if err := p.DB.QueryRow(`
+		SELECT id, name, sport_id FROM leagues ORDER BY id LIMIT 1
+	`).Scan(
+		&league.ID,
+		&league.Name,
+		&league.SportID,
+	); err != nil {
+		return league, err
+	}
+	return league, nil
+}
+
 func (p Postgres) GetTotalLeagues() (int, error) {
 	var totalLeagues int
 
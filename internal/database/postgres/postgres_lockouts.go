@@ -0,0 +1,154 @@
+package postgres
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+)
+
+func (p Postgres) CreateLockoutEvent(event model.LockoutEvent) error {
+	if _, err := p.DB.Exec(`
+		INSERT INTO lockout_events (account_id, ip_address, event, actor)
+		VALUES ($1, $2, $3, $4)
+	`, event.AccountID, event.IPAddress, event.Event, event.Actor); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (p Postgres) GetFailedLoginsByIP(ipAddress string, since time.Time) (int, error) {
+	var failedLogins int
+
+	row := p.DB.QueryRow(`
+		SELECT COUNT(*) FROM login_attempts
+		WHERE ip_address = $1 AND succeeded = false AND attempted_at > $2
+	`, ipAddress, since)
+	if err := row.Scan(&failedLogins); err != nil {
+		return 0, err
+	}
+
+	return failedLogins, nil
+}
+
+func (p Postgres) GetLoginLockout(accountID string) (model.LoginLockout, error) {
+	var lockout model.LoginLockout
+
+	if err := p.DB.QueryRow(`
+		SELECT account_id, failed_attempts, locked_until
+		FROM account_lockouts WHERE account_id = $1
+	`, accountID).Scan(
+		&lockout.AccountID,
+		&lockout.FailedAttempts,
+		&lockout.LockedUntil,
+	); err != nil {
+		return lockout, err
+	}
+
+	return lockout, nil
+}
+
+func (p Postgres) ListLockoutEvents(limit int) ([]model.LockoutEvent, error) {
+	events := []model.LockoutEvent{}
+
+	rows, err := p.DB.Query(`
+		SELECT id, account_id, ip_address, event, actor, created_at
+		FROM lockout_events ORDER BY created_at DESC, id DESC LIMIT $1
+	`, limit)
+	if err != nil {
+		return events, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var event model.LockoutEvent
+		if err := rows.Scan(
+			&event.ID,
+			&event.AccountID,
+			&event.IPAddress,
+			&event.Event,
+			&event.Actor,
+			&event.CreatedAt,
+		); err != nil {
+			return events, err
+		}
+		if event.AccountID != "" {
+			event.AccountID = util.ReturnSignedToken(event.AccountID)
+		}
+		if event.Actor != "" {
+			event.Actor = util.ReturnSignedToken(event.Actor)
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// LockAccount refuses logins for the account until the given time. An
+// existing longer lock is kept.
+func (p Postgres) LockAccount(accountID string, until time.Time) error {
+	if _, err := p.DB.Exec(`
+		UPDATE account_lockouts SET locked_until = GREATEST(locked_until, $2)
+		WHERE account_id = $1
+	`, accountID, until); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (p Postgres) RecordLoginAttempt(attempt model.LoginAttempt) error {
+	if _, err := p.DB.Exec(`
+		INSERT INTO login_attempts (account_id, email, ip_address, succeeded)
+		VALUES ($1, $2, $3, $4)
+	`, attempt.AccountID, attempt.Email, attempt.IPAddress, attempt.Succeeded); err != nil {
+		return err
+	}
+	return nil
+}
+
+// RecordLoginFailure increments the consecutive failures for the account and
+// returns the new total.
+func (p Postgres) RecordLoginFailure(accountID string) (int, error) {
+	var failedAttempts int
+
+	if err := p.DB.QueryRow(`
+		INSERT INTO account_lockouts (account_id, failed_attempts)
+		VALUES ($1, 1)
+		ON CONFLICT (account_id) DO UPDATE
+		SET failed_attempts = account_lockouts.failed_attempts + 1
+		RETURNING failed_attempts
+	`, accountID).Scan(&failedAttempts); err != nil {
+		return 0, err
+	}
+
+	return failedAttempts, nil
+}
+
+func (p Postgres) ResetLoginFailures(accountID string) error {
+	if _, err := p.DB.Exec(`
+		DELETE FROM account_lockouts WHERE account_id = $1
+	`, accountID); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (p Postgres) UnlockAccount(accountID string) error {
+	results, err := p.DB.Exec(`
+		DELETE FROM account_lockouts
+		WHERE account_id = $1 AND locked_until > now()
+	`, accountID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.New("Account not locked")
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package postgres
+
+import (
+	"errors"
+
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+)
+
+func (p Postgres) CreateEmergencyContact(contact model.EmergencyContact) error {
+	if _, err := p.DB.Exec(`
+		INSERT INTO emergency_contacts (
+			id, player_id, name, relationship, phone, email
+		)
+		VALUES (
+			$1, $2, $3, $4, $5, $6
+		)`,
+		contact.ID[:len(contact.ID)-1], contact.PlayerID, contact.Name,
+		contact.Relationship, contact.Phone, contact.Email,
+	); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (p Postgres) CreateMedicalAccessEvent(event model.MedicalAccessEvent) error {
+	if _, err := p.DB.Exec(`
+		INSERT INTO medical_access_events (
+			player_id, account_id, record, ip_address
+		)
+		VALUES ($1, $2, $3, $4)
+	`, event.PlayerID, event.AccountID, event.Record, event.IPAddress); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (p Postgres) DeleteEmergencyContact(playerID, contactID string) error {
+	results, err := p.DB.Exec(`
+		DELETE FROM emergency_contacts WHERE id = $1 AND player_id = $2
+	`, contactID, playerID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.New("Emergency contact not found")
+	}
+
+	return nil
+}
+
+func (p Postgres) GetMedicalRecord(playerID string) (model.MedicalRecord, error) {
+	var record model.MedicalRecord
+
+	if err := p.DB.QueryRow(`
+		SELECT player_id, data, updated_by, updated_at
+		FROM medical_records WHERE player_id = $1
+	`, playerID).Scan(
+		&record.PlayerID,
+		&record.Data,
+		&record.UpdatedBy,
+		&record.UpdatedAt,
+	); err != nil {
+		return record, err
+	}
+
+	return record, nil
+}
+
+func (p Postgres) ListEmergencyContacts(playerID string) ([]model.EmergencyContact, error) {
+	contacts := []model.EmergencyContact{}
+
+	rows, err := p.DB.Query(`
+		SELECT id, player_id, name, relationship, phone, email, created_at
+		FROM emergency_contacts WHERE player_id = $1 ORDER BY created_at, id
+	`, playerID)
+	if err != nil {
+		return contacts, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var contact model.EmergencyContact
+		if err := rows.Scan(
+			&contact.ID,
+			&contact.PlayerID,
+			&contact.Name,
+			&contact.Relationship,
+			&contact.Phone,
+			&contact.Email,
+			&contact.CreatedAt,
+		); err != nil {
+			return contacts, err
+		}
+		contact.ID = util.ReturnSignedToken(contact.ID)
+		contacts = append(contacts, contact)
+	}
+
+	return contacts, rows.Err()
+}
+
+func (p Postgres) ListMedicalAccessEvents(playerID string, limit int) ([]model.MedicalAccessEvent, error) {
+	events := []model.MedicalAccessEvent{}
+
+	rows, err := p.DB.Query(`
+		SELECT id, player_id, account_id, record, ip_address, created_at
+		FROM medical_access_events WHERE player_id = $1
+		ORDER BY created_at DESC, id DESC LIMIT $2
+	`, playerID, limit)
+	if err != nil {
+		return events, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var event model.MedicalAccessEvent
+		if err := rows.Scan(
+			&event.ID,
+			&event.PlayerID,
+			&event.AccountID,
+			&event.Record,
+			&event.IPAddress,
+			&event.CreatedAt,
+		); err != nil {
+			return events, err
+		}
+		event.AccountID = util.ReturnSignedToken(event.AccountID)
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// SetMedicalRecord stores the player's encrypted medical information,
+// replacing any earlier record.
+func (p Postgres) SetMedicalRecord(record model.MedicalRecord) error {
+	if _, err := p.DB.Exec(`
+		INSERT INTO medical_records (player_id, data, updated_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (player_id) DO UPDATE
+		SET data = EXCLUDED.data, updated_by = EXCLUDED.updated_by,
+			updated_at = now()
+	`, record.PlayerID, record.Data, record.UpdatedBy); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package postgres
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Leagueify/api/internal/model"
+)
+
+// migrationLockID is the advisory lock held while a migration is applied or
+// reverted so concurrent runners never apply the same version twice.
+const migrationLockID = 7428331
+
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+type migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+func loadMigrations() ([]migration, error) {
+	files, err := migrationFS.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int]*migration{}
+	for _, file := range files {
+		filename := file.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(filename, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(filename, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("invalid migration filename '%s'", filename)
+		}
+		base := strings.TrimSuffix(filename, fmt.Sprintf(".%s.sql", direction))
+		prefix, name, found := strings.Cut(base, "_")
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid migration filename '%s'", filename)
+		}
+		version, err := strconv.Atoi(prefix)
+		if err != nil || version < 1 {
+			return nil, fmt.Errorf("invalid migration version '%s'", filename)
+		}
+		contents, err := migrationFS.ReadFile(path.Join("migrations", filename))
+		if err != nil {
+			return nil, err
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if m.Name != name {
+			return nil, fmt.Errorf("conflicting names for migration version %d", version)
+		}
+		if direction == "up" {
+			m.Up = string(contents)
+		} else {
+			m.Down = string(contents)
+		}
+	}
+	migrations := []migration{}
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d is missing an up or down file", m.Version)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	for index, m := range migrations {
+		if m.Version != index+1 {
+			return nil, fmt.Errorf("migration versions are not sequential at %d", m.Version)
+		}
+	}
+	return migrations, nil
+}
+
+func (p Postgres) createMigrationsTable() error {
+	tx, err := p.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, migrationLockID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)
+	`); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (p Postgres) appliedMigrations() (map[int]time.Time, error) {
+	applied := map[int]time.Time{}
+
+	rows, err := p.DB.Query(`SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return applied, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return applied, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+func (p Postgres) MigrateUp() (int, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return 0, err
+	}
+	if err := p.createMigrationsTable(); err != nil {
+		return 0, err
+	}
+	var totalApplied int
+	for _, m := range migrations {
+		applied, err := p.applyMigration(m)
+		if err != nil {
+			return totalApplied, fmt.Errorf("migration %04d_%s: %w", m.Version, m.Name, err)
+		}
+		if applied {
+			totalApplied++
+		}
+	}
+	return totalApplied, nil
+}
+
+func (p Postgres) MigrateDown(steps int) (int, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return 0, err
+	}
+	if err := p.createMigrationsTable(); err != nil {
+		return 0, err
+	}
+	var totalReverted int
+	for index := len(migrations) - 1; index >= 0 && totalReverted < steps; index-- {
+		m := migrations[index]
+		reverted, err := p.revertMigration(m)
+		if err != nil {
+			return totalReverted, fmt.Errorf("migration %04d_%s: %w", m.Version, m.Name, err)
+		}
+		if reverted {
+			totalReverted++
+		}
+	}
+	return totalReverted, nil
+}
+
+func (p Postgres) MigrationStatus() ([]model.Migration, error) {
+	status := []model.Migration{}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return status, err
+	}
+	if err := p.createMigrationsTable(); err != nil {
+		return status, err
+	}
+	applied, err := p.appliedMigrations()
+	if err != nil {
+		return status, err
+	}
+	for _, m := range migrations {
+		entry := model.Migration{Version: m.Version, Name: m.Name}
+		if appliedAt, ok := applied[m.Version]; ok {
+			entry.IsApplied = true
+			entry.AppliedAt = appliedAt.Format(time.RFC3339)
+		}
+		status = append(status, entry)
+	}
+	return status, nil
+}
+
+func (p Postgres) PendingMigrations() (int, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return 0, err
+	}
+	if err := p.createMigrationsTable(); err != nil {
+		return 0, err
+	}
+	applied, err := p.appliedMigrations()
+	if err != nil {
+		return 0, err
+	}
+	var pending int
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; !ok {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+func (p Postgres) applyMigration(m migration) (bool, error) {
+	tx, err := p.DB.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, migrationLockID); err != nil {
+		return false, err
+	}
+	var exists bool
+	if err := tx.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)
+	`, m.Version).Scan(&exists); err != nil {
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
+	if _, err := tx.Exec(m.Up); err != nil {
+		return false, err
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO schema_migrations (version, name) VALUES ($1, $2)
+	`, m.Version, m.Name); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+func (p Postgres) revertMigration(m migration) (bool, error) {
+	tx, err := p.DB.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, migrationLockID); err != nil {
+		return false, err
+	}
+	var exists bool
+	if err := tx.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)
+	`, m.Version).Scan(&exists); err != nil {
+		return false, err
+	}
+	if !exists {
+		return false, nil
+	}
+	if _, err := tx.Exec(m.Down); err != nil {
+		return false, err
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM schema_migrations WHERE version = $1
+	`, m.Version); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when loading migrations", err)
+	}
+	assert.NotEmpty(t, migrations)
+	for index, migration := range migrations {
+		assert.Equal(t, index+1, migration.Version)
+		assert.NotEmpty(t, migration.Name)
+		assert.NotEmpty(t, migration.Up)
+		assert.NotEmpty(t, migration.Down)
+	}
+}
+
+func TestPendingMigrations(t *testing.T) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when loading migrations", err)
+	}
+	testCases := []struct {
+		Description     string
+		AppliedVersions int
+		ExpectedPending int
+	}{
+		{
+			Description:     "No Applied Migrations",
+			AppliedVersions: 0,
+			ExpectedPending: len(migrations),
+		},
+		{
+			Description:     "Partially Applied Migrations",
+			AppliedVersions: 1,
+			ExpectedPending: len(migrations) - 1,
+		},
+		{
+			Description:     "All Migrations Applied",
+			AppliedVersions: len(migrations),
+			ExpectedPending: 0,
+		},
+	}
+	for _, test := range testCases {
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+		}
+		db := Postgres{DB: mockDB}
+		rows := sqlmock.NewRows([]string{"version", "applied_at"})
+		for version := 1; version <= test.AppliedVersions; version++ {
+			rows.AddRow(version, time.Now())
+		}
+		mock.ExpectBegin()
+		mock.ExpectExec("SELECT pg_advisory_xact_lock").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+		mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").WillReturnRows(rows)
+
+		pending, err := db.PendingMigrations()
+		assert.NoError(t, err, test.Description)
+		assert.Equal(t, test.ExpectedPending, pending, test.Description)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
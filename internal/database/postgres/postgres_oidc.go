@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"database/sql"
+
+	"github.com/Leagueify/api/internal/model"
+)
+
+// ConsumeOIDCSignup spends the pending signup issued for tokenHash.
+func (p Postgres) ConsumeOIDCSignup(tx *sql.Tx, tokenHash string) (model.OIDCSignup, error) {
+	var signup model.OIDCSignup
+
+	if err := tx.QueryRow(`
+		DELETE FROM oidc_signups
+		WHERE token_hash = $1 AND expires_at > now()
+		RETURNING token_hash, provider, subject, email, email_verified,
+			first_name, last_name, phone, date_of_birth, label, expires_at
+	`, tokenHash).Scan(
+		&signup.TokenHash,
+		&signup.Provider,
+		&signup.Subject,
+		&signup.Email,
+		&signup.EmailVerified,
+		&signup.FirstName,
+		&signup.LastName,
+		&signup.Phone,
+		&signup.DateOfBirth,
+		&signup.Label,
+		&signup.ExpiresAt,
+	); err != nil {
+		return signup, err
+	}
+
+	return signup, nil
+}
+
+// ConsumeOIDCState spends the unexpired state issued to provider so each
+// authorization can be completed once.
+func (p Postgres) ConsumeOIDCState(stateHash, provider string) (model.OIDCState, error) {
+	var state model.OIDCState
+
+	if err := p.DB.QueryRow(`
+		DELETE FROM oidc_states
+		WHERE state_hash = $1 AND provider = $2 AND expires_at > now()
+		RETURNING state_hash, provider, nonce, code_verifier, label, expires_at
+	`, stateHash, provider).Scan(
+		&state.StateHash,
+		&state.Provider,
+		&state.Nonce,
+		&state.CodeVerifier,
+		&state.Label,
+		&state.ExpiresAt,
+	); err != nil {
+		return state, err
+	}
+
+	return state, nil
+}
+
+// CreateOIDCAccount creates the account and links it to the provider
+// identity it was signed up with.
+func (p Postgres) CreateOIDCAccount(tx *sql.Tx, account model.AccountCreation, identity model.OIDCIdentity) error {
+	if err := insertAccount(tx, account); err != nil {
+		return err
+	}
+	identity.AccountID = account.ID[:len(account.ID)-1]
+	return insertOIDCIdentity(tx, identity)
+}
+
+func (p Postgres) CreateOIDCIdentity(identity model.OIDCIdentity) error {
+	return insertOIDCIdentity(p.DB, identity)
+}
+
+func (p Postgres) CreateOIDCSignup(signup model.OIDCSignup) error {
+	if _, err := p.DB.Exec(`
+		INSERT INTO oidc_signups (
+			token_hash, provider, subject, email, email_verified,
+			first_name, last_name, phone, date_of_birth, label, expires_at
+		)
+		VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+		)`,
+		signup.TokenHash, signup.Provider, signup.Subject, signup.Email,
+		signup.EmailVerified, signup.FirstName, signup.LastName,
+		signup.Phone, signup.DateOfBirth, signup.Label, signup.ExpiresAt,
+	); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (p Postgres) CreateOIDCState(state model.OIDCState) error {
+	if _, err := p.DB.Exec(`
+		INSERT INTO oidc_states (
+			state_hash, provider, nonce, code_verifier, label, expires_at
+		)
+		VALUES (
+			$1, $2, $3, $4, $5, $6
+		)`,
+		state.StateHash, state.Provider, state.Nonce, state.CodeVerifier,
+		state.Label, state.ExpiresAt,
+	); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (p Postgres) GetOIDCIdentity(provider, subject string) (model.OIDCIdentity, error) {
+	var identity model.OIDCIdentity
+
+	if err := p.DB.QueryRow(`
+		SELECT provider, subject, account_id, email
+		FROM oidc_identities WHERE provider = $1 AND subject = $2
+	`, provider, subject).Scan(
+		&identity.Provider,
+		&identity.Subject,
+		&identity.AccountID,
+		&identity.Email,
+	); err != nil {
+		return identity, err
+	}
+
+	return identity, nil
+}
+
+func insertOIDCIdentity(db execer, identity model.OIDCIdentity) error {
+	if _, err := db.Exec(`
+		INSERT INTO oidc_identities (provider, subject, account_id, email)
+		VALUES ($1, $2, $3, $4)
+	`, identity.Provider, identity.Subject, identity.AccountID,
+		identity.Email,
+	); err != nil {
+		return err
+	}
+	return nil
+}
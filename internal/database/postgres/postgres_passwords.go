@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"database/sql"
+
+	"github.com/Leagueify/api/internal/model"
+)
+
+func (p Postgres) ConsumePasswordReset(tx *sql.Tx, tokenHash string) (string, error) {
+	var accountID string
+
+	if err := tx.QueryRow(`
+		UPDATE password_resets SET used_at = now()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > now()
+		RETURNING account_id
+	`, tokenHash).Scan(&accountID); err != nil {
+		return accountID, err
+	}
+	// outstanding tokens for the account are spent by a successful reset
+	if _, err := tx.Exec(`
+		UPDATE password_resets SET used_at = now()
+		WHERE account_id = $1 AND used_at IS NULL
+	`, accountID); err != nil {
+		return accountID, err
+	}
+
+	return accountID, nil
+}
+
+func (p Postgres) CreatePasswordReset(reset model.PasswordResetToken) error {
+	if _, err := p.DB.Exec(`
+		INSERT INTO password_resets (id, account_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`, reset.ID[:len(reset.ID)-1], reset.AccountID, reset.TokenHash,
+		reset.ExpiresAt,
+	); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/Leagueify/api/internal/model"
+)
+
+const paymentColumns = `
+	id, registration_id, kind, amount, provider, reference,
+	COALESCE(payment_id, ''), created_at
+`
+
+// CreatePayment records the payment or refund in the ledger and applies it to
+// the registration's amount paid. Providers may report the same payment more
+// than once, so false is returned when the provider's reference is already in
+// the ledger.
+func (p Postgres) CreatePayment(tx *sql.Tx, payment model.Payment) (bool, error) {
+	results, err := tx.Exec(`
+		INSERT INTO payment_ledger (
+			id, registration_id, kind, amount, provider, reference, payment_id
+		)
+		VALUES (
+			$1, $2, $3, $4, $5, $6, NULLIF($7, '')
+		)
+		ON CONFLICT (provider, reference) DO NOTHING
+	`,
+		payment.ID, payment.RegistrationID, payment.Kind, payment.Amount,
+		payment.Provider, payment.Reference, payment.PaymentID,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rows != 1 {
+		return false, nil
+	}
+
+	amount := payment.Amount
+	if payment.Kind == model.PaymentKindRefund {
+		amount = -amount
+	}
+	results, err = tx.Exec(`
+		UPDATE registrations SET amount_paid = amount_paid + $1 WHERE id = $2
+	`, amount, payment.RegistrationID)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err = results.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rows != 1 {
+		return false, errors.New("Registration update failed")
+	}
+
+	return true, nil
+}
+
+func (p Postgres) GetPayment(paymentID string) (model.Payment, error) {
+	var payment model.Payment
+
+	if err := scanPayment(p.DB.QueryRow(`
+		SELECT `+paymentColumns+` FROM payment_ledger WHERE id = $1
+	`, paymentID), &payment); err != nil {
+		return payment, err
+	}
+
+	return payment, nil
+}
+
+func (p Postgres) GetPaymentByReference(provider, reference string) (model.Payment, error) {
+	var payment model.Payment
+
+	if err := scanPayment(p.DB.QueryRow(`
+		SELECT `+paymentColumns+` FROM payment_ledger
+		WHERE provider = $1 AND reference = $2
+	`, provider, reference), &payment); err != nil {
+		return payment, err
+	}
+
+	return payment, nil
+}
+
+// ListPayments returns the registration's ledger in the order it was recorded.
+func (p Postgres) ListPayments(registrationID string) ([]model.Payment, error) {
+	payments := []model.Payment{}
+
+	rows, err := p.DB.Query(`
+		SELECT `+paymentColumns+` FROM payment_ledger
+		WHERE registration_id = $1
+		ORDER BY created_at, id
+	`, registrationID)
+	if err != nil {
+		return payments, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var payment model.Payment
+		if err := scanPayment(rows, &payment); err != nil {
+			return payments, err
+		}
+		payments = append(payments, payment)
+	}
+
+	return payments, rows.Err()
+}
+
+func scanPayment(row scanner, payment *model.Payment) error {
+	return row.Scan(
+		&payment.ID,
+		&payment.RegistrationID,
+		&payment.Kind,
+		&payment.Amount,
+		&payment.Provider,
+		&payment.Reference,
+		&payment.PaymentID,
+		&payment.CreatedAt,
+	)
+}
@@ -2,21 +2,36 @@ package postgres
 
 import (
 	"database/sql"
+	"errors"
 
 	"github.com/Leagueify/api/internal/model"
 )
 
+const playerColumns = `
+	players.id, players.first_name, players.last_name, players.date_of_birth,
+	players.position, players.gender, players.jersey_size,
+	players.jersey_number, players.school, players.grade, players.team,
+	players.division, EXISTS (
+		SELECT 1 FROM registration_players
+		JOIN seasons ON seasons.id = registration_players.season_id
+		WHERE registration_players.player_id = players.id
+			AND registration_players.status IN ('pending', 'confirmed', 'waitlisted')
+			AND seasons.end_date >= to_char(now(), 'YYYY-MM-DD')
+	) AS is_registered
+`
+
 func (p Postgres) CreatePlayer(player model.Player, tx *sql.Tx) error {
 	if _, err := tx.Exec(`
 		INSERT INTO players (
-			id, first_name, last_name, date_of_birth, position,
-			team, division, is_registered
+			id, first_name, last_name, date_of_birth, position, gender,
+			jersey_size, jersey_number, school, grade, team, division
 		)
 		VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
 		)`,
 		player.ID[:len(player.ID)-1], player.FirstName, player.LastName,
-		player.DateOfBirth, player.Position, "", "", false,
+		player.DateOfBirth, player.Position, player.Gender, player.JerseySize,
+		player.JerseyNumber, player.School, player.Grade, "", "",
 	); err != nil {
 		return err
 	}
@@ -35,29 +50,107 @@ func (p Postgres) DeletePlayer(playerID string, tx *sql.Tx) error {
 func (p Postgres) GetPlayer(playerID string) (model.Player, error) {
 	var player model.Player
 
-	if err := p.DB.QueryRow(`
-		SELECT * FROM players WHERE id = $1
-	`, playerID).Scan(
-		&player.ID,
-		&player.FirstName,
-		&player.LastName,
-		&player.DateOfBirth,
-		&player.Position,
-		&player.Team,
-		&player.Division,
-		&player.IsRegistered,
-	); err != nil {
+	if err := scanPlayer(p.DB.QueryRow(`
+		SELECT `+playerColumns+` FROM players WHERE id = $1
+	`, playerID), &player); err != nil {
 		return player, err
 	}
 
 	return player, nil
 }
 
-func (p Postgres) RegisterPlayer(tx *sql.Tx, playerID string) error {
-	if _, err := tx.Exec(`
-		UPDATE players SET is_registered = true WHERE id = $1
-	`, playerID); err != nil {
+// ListPlayers returns the players the account is a guardian of, in the order
+// the account became their guardian.
+func (p Postgres) ListPlayers(accountID string) ([]model.Player, error) {
+	players := []model.Player{}
+
+	rows, err := p.DB.Query(`
+		SELECT `+playerColumns+` FROM players
+		JOIN guardians ON guardians.player_id = players.id
+		WHERE guardians.account_id = $1
+		ORDER BY guardians.created_at, players.id
+	`, accountID)
+	if err != nil {
+		return players, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var player model.Player
+		if err := scanPlayer(rows, &player); err != nil {
+			return players, err
+		}
+		players = append(players, player)
+	}
+
+	return players, rows.Err()
+}
+
+// SetPlayerDivision places the player in the division. False is returned when
+// the player holds or is waiting for a place in a season that has not ended.
+func (p Postgres) SetPlayerDivision(playerID, division string) (bool, error) {
+	results, err := p.DB.Exec(`
+		UPDATE players SET division = $1
+		WHERE id = $2 AND NOT EXISTS (
+			SELECT 1 FROM registration_players
+			JOIN seasons ON seasons.id = registration_players.season_id
+			WHERE registration_players.player_id = players.id
+				AND registration_players.status IN ('pending', 'confirmed', 'waitlisted')
+				AND seasons.end_date >= to_char(now(), 'YYYY-MM-DD')
+		)
+	`, division, playerID)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
+
+func (p Postgres) UpdatePlayer(tx *sql.Tx, player model.Player) error {
+	results, err := tx.Exec(`
+		UPDATE players SET
+			first_name = $1, last_name = $2, date_of_birth = $3, position = $4,
+			gender = $5, jersey_size = $6, jersey_number = $7, school = $8,
+			grade = $9
+		WHERE id = $10
+	`,
+		player.FirstName, player.LastName, player.DateOfBirth, player.Position,
+		player.Gender, player.JerseySize, player.JerseyNumber, player.School,
+		player.Grade, player.ID,
+	)
+	if err != nil {
 		return err
 	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.New("Player update failed")
+	}
+
 	return nil
 }
+
+func scanPlayer(row scanner, player *model.Player) error {
+	return row.Scan(
+		&player.ID,
+		&player.FirstName,
+		&player.LastName,
+		&player.DateOfBirth,
+		&player.Position,
+		&player.Gender,
+		&player.JerseySize,
+		&player.JerseyNumber,
+		&player.School,
+		&player.Grade,
+		&player.Team,
+		&player.Division,
+		&player.IsRegistered,
+	)
+}
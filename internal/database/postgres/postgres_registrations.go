@@ -2,43 +2,361 @@ package postgres
 
 import (
 	"database/sql"
+	"errors"
 
 	"github.com/Leagueify/api/internal/model"
-	"github.com/lib/pq"
 )
 
+const registrationColumns = `
+	registrations.id, COALESCE(registrations.account_id, ''),
+	COALESCE(registrations.season_id, ''), registrations.amount_due,
+	registrations.amount_paid, registrations.created_at
+`
+
+// AddRegistrationPlayer adds the player to the registration. A player may only
+// be registered once per season, so false is returned when the player already
+// holds a registration for the season that was not withdrawn or refunded.
+// Players registered again on the registration they withdrew from keep the
+// fee they retained. Waitlisted players join the back of the waitlist.
+func (p Postgres) AddRegistrationPlayer(tx *sql.Tx, player model.RegistrationPlayer) (bool, error) {
+	results, err := tx.Exec(`
+		INSERT INTO registration_players (
+			registration_id, player_id, season_id, status, amount,
+			waitlist_position
+		)
+		VALUES (
+			$1, $2, NULLIF($3, ''), $4, $5,
+			CASE WHEN $4 = 'waitlisted' THEN nextval('waitlist_positions') END
+		)
+		ON CONFLICT (player_id, season_id) DO UPDATE SET
+			registration_id = EXCLUDED.registration_id,
+			status = EXCLUDED.status,
+			amount = EXCLUDED.amount,
+			waitlist_position = EXCLUDED.waitlist_position,
+			offer_expires_at = NULL,
+			retained = CASE
+				WHEN registration_players.registration_id = EXCLUDED.registration_id
+				THEN registration_players.retained ELSE 0
+			END,
+			updated_at = now()
+		WHERE registration_players.status IN ('withdrawn', 'refunded')
+	`,
+		player.RegistrationID, player.PlayerID, player.SeasonID, player.Status,
+		player.Amount,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
+
+// CreateRegistrationAdjustment takes the adjustment off the registration. A
+// coupon may only be applied to a registration once, so false is returned when
+// the coupon has already been applied.
+func (p Postgres) CreateRegistrationAdjustment(tx *sql.Tx, adjustment model.RegistrationAdjustment) (bool, error) {
+	results, err := tx.Exec(`
+		INSERT INTO registration_adjustments (
+			id, registration_id, kind, coupon_id, financial_aid_id, description,
+			amount
+		)
+		VALUES (
+			$1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6, $7
+		)
+		ON CONFLICT (registration_id, coupon_id) DO NOTHING
+	`,
+		adjustment.ID, adjustment.RegistrationID, adjustment.Kind,
+		adjustment.CouponID, adjustment.FinancialAidID, adjustment.Description,
+		adjustment.Amount,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
+
 func (p Postgres) CreateRegistration(tx *sql.Tx, registration model.Registration) error {
 	if _, err := tx.Exec(`
 		INSERT INTO registrations (
-			id, player_ids, amount_due, amount_paid
+			id, account_id, season_id, amount_due, amount_paid
 		)
 		VALUES (
-			$1, $2, $3, $4
+			$1, $2, $3, $4, $5
 		)`,
-		registration.ID, registration.PlayerIDs, registration.AmountDue,
-		registration.AmountPaid,
+		registration.ID, registration.AccountID, registration.SeasonID,
+		registration.AmountDue, registration.AmountPaid,
 	); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (p Postgres) GetRegistration(tx *sql.Tx, registrationID string) (pq.StringArray, error) {
-	var registeredPlayers pq.StringArray
+func (p Postgres) GetRegistration(registrationID string) (model.Registration, error) {
+	var registration model.Registration
+
+	if err := scanRegistration(p.DB.QueryRow(`
+		SELECT `+registrationColumns+` FROM registrations WHERE id = $1
+	`, registrationID), &registration); err != nil {
+		return registration, err
+	}
+
+	return registration, nil
+}
+
+// GetRegistrationForUpdate returns the registration, locking it until the
+// transaction ends.
+func (p Postgres) GetRegistrationForUpdate(tx *sql.Tx, registrationID string) (model.Registration, error) {
+	var registration model.Registration
+
+	if err := scanRegistration(tx.QueryRow(`
+		SELECT `+registrationColumns+` FROM registrations WHERE id = $1
+		FOR UPDATE
+	`, registrationID), &registration); err != nil {
+		return registration, err
+	}
+
+	return registration, nil
+}
+
+// GetSeasonRegistration returns the account's registration for the season,
+// locking it until the transaction ends.
+func (p Postgres) GetSeasonRegistration(tx *sql.Tx, accountID, seasonID string) (model.Registration, error) {
+	var registration model.Registration
+
+	if err := scanRegistration(tx.QueryRow(`
+		SELECT `+registrationColumns+` FROM registrations
+		WHERE account_id = $1 AND season_id = $2
+		FOR UPDATE
+	`, accountID, seasonID), &registration); err != nil {
+		return registration, err
+	}
+
+	return registration, nil
+}
+
+// ListAccountRegistrations returns the account's registrations, newest first.
+func (p Postgres) ListAccountRegistrations(accountID string) ([]model.Registration, error) {
+	return p.listRegistrations(`
+		SELECT `+registrationColumns+` FROM registrations
+		WHERE account_id = $1
+		ORDER BY created_at DESC, id
+	`, accountID)
+}
+
+// ListRegistrationAdjustments returns the coupons and financial aid taken off
+// the registration in the order they were applied.
+func (p Postgres) ListRegistrationAdjustments(registrationID string) ([]model.RegistrationAdjustment, error) {
+	adjustments := []model.RegistrationAdjustment{}
+
+	rows, err := p.DB.Query(`
+		SELECT
+			id, registration_id, kind, COALESCE(coupon_id, ''),
+			COALESCE(financial_aid_id, ''), description, amount, created_at
+		FROM registration_adjustments
+		WHERE registration_id = $1
+		ORDER BY created_at, id
+	`, registrationID)
+	if err != nil {
+		return adjustments, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var adjustment model.RegistrationAdjustment
+		if err := rows.Scan(
+			&adjustment.ID,
+			&adjustment.RegistrationID,
+			&adjustment.Kind,
+			&adjustment.CouponID,
+			&adjustment.FinancialAidID,
+			&adjustment.Description,
+			&adjustment.Amount,
+			&adjustment.CreatedAt,
+		); err != nil {
+			return adjustments, err
+		}
+		adjustments = append(adjustments, adjustment)
+	}
+
+	return adjustments, rows.Err()
+}
+
+// ListRegistrationPlayers returns the players on the registration in the
+// order they were registered.
+func (p Postgres) ListRegistrationPlayers(registrationID string) ([]model.RegistrationPlayer, error) {
+	players := []model.RegistrationPlayer{}
 
-	if err := tx.QueryRow(`
-		SELECT player_ids FROM registrations WHERE id = $1
-	`, registrationID).Scan(&registeredPlayers); err != nil {
-		return registeredPlayers, err
+	rows, err := p.DB.Query(`
+		SELECT
+			registration_players.registration_id, registration_players.player_id,
+			COALESCE(registration_players.season_id, ''), players.first_name,
+			players.last_name, players.division, registration_players.status,
+			registration_players.amount, registration_players.retained,
+			registration_players.offer_expires_at, registration_players.created_at,
+			registration_players.updated_at
+		FROM registration_players
+		JOIN players ON players.id = registration_players.player_id
+		WHERE registration_players.registration_id = $1
+		ORDER BY registration_players.created_at, registration_players.player_id
+	`, registrationID)
+	if err != nil {
+		return players, err
 	}
-	return registeredPlayers, nil
+	defer rows.Close()
+	for rows.Next() {
+		var player model.RegistrationPlayer
+		if err := rows.Scan(
+			&player.RegistrationID,
+			&player.PlayerID,
+			&player.SeasonID,
+			&player.FirstName,
+			&player.LastName,
+			&player.Division,
+			&player.Status,
+			&player.Amount,
+			&player.Retained,
+			&player.OfferExpiresAt,
+			&player.CreatedAt,
+			&player.UpdatedAt,
+		); err != nil {
+			return players, err
+		}
+		players = append(players, player)
+	}
+
+	return players, rows.Err()
 }
 
-func (p Postgres) SetRegistration(tx *sql.Tx, playerIDs pq.StringArray, registrationID string) error {
+// ListRegistrations returns registrations matching the filter. Registrations
+// match a status when any of their players hold it, and match a search on the
+// names or email of the account or the names of their players.
+func (p Postgres) ListRegistrations(filter model.RegistrationFilter) ([]model.Registration, error) {
+	return p.listRegistrations(`
+		SELECT `+registrationColumns+` FROM registrations
+		LEFT JOIN accounts ON accounts.id = registrations.account_id
+		WHERE ($1 = '' OR registrations.season_id = $1)
+		AND ($2 = '' OR EXISTS (
+			SELECT 1 FROM registration_players
+			WHERE registration_id = registrations.id AND status = $2
+		))
+		AND (
+			$3 = ''
+			OR ($3 = 'owing' AND registrations.amount_due > registrations.amount_paid)
+			OR ($3 = 'paid' AND registrations.amount_due <= registrations.amount_paid)
+		)
+		AND (
+			$4 = '' OR accounts.first_name ILIKE '%' || $4 || '%'
+			OR accounts.last_name ILIKE '%' || $4 || '%'
+			OR accounts.email ILIKE '%' || $4 || '%'
+			OR EXISTS (
+				SELECT 1 FROM registration_players
+				JOIN players ON players.id = registration_players.player_id
+				WHERE registration_players.registration_id = registrations.id
+				AND (
+					players.first_name ILIKE '%' || $4 || '%'
+					OR players.last_name ILIKE '%' || $4 || '%'
+				)
+			)
+		)
+		ORDER BY registrations.created_at DESC, registrations.id
+		LIMIT $5 OFFSET $6
+	`,
+		filter.SeasonID, filter.Status, filter.Balance, filter.Search,
+		filter.Limit, filter.Offset,
+	)
+}
+
+func (p Postgres) SetRegistrationAmountDue(tx *sql.Tx, registrationID string, amountDue int) error {
+	results, err := tx.Exec(`
+		UPDATE registrations SET amount_due = $1 WHERE id = $2
+	`, amountDue, registrationID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.New("Registration update failed")
+	}
+
+	return nil
+}
+
+// WithdrawRegistrationPlayer sets the status of a player holding a place on
+// the registration to withdrawn or refunded, keeping the part of their fee
+// retained, and takes them off their team. False is returned when the player
+// does not hold a place on the registration.
+func (p Postgres) WithdrawRegistrationPlayer(tx *sql.Tx, player model.RegistrationPlayer) (bool, error) {
+	results, err := tx.Exec(`
+		UPDATE registration_players
+		SET status = $1, retained = $2, updated_at = now()
+		WHERE registration_id = $3 AND player_id = $4
+		AND status IN ('pending', 'confirmed', 'waitlisted')
+	`, player.Status, player.Retained, player.RegistrationID, player.PlayerID)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rows != 1 {
+		return false, nil
+	}
+
 	if _, err := tx.Exec(`
-		UPDATE registrations SET player_ids = $1 WHERE id = $2
-	`); err != nil {
+		UPDATE players SET team = '' WHERE id = $1
+	`, player.PlayerID); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (p Postgres) listRegistrations(query string, args ...any) ([]model.Registration, error) {
+	registrations := []model.Registration{}
+
+	rows, err := p.DB.Query(query, args...)
+	if err != nil {
+		return registrations, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var registration model.Registration
+		if err := scanRegistration(rows, &registration); err != nil {
+			return registrations, err
+		}
+		registrations = append(registrations, registration)
+	}
+
+	return registrations, rows.Err()
+}
+
+func scanRegistration(row scanner, registration *model.Registration) error {
+	if err := row.Scan(
+		&registration.ID,
+		&registration.AccountID,
+		&registration.SeasonID,
+		&registration.AmountDue,
+		&registration.AmountPaid,
+		&registration.CreatedAt,
+	); err != nil {
 		return err
 	}
+	registration.Balance = registration.AmountDue - registration.AmountPaid
 	return nil
 }
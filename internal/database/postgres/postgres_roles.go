@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"errors"
+
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+)
+
+// GrantRole grants role to an account. Granting a role the account already
+// holds succeeds without changing the original grant.
+func (p Postgres) GrantRole(accountID, role, grantedBy string) error {
+	results, err := p.DB.Exec(`
+		INSERT INTO account_roles (account_id, role, granted_by)
+		SELECT id, $2, $3 FROM accounts WHERE id = $1
+		ON CONFLICT (account_id, role) DO UPDATE SET role = EXCLUDED.role
+	`, accountID, role, grantedBy)
+	if err != nil {
+		return err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.New("Account not found")
+	}
+
+	return nil
+}
+
+func (p Postgres) ListAccountRoles(accountID string) ([]model.AccountRole, error) {
+	roles := []model.AccountRole{}
+
+	rows, err := p.DB.Query(`
+		SELECT role, granted_by, granted_at FROM account_roles
+		WHERE account_id = $1 ORDER BY role
+	`, accountID)
+	if err != nil {
+		return roles, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var role model.AccountRole
+		if err := rows.Scan(
+			&role.Role,
+			&role.GrantedBy,
+			&role.GrantedAt,
+		); err != nil {
+			return roles, err
+		}
+		if role.GrantedBy != "" {
+			role.GrantedBy = util.ReturnSignedToken(role.GrantedBy)
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, rows.Err()
+}
+
+func (p Postgres) RevokeRole(accountID, role string) error {
+	results, err := p.DB.Exec(`
+		DELETE FROM account_roles WHERE account_id = $1 AND role = $2
+	`, accountID, role)
+	if err != nil {
+		return err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.New("Role not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+)
+
+func (p Postgres) CreateSession(session model.Session) error {
+	if _, err := p.DB.Exec(`
+		INSERT INTO sessions (
+			id, account_id, key_hash, label, user_agent, expires_at
+		)
+		VALUES (
+			$1, $2, $3, $4, $5, $6
+		)`,
+		session.ID[:len(session.ID)-1], session.AccountID, session.KeyHash,
+		session.Label, session.UserAgent, session.ExpiresAt,
+	); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (p Postgres) DeleteSession(accountID, sessionID string) error {
+	results, err := p.DB.Exec(`
+		DELETE FROM sessions WHERE id = $1 AND account_id = $2
+	`, sessionID, accountID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.New("Session not found")
+	}
+
+	return nil
+}
+
+// DeleteSessions ends every session for the account.
+func (p Postgres) DeleteSessions(tx *sql.Tx, accountID string) error {
+	if _, err := tx.Exec(`
+		DELETE FROM sessions WHERE account_id = $1
+	`, accountID); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (p Postgres) ListSessions(accountID string) ([]model.Session, error) {
+	sessions := []model.Session{}
+
+	rows, err := p.DB.Query(`
+		SELECT id, label, user_agent, created_at, last_used_at, expires_at
+		FROM sessions WHERE account_id = $1 AND expires_at > now()
+		ORDER BY last_used_at DESC
+	`, accountID)
+	if err != nil {
+		return sessions, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var session model.Session
+		if err := rows.Scan(
+			&session.ID,
+			&session.Label,
+			&session.UserAgent,
+			&session.CreatedAt,
+			&session.LastUsedAt,
+			&session.ExpiresAt,
+		); err != nil {
+			return sessions, err
+		}
+		session.ID = util.ReturnSignedToken(session.ID)
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"errors"
+
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+)
+
+// AssignTeamCoach makes the account a coach of the team. Only accounts
+// holding the coach role can be assigned.
+func (p Postgres) AssignTeamCoach(team, accountID, assignedBy string) error {
+	results, err := p.DB.Exec(`
+		INSERT INTO team_coaches (team, account_id, assigned_by)
+		SELECT $1, account_id, $3 FROM account_roles
+		WHERE account_id = $2 AND role = 'coach'
+		ON CONFLICT (team, account_id) DO UPDATE SET team = EXCLUDED.team
+	`, team, accountID, assignedBy)
+	if err != nil {
+		return err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.New("Coach not found")
+	}
+
+	return nil
+}
+
+// AssignTeamPlayer places the player on the team. Only players holding a
+// place in a season that has not ended can be placed on a team.
+func (p Postgres) AssignTeamPlayer(team, playerID string) error {
+	results, err := p.DB.Exec(`
+		UPDATE players SET team = $1
+		WHERE id = $2 AND EXISTS (
+			SELECT 1 FROM registration_players
+			JOIN seasons ON seasons.id = registration_players.season_id
+			WHERE registration_players.player_id = players.id
+				AND registration_players.status IN ('pending', 'confirmed')
+				AND seasons.end_date >= to_char(now(), 'YYYY-MM-DD')
+		)
+	`, team, playerID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.New("Player not found")
+	}
+
+	return nil
+}
+
+// IsTeamCoach reports whether the account coaches the team. Coaches whose
+// coach role has been revoked no longer count.
+func (p Postgres) IsTeamCoach(accountID, team string) (bool, error) {
+	var isCoach bool
+
+	row := p.DB.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM team_coaches
+			JOIN account_roles ON account_roles.account_id = team_coaches.account_id
+			WHERE team_coaches.team = $1 AND team_coaches.account_id = $2
+			AND account_roles.role = 'coach'
+		)
+	`, team, accountID)
+	if err := row.Scan(&isCoach); err != nil {
+		return false, err
+	}
+
+	return isCoach, nil
+}
+
+func (p Postgres) ListTeamCoaches(team string) ([]model.TeamCoach, error) {
+	coaches := []model.TeamCoach{}
+
+	rows, err := p.DB.Query(`
+		SELECT team, account_id, assigned_by, assigned_at FROM team_coaches
+		WHERE team = $1 ORDER BY assigned_at, account_id
+	`, team)
+	if err != nil {
+		return coaches, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var coach model.TeamCoach
+		if err := rows.Scan(
+			&coach.Team,
+			&coach.AccountID,
+			&coach.AssignedBy,
+			&coach.AssignedAt,
+		); err != nil {
+			return coaches, err
+		}
+		coach.AccountID = util.ReturnSignedToken(coach.AccountID)
+		if coach.AssignedBy != "" {
+			coach.AssignedBy = util.ReturnSignedToken(coach.AssignedBy)
+		}
+		coaches = append(coaches, coach)
+	}
+
+	return coaches, rows.Err()
+}
+
+func (p Postgres) RemoveTeamCoach(team, accountID string) error {
+	results, err := p.DB.Exec(`
+		DELETE FROM team_coaches WHERE team = $1 AND account_id = $2
+	`, team, accountID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.New("Coach not found")
+	}
+
+	return nil
+}
+
+func (p Postgres) RemoveTeamPlayer(team, playerID string) error {
+	results, err := p.DB.Exec(`
+		UPDATE players SET team = '' WHERE team = $1 AND id = $2
+	`, team, playerID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.New("Player not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,227 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/Leagueify/api/internal/model"
+	"github.com/lib/pq"
+)
+
+func (p Postgres) CreateLoginChallenge(challenge model.LoginChallenge) error {
+	if _, err := p.DB.Exec(`
+		INSERT INTO login_challenges (
+			id, account_id, token_hash, label, expires_at
+		)
+		VALUES (
+			$1, $2, $3, $4, $5
+		)`,
+		challenge.ID[:len(challenge.ID)-1], challenge.AccountID,
+		challenge.TokenHash, challenge.Label, challenge.ExpiresAt,
+	); err != nil {
+		return err
+	}
+	return nil
+}
+
+// CreateTwoFactor stores a pending secret for the account, replacing any
+// earlier enrollment that was never confirmed.
+func (p Postgres) CreateTwoFactor(accountID, secret string) error {
+	results, err := p.DB.Exec(`
+		INSERT INTO two_factor (account_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (account_id) DO UPDATE
+		SET secret = EXCLUDED.secret, last_counter = 0, created_at = now()
+		WHERE two_factor.enabled = false
+	`, accountID, secret)
+	if err != nil {
+		return err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.New("Two-factor authentication already enabled")
+	}
+
+	return nil
+}
+
+// DeleteLoginChallenge spends the challenge so it completes a single login.
+func (p Postgres) DeleteLoginChallenge(challengeID string) error {
+	results, err := p.DB.Exec(`
+		DELETE FROM login_challenges WHERE id = $1
+	`, challengeID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.New("Login challenge not found")
+	}
+
+	return nil
+}
+
+// DeleteTwoFactor removes the account's secret, recovery codes and any
+// outstanding login challenges.
+func (p Postgres) DeleteTwoFactor(tx *sql.Tx, accountID string) error {
+	results, err := tx.Exec(`
+		DELETE FROM two_factor WHERE account_id = $1
+	`, accountID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.New("Two-factor authentication not found")
+	}
+
+	if _, err := tx.Exec(`
+		DELETE FROM recovery_codes WHERE account_id = $1
+	`, accountID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM login_challenges WHERE account_id = $1
+	`, accountID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// EnableTwoFactor confirms a pending enrollment. counter is the time step of
+// the code used to confirm it, which cannot be used again.
+func (p Postgres) EnableTwoFactor(tx *sql.Tx, accountID string, counter int64) error {
+	results, err := tx.Exec(`
+		UPDATE two_factor
+		SET enabled = true, enabled_at = now(), last_counter = $2
+		WHERE account_id = $1 AND enabled = false
+	`, accountID, counter)
+	if err != nil {
+		return err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.New("Two-factor enrollment not found")
+	}
+
+	return nil
+}
+
+// GetLoginChallenge returns the unexpired challenge issued for tokenHash.
+func (p Postgres) GetLoginChallenge(tokenHash string) (model.LoginChallenge, error) {
+	var challenge model.LoginChallenge
+
+	if err := p.DB.QueryRow(`
+		SELECT id, account_id, token_hash, label, expires_at
+		FROM login_challenges WHERE token_hash = $1 AND expires_at > now()
+	`, tokenHash).Scan(
+		&challenge.ID,
+		&challenge.AccountID,
+		&challenge.TokenHash,
+		&challenge.Label,
+		&challenge.ExpiresAt,
+	); err != nil {
+		return challenge, err
+	}
+
+	return challenge, nil
+}
+
+func (p Postgres) GetTwoFactor(accountID string) (model.TwoFactor, error) {
+	var twoFactor model.TwoFactor
+
+	if err := p.DB.QueryRow(`
+		SELECT two_factor.account_id, secret, enabled, last_counter, (
+			SELECT COUNT(*) FROM recovery_codes
+			WHERE recovery_codes.account_id = two_factor.account_id
+			AND used_at IS NULL
+		)
+		FROM two_factor WHERE two_factor.account_id = $1
+	`, accountID).Scan(
+		&twoFactor.AccountID,
+		&twoFactor.Secret,
+		&twoFactor.Enabled,
+		&twoFactor.LastCounter,
+		&twoFactor.RecoveryCodes,
+	); err != nil {
+		return twoFactor, err
+	}
+
+	return twoFactor, nil
+}
+
+// ReplaceRecoveryCodes discards the account's recovery codes in favour of
+// the given hashes.
+func (p Postgres) ReplaceRecoveryCodes(tx *sql.Tx, accountID string, codeHashes []string) error {
+	if _, err := tx.Exec(`
+		DELETE FROM recovery_codes WHERE account_id = $1
+	`, accountID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO recovery_codes (account_id, code_hash)
+		SELECT $1, unnest($2::TEXT[])
+	`, accountID, pq.Array(codeHashes)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpdateTwoFactorCounter records the time step of an accepted code. Only a
+// later step is accepted so concurrent requests cannot reuse the same code.
+func (p Postgres) UpdateTwoFactorCounter(accountID string, counter int64) error {
+	results, err := p.DB.Exec(`
+		UPDATE two_factor SET last_counter = $2
+		WHERE account_id = $1 AND enabled = true AND last_counter < $2
+	`, accountID, counter)
+	if err != nil {
+		return err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.New("Two-factor code already used")
+	}
+
+	return nil
+}
+
+func (p Postgres) UseRecoveryCode(accountID, codeHash string) error {
+	results, err := p.DB.Exec(`
+		UPDATE recovery_codes SET used_at = now()
+		WHERE account_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`, accountID, codeHash)
+	if err != nil {
+		return err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.New("Recovery code not found")
+	}
+
+	return nil
+}
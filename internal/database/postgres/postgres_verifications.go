@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/Leagueify/api/internal/model"
+)
+
+func (p Postgres) ConsumeAccountVerification(tx *sql.Tx, accountID, tokenHash string) error {
+	results, err := tx.Exec(`
+		UPDATE account_verifications SET used_at = now()
+		WHERE account_id = $1 AND token_hash = $2 AND used_at IS NULL
+			AND expires_at > now()
+	`, accountID, tokenHash)
+	if err != nil {
+		return err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.New("Account verification failed")
+	}
+
+	return nil
+}
+
+func (p Postgres) CreateAccountVerification(verification model.AccountVerificationToken) error {
+	tx, err := p.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	// only the most recently sent link can verify the account
+	if _, err := tx.Exec(`
+		UPDATE account_verifications SET used_at = now()
+		WHERE account_id = $1 AND used_at IS NULL
+	`, verification.AccountID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO account_verifications (
+			id, account_id, token_hash, expires_at
+		)
+		VALUES ($1, $2, $3, $4)
+	`, verification.ID[:len(verification.ID)-1], verification.AccountID,
+		verification.TokenHash, verification.ExpiresAt,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
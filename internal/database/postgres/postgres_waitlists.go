@@ -0,0 +1,416 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+
+	"github.com/Leagueify/api/internal/model"
+)
+
+// divisionCapacityQuery returns the capacities of a season's divisions with
+// the players holding, offered and waiting for a place in each. Capacities
+// of a single division are returned when one is given.
+const divisionCapacityQuery = `
+	SELECT
+		division_capacities.season_id, division_capacities.division,
+		division_capacities.capacity, division_capacities.offer_hours,
+		COUNT(*) FILTER (
+			WHERE registration_players.status IN ('pending', 'confirmed')
+		),
+		COUNT(*) FILTER (
+			WHERE registration_players.status = 'waitlisted'
+			AND registration_players.offer_expires_at > now()
+		),
+		COUNT(*) FILTER (WHERE registration_players.status = 'waitlisted')
+	FROM division_capacities
+	LEFT JOIN (
+		registration_players
+		JOIN players ON players.id = registration_players.player_id
+	) ON registration_players.season_id = division_capacities.season_id
+		AND players.division = division_capacities.division
+	WHERE division_capacities.season_id = $1
+	AND ($2 = '' OR division_capacities.division = $2)
+	GROUP BY division_capacities.season_id, division_capacities.division
+	ORDER BY division_capacities.division
+`
+
+// AcceptWaitlistOffer gives a waitlisted player the place they were offered.
+// False is returned when the player holds no offer or it has expired.
+func (p Postgres) AcceptWaitlistOffer(tx *sql.Tx, registrationID, playerID string) (bool, error) {
+	return updateWaitlistedPlayer(tx, `
+		UPDATE registration_players SET
+			status = 'pending', waitlist_position = NULL,
+			offer_expires_at = NULL, updated_at = now()
+		WHERE registration_id = $1 AND player_id = $2
+		AND status = 'waitlisted' AND offer_expires_at > now()
+	`, registrationID, playerID)
+}
+
+// DeclineWaitlistPlace takes a waitlisted player off the waitlist, giving up
+// any place they were offered. False is returned when the player is not
+// waitlisted.
+func (p Postgres) DeclineWaitlistPlace(tx *sql.Tx, registrationID, playerID string) (bool, error) {
+	return updateWaitlistedPlayer(tx, `
+		UPDATE registration_players SET
+			status = 'withdrawn', waitlist_position = NULL,
+			offer_expires_at = NULL, updated_at = now()
+		WHERE registration_id = $1 AND player_id = $2 AND status = 'waitlisted'
+	`, registrationID, playerID)
+}
+
+// GetDivisionCapacityForUpdate returns the capacity of the season's division,
+// locking it until the transaction ends. Every change to who holds a place in
+// the division takes this lock first, so the counts returned stay accurate
+// until then.
+func (p Postgres) GetDivisionCapacityForUpdate(tx *sql.Tx, seasonID, division string) (model.DivisionCapacity, error) {
+	var capacity model.DivisionCapacity
+
+	// the lock is taken before counting so the counts include the players
+	// placed by whoever held it before
+	if err := tx.QueryRow(`
+		SELECT season_id FROM division_capacities
+		WHERE season_id = $1 AND division = $2
+		FOR UPDATE
+	`, seasonID, division).Scan(&capacity.SeasonID); err != nil {
+		return capacity, err
+	}
+
+	if err := scanDivisionCapacity(
+		tx.QueryRow(divisionCapacityQuery, seasonID, division), &capacity,
+	); err != nil {
+		return capacity, err
+	}
+
+	return capacity, nil
+}
+
+// ListDivisionCapacities returns the capacities of the season's divisions in
+// division order.
+func (p Postgres) ListDivisionCapacities(seasonID string) ([]model.DivisionCapacity, error) {
+	capacities := []model.DivisionCapacity{}
+
+	rows, err := p.DB.Query(divisionCapacityQuery, seasonID, "")
+	if err != nil {
+		return capacities, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var capacity model.DivisionCapacity
+		if err := scanDivisionCapacity(rows, &capacity); err != nil {
+			return capacities, err
+		}
+		capacities = append(capacities, capacity)
+	}
+
+	return capacities, rows.Err()
+}
+
+// ListWaitlist returns the season's waitlisted players in the order they will
+// be offered a place, numbered from one within each division.
+func (p Postgres) ListWaitlist(seasonID string, filter model.WaitlistFilter) ([]model.WaitlistEntry, error) {
+	entries := []model.WaitlistEntry{}
+
+	rows, err := p.DB.Query(`
+		SELECT
+			registration_players.registration_id, registration_players.player_id,
+			registration_players.season_id, players.first_name, players.last_name,
+			players.division, ROW_NUMBER() OVER (
+				PARTITION BY players.division
+				ORDER BY registration_players.waitlist_position
+			), registration_players.offer_expires_at,
+			registration_players.created_at
+		FROM registration_players
+		JOIN players ON players.id = registration_players.player_id
+		WHERE registration_players.season_id = $1
+		AND registration_players.status = 'waitlisted'
+		AND ($2 = '' OR players.division = $2)
+		ORDER BY players.division, registration_players.waitlist_position
+	`, seasonID, filter.Division)
+	if err != nil {
+		return entries, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var entry model.WaitlistEntry
+		if err := rows.Scan(
+			&entry.RegistrationID,
+			&entry.PlayerID,
+			&entry.SeasonID,
+			&entry.FirstName,
+			&entry.LastName,
+			&entry.Division,
+			&entry.Position,
+			&entry.OfferExpiresAt,
+			&entry.CreatedAt,
+		); err != nil {
+			return entries, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// MoveWaitlistEntry moves a waitlisted player to position within their
+// division's waitlist, or to the back of it when position is past the end.
+// The positions the division's players hold are handed out again in the new
+// order, so players waitlisted later still queue behind them. False is
+// returned when the player is not waitlisted.
+func (p Postgres) MoveWaitlistEntry(tx *sql.Tx, entry model.WaitlistEntry, position int) (bool, error) {
+	rows, err := tx.Query(`
+		SELECT registration_players.player_id, registration_players.waitlist_position
+		FROM registration_players
+		JOIN players ON players.id = registration_players.player_id
+		WHERE registration_players.season_id = $1 AND players.division = $2
+		AND registration_players.status = 'waitlisted'
+		ORDER BY registration_players.waitlist_position
+		FOR UPDATE OF registration_players
+	`, entry.SeasonID, entry.Division)
+	if err != nil {
+		return false, err
+	}
+	playerIDs := []string{}
+	positions := []int64{}
+	for rows.Next() {
+		var playerID string
+		var position int64
+		if err := rows.Scan(&playerID, &position); err != nil {
+			rows.Close()
+			return false, err
+		}
+		playerIDs = append(playerIDs, playerID)
+		positions = append(positions, position)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+
+	index := slices.Index(playerIDs, entry.PlayerID)
+	if index == -1 {
+		return false, nil
+	}
+	playerIDs = slices.Delete(playerIDs, index, index+1)
+	playerIDs = slices.Insert(playerIDs, min(position, len(playerIDs)+1)-1, entry.PlayerID)
+	for index, playerID := range playerIDs {
+		if _, err := tx.Exec(`
+			UPDATE registration_players
+			SET waitlist_position = $1, updated_at = now()
+			WHERE season_id = $2 AND player_id = $3
+		`, positions[index], entry.SeasonID, playerID); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// OfferWaitlistPlaces offers the places open in the season's division to the
+// players next on its waitlist, returning the offers made. Each offer holds a
+// place for the division's offer hours. The division's capacity is locked
+// until the transaction ends, and divisions without one have no waitlist to
+// offer places to.
+func (p Postgres) OfferWaitlistPlaces(tx *sql.Tx, seasonID, division string) ([]model.WaitlistOffer, error) {
+	offers := []model.WaitlistOffer{}
+
+	capacity, err := p.GetDivisionCapacityForUpdate(tx, seasonID, division)
+	if errors.Is(err, sql.ErrNoRows) {
+		return offers, nil
+	}
+	if err != nil {
+		return offers, err
+	}
+	open := capacity.Capacity - capacity.Placed - capacity.Offered
+	if open <= 0 {
+		return offers, nil
+	}
+
+	rows, err := tx.Query(`
+		WITH offered AS (
+			UPDATE registration_players SET
+				offer_expires_at = now() + make_interval(hours => $3),
+				updated_at = now()
+			WHERE (registration_id, player_id) IN (
+				SELECT
+					registration_players.registration_id,
+					registration_players.player_id
+				FROM registration_players
+				JOIN players ON players.id = registration_players.player_id
+				WHERE registration_players.season_id = $1
+				AND players.division = $2
+				AND registration_players.status = 'waitlisted'
+				AND registration_players.offer_expires_at IS NULL
+				ORDER BY registration_players.waitlist_position
+				LIMIT $4
+			)
+			RETURNING registration_id, player_id, waitlist_position, offer_expires_at
+		)
+		SELECT
+			offered.registration_id, offered.player_id,
+			TRIM(players.first_name || ' ' || players.last_name), players.division,
+			seasons.name, COALESCE(accounts.email, ''),
+			COALESCE(accounts.first_name, ''), offered.offer_expires_at
+		FROM offered
+		JOIN players ON players.id = offered.player_id
+		JOIN registrations ON registrations.id = offered.registration_id
+		JOIN seasons ON seasons.id = registrations.season_id
+		LEFT JOIN accounts ON accounts.id = registrations.account_id
+		ORDER BY offered.waitlist_position
+	`, seasonID, division, capacity.OfferHours, open)
+	if err != nil {
+		return offers, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var offer model.WaitlistOffer
+		if err := rows.Scan(
+			&offer.RegistrationID,
+			&offer.PlayerID,
+			&offer.PlayerName,
+			&offer.Division,
+			&offer.SeasonName,
+			&offer.Email,
+			&offer.FirstName,
+			&offer.ExpiresAt,
+		); err != nil {
+			return offers, err
+		}
+		offers = append(offers, offer)
+	}
+
+	return offers, rows.Err()
+}
+
+// PromoteWaitlistedPlayer gives a waitlisted player a place whether or not
+// their division has room. False is returned when the player is not
+// waitlisted.
+func (p Postgres) PromoteWaitlistedPlayer(tx *sql.Tx, registrationID, playerID string) (bool, error) {
+	return updateWaitlistedPlayer(tx, `
+		UPDATE registration_players SET
+			status = 'pending', waitlist_position = NULL,
+			offer_expires_at = NULL, updated_at = now()
+		WHERE registration_id = $1 AND player_id = $2 AND status = 'waitlisted'
+	`, registrationID, playerID)
+}
+
+// RefreshWaitlists withdraws the waitlisted players whose offers have expired
+// and offers the places open in each division with a waitlist to the players
+// next in line, returning the offers made. Each division is refreshed in its
+// own transaction.
+func (p Postgres) RefreshWaitlists() ([]model.WaitlistOffer, error) {
+	offers := []model.WaitlistOffer{}
+
+	rows, err := p.DB.Query(`
+		SELECT DISTINCT division_capacities.season_id, division_capacities.division
+		FROM division_capacities
+		JOIN registration_players
+			ON registration_players.season_id = division_capacities.season_id
+		JOIN players ON players.id = registration_players.player_id
+			AND players.division = division_capacities.division
+		WHERE registration_players.status = 'waitlisted'
+		ORDER BY division_capacities.season_id, division_capacities.division
+	`)
+	if err != nil {
+		return offers, err
+	}
+	divisions := []model.DivisionCapacity{}
+	for rows.Next() {
+		var division model.DivisionCapacity
+		if err := rows.Scan(&division.SeasonID, &division.Division); err != nil {
+			rows.Close()
+			return offers, err
+		}
+		divisions = append(divisions, division)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return offers, err
+	}
+
+	for _, division := range divisions {
+		made, err := p.refreshWaitlist(division.SeasonID, division.Division)
+		if err != nil {
+			return offers, err
+		}
+		offers = append(offers, made...)
+	}
+
+	return offers, nil
+}
+
+// SetDivisionCapacity creates or replaces the capacity of a season's
+// division, locking it until the transaction ends.
+func (p Postgres) SetDivisionCapacity(tx *sql.Tx, capacity model.DivisionCapacity) error {
+	if _, err := tx.Exec(`
+		INSERT INTO division_capacities (
+			season_id, division, capacity, offer_hours
+		)
+		VALUES (
+			$1, $2, $3, $4
+		)
+		ON CONFLICT (season_id, division) DO UPDATE SET
+			capacity = EXCLUDED.capacity,
+			offer_hours = EXCLUDED.offer_hours,
+			updated_at = now()
+	`,
+		capacity.SeasonID, capacity.Division, capacity.Capacity,
+		capacity.OfferHours,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (p Postgres) refreshWaitlist(seasonID, division string) ([]model.WaitlistOffer, error) {
+	tx, err := p.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if _, err := p.GetDivisionCapacityForUpdate(tx, seasonID, division); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`
+		UPDATE registration_players SET
+			status = 'withdrawn', waitlist_position = NULL,
+			offer_expires_at = NULL, updated_at = now()
+		WHERE season_id = $1 AND status = 'waitlisted'
+		AND offer_expires_at <= now()
+		AND player_id IN (SELECT id FROM players WHERE division = $2)
+	`, seasonID, division); err != nil {
+		return nil, err
+	}
+	offers, err := p.OfferWaitlistPlaces(tx, seasonID, division)
+	if err != nil {
+		return nil, err
+	}
+	return offers, tx.Commit()
+}
+
+func updateWaitlistedPlayer(tx *sql.Tx, query, registrationID, playerID string) (bool, error) {
+	results, err := tx.Exec(query, registrationID, playerID)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
+
+func scanDivisionCapacity(row scanner, capacity *model.DivisionCapacity) error {
+	return row.Scan(
+		&capacity.SeasonID,
+		&capacity.Division,
+		&capacity.Capacity,
+		&capacity.OfferHours,
+		&capacity.Placed,
+		&capacity.Offered,
+		&capacity.Waitlisted,
+	)
+}
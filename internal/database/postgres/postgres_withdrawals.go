@@ -0,0 +1,176 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/Leagueify/api/internal/model"
+)
+
+const withdrawalColumns = `
+	id, registration_id, player_id, reason, status, refund_amount, refunded,
+	note, reviewed_by, reviewed_at, created_at
+`
+
+// CreateWithdrawal records a withdrawal request. A player may only have one
+// withdrawal awaiting review, so false is returned when they already have one.
+func (p Postgres) CreateWithdrawal(withdrawal model.Withdrawal) (bool, error) {
+	results, err := p.DB.Exec(`
+		INSERT INTO withdrawals (
+			id, registration_id, player_id, reason, refund_amount
+		)
+		VALUES (
+			$1, $2, $3, $4, $5
+		)
+		ON CONFLICT (registration_id, player_id) WHERE status = 'pending' DO NOTHING
+	`,
+		withdrawal.ID, withdrawal.RegistrationID, withdrawal.PlayerID,
+		withdrawal.Reason, withdrawal.RefundAmount,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
+
+// GetRefundPolicy returns the season's refund policy. Seasons without one get
+// an empty policy, which refunds nothing.
+func (p Postgres) GetRefundPolicy(seasonID string) (model.RefundPolicy, error) {
+	policy := model.RefundPolicy{SeasonID: seasonID}
+
+	err := p.DB.QueryRow(`
+		SELECT full_refund_until, partial_refund_until, partial_refund_percent
+		FROM refund_policies WHERE season_id = $1
+	`, seasonID).Scan(
+		&policy.FullRefundUntil,
+		&policy.PartialRefundUntil,
+		&policy.PartialRefundPercent,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return policy, nil
+	}
+
+	return policy, err
+}
+
+func (p Postgres) GetWithdrawal(withdrawalID string) (model.Withdrawal, error) {
+	var withdrawal model.Withdrawal
+
+	if err := scanWithdrawal(p.DB.QueryRow(`
+		SELECT `+withdrawalColumns+` FROM withdrawals WHERE id = $1
+	`, withdrawalID), &withdrawal); err != nil {
+		return withdrawal, err
+	}
+
+	return withdrawal, nil
+}
+
+// ListRegistrationWithdrawals returns the registration's withdrawals, oldest
+// first.
+func (p Postgres) ListRegistrationWithdrawals(registrationID string) ([]model.Withdrawal, error) {
+	return p.listWithdrawals(`
+		SELECT `+withdrawalColumns+` FROM withdrawals
+		WHERE registration_id = $1
+		ORDER BY created_at, id
+	`, registrationID)
+}
+
+// ListWithdrawals returns withdrawals with the status, or every withdrawal
+// when no status is given, oldest first.
+func (p Postgres) ListWithdrawals(filter model.WithdrawalFilter) ([]model.Withdrawal, error) {
+	return p.listWithdrawals(`
+		SELECT `+withdrawalColumns+` FROM withdrawals
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at, id
+	`, filter.Status)
+}
+
+// ReviewWithdrawal records the decision on a withdrawal. Withdrawals are only
+// reviewed once, so false is returned when it is no longer pending.
+func (p Postgres) ReviewWithdrawal(tx *sql.Tx, withdrawal model.Withdrawal) (bool, error) {
+	results, err := tx.Exec(`
+		UPDATE withdrawals SET
+			status = $1, refund_amount = $2, refunded = $3, note = $4,
+			reviewed_by = $5, reviewed_at = now(), updated_at = now()
+		WHERE id = $6 AND status = 'pending'
+	`,
+		withdrawal.Status, withdrawal.RefundAmount, withdrawal.Refunded,
+		withdrawal.Note, withdrawal.ReviewedBy, withdrawal.ID,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := results.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
+
+// SetRefundPolicy creates or replaces the season's refund policy.
+func (p Postgres) SetRefundPolicy(policy model.RefundPolicy) error {
+	if _, err := p.DB.Exec(`
+		INSERT INTO refund_policies (
+			season_id, full_refund_until, partial_refund_until,
+			partial_refund_percent
+		)
+		VALUES (
+			$1, $2, $3, $4
+		)
+		ON CONFLICT (season_id) DO UPDATE SET
+			full_refund_until = EXCLUDED.full_refund_until,
+			partial_refund_until = EXCLUDED.partial_refund_until,
+			partial_refund_percent = EXCLUDED.partial_refund_percent,
+			updated_at = now()
+	`,
+		policy.SeasonID, policy.FullRefundUntil, policy.PartialRefundUntil,
+		policy.PartialRefundPercent,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (p Postgres) listWithdrawals(query string, args ...any) ([]model.Withdrawal, error) {
+	withdrawals := []model.Withdrawal{}
+
+	rows, err := p.DB.Query(query, args...)
+	if err != nil {
+		return withdrawals, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var withdrawal model.Withdrawal
+		if err := scanWithdrawal(rows, &withdrawal); err != nil {
+			return withdrawals, err
+		}
+		withdrawals = append(withdrawals, withdrawal)
+	}
+
+	return withdrawals, rows.Err()
+}
+
+func scanWithdrawal(row scanner, withdrawal *model.Withdrawal) error {
+	return row.Scan(
+		&withdrawal.ID,
+		&withdrawal.RegistrationID,
+		&withdrawal.PlayerID,
+		&withdrawal.Reason,
+		&withdrawal.Status,
+		&withdrawal.RefundAmount,
+		&withdrawal.Refunded,
+		&withdrawal.Note,
+		&withdrawal.ReviewedBy,
+		&withdrawal.ReviewedAt,
+		&withdrawal.CreatedAt,
+	)
+}
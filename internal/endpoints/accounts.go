@@ -1,20 +1,34 @@
 package api
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/Leagueify/api/internal/auth"
+	"github.com/Leagueify/api/internal/config"
 	"github.com/Leagueify/api/internal/model"
 	"github.com/Leagueify/api/internal/util"
 	"github.com/labstack/echo/v4"
+	"github.com/lib/pq"
 )
 
+// minimumAccountAge is the youngest an account holder can be.
+const minimumAccountAge = 18
+
 func (api *API) Accounts(e *echo.Group) {
 	e.POST("/accounts", api.createAccount)
 	e.POST("/accounts/:id/verify", api.verifyAccount)
+	e.POST("/accounts/verify/resend", api.resendVerification)
 	e.POST("/accounts/login", api.loginAccount)
 	e.POST("/accounts/logout", api.requiresAuth(api.logoutAccount))
+	e.GET("/accounts/me", api.requiresAuth(api.getProfile))
+	e.PATCH("/accounts/me", api.requiresAuth(api.updateProfile))
+	e.DELETE("/accounts/me", api.requiresAuth(api.deleteProfile))
 }
 
 func (api *API) createAccount(c echo.Context) (err error) {
@@ -27,14 +41,9 @@ func (api *API) createAccount(c echo.Context) (err error) {
 	if err := c.Validate(account); err != nil {
 		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
 	}
-	// Calculate Age
-	today := time.Now().Format(time.DateOnly)
-	age, err := util.CalculateAge(account.DateOfBirth, today)
-	if err != nil {
-		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
-	}
-	if age < 18 {
-		return util.SendStatus(http.StatusBadRequest, c, "must be 18 or older to create an account")
+	// Account holders must be adults
+	if detail := checkAccountAge(account.DateOfBirth); detail != "" {
+		return util.SendStatus(http.StatusBadRequest, c, detail)
 	}
 	// Set account.ID overriding provided ID
 	account.ID = util.SignedToken(8)
@@ -63,6 +72,12 @@ func (api *API) createAccount(c echo.Context) (err error) {
 	if err := api.DB.CreateAccount(account); err != nil {
 		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
 	}
+	// Send verification email to inactive accounts
+	if !account.IsActive {
+		if err := api.sendVerification(account.ID, account.FirstName, account.Email); err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+	}
 	// Successful Account Creation
 	return c.JSON(http.StatusCreated,
 		map[string]string{
@@ -71,41 +86,251 @@ func (api *API) createAccount(c echo.Context) (err error) {
 	)
 }
 
+// deleteProfile removes the account along with the players no other guardian
+// looks after and who have never been registered. Players registered in the
+// past are kept without a guardian so their registration history remains.
+// Accounts with players registered for a current season must withdraw them
+// first, and the last administrator cannot be removed.
+func (api *API) deleteProfile(c echo.Context) error {
+	account := getAccount(c)
+	payload := model.AccountDeletion{}
+	// bind payload to model
+	if err := c.Bind(&payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	if !auth.ComparePasswords(payload.Password, account.Password) {
+		return util.SendStatus(http.StatusUnauthorized, c, "")
+	}
+	for _, playerID := range account.Players {
+		player, err := api.DB.GetPlayer(playerID)
+		if err != nil {
+			continue
+		}
+		if player.IsRegistered {
+			return util.SendStatus(
+				http.StatusConflict, c,
+				"registered players must be withdrawn before deleting the account",
+			)
+		}
+	}
+	// Begin Transaction
+	tx, err := api.DB.BeginTransaction()
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	defer tx.Rollback()
+	if account.IsAdmin {
+		totalAdmins, err := api.DB.GetTotalAdmins(tx)
+		if err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+		if totalAdmins <= 1 {
+			return util.SendStatus(http.StatusConflict, c, "last administrator cannot be deleted")
+		}
+	}
+	if err := api.DB.ReleasePlayers(tx, account.ID); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if err := api.DB.DeleteAccount(tx, account.ID); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if err := tx.Commit(); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (api *API) getProfile(c echo.Context) error {
+	return c.JSON(http.StatusOK, accountProfile(getAccount(c)))
+}
+
+// loginAccount exchanges credentials for an API key. Addresses with too many
+// recent failures are refused, and accounts back off exponentially after
+// repeated failures until they are locked. Accounts with two-factor
+// authentication receive a challenge to complete with verifyLogin instead.
 func (api *API) loginAccount(c echo.Context) error {
 	credentials := &model.AccountLogin{}
 	if err := c.Bind(&credentials); err != nil {
 		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
 	}
+	attempt := model.LoginAttempt{
+		Email:     credentials.Email,
+		IPAddress: c.RealIP(),
+	}
+	// Refuse addresses with too many recent failures
+	failures, err := api.DB.GetFailedLoginsByIP(
+		attempt.IPAddress, time.Now().Add(-auth.LoginAttemptWindow),
+	)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if failures >= auth.LoginIPThreshold {
+		if failures == auth.LoginIPThreshold {
+			if err := api.DB.CreateLockoutEvent(model.LockoutEvent{
+				IPAddress: attempt.IPAddress,
+				Event:     auth.LockoutEventIPBlocked,
+			}); err != nil {
+				return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+			}
+		}
+		if err := api.DB.RecordLoginAttempt(attempt); err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+		return tooManyLoginAttempts(c, auth.LoginAttemptWindow)
+	}
 	account, err := api.DB.GetAccountByEmail(credentials.Email)
 	if err != nil {
-		return util.SendStatus(http.StatusUnauthorized, c, "")
+		// compare against no password so unknown emails take as long to
+		// refuse as a wrong password
+		auth.ComparePasswords(credentials.Password, "")
+		return api.failLogin(c, attempt)
+	}
+	attempt.AccountID = account.ID
+	// Refuse accounts that are backing off or locked
+	lockout, err := api.DB.GetLoginLockout(account.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if wait := time.Until(lockout.LockedUntil); wait > 0 {
+		if err := api.DB.RecordLoginAttempt(attempt); err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+		return tooManyLoginAttempts(c, wait)
 	}
 
 	if !auth.ComparePasswords(credentials.Password, account.Password) {
-		return util.SendStatus(http.StatusUnauthorized, c, "")
+		return api.failLogin(c, attempt)
 	}
 
-	// Generate API Key
-	apikey := util.SignedToken(64)
-	if err := api.DB.SetAPIKey(apikey, account.ID); err != nil {
-		return util.SendStatus(http.StatusUnauthorized, c, "")
+	// Accounts with two-factor authentication finish logging in with a code
+	twoFactor, err := api.DB.GetTwoFactor(account.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if twoFactor.Enabled {
+		return api.challengeLogin(c, account.ID, credentials.Label)
 	}
 
-	// Return API Key
+	return api.completeLogin(c, attempt, lockout.FailedAttempts, credentials.Label)
+}
+
+func (api *API) logoutAccount(c echo.Context) error {
+	account := getAccount(c)
+	if err := api.DB.DeleteSession(account.ID, account.SessionID); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+
+	return c.JSON(http.StatusOK, "{}")
+}
+
+func (api *API) resendVerification(c echo.Context) error {
+	payload := model.AccountVerificationResend{}
+	// bind payload to model
+	if err := c.Bind(&payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	// unknown and active accounts receive the same response
+	account, err := api.DB.GetAccountByEmail(payload.Email)
+	if err != nil || account.IsActive {
+		return c.JSON(http.StatusOK,
+			map[string]string{
+				"status": "successful",
+			},
+		)
+	}
+	if err := api.sendVerification(
+		util.ReturnSignedToken(account.ID), account.FirstName, account.Email,
+	); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
 	return c.JSON(http.StatusOK,
 		map[string]string{
 			"status": "successful",
-			"apikey": apikey,
 		},
 	)
 }
 
-func (api *API) logoutAccount(c echo.Context) error {
-	if err := api.DB.SetAPIKey(" ", api.Account.ID); err != nil {
+// updateProfile applies the provided fields to the account. A new email
+// address must be verified again when email is configured, and a new
+// password requires the current password and ends every session.
+func (api *API) updateProfile(c echo.Context) error {
+	account := getAccount(c)
+	payload := model.AccountUpdate{}
+	// bind payload to model
+	if err := c.Bind(&payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	if payload.FirstName != nil {
+		account.FirstName = *payload.FirstName
+	}
+	if payload.LastName != nil {
+		account.LastName = *payload.LastName
+	}
+	if payload.Phone != nil {
+		account.Phone = *payload.Phone
+	}
+	if payload.Coach != nil {
+		account.Coach = *payload.Coach
+	}
+	if payload.Volunteer != nil {
+		account.Volunteer = *payload.Volunteer
+	}
+	if payload.Password != nil {
+		if !auth.ComparePasswords(payload.CurrentPassword, account.Password) {
+			return util.SendStatus(http.StatusBadRequest, c, "invalid current password")
+		}
+		if err := auth.HashPassword(payload.Password); err != nil {
+			return util.SendStatus(http.StatusBadRequest, c, err.Error())
+		}
+	}
+	if payload.Email != nil && *payload.Email != account.Email {
+		account.Email = *payload.Email
+		emailConfig, err := api.DB.GetTotalEmailConfigs()
+		if err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+		if emailConfig != 0 {
+			account.IsActive = false
+		}
+	}
+	// Begin Transaction
+	tx, err := api.DB.BeginTransaction()
+	if err != nil {
 		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
 	}
-
-	return c.JSON(http.StatusOK, "{}")
+	defer tx.Rollback()
+	if err := api.DB.UpdateAccount(tx, account); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	if payload.Password != nil {
+		if err := api.DB.UpdatePassword(tx, account.ID, *payload.Password); err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	// Send verification email to the new address
+	if !account.IsActive {
+		if err := api.sendVerification(
+			util.ReturnSignedToken(account.ID), account.FirstName, account.Email,
+		); err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+	}
+	return c.JSON(http.StatusOK, accountProfile(account))
 }
 
 func (api *API) verifyAccount(c echo.Context) (err error) {
@@ -114,12 +339,68 @@ func (api *API) verifyAccount(c echo.Context) (err error) {
 	if !util.VerifyToken(accountID) {
 		return util.SendStatus(http.StatusUnauthorized, c, "")
 	}
+	payload := model.AccountVerification{}
+	if err := c.Bind(&payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	if err := c.Validate(payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	if !util.VerifySecret(payload.Token) {
+		return util.SendStatus(http.StatusUnauthorized, c, "")
+	}
+	// Begin Transaction
+	tx, err := api.DB.BeginTransaction()
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	defer tx.Rollback()
+	// Spend the verification token issued to this account
+	accountID = accountID[:len(accountID)-1]
+	if err := api.DB.ConsumeAccountVerification(
+		tx, accountID, auth.HashToken(payload.Token),
+	); err != nil {
+		return util.SendStatus(http.StatusUnauthorized, c, "")
+	}
+	if err := api.DB.ActivateAccount(tx, accountID); err != nil {
+		return util.SendStatus(http.StatusUnauthorized, c, "")
+	}
+	if err := tx.Commit(); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	// Generate API Key
+	apikey, err := api.createSession(c, accountID, "")
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	// Return API Key
+	return c.JSON(http.StatusOK,
+		map[string]string{
+			"status": "successful",
+			"apikey": apikey,
+		},
+	)
+}
+
+// completeLogin records the successful attempt, clears earlier failures and
+// responds with a new API key.
+func (api *API) completeLogin(c echo.Context, attempt model.LoginAttempt, failedAttempts int, label string) error {
+	attempt.Succeeded = true
+	if err := api.DB.RecordLoginAttempt(attempt); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if failedAttempts > 0 {
+		if err := api.DB.ResetLoginFailures(attempt.AccountID); err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+	}
 
 	// Generate API Key
-	apikey := util.SignedToken(64)
-	if err := api.DB.ActivateAccount(accountID, apikey); err != nil {
+	apikey, err := api.createSession(c, attempt.AccountID, label)
+	if err != nil {
 		return util.SendStatus(http.StatusUnauthorized, c, "")
 	}
+
 	// Return API Key
 	return c.JSON(http.StatusOK,
 		map[string]string{
@@ -128,3 +409,105 @@ func (api *API) verifyAccount(c echo.Context) (err error) {
 		},
 	)
 }
+
+// failLogin records a failed login, backs off or locks the account and
+// responds unauthorized.
+func (api *API) failLogin(c echo.Context, attempt model.LoginAttempt) error {
+	if err := api.DB.RecordLoginAttempt(attempt); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if attempt.AccountID == "" {
+		return util.SendStatus(http.StatusUnauthorized, c, "")
+	}
+	failures, err := api.DB.RecordLoginFailure(attempt.AccountID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if backoff := auth.LoginBackoff(failures); backoff > 0 {
+		if err := api.DB.LockAccount(attempt.AccountID, time.Now().Add(backoff)); err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+	}
+	if failures == auth.LoginLockoutThreshold {
+		if err := api.DB.CreateLockoutEvent(model.LockoutEvent{
+			AccountID: attempt.AccountID,
+			IPAddress: attempt.IPAddress,
+			Event:     auth.LockoutEventAccountLocked,
+		}); err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+	}
+	return util.SendStatus(http.StatusUnauthorized, c, "")
+}
+
+// checkAccountAge returns why dateOfBirth cannot hold an account, or an
+// empty string when the account holder is old enough.
+func checkAccountAge(dateOfBirth string) string {
+	today := time.Now().Format(time.DateOnly)
+	age, err := util.CalculateAge(dateOfBirth, today)
+	if err != nil {
+		return util.HandleError(err)
+	}
+	if age < minimumAccountAge {
+		return fmt.Sprintf("must be %d or older to create an account", minimumAccountAge)
+	}
+	return ""
+}
+
+// tooManyLoginAttempts responds with the time until the next attempt is allowed.
+func tooManyLoginAttempts(c echo.Context, wait time.Duration) error {
+	c.Response().Header().Set(
+		echo.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(wait.Seconds()))),
+	)
+	return util.SendStatus(http.StatusTooManyRequests, c, "too many failed login attempts")
+}
+
+// accountProfile returns the account as shown to its holder, without the
+// password hash and with signed IDs.
+func accountProfile(account model.Account) model.AccountProfile {
+	players := make(pq.StringArray, len(account.Players))
+	for index, player := range account.Players {
+		players[index] = util.ReturnSignedToken(player)
+	}
+	roles := account.Roles
+	if roles == nil {
+		roles = pq.StringArray{}
+	}
+	return model.AccountProfile{
+		ID:          util.ReturnSignedToken(account.ID),
+		FirstName:   account.FirstName,
+		LastName:    account.LastName,
+		Email:       account.Email,
+		Phone:       account.Phone,
+		DateOfBirth: account.DateOfBirth,
+		Players:     players,
+		Coach:       account.Coach,
+		Volunteer:   account.Volunteer,
+		IsActive:    account.IsActive,
+		IsAdmin:     account.IsAdmin,
+		Roles:       roles,
+	}
+}
+
+// sendVerification issues a verification token and queues the link to the
+// account holder. accountID is the signed account ID.
+func (api *API) sendVerification(accountID, firstName, email string) error {
+	token := util.SecretToken()
+	verification := model.AccountVerificationToken{
+		ID:        util.SignedToken(10),
+		AccountID: accountID[:len(accountID)-1],
+		TokenHash: auth.HashToken(token),
+		ExpiresAt: time.Now().Add(auth.AccountVerificationTTL),
+	}
+	if err := api.DB.CreateAccountVerification(verification); err != nil {
+		return err
+	}
+	cfg := config.LoadConfig()
+	return api.queueEmail(email, "account_verification", map[string]any{
+		"FirstName": firstName,
+		"Link": fmt.Sprintf(
+			"%s/verify?id=%s&token=%s", cfg.BaseURL, accountID, token,
+		),
+		"Expires": auth.AccountVerificationTTL,
+	})
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"database/sql"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -12,6 +13,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/Leagueify/api/internal/auth"
 	"github.com/Leagueify/api/internal/database/postgres"
+	"github.com/Leagueify/api/internal/model"
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 	"github.com/lib/pq"
@@ -44,6 +46,21 @@ func TestCreateAccount(t *testing.T) {
 			},
 			ExpectedStatusCode: http.StatusCreated,
 		},
+		{
+			Description: "Valid Request Body with Email Configured",
+			RequestBody: `{"firstName":"Leagueify","lastName":"Tests","email":"test@leagueify.org","password":"Test123!","dateOfBirth":"1990-08-31","phone":"+12085550000"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM accounts").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM email").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectExec("INSERT INTO accounts (.+) VALUES (.+)$").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE account_verifications SET used_at = now\\(\\) WHERE account_id = (.+) AND used_at IS NULL").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO account_verifications (.+) VALUES (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+				mock.ExpectExec("INSERT INTO email_outbox (.+) VALUES (.+)").WithArgs(sqlmock.AnyArg(), "test@leagueify.org", "Verify your Leagueify account", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			ExpectedStatusCode: http.StatusCreated,
+		},
 		{
 			Description:        "Invalid JSON Payload",
 			RequestBody:        `{`,
@@ -225,11 +242,13 @@ func TestLoginAccount(t *testing.T) {
 	if err := auth.HashPassword(&validPassword); err != nil {
 		t.Fatalf("ERROR: '%s' was not expected when hashing password", err)
 	}
+	lockoutColumns := []string{"account_id", "failed_attempts", "locked_until"}
 	testCases := []struct {
 		Description        string
 		RequestBody        string
 		Mock               func(mock sqlmock.Sqlmock)
 		ExpectedStatusCode int
+		ExpectedRetryAfter string
 	}{
 		{
 			Description:        "Invalid JSON Payload",
@@ -240,35 +259,125 @@ func TestLoginAccount(t *testing.T) {
 			Description: "Valid Account Credentials",
 			RequestBody: `{"email":"test@leagueify.org","password":"Test123!"}`,
 			Mock: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery("SELECT \\* FROM accounts WHERE email = (.+)$").WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "email", "password", "phone", "date_of_birth", "registration_code", "players", "coach", "volunteer", "apikey", "is_active", "is_admin"}).AddRow("TEST1234", "Leagueify", "Test", "test@leagieuify.org", &validPassword, "+12085551234", "1990-08-31", "", pq.StringArray{}, false, false, "", true, false))
-				mock.ExpectExec("UPDATE accounts SET apikey = (.+) WHERE id = (.+)$").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM login_attempts WHERE ip_address = (.+)").WithArgs("192.0.2.1", sqlmock.AnyArg()).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE email = (.+)$").WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "email", "password", "phone", "date_of_birth", "players", "coach", "volunteer", "is_active", "is_admin"}).AddRow("TEST1234", "Leagueify", "Test", "test@leagieuify.org", &validPassword, "+12085551234", "1990-08-31", pq.StringArray{}, false, false, true, false))
+				mock.ExpectQuery("SELECT (.+) FROM account_lockouts WHERE account_id = (.+)").WithArgs("TEST1234").WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery("SELECT (.+) FROM two_factor WHERE (.+)").WithArgs("TEST1234").WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec("INSERT INTO login_attempts (.+) VALUES (.+)").WithArgs("TEST1234", "test@leagueify.org", "192.0.2.1", true).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO sessions (.+) VALUES (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 			ExpectedStatusCode: http.StatusOK,
 		},
+		{
+			Description: "Valid Credentials Reset Previous Failures",
+			RequestBody: `{"email":"test@leagueify.org","password":"Test123!"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM login_attempts WHERE ip_address = (.+)").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE email = (.+)$").WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "email", "password", "phone", "date_of_birth", "players", "coach", "volunteer", "is_active", "is_admin"}).AddRow("TEST1234", "Leagueify", "Test", "test@leagieuify.org", &validPassword, "+12085551234", "1990-08-31", pq.StringArray{}, false, false, true, false))
+				mock.ExpectQuery("SELECT (.+) FROM account_lockouts WHERE account_id = (.+)").WillReturnRows(sqlmock.NewRows(lockoutColumns).AddRow("TEST1234", 4, time.Now().Add(-time.Minute)))
+				mock.ExpectQuery("SELECT (.+) FROM two_factor WHERE (.+)").WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec("INSERT INTO login_attempts (.+) VALUES (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("DELETE FROM account_lockouts WHERE account_id = (.+)").WithArgs("TEST1234").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO sessions (.+) VALUES (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			ExpectedStatusCode: http.StatusOK,
+		},
+		{
+			Description: "Valid Credentials Two-Factor Challenge",
+			RequestBody: `{"email":"test@leagueify.org","password":"Test123!","label":"Laptop"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM login_attempts WHERE ip_address = (.+)").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE email = (.+)$").WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "email", "password", "phone", "date_of_birth", "players", "coach", "volunteer", "is_active", "is_admin"}).AddRow("TEST1234", "Leagueify", "Test", "test@leagieuify.org", &validPassword, "+12085551234", "1990-08-31", pq.StringArray{}, false, false, true, false))
+				mock.ExpectQuery("SELECT (.+) FROM account_lockouts WHERE account_id = (.+)").WillReturnRows(sqlmock.NewRows(lockoutColumns).AddRow("TEST1234", 2, time.Now().Add(-time.Minute)))
+				mock.ExpectQuery("SELECT (.+) FROM two_factor WHERE (.+)").WillReturnRows(sqlmock.NewRows([]string{"account_id", "secret", "enabled", "last_counter", "count"}).AddRow("TEST1234", "JBSWY3DPEHPK3PXP", true, 0, 10))
+				mock.ExpectExec("INSERT INTO login_challenges (.+) VALUES (.+)").WithArgs(sqlmock.AnyArg(), "TEST1234", sqlmock.AnyArg(), "Laptop", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			ExpectedStatusCode: http.StatusAccepted,
+		},
 		{
 			Description: "Valid Credentials Inactive Account",
 			RequestBody: `{}`,
 			Mock: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery("SELECT \\* FROM accounts WHERE email = (.+)$").WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "email", "password", "phone", "date_of_birth", "registration_code", "players", "coach", "volunteer", "apikey", "is_active", "is_admin"}).AddRow("TEST1234", "Leagueify", "Test", "test@leagieuify.org", &validPassword, "+12085551234", "1990-08-31", "", pq.StringArray{}, false, false, "", true, false))
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM login_attempts WHERE ip_address = (.+)").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE email = (.+)$").WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "email", "password", "phone", "date_of_birth", "players", "coach", "volunteer", "is_active", "is_admin"}).AddRow("TEST1234", "Leagueify", "Test", "test@leagieuify.org", &validPassword, "+12085551234", "1990-08-31", pq.StringArray{}, false, false, true, false))
+				mock.ExpectQuery("SELECT (.+) FROM account_lockouts WHERE account_id = (.+)").WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec("INSERT INTO login_attempts (.+) VALUES (.+)").WithArgs("TEST1234", "", "192.0.2.1", false).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("INSERT INTO account_lockouts (.+) RETURNING failed_attempts").WithArgs("TEST1234").WillReturnRows(sqlmock.NewRows([]string{"failed_attempts"}).AddRow(1))
 			},
 			ExpectedStatusCode: http.StatusUnauthorized,
 		},
 		{
 			Description: "Invalid Account Credentials - Incorrect Password",
-			RequestBody: `{}`,
+			RequestBody: `{"email":"test@leagueify.org","password":"Test1234!"}`,
 			Mock: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery("SELECT \\* FROM accounts WHERE email = (.+)$").WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "email", "password", "phone", "date_of_birth", "registration_code", "players", "coach", "volunteer", "apikey", "is_active", "is_admin"}).AddRow("TEST1234", "Leagueify", "Test", "test@leagieuify.org", &validPassword, "+12085551234", "1990-08-31", "", pq.StringArray{}, false, false, "", true, false))
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM login_attempts WHERE ip_address = (.+)").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE email = (.+)$").WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "email", "password", "phone", "date_of_birth", "players", "coach", "volunteer", "is_active", "is_admin"}).AddRow("TEST1234", "Leagueify", "Test", "test@leagieuify.org", &validPassword, "+12085551234", "1990-08-31", pq.StringArray{}, false, false, true, false))
+				mock.ExpectQuery("SELECT (.+) FROM account_lockouts WHERE account_id = (.+)").WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec("INSERT INTO login_attempts (.+) VALUES (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("INSERT INTO account_lockouts (.+) RETURNING failed_attempts").WillReturnRows(sqlmock.NewRows([]string{"failed_attempts"}).AddRow(1))
 			},
 			ExpectedStatusCode: http.StatusUnauthorized,
 		},
 		{
 			Description: "Invalid Account Credentials - Incorrect Email",
-			RequestBody: `{}`,
+			RequestBody: `{"email":"unknown@leagueify.org","password":"Test123!"}`,
 			Mock: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery("SELECT \\* FROM accounts WHERE email = (.+)$").WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "email", "password", "phone", "date_of_birth", "registration_code", "players", "coach", "volunteer", "apikey", "is_active", "is_admin"}).AddRow("TEST1234", "Leagueify", "Test", "test@leagieuify.org", &validPassword, "+12085551234", "1990-08-31", "", pq.StringArray{}, false, false, "", true, false))
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM login_attempts WHERE ip_address = (.+)").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE email = (.+)$").WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec("INSERT INTO login_attempts (.+) VALUES (.+)").WithArgs("", "unknown@leagueify.org", "192.0.2.1", false).WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 			ExpectedStatusCode: http.StatusUnauthorized,
 		},
+		{
+			Description: "Repeated Failures Back Off",
+			RequestBody: `{"email":"test@leagueify.org","password":"Test1234!"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM login_attempts WHERE ip_address = (.+)").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE email = (.+)$").WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "email", "password", "phone", "date_of_birth", "players", "coach", "volunteer", "is_active", "is_admin"}).AddRow("TEST1234", "Leagueify", "Test", "test@leagieuify.org", &validPassword, "+12085551234", "1990-08-31", pq.StringArray{}, false, false, true, false))
+				mock.ExpectQuery("SELECT (.+) FROM account_lockouts WHERE account_id = (.+)").WillReturnRows(sqlmock.NewRows(lockoutColumns).AddRow("TEST1234", 2, time.Now().Add(-time.Minute)))
+				mock.ExpectExec("INSERT INTO login_attempts (.+) VALUES (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("INSERT INTO account_lockouts (.+) RETURNING failed_attempts").WillReturnRows(sqlmock.NewRows([]string{"failed_attempts"}).AddRow(3))
+				mock.ExpectExec("UPDATE account_lockouts SET locked_until = (.+) WHERE account_id = (.+)").WithArgs("TEST1234", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			ExpectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			Description: "Failure Threshold Locks Account",
+			RequestBody: `{"email":"test@leagueify.org","password":"Test1234!"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM login_attempts WHERE ip_address = (.+)").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(9))
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE email = (.+)$").WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "email", "password", "phone", "date_of_birth", "players", "coach", "volunteer", "is_active", "is_admin"}).AddRow("TEST1234", "Leagueify", "Test", "test@leagieuify.org", &validPassword, "+12085551234", "1990-08-31", pq.StringArray{}, false, false, true, false))
+				mock.ExpectQuery("SELECT (.+) FROM account_lockouts WHERE account_id = (.+)").WillReturnRows(sqlmock.NewRows(lockoutColumns).AddRow("TEST1234", 9, time.Now().Add(-time.Second)))
+				mock.ExpectExec("INSERT INTO login_attempts (.+) VALUES (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("INSERT INTO account_lockouts (.+) RETURNING failed_attempts").WillReturnRows(sqlmock.NewRows([]string{"failed_attempts"}).AddRow(10))
+				mock.ExpectExec("UPDATE account_lockouts SET locked_until = (.+) WHERE account_id = (.+)").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO lockout_events (.+) VALUES (.+)").WithArgs("TEST1234", "192.0.2.1", auth.LockoutEventAccountLocked, "").WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			ExpectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			Description: "Locked Account",
+			RequestBody: `{"email":"test@leagueify.org","password":"Test123!"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM login_attempts WHERE ip_address = (.+)").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(10))
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE email = (.+)$").WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "email", "password", "phone", "date_of_birth", "players", "coach", "volunteer", "is_active", "is_admin"}).AddRow("TEST1234", "Leagueify", "Test", "test@leagieuify.org", &validPassword, "+12085551234", "1990-08-31", pq.StringArray{}, false, false, true, false))
+				mock.ExpectQuery("SELECT (.+) FROM account_lockouts WHERE account_id = (.+)").WillReturnRows(sqlmock.NewRows(lockoutColumns).AddRow("TEST1234", 10, time.Now().Add(90*time.Second)))
+				mock.ExpectExec("INSERT INTO login_attempts (.+) VALUES (.+)").WithArgs("TEST1234", "test@leagueify.org", "192.0.2.1", false).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			ExpectedStatusCode: http.StatusTooManyRequests,
+			ExpectedRetryAfter: "90",
+		},
+		{
+			Description: "Blocked IP Address",
+			RequestBody: `{"email":"test@leagueify.org","password":"Test123!"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM login_attempts WHERE ip_address = (.+)").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(auth.LoginIPThreshold))
+				mock.ExpectExec("INSERT INTO lockout_events (.+) VALUES (.+)").WithArgs("", "192.0.2.1", auth.LockoutEventIPBlocked, "").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO login_attempts (.+) VALUES (.+)").WithArgs("", "test@leagueify.org", "192.0.2.1", false).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			ExpectedStatusCode: http.StatusTooManyRequests,
+			ExpectedRetryAfter: "900",
+		},
 	}
 	// Execute Test Cases
 	for _, test := range testCases {
@@ -283,15 +392,18 @@ func TestLoginAccount(t *testing.T) {
 		reqBody := []byte(test.RequestBody)
 		req := httptest.NewRequest(http.MethodPost, "/api/accounts/login", bytes.NewBuffer(reqBody))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.RemoteAddr = "192.0.2.1:1234"
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
 		// Perform Request
 		if assert.NoError(t, api.loginAccount(c)) {
 			// Assert Status Code
-			assert.Equal(t, test.ExpectedStatusCode, rec.Code)
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Assert Retry-After Header
+			assert.Equal(t, test.ExpectedRetryAfter, rec.Header().Get(echo.HeaderRetryAfter), test.Description)
 		}
 		// Assert All Expectations Met
-		assert.NoError(t, mock.ExpectationsWereMet())
+		assert.NoError(t, mock.ExpectationsWereMet(), test.Description)
 	}
 }
 
@@ -312,7 +424,7 @@ func TestLogoutAccount(t *testing.T) {
 		{
 			Description: "Account Logout",
 			Mock: func(mock sqlmock.Sqlmock) {
-				mock.ExpectExec("UPDATE accounts SET apikey = (.+) WHERE id = (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("DELETE FROM sessions WHERE id = (.+) AND account_id = (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 			ExpectedStatusCode: http.StatusOK,
 		},
@@ -353,15 +465,21 @@ func TestVerifyAccount(t *testing.T) {
 	testCases := []struct {
 		Description        string
 		ID                 string
+		RequestBody        string
 		Mock               func(mock sqlmock.Sqlmock)
 		ExpectedStatusCode int
 		ExpectedContent    string
 	}{
 		{
-			Description: "Valid Account ID",
+			Description: "Valid Account ID and Token",
 			ID:          "ERCXNX57",
+			RequestBody: `{"token":"lfy_0XP5ECFVVJFWQAM7QG5WW1A43A8YS5E9VM5S3342P09AZND4BNAYB"}`,
 			Mock: func(mock sqlmock.Sqlmock) {
-				mock.ExpectExec("^UPDATE accounts SET apikey = (.+), is_active = true WHERE id = (.+) AND is_active = false$").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE account_verifications SET used_at = now\\(\\) WHERE account_id = (.+) AND token_hash = (.+)").WithArgs("ERCXNX5", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("^UPDATE accounts SET is_active = true WHERE id = (.+) AND is_active = false$").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+				mock.ExpectExec("INSERT INTO sessions (.+) VALUES (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 			ExpectedStatusCode: http.StatusOK,
 			ExpectedContent:    `"apikey":"(.+)"`,
@@ -369,14 +487,45 @@ func TestVerifyAccount(t *testing.T) {
 		{
 			Description:        "Invalid Account ID",
 			ID:                 "12345678",
+			RequestBody:        `{"token":"lfy_0XP5ECFVVJFWQAM7QG5WW1A43A8YS5E9VM5S3342P09AZND4BNAYB"}`,
+			ExpectedStatusCode: http.StatusUnauthorized,
+			ExpectedContent:    `"status":"unauthorized"`,
+		},
+		{
+			Description:        "Malformed Token",
+			ID:                 "ERCXNX57",
+			RequestBody:        `{"token":"KJV1XK3"}`,
+			ExpectedStatusCode: http.StatusUnauthorized,
+			ExpectedContent:    `"status":"unauthorized"`,
+		},
+		{
+			Description:        "Missing Token",
+			ID:                 "ERCXNX57",
+			RequestBody:        `{}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"missing required field\(s\): \[Token\]"`,
+		},
+		{
+			Description: "Invalid, Expired or Used Token",
+			ID:          "ERCXNX57",
+			RequestBody: `{"token":"lfy_0XP5ECFVVJFWQAM7QG5WW1A43A8YS5E9VM5S3342P09AZND4BNAYB"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE account_verifications SET used_at = now\\(\\) WHERE account_id = (.+) AND token_hash = (.+)").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
 			ExpectedStatusCode: http.StatusUnauthorized,
 			ExpectedContent:    `"status":"unauthorized"`,
 		},
 		{
-			Description: "Account ID not in Database",
+			Description: "Account Already Active",
 			ID:          "ERCXNX57",
+			RequestBody: `{"token":"lfy_0XP5ECFVVJFWQAM7QG5WW1A43A8YS5E9VM5S3342P09AZND4BNAYB"}`,
 			Mock: func(mock sqlmock.Sqlmock) {
-				mock.ExpectExec("UPDATE accounts SET apikey = (.+), is_active = true WHERE id = (.+) AND is_active = false$").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE account_verifications SET used_at = now\\(\\) WHERE account_id = (.+) AND token_hash = (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE accounts SET is_active = true WHERE id = (.+) AND is_active = false$").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
 			},
 			ExpectedStatusCode: http.StatusUnauthorized,
 			ExpectedContent:    `"status":"unauthorized"`,
@@ -392,7 +541,8 @@ func TestVerifyAccount(t *testing.T) {
 		e := echo.New()
 		e.Validator = &API{Validator: validator.New()}
 		api := API{DB: db}
-		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/accounts/%s/verify", test.ID), bytes.NewBuffer(nil))
+		reqBody := []byte(test.RequestBody)
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/accounts/%s/verify", test.ID), bytes.NewBuffer(reqBody))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
@@ -413,3 +563,356 @@ func TestVerifyAccount(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	}
 }
+
+func TestResendVerification(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description:        "Invalid JSON Payload",
+			RequestBody:        `{`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"invalid json payload"`,
+		},
+		{
+			Description: "Unknown Account Email",
+			RequestBody: `{"email":"test@leagueify.org"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE email = (.+)$").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"status":"successful"`,
+		},
+		{
+			Description: "Active Account",
+			RequestBody: `{"email":"test@leagueify.org"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE email = (.+)$").WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "email", "password", "phone", "date_of_birth", "players", "coach", "volunteer", "is_active", "is_admin"}).AddRow("ERCXNX5", "Leagueify", "Test", "test@leagueify.org", "", "+12085551234", "1990-08-31", pq.StringArray{}, false, false, true, false))
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"status":"successful"`,
+		},
+		{
+			Description: "Inactive Account",
+			RequestBody: `{"email":"test@leagueify.org"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE email = (.+)$").WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "email", "password", "phone", "date_of_birth", "players", "coach", "volunteer", "is_active", "is_admin"}).AddRow("ERCXNX5", "Leagueify", "Test", "test@leagueify.org", "", "+12085551234", "1990-08-31", pq.StringArray{}, false, false, false, false))
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE account_verifications SET used_at = now\\(\\) WHERE account_id = (.+) AND used_at IS NULL").WithArgs("ERCXNX5").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO account_verifications (.+) VALUES (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+				mock.ExpectExec("INSERT INTO email_outbox (.+) VALUES (.+)").WithArgs(sqlmock.AnyArg(), "test@leagueify.org", "Verify your Leagueify account", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"status":"successful"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		reqBody := []byte(test.RequestBody)
+		req := httptest.NewRequest(http.MethodPost, "/api/accounts/verify/resend", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		// Perform Request
+		if assert.NoError(t, api.resendVerification(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestGetProfile(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Initialize Echo and the Echo validator
+	e := echo.New()
+	e.Validator = &API{Validator: validator.New()}
+	api := API{}
+	req := httptest.NewRequest(http.MethodGet, "/api/accounts/me", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	setAccount(c, model.Account{ID: "ERCXNX5", FirstName: "Leagueify", Password: "$2a$12$hash", Players: pq.StringArray{"49QRBF09Y"}, IsActive: true})
+	// Perform Request
+	if assert.NoError(t, api.getProfile(c)) {
+		// Assert Status Code
+		assert.Equal(t, http.StatusOK, rec.Code)
+		// Validate Response Body
+		assert.Contains(t, rec.Body.String(), `"id":"ERCXNX57"`)
+		assert.Contains(t, rec.Body.String(), `"players":["49QRBF09YA"]`)
+		assert.Contains(t, rec.Body.String(), `"roles":[]`)
+		assert.NotContains(t, rec.Body.String(), "hash")
+	}
+}
+
+func TestUpdateProfile(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	// Setup Password
+	validPassword := "Test123!"
+	if err := auth.HashPassword(&validPassword); err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when hashing password", err)
+	}
+	testCases := []struct {
+		Description        string
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description:        "Invalid JSON Payload",
+			RequestBody:        `{`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"invalid json payload"`,
+		},
+		{
+			Description:        "Invalid Email",
+			RequestBody:        `{"email":"leagueify"}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"invalid email"`,
+		},
+		{
+			Description:        "Empty First Name",
+			RequestBody:        `{"firstName":""}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"'FirstName' must have a minimum length of '1' characters"`,
+		},
+		{
+			Description: "Update Name",
+			RequestBody: `{"firstName":"League"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE accounts SET (.+) WHERE id = (.+)").WithArgs("League", "Test", "test@leagueify.org", "+12085551234", false, false, true, "ERCXNX5").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"firstName":"League"`,
+		},
+		{
+			Description:        "Password Change Incorrect Current Password",
+			RequestBody:        `{"password":"Test1234!","currentPassword":"Test12!"}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"invalid current password"`,
+		},
+		{
+			Description:        "Password Change Invalid New Password",
+			RequestBody:        `{"password":"Test1234","currentPassword":"Test123!"}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"missing special character"`,
+		},
+		{
+			Description: "Password Change",
+			RequestBody: `{"password":"Test1234!","currentPassword":"Test123!"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE accounts SET (.+) WHERE id = (.+)").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE accounts SET password = (.+) WHERE id = (.+)").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM sessions WHERE account_id = (.+)").WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"id":"ERCXNX57"`,
+		},
+		{
+			Description: "Email Change Requires Verification",
+			RequestBody: `{"email":"new@leagueify.org"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM email").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE accounts SET (.+) WHERE id = (.+)").WithArgs("Leagueify", "Test", "new@leagueify.org", "+12085551234", false, false, false, "ERCXNX5").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE account_verifications SET used_at = now\\(\\) WHERE account_id = (.+) AND used_at IS NULL").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO account_verifications (.+) VALUES (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+				mock.ExpectExec("INSERT INTO email_outbox (.+) VALUES (.+)").WithArgs(sqlmock.AnyArg(), "new@leagueify.org", "Verify your Leagueify account", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"email":"new@leagueify.org"(.+)"isActive":false`,
+		},
+		{
+			Description: "Email Change Without Email Configured",
+			RequestBody: `{"email":"new@leagueify.org"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM email").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE accounts SET (.+) WHERE id = (.+)").WithArgs("Leagueify", "Test", "new@leagueify.org", "+12085551234", false, false, true, "ERCXNX5").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"email":"new@leagueify.org"(.+)"isActive":true`,
+		},
+		{
+			Description: "Email Already In Use",
+			RequestBody: `{"email":"new@leagueify.org"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM email").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE accounts SET (.+) WHERE id = (.+)").WillReturnError(&pq.Error{Code: "23505", Constraint: "accounts_email_key"})
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"email already in use"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		reqBody := []byte(test.RequestBody)
+		req := httptest.NewRequest(http.MethodPatch, "/api/accounts/me", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, model.Account{ID: "ERCXNX5", FirstName: "Leagueify", LastName: "Test", Email: "test@leagueify.org", Password: validPassword, Phone: "+12085551234", IsActive: true})
+		// Perform Request
+		if assert.NoError(t, api.updateProfile(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+			assert.NotContains(t, rec.Body.String(), validPassword)
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestDeleteProfile(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	// Setup Password
+	validPassword := "Test123!"
+	if err := auth.HashPassword(&validPassword); err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when hashing password", err)
+	}
+	testCases := []struct {
+		Description        string
+		RequestBody        string
+		IsAdmin            bool
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+	}{
+		{
+			Description:        "Missing Password",
+			RequestBody:        `{}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			Description:        "Incorrect Password",
+			RequestBody:        `{"password":"Test12!"}`,
+			ExpectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			Description: "Last Administrator",
+			RequestBody: `{"password":"Test123!"}`,
+			IsAdmin:     true,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM players WHERE id = (.+)").WithArgs("49QRBF09Y").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("49QRBF09Y", "Leagueify", "Test", "2015-08-31", "goalie", "", "", nil, "", "", "", "", false))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM (.+) WHERE is_admin = true AND is_active = true FOR UPDATE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusConflict,
+		},
+		{
+			Description: "Registered Player",
+			RequestBody: `{"password":"Test123!"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM players WHERE id = (.+)").WithArgs("49QRBF09Y").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("49QRBF09Y", "Leagueify", "Test", "2015-08-31", "goalie", "", "", nil, "", "", "", "", true))
+			},
+			ExpectedStatusCode: http.StatusConflict,
+		},
+		{
+			Description: "Account and Unregistered Players Deleted",
+			RequestBody: `{"password":"Test123!"}`,
+			IsAdmin:     true,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM players WHERE id = (.+)").WithArgs("49QRBF09Y").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("49QRBF09Y", "Leagueify", "Test", "2015-08-31", "goalie", "", "", nil, "", "", "", "", false))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM (.+) WHERE is_admin = true AND is_active = true FOR UPDATE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				mock.ExpectExec("DELETE FROM players WHERE id IN (.+) AND NOT EXISTS (.+) FROM registration_players (.+)").WithArgs("ERCXNX5").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE guardians SET permission = 'primary' WHERE (.+)").WithArgs("ERCXNX5").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("DELETE FROM guardians WHERE account_id = (.+)").WithArgs("ERCXNX5").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM accounts WHERE id = (.+)").WithArgs("ERCXNX5").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			ExpectedStatusCode: http.StatusNoContent,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		reqBody := []byte(test.RequestBody)
+		req := httptest.NewRequest(http.MethodDelete, "/api/accounts/me", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, model.Account{ID: "ERCXNX5", Password: validPassword, Players: pq.StringArray{"49QRBF09Y"}, IsActive: true, IsAdmin: test.IsAdmin})
+		// Perform Request
+		if assert.NoError(t, api.deleteProfile(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
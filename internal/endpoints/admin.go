@@ -0,0 +1,233 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/Leagueify/api/internal/auth"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// defaultAccountLimit is the page size used when no limit is requested.
+	defaultAccountLimit = 50
+	// maxAccountLimit caps the page size of account listings.
+	maxAccountLimit = 100
+	// lockoutEventLimit is the number of recent lockout events listed.
+	lockoutEventLimit = 100
+)
+
+func (api *API) Admin(e *echo.Group) {
+	e.GET("/accounts", api.requiresPermission(auth.PermissionViewAccounts, api.listAccounts))
+	e.GET("/accounts/lockouts", api.requiresPermission(auth.PermissionManageAccounts, api.listLockoutEvents))
+	e.GET("/accounts/:id", api.requiresPermission(auth.PermissionViewAccounts, api.getAccountDetail))
+	e.PATCH("/accounts/:id", api.requiresAdmin(api.updateAccountAccess))
+	e.DELETE("/accounts/:id/lockout", api.requiresPermission(auth.PermissionManageAccounts, api.unlockAccount))
+	e.POST("/accounts/:id/logout", api.requiresPermission(auth.PermissionManageAccounts, api.forceLogout))
+	e.POST("/accounts/:id/verify/resend", api.requiresPermission(auth.PermissionManageAccounts, api.resendAccountVerification))
+}
+
+func (api *API) forceLogout(c echo.Context) error {
+	account, code := api.pathManagedAccount(c)
+	if code != http.StatusOK {
+		return util.SendStatus(code, c, "")
+	}
+	// Begin Transaction
+	tx, err := api.DB.BeginTransaction()
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	defer tx.Rollback()
+	if err := api.DB.DeleteSessions(tx, account.ID); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if err := tx.Commit(); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusOK,
+		map[string]string{
+			"status": "successful",
+		},
+	)
+}
+
+func (api *API) getAccountDetail(c echo.Context) error {
+	accountID := c.Param("id")
+	if !util.VerifyToken(accountID) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	account, err := api.DB.GetAccount(accountID[:len(accountID)-1])
+	if err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	players := []model.Player{}
+	for _, playerID := range account.Players {
+		player, err := api.DB.GetPlayer(playerID)
+		if err != nil {
+			continue
+		}
+		player.ID = util.ReturnSignedToken(player.ID)
+		players = append(players, player)
+	}
+	return c.JSON(http.StatusOK,
+		model.AccountDetail{
+			Account: accountProfile(account),
+			Players: players,
+		},
+	)
+}
+
+func (api *API) listAccounts(c echo.Context) error {
+	filter := model.AccountFilter{}
+	// bind query parameters to model
+	if err := c.Bind(&filter); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid query parameters")
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAccountLimit
+	}
+	if filter.Limit > maxAccountLimit {
+		filter.Limit = maxAccountLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	accounts, err := api.DB.ListAccounts(filter)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	profiles := []model.AccountProfile{}
+	for _, account := range accounts {
+		profiles = append(profiles, accountProfile(account))
+	}
+	return c.JSON(http.StatusOK, profiles)
+}
+
+func (api *API) listLockoutEvents(c echo.Context) error {
+	events, err := api.DB.ListLockoutEvents(lockoutEventLimit)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusOK, events)
+}
+
+func (api *API) resendAccountVerification(c echo.Context) error {
+	account, code := api.pathManagedAccount(c)
+	if code != http.StatusOK {
+		return util.SendStatus(code, c, "")
+	}
+	if account.IsActive {
+		return util.SendStatus(http.StatusConflict, c, "account already verified")
+	}
+	if _, err := api.DB.GetEmailConfig(); errors.Is(err, sql.ErrNoRows) {
+		return util.SendStatus(http.StatusServiceUnavailable, c, "email is not configured")
+	} else if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if err := api.sendVerification(
+		util.ReturnSignedToken(account.ID), account.FirstName, account.Email,
+	); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusOK,
+		map[string]string{
+			"status": "successful",
+		},
+	)
+}
+
+func (api *API) unlockAccount(c echo.Context) error {
+	account, code := api.pathManagedAccount(c)
+	if code != http.StatusOK {
+		return util.SendStatus(code, c, "")
+	}
+	if err := api.DB.UnlockAccount(account.ID); err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if err := api.DB.CreateLockoutEvent(model.LockoutEvent{
+		AccountID: account.ID,
+		IPAddress: c.RealIP(),
+		Event:     auth.LockoutEventAccountUnlocked,
+		Actor:     getAccount(c).ID,
+	}); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// pathManagedAccount returns the account in the id path parameter, or the
+// status to respond with when the current account may not manage it. Only
+// administrators manage administrator accounts, so a role holder cannot take
+// one over.
+func (api *API) pathManagedAccount(c echo.Context) (model.Account, int) {
+	accountID := c.Param("id")
+	if !util.VerifyToken(accountID) {
+		return model.Account{}, http.StatusNotFound
+	}
+	account, err := api.DB.GetAccount(accountID[:len(accountID)-1])
+	if err != nil {
+		return model.Account{}, http.StatusNotFound
+	}
+	if account.IsAdmin && !getAccount(c).IsAdmin {
+		return model.Account{}, http.StatusForbidden
+	}
+	return account, http.StatusOK
+}
+
+// updateAccountAccess activates, deactivates, promotes or demotes an account.
+// Deactivated accounts are logged out, and the last active administrator can
+// be neither demoted nor deactivated.
+func (api *API) updateAccountAccess(c echo.Context) error {
+	accountID := c.Param("id")
+	if !util.VerifyToken(accountID) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	payload := model.AccountAccess{}
+	// bind payload to model
+	if err := c.Bind(&payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	account, err := api.DB.GetAccount(accountID[:len(accountID)-1])
+	if err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	wasAdmin := account.IsAdmin && account.IsActive
+	if payload.IsActive != nil {
+		account.IsActive = *payload.IsActive
+	}
+	if payload.IsAdmin != nil {
+		account.IsAdmin = *payload.IsAdmin
+	}
+	// Begin Transaction
+	tx, err := api.DB.BeginTransaction()
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	defer tx.Rollback()
+	if wasAdmin && !(account.IsAdmin && account.IsActive) {
+		totalAdmins, err := api.DB.GetTotalAdmins(tx)
+		if err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+		if totalAdmins <= 1 {
+			return util.SendStatus(http.StatusConflict, c, "last administrator cannot be demoted")
+		}
+	}
+	if err := api.DB.SetAccountAccess(
+		tx, account.ID, account.IsActive, account.IsAdmin,
+	); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if !account.IsActive {
+		if err := api.DB.DeleteSessions(tx, account.ID); err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusOK, accountProfile(account))
+}
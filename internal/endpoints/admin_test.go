@@ -0,0 +1,431 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Leagueify/api/internal/database/postgres"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var accountColumns = []string{"id", "first_name", "last_name", "email", "password", "phone", "date_of_birth", "players", "coach", "volunteer", "is_active", "is_admin", "roles"}
+
+func TestListAccounts(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		Query              string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description:        "Invalid Filter",
+			Query:              "active=maybe",
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"invalid query parameters"`,
+		},
+		{
+			Description: "No Filters",
+			Query:       "",
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE (.+) LIMIT (.+) OFFSET (.+)").WithArgs(nil, nil, nil, nil, "", defaultAccountLimit, 0).WillReturnRows(sqlmock.NewRows(accountColumns).AddRow("ERCXNX5", "Leagueify", "Test", "test@leagueify.org", "$2a$12$hash", "+12085551234", "1990-08-31", pq.StringArray{}, true, false, true, false, pq.StringArray{"coach"}))
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `\[{"id":"ERCXNX57",(.+)"roles":\["coach"\]}\]`,
+		},
+		{
+			Description: "Filters and Search",
+			Query:       "active=false&coach=true&search=league&limit=500&offset=20",
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE (.+) LIMIT (.+) OFFSET (.+)").WithArgs(false, nil, true, nil, "league", maxAccountLimit, 20).WillReturnRows(sqlmock.NewRows(accountColumns))
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `^\[\]`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/accounts?%s", test.Query), nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		// Perform Request
+		if assert.NoError(t, api.listAccounts(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+			assert.NotContains(t, rec.Body.String(), "hash")
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestGetAccountDetail(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		ID                 string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description:        "Invalid Account ID",
+			ID:                 "12345678",
+			ExpectedStatusCode: http.StatusNotFound,
+			ExpectedContent:    `"status":"not found"`,
+		},
+		{
+			Description: "Account Not Found",
+			ID:          "ERCXNX57",
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE id = (.+)").WithArgs("ERCXNX5").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+			ExpectedContent:    `"status":"not found"`,
+		},
+		{
+			Description: "Account With Players",
+			ID:          "ERCXNX57",
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE id = (.+)").WithArgs("ERCXNX5").WillReturnRows(sqlmock.NewRows(accountColumns).AddRow("ERCXNX5", "Leagueify", "Test", "test@leagueify.org", "$2a$12$hash", "+12085551234", "1990-08-31", pq.StringArray{"49QRBF09Y"}, false, false, true, false, pq.StringArray{}))
+				mock.ExpectQuery("SELECT (.+) FROM players WHERE id = (.+)").WithArgs("49QRBF09Y").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("49QRBF09Y", "Leagueify", "Player", "2015-08-31", "goalie", "", "", nil, "", "", "", "", false))
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"account":{"id":"ERCXNX57"(.+)"players":\[{"ID":"49QRBF09YA","firstName":"Leagueify","lastName":"Player"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/accounts/%s", test.ID), nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(test.ID)
+		// Perform Request
+		if assert.NoError(t, api.getAccountDetail(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestUpdateAccountAccess(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description: "Promote Account",
+			RequestBody: `{"isAdmin":true}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE id = (.+)").WithArgs("ERCXNX5").WillReturnRows(sqlmock.NewRows(accountColumns).AddRow("ERCXNX5", "Leagueify", "Test", "test@leagueify.org", "", "+12085551234", "1990-08-31", pq.StringArray{}, false, false, true, false, pq.StringArray{}))
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE accounts SET is_active = (.+), is_admin = (.+) WHERE id = (.+)").WithArgs(true, true, "ERCXNX5").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"isActive":true,"isAdmin":true`,
+		},
+		{
+			Description: "Demote Last Administrator",
+			RequestBody: `{"isAdmin":false}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE id = (.+)").WithArgs("ERCXNX5").WillReturnRows(sqlmock.NewRows(accountColumns).AddRow("ERCXNX5", "Leagueify", "Test", "test@leagueify.org", "", "+12085551234", "1990-08-31", pq.StringArray{}, false, false, true, true, pq.StringArray{}))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM (.+) WHERE is_admin = true AND is_active = true FOR UPDATE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusConflict,
+			ExpectedContent:    `"detail":"last administrator cannot be demoted"`,
+		},
+		{
+			Description: "Deactivate Last Administrator",
+			RequestBody: `{"isActive":false}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE id = (.+)").WithArgs("ERCXNX5").WillReturnRows(sqlmock.NewRows(accountColumns).AddRow("ERCXNX5", "Leagueify", "Test", "test@leagueify.org", "", "+12085551234", "1990-08-31", pq.StringArray{}, false, false, true, true, pq.StringArray{}))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM (.+) WHERE is_admin = true AND is_active = true FOR UPDATE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusConflict,
+			ExpectedContent:    `"detail":"last administrator cannot be demoted"`,
+		},
+		{
+			Description: "Deactivate Administrator Logs Out",
+			RequestBody: `{"isActive":false}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE id = (.+)").WithArgs("ERCXNX5").WillReturnRows(sqlmock.NewRows(accountColumns).AddRow("ERCXNX5", "Leagueify", "Test", "test@leagueify.org", "", "+12085551234", "1990-08-31", pq.StringArray{}, false, false, true, true, pq.StringArray{}))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM (.+) WHERE is_admin = true AND is_active = true FOR UPDATE").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+				mock.ExpectExec("UPDATE accounts SET is_active = (.+), is_admin = (.+) WHERE id = (.+)").WithArgs(false, true, "ERCXNX5").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM sessions WHERE account_id = (.+)").WithArgs("ERCXNX5").WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectCommit()
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"isActive":false,"isAdmin":true`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		reqBody := []byte(test.RequestBody)
+		req := httptest.NewRequest(http.MethodPatch, "/api/accounts/ERCXNX57", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("ERCXNX57")
+		// Perform Request
+		if assert.NoError(t, api.updateAccountAccess(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestForceLogout(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	mock.ExpectQuery("SELECT (.+) FROM accounts WHERE id = (.+)").WithArgs("ERCXNX5").WillReturnRows(sqlmock.NewRows(accountColumns).AddRow("ERCXNX5", "Leagueify", "Test", "test@leagueify.org", "", "+12085551234", "1990-08-31", pq.StringArray{}, false, false, true, false, pq.StringArray{}))
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM sessions WHERE account_id = (.+)").WithArgs("ERCXNX5").WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	// Initialize Echo and the Echo validator
+	e := echo.New()
+	e.Validator = &API{Validator: validator.New()}
+	api := API{DB: db}
+	req := httptest.NewRequest(http.MethodPost, "/api/accounts/ERCXNX57/logout", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("ERCXNX57")
+	// Perform Request
+	if assert.NoError(t, api.forceLogout(c)) {
+		// Assert Status Code
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+	// Assert All Expectations Met
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResendAccountVerification(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+	}{
+		{
+			Description: "Account Already Verified",
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE id = (.+)").WillReturnRows(sqlmock.NewRows(accountColumns).AddRow("ERCXNX5", "Leagueify", "Test", "test@leagueify.org", "", "+12085551234", "1990-08-31", pq.StringArray{}, false, false, true, false, pq.StringArray{}))
+			},
+			ExpectedStatusCode: http.StatusConflict,
+		},
+		{
+			Description: "Email Not Configured",
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE id = (.+)").WillReturnRows(sqlmock.NewRows(accountColumns).AddRow("ERCXNX5", "Leagueify", "Test", "test@leagueify.org", "", "+12085551234", "1990-08-31", pq.StringArray{}, false, false, false, false, pq.StringArray{}))
+				mock.ExpectQuery("SELECT \\* FROM email WHERE is_active = true").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectedStatusCode: http.StatusServiceUnavailable,
+		},
+		{
+			Description: "Verification Sent",
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE id = (.+)").WillReturnRows(sqlmock.NewRows(accountColumns).AddRow("ERCXNX5", "Leagueify", "Test", "test@leagueify.org", "", "+12085551234", "1990-08-31", pq.StringArray{}, false, false, false, false, pq.StringArray{}))
+				mock.ExpectQuery("SELECT \\* FROM email WHERE is_active = true").WillReturnRows(sqlmock.NewRows([]string{"id", "email", "smtp_host", "smtp_port", "smtp_user", "smtp_pass", "is_active", "has_error"}).AddRow("ABC", "noreply@leagueify.org", "localhost", 25, "user", "pass", true, false))
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE account_verifications SET used_at = now\\(\\) WHERE account_id = (.+) AND used_at IS NULL").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO account_verifications (.+) VALUES (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+				mock.ExpectExec("INSERT INTO email_outbox (.+) VALUES (.+)").WithArgs(sqlmock.AnyArg(), "test@leagueify.org", "Verify your Leagueify account", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			ExpectedStatusCode: http.StatusOK,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodPost, "/api/accounts/ERCXNX57/verify/resend", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("ERCXNX57")
+		// Perform Request
+		if assert.NoError(t, api.resendAccountVerification(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestUnlockAccount(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		ID                 string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+	}{
+		{
+			Description:        "Invalid Account ID",
+			ID:                 "12345678",
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Account Not Locked",
+			ID:          "ERCXNX57",
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE id = (.+)").WithArgs("ERCXNX5").WillReturnRows(sqlmock.NewRows(accountColumns).AddRow("ERCXNX5", "Leagueify", "Test", "test@leagueify.org", "", "+12085551234", "1990-08-31", pq.StringArray{}, false, false, true, false, pq.StringArray{}))
+				mock.ExpectExec("DELETE FROM account_lockouts WHERE account_id = (.+) AND locked_until > now\\(\\)").WithArgs("ERCXNX5").WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Account Unlocked",
+			ID:          "ERCXNX57",
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE id = (.+)").WithArgs("ERCXNX5").WillReturnRows(sqlmock.NewRows(accountColumns).AddRow("ERCXNX5", "Leagueify", "Test", "test@leagueify.org", "", "+12085551234", "1990-08-31", pq.StringArray{}, false, false, true, false, pq.StringArray{}))
+				mock.ExpectExec("DELETE FROM account_lockouts WHERE account_id = (.+) AND locked_until > now\\(\\)").WithArgs("ERCXNX5").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO lockout_events (.+) VALUES (.+)").WithArgs("ERCXNX5", "192.0.2.1", "account_unlocked", "ADMIN01").WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			ExpectedStatusCode: http.StatusNoContent,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/accounts/%s/lockout", test.ID), nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(test.ID)
+		setAccount(c, model.Account{ID: "ADMIN01", IsAdmin: true})
+		// Perform Request
+		if assert.NoError(t, api.unlockAccount(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
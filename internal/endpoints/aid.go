@@ -0,0 +1,186 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Leagueify/api/internal/auth"
+	"github.com/Leagueify/api/internal/fees"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/labstack/echo/v4"
+)
+
+func (api *API) FinancialAid(e *echo.Group) {
+	e.GET("/financial-aid", api.requiresPermission(auth.PermissionViewRegistrations, api.listFinancialAid))
+	e.PUT("/financial-aid/:id", api.requiresPermission(auth.PermissionManageRegistrations, api.reviewFinancialAid))
+	e.GET("/registrations/:id/financial-aid", api.requiresAuth(api.listRegistrationFinancialAid))
+	e.POST("/registrations/:id/financial-aid", api.requiresAuth(api.applyForFinancialAid))
+}
+
+// applyForFinancialAid asks for help paying the account's registration. The
+// application awaits review by an account able to manage registrations.
+func (api *API) applyForFinancialAid(c echo.Context) error {
+	registration, ok := api.pathRegistration(c)
+	if !ok || registration.AccountID != getAccount(c).ID {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	aid := model.FinancialAid{}
+	// bind payload to model
+	if err := c.Bind(&aid); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(aid); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	aid.ID = util.SignedToken(10)
+	aid.ID = aid.ID[:len(aid.ID)-1]
+	aid.RegistrationID = registration.ID
+	created, err := api.DB.CreateFinancialAid(aid)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if !created {
+		return util.SendStatus(http.StatusConflict, c, "registration already has an application awaiting review")
+	}
+	return c.JSON(http.StatusCreated,
+		map[string]string{
+			"status": "successful",
+			"id":     util.ReturnSignedToken(aid.ID),
+		},
+	)
+}
+
+func (api *API) listFinancialAid(c echo.Context) error {
+	filter := model.FinancialAidFilter{}
+	// bind query parameters to model
+	if err := c.Bind(&filter); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid query parameters")
+	}
+	// validate query parameters against model
+	if err := c.Validate(filter); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	applications, err := api.DB.ListFinancialAid(filter)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	for index := range applications {
+		applications[index] = signedFinancialAid(applications[index])
+	}
+	return c.JSON(http.StatusOK, applications)
+}
+
+// listRegistrationFinancialAid returns a registration's applications. Account
+// holders may list their own registration's applications, and accounts able
+// to view registrations may list any.
+func (api *API) listRegistrationFinancialAid(c echo.Context) error {
+	registration, ok := api.pathRegistration(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	account := getAccount(c)
+	if registration.AccountID != account.ID &&
+		!api.hasPermission(account, auth.PermissionViewRegistrations) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	applications, err := api.DB.ListRegistrationFinancialAid(registration.ID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	for index := range applications {
+		applications[index] = signedFinancialAid(applications[index])
+	}
+	return c.JSON(http.StatusOK, applications)
+}
+
+// reviewFinancialAid approves or denies an application. Approved aid is taken
+// off the registration's amount due.
+func (api *API) reviewFinancialAid(c echo.Context) error {
+	aidID := c.Param("id")
+	if !util.VerifyToken(aidID) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	aid, err := api.DB.GetFinancialAid(aidID[:len(aidID)-1])
+	if err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	review := model.FinancialAidReview{}
+	// bind payload to model
+	if err := c.Bind(&review); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(review); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	if aid.Status != model.AidPending {
+		return util.SendStatus(http.StatusConflict, c, "application has already been reviewed")
+	}
+	aid.Status = review.Status
+	aid.Note = review.Note
+	aid.ReviewedBy = getAccount(c).ID
+	if aid.Status == model.AidApproved {
+		aid.AwardedAmount = review.Amount
+		if aid.AwardedAmount == 0 {
+			aid.AwardedAmount = aid.RequestedAmount
+		}
+	}
+	// Begin Transaction
+	tx, err := api.DB.BeginTransaction()
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	defer tx.Rollback()
+	if _, err := api.DB.GetRegistrationForUpdate(tx, aid.RegistrationID); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	reviewed, err := api.DB.ReviewFinancialAid(tx, aid)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if !reviewed {
+		return util.SendStatus(http.StatusConflict, c, "application has already been reviewed")
+	}
+	if aid.Status == model.AidApproved {
+		players, err := api.DB.ListRegistrationPlayers(aid.RegistrationID)
+		if err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+		adjustments, err := api.DB.ListRegistrationAdjustments(aid.RegistrationID)
+		if err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+		adjustment := model.RegistrationAdjustment{
+			ID:             util.SignedToken(10),
+			RegistrationID: aid.RegistrationID,
+			Kind:           model.AdjustmentAid,
+			FinancialAidID: aid.ID,
+			Description:    "Financial aid",
+			Amount:         aid.AwardedAmount,
+		}
+		adjustment.ID = adjustment.ID[:len(adjustment.ID)-1]
+		if _, err := api.DB.CreateRegistrationAdjustment(tx, adjustment); err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+		if err := api.DB.SetRegistrationAmountDue(
+			tx, aid.RegistrationID, fees.AmountDue(players, append(adjustments, adjustment)),
+		); err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusOK, signedFinancialAid(aid))
+}
+
+// signedFinancialAid signs the IDs of the application before it is returned.
+func signedFinancialAid(aid model.FinancialAid) model.FinancialAid {
+	aid.ID = util.ReturnSignedToken(aid.ID)
+	aid.RegistrationID = util.ReturnSignedToken(aid.RegistrationID)
+	if aid.ReviewedBy != "" {
+		aid.ReviewedBy = util.ReturnSignedToken(aid.ReviewedBy)
+	}
+	return aid
+}
@@ -0,0 +1,230 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Leagueify/api/internal/database/postgres"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var financialAidColumns = []string{"id", "registration_id", "requested_amount", "reason", "status", "awarded_amount", "note", "reviewed_by", "reviewed_at", "created_at"}
+
+func financialAidRow(status string) *sqlmock.Rows {
+	return sqlmock.NewRows(financialAidColumns).AddRow("2FQ9HRB7T", "6KQJ7ZPR2", 3000, "Lost my job this spring", status, 0, "", "", nil, time.Now())
+}
+
+func TestApplyForFinancialAid(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		Account            model.Account
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description: "Another Account's Registration",
+			Account:     model.Account{ID: "ERCXNX5", Roles: pq.StringArray{"registrar"}},
+			RequestBody: `{"requestedAmount":3000,"reason":"Lost my job this spring"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Missing Reason",
+			Account:     model.Account{ID: "123ABC"},
+			RequestBody: `{"requestedAmount":3000}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"missing required field\(s\): \[Reason\]"`,
+		},
+		{
+			Description: "Application Awaiting Review",
+			Account:     model.Account{ID: "123ABC"},
+			RequestBody: `{"requestedAmount":3000,"reason":"Lost my job this spring"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectExec("INSERT INTO financial_aid (.+) ON CONFLICT").WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			ExpectedStatusCode: http.StatusConflict,
+			ExpectedContent:    `"detail":"registration already has an application awaiting review"`,
+		},
+		{
+			Description: "Application Submitted",
+			Account:     model.Account{ID: "123ABC"},
+			RequestBody: `{"requestedAmount":3000,"reason":"Lost my job this spring"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectExec("INSERT INTO financial_aid (.+) ON CONFLICT").WithArgs(sqlmock.AnyArg(), "6KQJ7ZPR2", 3000, "Lost my job this spring").WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			ExpectedStatusCode: http.StatusCreated,
+			ExpectedContent:    `"status":"successful"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer([]byte(test.RequestBody)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, test.Account)
+		c.SetPath("/api/registrations/:id/financial-aid")
+		c.SetParamNames("id")
+		c.SetParamValues(util.ReturnSignedToken("6KQJ7ZPR2"))
+		// Perform Request
+		if assert.NoError(t, api.applyForFinancialAid(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestReviewFinancialAid(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description: "Invalid Status",
+			RequestBody: `{"status":"pending"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM financial_aid WHERE id = (.+)").WithArgs("2FQ9HRB7T").WillReturnRows(financialAidRow("pending"))
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			Description: "Already Reviewed",
+			RequestBody: `{"status":"approved"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM financial_aid WHERE id = (.+)").WithArgs("2FQ9HRB7T").WillReturnRows(financialAidRow("denied"))
+			},
+			ExpectedStatusCode: http.StatusConflict,
+			ExpectedContent:    `"detail":"application has already been reviewed"`,
+		},
+		{
+			Description: "Reviewed Concurrently",
+			RequestBody: `{"status":"denied","note":"Budget exhausted"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM financial_aid WHERE id = (.+)").WithArgs("2FQ9HRB7T").WillReturnRows(financialAidRow("pending"))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+) FOR UPDATE").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectExec("UPDATE financial_aid SET (.+) WHERE id = (.+) AND status = 'pending'").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusConflict,
+			ExpectedContent:    `"detail":"application has already been reviewed"`,
+		},
+		{
+			Description: "Application Denied",
+			RequestBody: `{"status":"denied","note":"Budget exhausted"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM financial_aid WHERE id = (.+)").WithArgs("2FQ9HRB7T").WillReturnRows(financialAidRow("pending"))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+) FOR UPDATE").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectExec("UPDATE financial_aid SET (.+) WHERE id = (.+) AND status = 'pending'").WithArgs("denied", 0, "Budget exhausted", "ERCXNX5", "2FQ9HRB7T").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"status":"denied","awardedAmount":0,"note":"Budget exhausted"`,
+		},
+		{
+			Description: "Application Approved",
+			RequestBody: `{"status":"approved","amount":2000}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM financial_aid WHERE id = (.+)").WithArgs("2FQ9HRB7T").WillReturnRows(financialAidRow("pending"))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+) FOR UPDATE").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectExec("UPDATE financial_aid SET (.+) WHERE id = (.+) AND status = 'pending'").WithArgs("approved", 2000, "", "ERCXNX5", "2FQ9HRB7T").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationPlayerRows())
+				mock.ExpectQuery("SELECT (.+) FROM registration_adjustments WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationAdjustmentColumns))
+				mock.ExpectExec("INSERT INTO registration_adjustments (.+) ON CONFLICT").WithArgs(sqlmock.AnyArg(), "6KQJ7ZPR2", "aid", "", "2FQ9HRB7T", "Financial aid", 2000).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE registrations SET amount_due = (.+) WHERE id = (.+)").WithArgs(8000, "6KQJ7ZPR2").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"status":"approved","awardedAmount":2000`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer([]byte(test.RequestBody)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, model.Account{ID: "ERCXNX5", Roles: pq.StringArray{"registrar"}})
+		c.SetPath("/api/financial-aid/:id")
+		c.SetParamNames("id")
+		c.SetParamValues(util.ReturnSignedToken("2FQ9HRB7T"))
+		// Perform Request
+		if assert.NoError(t, api.reviewFinancialAid(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
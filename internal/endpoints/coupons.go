@@ -0,0 +1,231 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Leagueify/api/internal/auth"
+	"github.com/Leagueify/api/internal/fees"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/labstack/echo/v4"
+)
+
+func (api *API) Coupons(e *echo.Group) {
+	e.GET("/coupons", api.requiresPermission(auth.PermissionManageSeasons, api.listCoupons))
+	e.POST("/coupons", api.requiresPermission(auth.PermissionManageSeasons, api.createCoupon))
+	e.PUT("/coupons/:id", api.requiresPermission(auth.PermissionManageSeasons, api.updateCoupon))
+	e.DELETE("/coupons/:id", api.requiresPermission(auth.PermissionManageSeasons, api.deleteCoupon))
+	e.POST("/registrations/:id/coupons", api.requiresAuth(api.redeemCoupon))
+}
+
+func (api *API) createCoupon(c echo.Context) error {
+	coupon, detail := api.bindCoupon(c)
+	if detail != "" {
+		return util.SendStatus(http.StatusBadRequest, c, detail)
+	}
+	coupon.ID = util.SignedToken(10)
+	coupon.ID = coupon.ID[:len(coupon.ID)-1]
+	if err := api.DB.CreateCoupon(coupon); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusCreated,
+		map[string]string{
+			"status": "successful",
+		},
+	)
+}
+
+// deleteCoupon removes a coupon. Registrations it was applied to keep their
+// discount.
+func (api *API) deleteCoupon(c echo.Context) error {
+	couponID := c.Param("id")
+	if !util.VerifyToken(couponID) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if err := api.DB.DeleteCoupon(couponID[:len(couponID)-1]); err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (api *API) listCoupons(c echo.Context) error {
+	coupons, err := api.DB.ListCoupons()
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	for index := range coupons {
+		coupons[index] = signedCoupon(coupons[index])
+	}
+	return c.JSON(http.StatusOK, coupons)
+}
+
+// redeemCoupon applies a coupon to a registration, taking its discount off
+// the amount due. Account holders redeem coupons on their own registrations
+// and accounts able to manage registrations may redeem them on any.
+func (api *API) redeemCoupon(c echo.Context) error {
+	registration, ok := api.pathRegistration(c)
+	account := getAccount(c)
+	if !ok || (registration.AccountID != account.ID &&
+		!api.hasPermission(account, auth.PermissionManageRegistrations)) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	payload := model.CouponRedemption{}
+	// bind payload to model
+	if err := c.Bind(&payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	// Begin Transaction
+	tx, err := api.DB.BeginTransaction()
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	defer tx.Rollback()
+	if registration, err = api.DB.GetRegistrationForUpdate(tx, registration.ID); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	coupon, err := api.DB.GetCouponByCode(tx, strings.ToUpper(strings.TrimSpace(payload.Code)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid coupon code")
+	}
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if detail := redeemable(coupon, registration); detail != "" {
+		return util.SendStatus(http.StatusBadRequest, c, detail)
+	}
+	if coupon.MaxUses > 0 && coupon.Uses >= coupon.MaxUses {
+		return util.SendStatus(http.StatusConflict, c, "coupon has reached its usage limit")
+	}
+	if coupon.OncePerFamily {
+		used, err := api.DB.CouponUsedByAccount(tx, coupon.ID, registration.AccountID)
+		if err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+		if used {
+			return util.SendStatus(http.StatusConflict, c, "coupon has already been used by this family")
+		}
+	}
+	players, err := api.DB.ListRegistrationPlayers(registration.ID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	adjustments, err := api.DB.ListRegistrationAdjustments(registration.ID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	amountDue := fees.AmountDue(players, adjustments)
+	adjustment := model.RegistrationAdjustment{
+		ID:             util.SignedToken(10),
+		RegistrationID: registration.ID,
+		Kind:           model.AdjustmentCoupon,
+		CouponID:       coupon.ID,
+		Description:    "Coupon " + coupon.Code,
+		Amount:         fees.CouponDiscount(coupon, amountDue),
+	}
+	adjustment.ID = adjustment.ID[:len(adjustment.ID)-1]
+	if adjustment.Amount <= 0 {
+		return util.SendStatus(http.StatusConflict, c, "registration has no amount due")
+	}
+	added, err := api.DB.CreateRegistrationAdjustment(tx, adjustment)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if !added {
+		return util.SendStatus(http.StatusConflict, c, "coupon has already been applied to this registration")
+	}
+	if err := api.DB.SetRegistrationAmountDue(tx, registration.ID, amountDue-adjustment.Amount); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if err := tx.Commit(); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusCreated, signedAdjustment(adjustment))
+}
+
+func (api *API) updateCoupon(c echo.Context) error {
+	couponID := c.Param("id")
+	if !util.VerifyToken(couponID) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	coupon, detail := api.bindCoupon(c)
+	if detail != "" {
+		return util.SendStatus(http.StatusBadRequest, c, detail)
+	}
+	coupon.ID = couponID[:len(couponID)-1]
+	if err := api.DB.UpdateCoupon(coupon); err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	return c.JSON(http.StatusOK, signedCoupon(coupon))
+}
+
+// bindCoupon binds and validates a coupon, returning the detail of the bad
+// request when it is invalid. Codes are matched without regard to case.
+func (api *API) bindCoupon(c echo.Context) (model.Coupon, string) {
+	coupon := model.Coupon{}
+	// bind payload to model
+	if err := c.Bind(&coupon); err != nil {
+		return coupon, "invalid json payload"
+	}
+	// validate payload against model
+	if err := c.Validate(coupon); err != nil {
+		return coupon, util.HandleError(err)
+	}
+	if coupon.Kind == model.CouponPercent && coupon.Value > 100 {
+		return coupon, "percent coupons cannot take more than 100 percent off"
+	}
+	coupon.Code = strings.ToUpper(coupon.Code)
+	if coupon.SeasonID != "" {
+		if !util.VerifyToken(coupon.SeasonID) {
+			return coupon, "invalid season"
+		}
+		if _, err := api.DB.GetSeason(coupon.SeasonID); err != nil {
+			return coupon, "invalid season"
+		}
+		coupon.SeasonID = coupon.SeasonID[:len(coupon.SeasonID)-1]
+	}
+	return coupon, ""
+}
+
+// redeemable returns why the coupon cannot be redeemed on the registration, or
+// an empty string when it can.
+func redeemable(coupon model.Coupon, registration model.Registration) string {
+	if coupon.Disabled {
+		return "invalid coupon code"
+	}
+	if coupon.ExpiresOn != "" && time.Now().Format(time.DateOnly) > coupon.ExpiresOn {
+		return "coupon has expired"
+	}
+	if coupon.SeasonID != "" && coupon.SeasonID != registration.SeasonID {
+		return "coupon does not apply to this season"
+	}
+	return ""
+}
+
+// signedAdjustment signs the IDs of the adjustment before it is returned.
+func signedAdjustment(adjustment model.RegistrationAdjustment) model.RegistrationAdjustment {
+	adjustment.ID = util.ReturnSignedToken(adjustment.ID)
+	if adjustment.CouponID != "" {
+		adjustment.CouponID = util.ReturnSignedToken(adjustment.CouponID)
+	}
+	if adjustment.FinancialAidID != "" {
+		adjustment.FinancialAidID = util.ReturnSignedToken(adjustment.FinancialAidID)
+	}
+	return adjustment
+}
+
+// signedCoupon signs the IDs of the coupon before it is returned.
+func signedCoupon(coupon model.Coupon) model.Coupon {
+	coupon.ID = util.ReturnSignedToken(coupon.ID)
+	if coupon.SeasonID != "" {
+		coupon.SeasonID = util.ReturnSignedToken(coupon.SeasonID)
+	}
+	return coupon
+}
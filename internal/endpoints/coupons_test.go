@@ -0,0 +1,297 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Leagueify/api/internal/database/postgres"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var couponColumns = []string{"id", "code", "kind", "value", "season_id", "max_uses", "once_per_family", "expires_on", "disabled", "uses", "created_at"}
+
+var couponLock = "SELECT id FROM coupons WHERE code = (.+) FOR UPDATE"
+
+func couponRow(seasonID string, maxUses int, oncePerFamily bool, expiresOn string, uses int) *sqlmock.Rows {
+	return sqlmock.NewRows(couponColumns).AddRow("4CPN8WQ2R", "SPRING25", "percent", 25, seasonID, maxUses, oncePerFamily, expiresOn, false, uses, time.Now())
+}
+
+func TestCreateCoupon(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description:        "Missing Kind",
+			RequestBody:        `{"code":"spring25","value":25}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"missing required field\(s\): \[Kind\]"`,
+		},
+		{
+			Description:        "Percent Over 100",
+			RequestBody:        `{"code":"spring25","kind":"percent","value":125}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"percent coupons cannot take more than 100 percent off"`,
+		},
+		{
+			Description:        "Invalid Season",
+			RequestBody:        `{"code":"spring25","kind":"percent","value":25,"seasonId":"BJ7Q4NVRN"}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"invalid season"`,
+		},
+		{
+			Description: "Code Already In Use",
+			RequestBody: `{"code":"spring25","kind":"fixed","value":2500}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("INSERT INTO coupons").WillReturnError(&pq.Error{Code: "23505", Constraint: "coupons_code_key"})
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"code already in use"`,
+		},
+		{
+			Description: "Season Coupon Created",
+			RequestBody: `{"code":"spring25","kind":"percent","value":25,"seasonId":"BJ7Q4NVRNQ","maxUses":100,"oncePerFamily":true,"expiresOn":"2024-03-31"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+				mock.ExpectExec("INSERT INTO coupons").WithArgs(sqlmock.AnyArg(), "SPRING25", "percent", 25, "BJ7Q4NVRN", 100, true, "2024-03-31", false).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			ExpectedStatusCode: http.StatusCreated,
+			ExpectedContent:    `"status":"successful"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer([]byte(test.RequestBody)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/coupons")
+		// Perform Request
+		if assert.NoError(t, api.createCoupon(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestRedeemCouponCountsUsesAfterLock(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	e := echo.New()
+	e.Validator = &API{Validator: validator.New()}
+	api := API{DB: db}
+	// The last use is redeemed by another registration while this one waits
+	// for the coupon lock, so the uses are only counted once it is held
+	mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+) FOR UPDATE").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+	mock.ExpectQuery(couponLock).WithArgs("SPRING25").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("4CPN8WQ2R"))
+	mock.ExpectQuery("SELECT (.+) count(.+) FROM registration_adjustments (.+) FROM coupons WHERE id = (.+)").WithArgs("4CPN8WQ2R").WillReturnRows(couponRow("", 10, false, "", 10))
+	mock.ExpectRollback()
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer([]byte(`{"code":"spring25"}`)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	setAccount(c, model.Account{ID: "123ABC"})
+	c.SetParamNames("id")
+	c.SetParamValues(util.ReturnSignedToken("6KQJ7ZPR2"))
+	if assert.NoError(t, api.redeemCoupon(c)) {
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Regexp(t, regexp.MustCompile(`"detail":"coupon has reached its usage limit"`), rec.Body.String())
+	}
+	// Assert All Expectations Met
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedeemCoupon(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	lockCoupon := func(mock sqlmock.Sqlmock, coupon *sqlmock.Rows) {
+		mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+) FOR UPDATE").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+		mock.ExpectQuery(couponLock).WithArgs("SPRING25").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("4CPN8WQ2R"))
+		mock.ExpectQuery("SELECT (.+) FROM coupons WHERE id = (.+)").WithArgs("4CPN8WQ2R").WillReturnRows(coupon)
+	}
+	testCases := []struct {
+		Description        string
+		Account            model.Account
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description: "Another Account's Registration",
+			Account:     model.Account{ID: "ERCXNX5"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Unknown Code",
+			Account:     model.Account{ID: "123ABC"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+) FOR UPDATE").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery(couponLock).WithArgs("SPRING25").WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"invalid coupon code"`,
+		},
+		{
+			Description: "Expired Coupon",
+			Account:     model.Account{ID: "123ABC"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				lockCoupon(mock, couponRow("", 0, false, yesterday, 0))
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"coupon has expired"`,
+		},
+		{
+			Description: "Coupon for Another Season",
+			Account:     model.Account{ID: "123ABC"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				lockCoupon(mock, couponRow("W4SBH35WV", 0, false, "", 0))
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"coupon does not apply to this season"`,
+		},
+		{
+			Description: "Usage Limit Reached",
+			Account:     model.Account{ID: "123ABC"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				lockCoupon(mock, couponRow("BJ7Q4NVRN", 10, false, tomorrow, 10))
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusConflict,
+			ExpectedContent:    `"detail":"coupon has reached its usage limit"`,
+		},
+		{
+			Description: "Already Used by Family",
+			Account:     model.Account{ID: "123ABC"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				lockCoupon(mock, couponRow("", 0, true, "", 3))
+				mock.ExpectQuery("SELECT EXISTS (.+) FROM registration_adjustments").WithArgs("4CPN8WQ2R", "123ABC").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusConflict,
+			ExpectedContent:    `"detail":"coupon has already been used by this family"`,
+		},
+		{
+			Description: "Coupon Applied",
+			Account:     model.Account{ID: "123ABC"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				lockCoupon(mock, couponRow("BJ7Q4NVRN", 10, true, tomorrow, 3))
+				mock.ExpectQuery("SELECT EXISTS (.+) FROM registration_adjustments").WithArgs("4CPN8WQ2R", "123ABC").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationPlayerRows())
+				mock.ExpectQuery("SELECT (.+) FROM registration_adjustments WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationAdjustmentColumns).AddRow("8AJD3KXN5", "6KQJ7ZPR2", "aid", "", "2FQ9HRB7T", "Financial aid", 2000, time.Now()))
+				mock.ExpectExec("INSERT INTO registration_adjustments (.+) ON CONFLICT").WithArgs(sqlmock.AnyArg(), "6KQJ7ZPR2", "coupon", "4CPN8WQ2R", "", "Coupon SPRING25", 2000).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE registrations SET amount_due = (.+) WHERE id = (.+)").WithArgs(6000, "6KQJ7ZPR2").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			ExpectedStatusCode: http.StatusCreated,
+			ExpectedContent:    `"kind":"coupon","couponId":"4CPN8WQ2R.","description":"Coupon SPRING25","amount":2000`,
+		},
+		{
+			Description: "Coupon Already Applied",
+			Account:     model.Account{ID: "ERCXNX5", Roles: pq.StringArray{"registrar"}},
+			Mock: func(mock sqlmock.Sqlmock) {
+				lockCoupon(mock, couponRow("", 0, false, "", 3))
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationPlayerRows())
+				mock.ExpectQuery("SELECT (.+) FROM registration_adjustments WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationAdjustmentColumns))
+				mock.ExpectExec("INSERT INTO registration_adjustments (.+) ON CONFLICT").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusConflict,
+			ExpectedContent:    `"detail":"coupon has already been applied to this registration"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer([]byte(`{"code":" spring25 "}`)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, test.Account)
+		c.SetPath("/api/registrations/:id/coupons")
+		c.SetParamNames("id")
+		c.SetParamValues(util.ReturnSignedToken("6KQJ7ZPR2"))
+		// Perform Request
+		if assert.NoError(t, api.redeemCoupon(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
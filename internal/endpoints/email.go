@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/smtp"
 
+	"github.com/Leagueify/api/internal/mail"
 	"github.com/Leagueify/api/internal/model"
 	"github.com/Leagueify/api/internal/util"
 	"github.com/labstack/echo/v4"
@@ -64,3 +65,12 @@ func (api *API) createConfig(c echo.Context) error {
 		},
 	)
 }
+
+// queueEmail renders a mail template and stores it in the outbox for delivery.
+func (api *API) queueEmail(recipient, template string, data any) error {
+	email, err := mail.Compose(recipient, template, data)
+	if err != nil {
+		return err
+	}
+	return api.DB.CreateOutboundEmail(email)
+}
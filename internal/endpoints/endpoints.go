@@ -1,42 +1,71 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 
+	"github.com/Leagueify/api/internal/auth"
+	"github.com/Leagueify/api/internal/config"
 	"github.com/Leagueify/api/internal/database"
 	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/oidc"
+	"github.com/Leagueify/api/internal/payments"
 	"github.com/Leagueify/api/internal/util"
-	"github.com/getsentry/sentry-go"
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 	_ "github.com/lib/pq"
 )
 
 type API struct {
-	Account   model.Account
 	DB        database.Database
 	Validator *validator.Validate
+	// Cipher encrypts medical information at rest. Medical information is
+	// unavailable when no ENCRYPTION_KEY is configured.
+	Cipher *auth.Cipher
+	// PaymentProvider collects registration fees. Payments are unavailable
+	// when no PAYMENTS_PROVIDER is configured.
+	PaymentProvider payments.Provider
+	// Providers are the OpenID Connect identity providers account holders
+	// can sign in with, keyed by the name used in their routes.
+	Providers map[string]oidc.Provider
+	// RequireAdmin2FA refuses administrative routes, and routes allowed by a
+	// role's permissions, to accounts that have not enabled two-factor
+	// authentication.
+	RequireAdmin2FA bool
 }
 
 func (api *API) requiresAdmin(f func(echo.Context) error) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		var err error
-
-		apikey := c.Request().Header.Get("apiKey")
-		if !util.VerifyToken(apikey) {
+		account, ok := api.authenticate(c)
+		if !ok || !account.IsAdmin {
 			return util.SendStatus(http.StatusUnauthorized, c, "")
 		}
+		if api.missingTwoFactor(account) {
+			return util.SendStatus(http.StatusForbidden, c, "two-factor authentication required")
+		}
+		setAccount(c, account)
 
-		api.Account, err = api.DB.GetAccountByAPIKey(apikey)
-		if err != nil {
+		return f(c)
+	}
+}
+
+// requiresPermission allows administrators and accounts holding a role that
+// grants permission.
+func (api *API) requiresPermission(permission auth.Permission, f func(echo.Context) error) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		account, ok := api.authenticate(c)
+		if !ok {
 			return util.SendStatus(http.StatusUnauthorized, c, "")
 		}
-		if !api.Account.IsActive {
-			return util.SendStatus(http.StatusUnauthorized, c, "")
+		if !auth.HasPermission(account, permission) {
+			return util.SendStatus(http.StatusForbidden, c, "")
 		}
-		if !api.Account.IsAdmin {
-			return util.SendStatus(http.StatusUnauthorized, c, "")
+		// role holders are held to the same two-factor requirement as
+		// administrators
+		if api.missingTwoFactor(account) {
+			return util.SendStatus(http.StatusForbidden, c, "two-factor authentication required")
 		}
+		setAccount(c, account)
 
 		return f(c)
 	}
@@ -44,25 +73,64 @@ func (api *API) requiresAdmin(f func(echo.Context) error) echo.HandlerFunc {
 
 func (api *API) requiresAuth(f func(echo.Context) error) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		var err error
-
-		apikey := c.Request().Header.Get("apiKey")
-		if !util.VerifyToken(apikey) {
-			return util.SendStatus(http.StatusUnauthorized, c, "")
-		}
-
-		api.Account, err = api.DB.GetAccountByAPIKey(apikey)
-		if err != nil {
-			return util.SendStatus(http.StatusUnauthorized, c, "")
-		}
-		if !api.Account.IsActive {
+		account, ok := api.authenticate(c)
+		if !ok {
 			return util.SendStatus(http.StatusUnauthorized, c, "")
 		}
+		setAccount(c, account)
 
 		return f(c)
 	}
 }
 
+// authenticate resolves the active account for the request's apiKey header.
+func (api *API) authenticate(c echo.Context) (model.Account, bool) {
+	apikey := c.Request().Header.Get("apiKey")
+	if !util.VerifySecret(apikey) {
+		return model.Account{}, false
+	}
+
+	account, err := api.DB.GetAccountByAPIKey(auth.HashToken(apikey))
+	if err != nil {
+		return model.Account{}, false
+	}
+	if !account.IsActive {
+		return model.Account{}, false
+	}
+
+	return account, true
+}
+
+// hasPermission reports whether the account holds permission and has met the
+// two-factor requirement for using it. Handlers open to account holders check
+// it before acting on anything but the account's own records.
+func (api *API) hasPermission(account model.Account, permission auth.Permission) bool {
+	return auth.HasPermission(account, permission) && !api.missingTwoFactor(account)
+}
+
+// missingTwoFactor reports whether the account must enable two-factor
+// authentication before using the administrator or role permissions it was
+// allowed on.
+func (api *API) missingTwoFactor(account model.Account) bool {
+	if !api.RequireAdmin2FA {
+		return false
+	}
+	twoFactor, err := api.DB.GetTwoFactor(account.ID)
+	return err != nil || !twoFactor.Enabled
+}
+
+// getAccount returns the account authenticated for the current request.
+func getAccount(c echo.Context) model.Account {
+	account, _ := auth.AccountFromContext(c.Request().Context())
+	return account
+}
+
+// setAccount stores the authenticated account on the current request.
+func setAccount(c echo.Context, account model.Account) {
+	ctx := auth.WithAccount(c.Request().Context(), account)
+	c.SetRequest(c.Request().WithContext(ctx))
+}
+
 func (api *API) Validate(i interface{}) error {
 	if err := api.Validator.Struct(i); err != nil {
 		return err
@@ -70,21 +138,61 @@ func (api *API) Validate(i interface{}) error {
 	return nil
 }
 
-func Routes(e *echo.Echo) {
-	db, err := database.GetDatabase()
-	if err != nil {
-		sentry.CaptureException(err)
+func Routes(e *echo.Echo, db database.Database) {
+	cfg := config.LoadConfig()
+	api := &API{
+		DB:              db,
+		Providers:       map[string]oidc.Provider{},
+		RequireAdmin2FA: cfg.RequireAdmin2FA,
+	}
+	if len(cfg.EncryptionKey) > 0 {
+		cipher, err := auth.NewCipher(cfg.EncryptionKey)
+		if err != nil {
+			panic(err)
+		}
+		api.Cipher = cipher
+	}
+	for _, provider := range cfg.OIDCProviders {
+		api.Providers[provider.Name] = oidc.NewClient(
+			provider.Issuer, provider.ClientID, provider.ClientSecret,
+			fmt.Sprintf("%s/oidc/%s/callback", cfg.BaseURL, provider.Name),
+		)
+	}
+	switch cfg.PaymentsProvider {
+	case "fake":
+		api.PaymentProvider = payments.NewFake(cfg.BaseURL, cfg.PaymentsWebhookSecret)
+	case "stripe":
+		stripe := payments.NewStripe(cfg.StripeSecretKey, cfg.PaymentsWebhookSecret, cfg.PaymentsCurrency)
+		stripe.BaseURL = cfg.StripeAPIURL
+		api.PaymentProvider = stripe
 	}
-	api := &API{DB: db}
 	e.Validator = &API{Validator: validator.New()}
 	// Create API Group
 	routes := e.Group("/api")
 	// Register API Routes
 	api.Accounts(routes)
+	api.Admin(routes)
+	api.Coupons(routes)
 	api.Email(routes)
+	api.Fees(routes)
+	api.FinancialAid(routes)
+	api.Guardians(routes)
+	api.Installments(routes)
+	api.Invoices(routes)
 	api.Leagues(routes)
+	api.Medical(routes)
+	api.OIDC(routes)
+	api.Passwords(routes)
+	api.Payments(routes)
 	api.Players(routes)
 	api.Positions(routes)
+	api.Registrations(routes)
+	api.Roles(routes)
 	api.Seasons(routes)
+	api.Sessions(routes)
 	api.Sports(routes)
+	api.Teams(routes)
+	api.TwoFactor(routes)
+	api.Waitlists(routes)
+	api.Withdrawals(routes)
 }
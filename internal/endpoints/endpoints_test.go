@@ -0,0 +1,260 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Leagueify/api/internal/auth"
+	"github.com/Leagueify/api/internal/database/postgres"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/labstack/echo/v4"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrentAuthentication(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	mock.MatchExpectationsInOrder(false)
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description string
+		Middleware  func(api *API, f func(echo.Context) error) echo.HandlerFunc
+		IsAdmin     bool
+		Roles       pq.StringArray
+	}{
+		{
+			Description: "Interleaved Authenticated Requests",
+			Middleware: func(api *API, f func(echo.Context) error) echo.HandlerFunc {
+				return api.requiresAuth(f)
+			},
+		},
+		{
+			Description: "Interleaved Admin Requests",
+			Middleware: func(api *API, f func(echo.Context) error) echo.HandlerFunc {
+				return api.requiresAdmin(f)
+			},
+			IsAdmin: true,
+		},
+		{
+			Description: "Interleaved Permission Requests",
+			Middleware: func(api *API, f func(echo.Context) error) echo.HandlerFunc {
+				return api.requiresPermission(auth.PermissionManageRegistrations, f)
+			},
+			Roles: pq.StringArray{"registrar"},
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		const totalRequests = 25
+		api := &API{DB: db}
+		// Register an account and API key per request
+		apikeys := make([]string, totalRequests)
+		accountIDs := make([]string, totalRequests)
+		for index := range apikeys {
+			apikeys[index] = util.SecretToken()
+			accountIDs[index] = fmt.Sprintf("ACCOUNT%02d", index)
+			mock.ExpectQuery("UPDATE sessions SET last_used_at = now\\(\\) WHERE key_hash = (.+)").
+				WithArgs(auth.HashToken(apikeys[index])).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "email", "password", "phone", "date_of_birth", "players", "coach", "volunteer", "is_active", "is_admin", "session_id", "roles"}).AddRow(accountIDs[index], "Leagueify", "Test", "test@leagueify.org", "", "+12085551234", "1990-08-31", pq.StringArray{}, false, false, true, test.IsAdmin, fmt.Sprintf("SESSION%02d", index), test.Roles))
+		}
+		// Hold every handler until all requests have authenticated
+		var authenticated sync.WaitGroup
+		authenticated.Add(totalRequests)
+		handler := test.Middleware(api, func(c echo.Context) error {
+			authenticated.Done()
+			authenticated.Wait()
+			return c.String(http.StatusOK, getAccount(c).ID)
+		})
+		// Perform Requests
+		e := echo.New()
+		var requests sync.WaitGroup
+		responses := make([]*httptest.ResponseRecorder, totalRequests)
+		for index := range apikeys {
+			requests.Add(1)
+			go func(index int) {
+				defer requests.Done()
+				req := httptest.NewRequest(http.MethodGet, "/api/players", nil)
+				req.Header.Set("apiKey", apikeys[index])
+				responses[index] = httptest.NewRecorder()
+				c := e.NewContext(req, responses[index])
+				assert.NoError(t, handler(c))
+			}(index)
+		}
+		requests.Wait()
+		// Assert Each Request Kept Its Own Account
+		for index, rec := range responses {
+			assert.Equal(t, http.StatusOK, rec.Code, test.Description)
+			assert.Equal(t, accountIDs[index], rec.Body.String(), test.Description)
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestRequiresPermission(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		IsAdmin            bool
+		Roles              pq.StringArray
+		RequireAdmin2FA    bool
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+	}{
+		{
+			Description:        "Administrator Holds Every Permission",
+			IsAdmin:            true,
+			Roles:              pq.StringArray{},
+			ExpectedStatusCode: http.StatusOK,
+		},
+		{
+			Description:        "Role Grants Permission",
+			Roles:              pq.StringArray{"coach", "league_admin"},
+			ExpectedStatusCode: http.StatusOK,
+		},
+		{
+			Description:        "Role Without Permission",
+			Roles:              pq.StringArray{"registrar"},
+			ExpectedStatusCode: http.StatusForbidden,
+		},
+		{
+			Description:        "No Roles",
+			Roles:              pq.StringArray{},
+			ExpectedStatusCode: http.StatusForbidden,
+		},
+		{
+			Description:     "Role Holder Without Two-Factor",
+			Roles:           pq.StringArray{"league_admin"},
+			RequireAdmin2FA: true,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM two_factor WHERE (.+)").WithArgs("ERCXNX5").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectedStatusCode: http.StatusForbidden,
+		},
+		{
+			Description:     "Role Holder With Two-Factor",
+			Roles:           pq.StringArray{"league_admin"},
+			RequireAdmin2FA: true,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM two_factor WHERE (.+)").WithArgs("ERCXNX5").WillReturnRows(sqlmock.NewRows([]string{"account_id", "secret", "enabled", "last_counter", "count"}).AddRow("ERCXNX5", "JBSWY3DPEHPK3PXP", true, 0, 10))
+			},
+			ExpectedStatusCode: http.StatusOK,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		apikey := util.SecretToken()
+		mock.ExpectQuery("UPDATE sessions SET last_used_at = now\\(\\) WHERE key_hash = (.+)").
+			WithArgs(auth.HashToken(apikey)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "email", "password", "phone", "date_of_birth", "players", "coach", "volunteer", "is_active", "is_admin", "session_id", "roles"}).AddRow("ERCXNX5", "Leagueify", "Test", "test@leagueify.org", "", "+12085551234", "1990-08-31", pq.StringArray{}, false, false, true, test.IsAdmin, "49QRBF09Y", test.Roles))
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		api := &API{DB: db, RequireAdmin2FA: test.RequireAdmin2FA}
+		handler := api.requiresPermission(auth.PermissionManageSeasons, func(c echo.Context) error {
+			return c.NoContent(http.StatusOK)
+		})
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/api/seasons", nil)
+		req.Header.Set("apiKey", apikey)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		// Perform Request
+		if assert.NoError(t, handler(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestRequiresAdminTwoFactor(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		RequireAdmin2FA    bool
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+	}{
+		{
+			Description:        "Two-Factor Not Required",
+			ExpectedStatusCode: http.StatusOK,
+		},
+		{
+			Description:     "Two-Factor Not Enrolled",
+			RequireAdmin2FA: true,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM two_factor WHERE (.+)").WithArgs("ERCXNX5").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectedStatusCode: http.StatusForbidden,
+		},
+		{
+			Description:     "Two-Factor Pending Confirmation",
+			RequireAdmin2FA: true,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM two_factor WHERE (.+)").WillReturnRows(sqlmock.NewRows([]string{"account_id", "secret", "enabled", "last_counter", "count"}).AddRow("ERCXNX5", "JBSWY3DPEHPK3PXP", false, 0, 0))
+			},
+			ExpectedStatusCode: http.StatusForbidden,
+		},
+		{
+			Description:     "Two-Factor Enabled",
+			RequireAdmin2FA: true,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM two_factor WHERE (.+)").WillReturnRows(sqlmock.NewRows([]string{"account_id", "secret", "enabled", "last_counter", "count"}).AddRow("ERCXNX5", "JBSWY3DPEHPK3PXP", true, 0, 10))
+			},
+			ExpectedStatusCode: http.StatusOK,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		apikey := util.SecretToken()
+		mock.ExpectQuery("UPDATE sessions SET last_used_at = now\\(\\) WHERE key_hash = (.+)").
+			WithArgs(auth.HashToken(apikey)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "email", "password", "phone", "date_of_birth", "players", "coach", "volunteer", "is_active", "is_admin", "session_id", "roles"}).AddRow("ERCXNX5", "Leagueify", "Test", "test@leagueify.org", "", "+12085551234", "1990-08-31", pq.StringArray{}, false, false, true, true, "49QRBF09Y", pq.StringArray{}))
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		api := &API{DB: db, RequireAdmin2FA: test.RequireAdmin2FA}
+		handler := api.requiresAdmin(func(c echo.Context) error {
+			return c.NoContent(http.StatusOK)
+		})
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/api/seasons", nil)
+		req.Header.Set("apiKey", apikey)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		// Perform Request
+		if assert.NoError(t, handler(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
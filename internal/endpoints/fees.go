@@ -0,0 +1,125 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Leagueify/api/internal/auth"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/labstack/echo/v4"
+)
+
+func (api *API) Fees(e *echo.Group) {
+	e.GET("/seasons/:id/fees", api.listFeeSchedules)
+	e.POST("/seasons/:id/fees", api.requiresPermission(auth.PermissionManageSeasons, api.createFeeSchedule))
+	e.PUT("/seasons/:id/fees/:fee", api.requiresPermission(auth.PermissionManageSeasons, api.updateFeeSchedule))
+	e.DELETE("/seasons/:id/fees/:fee", api.requiresPermission(auth.PermissionManageSeasons, api.deleteFeeSchedule))
+}
+
+func (api *API) createFeeSchedule(c echo.Context) error {
+	seasonID, ok := api.pathSeason(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	schedule, detail := bindFeeSchedule(c)
+	if detail != "" {
+		return util.SendStatus(http.StatusBadRequest, c, detail)
+	}
+	schedule.ID = util.SignedToken(10)
+	schedule.ID = schedule.ID[:len(schedule.ID)-1]
+	schedule.SeasonID = seasonID
+	if err := api.DB.CreateFeeSchedule(schedule); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusCreated,
+		map[string]string{
+			"status": "successful",
+		},
+	)
+}
+
+func (api *API) deleteFeeSchedule(c echo.Context) error {
+	seasonID, ok := api.pathSeason(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	scheduleID := c.Param("fee")
+	if !util.VerifyToken(scheduleID) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if err := api.DB.DeleteFeeSchedule(seasonID, scheduleID[:len(scheduleID)-1]); err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (api *API) listFeeSchedules(c echo.Context) error {
+	seasonID, ok := api.pathSeason(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	schedules, err := api.DB.ListFeeSchedules(seasonID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	for index := range schedules {
+		schedules[index].ID = util.ReturnSignedToken(schedules[index].ID)
+	}
+	return c.JSON(http.StatusOK, schedules)
+}
+
+// updateFeeSchedule replaces a fee schedule. Players already registered keep
+// the amount they were charged.
+func (api *API) updateFeeSchedule(c echo.Context) error {
+	seasonID, ok := api.pathSeason(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	scheduleID := c.Param("fee")
+	if !util.VerifyToken(scheduleID) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	schedule, detail := bindFeeSchedule(c)
+	if detail != "" {
+		return util.SendStatus(http.StatusBadRequest, c, detail)
+	}
+	schedule.ID = scheduleID[:len(scheduleID)-1]
+	schedule.SeasonID = seasonID
+	if err := api.DB.UpdateFeeSchedule(schedule); err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	schedule.ID = scheduleID
+	return c.JSON(http.StatusOK, schedule)
+}
+
+// bindFeeSchedule binds and validates a fee schedule, returning the detail of
+// the bad request when it is invalid.
+func bindFeeSchedule(c echo.Context) (model.FeeSchedule, string) {
+	schedule := model.FeeSchedule{}
+	// bind payload to model
+	if err := c.Bind(&schedule); err != nil {
+		return schedule, "invalid json payload"
+	}
+	// validate payload against model
+	if err := c.Validate(schedule); err != nil {
+		return schedule, util.HandleError(err)
+	}
+	// the early bird price must end before the late fee starts
+	if schedule.EarlyBirdEnds != "" && schedule.LateFeeStarts != "" &&
+		schedule.EarlyBirdEnds >= schedule.LateFeeStarts {
+		return schedule, "incorrect date range(s): [EarlyBirdEnds-LateFeeStarts]"
+	}
+	return schedule, ""
+}
+
+// pathSeason returns the unsigned ID of the season in the id path parameter.
+func (api *API) pathSeason(c echo.Context) (string, bool) {
+	seasonID := c.Param("id")
+	if !util.VerifyToken(seasonID) {
+		return "", false
+	}
+	if _, err := api.DB.GetSeason(seasonID); err != nil {
+		return "", false
+	}
+	return seasonID[:len(seasonID)-1], true
+}
@@ -0,0 +1,281 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Leagueify/api/internal/database/postgres"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateFeeSchedule(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		SeasonID           string
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description:        "Invalid Season ID",
+			SeasonID:           "BJ7Q4NVRN",
+			RequestBody:        `{"baseFee":10000}`,
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Season Not Found",
+			SeasonID:    "BJ7Q4NVRNQ",
+			RequestBody: `{"baseFee":10000}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Negative Fee",
+			SeasonID:    "BJ7Q4NVRNQ",
+			RequestBody: `{"baseFee":-1}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			Description: "Early Bird Fee Without End Date",
+			SeasonID:    "BJ7Q4NVRNQ",
+			RequestBody: `{"baseFee":10000,"earlyBirdFee":8000}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			Description: "Late Fee Without Start Date",
+			SeasonID:    "BJ7Q4NVRNQ",
+			RequestBody: `{"baseFee":10000,"lateFee":2500}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			Description: "Sibling Discount Over 100 Percent",
+			SeasonID:    "BJ7Q4NVRNQ",
+			RequestBody: `{"baseFee":10000,"siblingDiscount":101}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			Description: "Early Bird Ends After Late Fee Starts",
+			SeasonID:    "BJ7Q4NVRNQ",
+			RequestBody: `{"baseFee":10000,"earlyBirdFee":8000,"earlyBirdEnds":"2024-03-01","lateFee":2500,"lateFeeStarts":"2024-02-01"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"incorrect date range\(s\): \[EarlyBirdEnds-LateFeeStarts\]"`,
+		},
+		{
+			Description: "Fee Schedule Created",
+			SeasonID:    "BJ7Q4NVRNQ",
+			RequestBody: `{"division":"U12","baseFee":10000,"earlyBirdFee":8000,"earlyBirdEnds":"2024-02-01","lateFee":2500,"lateFeeStarts":"2024-03-01","siblingDiscount":10,"familyMax":25000}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+				mock.ExpectExec("INSERT INTO fee_schedules (.+) VALUES (.+)").WithArgs(sqlmock.AnyArg(), "BJ7Q4NVRN", "U12", 10000, 8000, "2024-02-01", 2500, "2024-03-01", 10, 25000).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			ExpectedStatusCode: http.StatusCreated,
+			ExpectedContent:    `"status":"successful"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer([]byte(test.RequestBody)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/seasons/:id/fees")
+		c.SetParamNames("id")
+		c.SetParamValues(test.SeasonID)
+		// Perform Request
+		if assert.NoError(t, api.createFeeSchedule(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestListFeeSchedules(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+	mock.ExpectQuery("SELECT (.+) FROM fee_schedules WHERE season_id = (.+)").WithArgs("BJ7Q4NVRN").WillReturnRows(sqlmock.NewRows(feeScheduleColumns).AddRow("FEE1234", "BJ7Q4NVRN", "", 10000, 8000, "2024-02-01", 0, "", 10, 0))
+	// Initialize Echo and the Echo validator
+	e := echo.New()
+	e.Validator = &API{Validator: validator.New()}
+	api := API{DB: db}
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/api/seasons/:id/fees")
+	c.SetParamNames("id")
+	c.SetParamValues("BJ7Q4NVRNQ")
+	// Perform Request
+	if assert.NoError(t, api.listFeeSchedules(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"id":"%s","division":"","baseFee":10000,"earlyBirdFee":8000`, util.ReturnSignedToken("FEE1234")))
+	}
+	// Assert All Expectations Met
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateFeeSchedule(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		ScheduleID         string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+	}{
+		{
+			Description: "Invalid Fee Schedule ID",
+			ScheduleID:  "FEE1234",
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Fee Schedule Not Found",
+			ScheduleID:  util.ReturnSignedToken("FEE1234"),
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+				mock.ExpectExec("UPDATE fee_schedules SET (.+) WHERE (.+)").WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Fee Schedule Updated",
+			ScheduleID:  util.ReturnSignedToken("FEE1234"),
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+				mock.ExpectExec("UPDATE fee_schedules SET (.+) WHERE (.+)").WithArgs("", 9000, nil, "", 0, "", 0, 0, "BJ7Q4NVRN", "FEE1234").WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			ExpectedStatusCode: http.StatusOK,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer([]byte(`{"baseFee":9000}`)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/seasons/:id/fees/:fee")
+		c.SetParamNames("id", "fee")
+		c.SetParamValues("BJ7Q4NVRNQ", test.ScheduleID)
+		// Perform Request
+		if assert.NoError(t, api.updateFeeSchedule(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestDeleteFeeSchedule(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		Rows               int64
+		ExpectedStatusCode int
+	}{
+		{Description: "Fee Schedule Not Found", Rows: 0, ExpectedStatusCode: http.StatusNotFound},
+		{Description: "Fee Schedule Deleted", Rows: 1, ExpectedStatusCode: http.StatusNoContent},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+		mock.ExpectExec("DELETE FROM fee_schedules WHERE (.+)").WithArgs("BJ7Q4NVRN", "FEE1234").WillReturnResult(sqlmock.NewResult(0, test.Rows))
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodDelete, "/", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/seasons/:id/fees/:fee")
+		c.SetParamNames("id", "fee")
+		c.SetParamValues("BJ7Q4NVRNQ", util.ReturnSignedToken("FEE1234"))
+		// Perform Request
+		if assert.NoError(t, api.deleteFeeSchedule(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Leagueify/api/internal/auth"
+	"github.com/Leagueify/api/internal/config"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/labstack/echo/v4"
+)
+
+func (api *API) Guardians(e *echo.Group) {
+	e.POST("/players/guardians/accept", api.requiresAuth(api.acceptGuardianInvite))
+	e.GET("/players/:id/guardians", api.requiresAuth(api.listGuardians))
+	e.POST("/players/:id/guardians", api.requiresAuth(api.inviteGuardian))
+	e.DELETE("/players/:id/guardians/:account", api.requiresAuth(api.removeGuardian))
+}
+
+// acceptGuardianInvite makes the account a guardian of the invited player.
+// Invites can only be accepted by the account holding the invited email.
+func (api *API) acceptGuardianInvite(c echo.Context) error {
+	account := getAccount(c)
+	payload := model.GuardianInviteAcceptance{}
+	// bind payload to model
+	if err := c.Bind(&payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	if !util.VerifySecret(payload.Token) {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid or expired token")
+	}
+	// Begin Transaction
+	tx, err := api.DB.BeginTransaction()
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	defer tx.Rollback()
+	invite, err := api.DB.ConsumeGuardianInvite(tx, auth.HashToken(payload.Token))
+	if err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid or expired token")
+	}
+	if !strings.EqualFold(invite.Email, account.Email) {
+		return util.SendStatus(http.StatusForbidden, c, "invite was sent to a different email address")
+	}
+	if err := api.DB.CreateGuardian(tx, model.Guardian{
+		AccountID:  account.ID,
+		PlayerID:   invite.PlayerID,
+		Permission: invite.Permission,
+	}); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if err := tx.Commit(); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusOK,
+		map[string]string{
+			"status": "successful",
+		},
+	)
+}
+
+// inviteGuardian emails an invite to become a guardian of the player. Only
+// primary guardians can invite.
+func (api *API) inviteGuardian(c echo.Context) error {
+	guardian, ok := api.playerGuardian(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if !auth.GuardianPermission(guardian.Permission).CanManage() {
+		return util.SendStatus(http.StatusForbidden, c, "")
+	}
+	invite := model.GuardianInvite{}
+	// bind payload to model
+	if err := c.Bind(&invite); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(invite); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	if !auth.IsGuardianPermission(invite.Permission) {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid guardian permission")
+	}
+	// existing guardians are not invited again
+	if invitee, err := api.DB.GetAccountByEmail(invite.Email); err == nil {
+		if _, err := api.DB.GetGuardian(invitee.ID, guardian.PlayerID); err == nil {
+			return util.SendStatus(http.StatusConflict, c, "account is already a guardian of this player")
+		}
+	}
+	player, err := api.DB.GetPlayer(guardian.PlayerID)
+	if err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	account := getAccount(c)
+	token := util.SecretToken()
+	invite.ID = util.SignedToken(10)
+	invite.PlayerID = guardian.PlayerID
+	invite.InvitedBy = account.ID
+	invite.TokenHash = auth.HashToken(token)
+	invite.ExpiresAt = time.Now().Add(auth.GuardianInviteTTL)
+	if err := api.DB.CreateGuardianInvite(invite); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	cfg := config.LoadConfig()
+	if err := api.queueEmail(invite.Email, "guardian_invite", map[string]any{
+		"InvitedBy":  fmt.Sprintf("%s %s", account.FirstName, account.LastName),
+		"PlayerName": player.FirstName,
+		"Link":       fmt.Sprintf("%s/guardians/accept?token=%s", cfg.BaseURL, token),
+		"Expires":    auth.GuardianInviteTTL,
+	}); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusCreated,
+		map[string]string{
+			"status": "successful",
+		},
+	)
+}
+
+func (api *API) listGuardians(c echo.Context) error {
+	guardian, ok := api.playerGuardian(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	guardians, err := api.DB.ListGuardians(guardian.PlayerID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusOK, guardians)
+}
+
+// removeGuardian removes a guardian from the player. Primary guardians can
+// remove anyone and every guardian can remove themselves, but a player always
+// keeps at least one primary guardian.
+func (api *API) removeGuardian(c echo.Context) error {
+	guardian, ok := api.playerGuardian(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	accountID := c.Param("account")
+	if !util.VerifyToken(accountID) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	accountID = accountID[:len(accountID)-1]
+	if accountID != guardian.AccountID && !auth.GuardianPermission(guardian.Permission).CanManage() {
+		return util.SendStatus(http.StatusForbidden, c, "")
+	}
+	removed, err := api.DB.GetGuardian(accountID, guardian.PlayerID)
+	if err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	// Begin Transaction
+	tx, err := api.DB.BeginTransaction()
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	defer tx.Rollback()
+	if auth.GuardianPermission(removed.Permission) == auth.GuardianPrimary {
+		totalGuardians, err := api.DB.GetTotalPrimaryGuardians(tx, guardian.PlayerID)
+		if err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+		if totalGuardians <= 1 {
+			return util.SendStatus(http.StatusConflict, c, "last primary guardian cannot be removed")
+		}
+	}
+	if err := api.DB.DeleteGuardian(tx, accountID, guardian.PlayerID); err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if err := tx.Commit(); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,291 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Leagueify/api/internal/database/postgres"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInviteGuardian(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description: "Not Guardian",
+			RequestBody: `{"email":"second@leagueify.org","permission":"secondary"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("ERCXNX5", "49QRBF09Y").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Secondary Guardian",
+			RequestBody: `{"email":"second@leagueify.org","permission":"secondary"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("ERCXNX5", "49QRBF09Y", "secondary", time.Now()))
+			},
+			ExpectedStatusCode: http.StatusForbidden,
+		},
+		{
+			Description: "Invalid Permission",
+			RequestBody: `{"email":"second@leagueify.org","permission":"owner"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("ERCXNX5", "49QRBF09Y", "primary", time.Now()))
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"invalid guardian permission"`,
+		},
+		{
+			Description: "Already Guardian",
+			RequestBody: `{"email":"second@leagueify.org","permission":"secondary"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("ERCXNX5", "49QRBF09Y", "primary", time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE email = (.+)$").WithArgs("second@leagueify.org").WillReturnRows(sqlmock.NewRows(accountColumns[:12]).AddRow("SECOND1", "Second", "Test", "second@leagueify.org", "", "+12085550000", "1990-08-31", pq.StringArray{"49QRBF09Y"}, false, false, true, false))
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("SECOND1", "49QRBF09Y").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("SECOND1", "49QRBF09Y", "view", time.Now()))
+			},
+			ExpectedStatusCode: http.StatusConflict,
+		},
+		{
+			Description: "Invite Sent",
+			RequestBody: `{"email":"second@leagueify.org","permission":"secondary"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("ERCXNX5", "49QRBF09Y", "primary", time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE email = (.+)$").WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery("SELECT (.+) FROM players WHERE id = (.+)").WithArgs("49QRBF09Y").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("49QRBF09Y", "Player", "Test", "2015-08-31", "goalie", "", "", nil, "", "", "", "", false))
+				mock.ExpectExec("INSERT INTO guardian_invites (.+) VALUES (.+)").WithArgs(sqlmock.AnyArg(), "49QRBF09Y", "ERCXNX5", "second@leagueify.org", "secondary", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO email_outbox (.+) VALUES (.+)").WithArgs(sqlmock.AnyArg(), "second@leagueify.org", "Leagueify Test invited you to Leagueify", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			ExpectedStatusCode: http.StatusCreated,
+			ExpectedContent:    `"status":"successful"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodPost, "/api/players/49QRBF09YA/guardians", bytes.NewBufferString(test.RequestBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, model.Account{ID: "ERCXNX5", FirstName: "Leagueify", LastName: "Test"})
+		c.SetParamNames("id")
+		c.SetParamValues("49QRBF09YA")
+		// Perform Request
+		if assert.NoError(t, api.inviteGuardian(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Assert Response Content
+			assert.Regexp(t, regexp.MustCompile(test.ExpectedContent), rec.Body.String(), test.Description)
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet(), test.Description)
+	}
+}
+
+func TestAcceptGuardianInvite(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	token := util.SecretToken()
+	inviteColumns := []string{"id", "player_id", "invited_by", "email", "permission", "expires_at"}
+	testCases := []struct {
+		Description        string
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description:        "Missing Token",
+			RequestBody:        `{}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			Description:        "Malformed Token",
+			RequestBody:        `{"token":"token"}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"invalid or expired token"`,
+		},
+		{
+			Description: "Expired Invite",
+			RequestBody: `{"token":"` + token + `"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("UPDATE guardian_invites SET accepted_at = now\\(\\) WHERE (.+) RETURNING (.+)").WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"invalid or expired token"`,
+		},
+		{
+			Description: "Different Email Address",
+			RequestBody: `{"token":"` + token + `"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("UPDATE guardian_invites SET accepted_at = now\\(\\) WHERE (.+) RETURNING (.+)").WillReturnRows(sqlmock.NewRows(inviteColumns).AddRow("INVITE123", "49QRBF09Y", "ERCXNX5", "other@leagueify.org", "secondary", time.Now().Add(time.Hour)))
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusForbidden,
+		},
+		{
+			Description: "Invite Accepted",
+			RequestBody: `{"token":"` + token + `"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("UPDATE guardian_invites SET accepted_at = now\\(\\) WHERE (.+) RETURNING (.+)").WillReturnRows(sqlmock.NewRows(inviteColumns).AddRow("INVITE123", "49QRBF09Y", "ERCXNX5", "Second@Leagueify.org", "secondary", time.Now().Add(time.Hour)))
+				mock.ExpectExec("INSERT INTO guardians (.+) VALUES (.+) ON CONFLICT (.+)").WithArgs("SECOND1", "49QRBF09Y", "secondary").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"status":"successful"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodPost, "/api/players/guardians/accept", bytes.NewBufferString(test.RequestBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, model.Account{ID: "SECOND1", Email: "second@leagueify.org"})
+		// Perform Request
+		if assert.NoError(t, api.acceptGuardianInvite(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Assert Response Content
+			assert.Regexp(t, regexp.MustCompile(test.ExpectedContent), rec.Body.String(), test.Description)
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet(), test.Description)
+	}
+}
+
+func TestRemoveGuardian(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		AccountID          string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+	}{
+		{
+			Description: "Secondary Guardian Removes Another",
+			AccountID:   "ERCXNX57",
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("SECOND1", "49QRBF09Y").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("SECOND1", "49QRBF09Y", "secondary", time.Now()))
+			},
+			ExpectedStatusCode: http.StatusForbidden,
+		},
+		{
+			Description: "Secondary Guardian Removes Themselves",
+			AccountID:   util.ReturnSignedToken("SECOND1"),
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("SECOND1", "49QRBF09Y").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("SECOND1", "49QRBF09Y", "secondary", time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("SECOND1", "49QRBF09Y").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("SECOND1", "49QRBF09Y", "secondary", time.Now()))
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM guardians WHERE (.+)").WithArgs("SECOND1", "49QRBF09Y").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			ExpectedStatusCode: http.StatusNoContent,
+		},
+		{
+			Description: "Last Primary Guardian",
+			AccountID:   util.ReturnSignedToken("SECOND1"),
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("SECOND1", "49QRBF09Y", "primary", time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("SECOND1", "49QRBF09Y", "primary", time.Now()))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM (.+) FOR UPDATE").WithArgs("49QRBF09Y").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusConflict,
+		},
+		{
+			Description: "Primary Guardian Removes Another",
+			AccountID:   "ERCXNX57",
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("SECOND1", "49QRBF09Y", "primary", time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("ERCXNX5", "49QRBF09Y").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("ERCXNX5", "49QRBF09Y", "view", time.Now()))
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM guardians WHERE (.+)").WithArgs("ERCXNX5", "49QRBF09Y").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			ExpectedStatusCode: http.StatusNoContent,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodDelete, "/", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, model.Account{ID: "SECOND1"})
+		c.SetPath("/api/players/:id/guardians/:account")
+		c.SetParamNames("id", "account")
+		c.SetParamValues("49QRBF09YA", test.AccountID)
+		// Perform Request
+		if assert.NoError(t, api.removeGuardian(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet(), test.Description)
+	}
+}
@@ -0,0 +1,212 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Leagueify/api/internal/auth"
+	"github.com/Leagueify/api/internal/installments"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/labstack/echo/v4"
+)
+
+func (api *API) Installments(e *echo.Group) {
+	e.GET("/seasons/:id/installment-plans", api.listInstallmentPlans)
+	e.POST("/seasons/:id/installment-plans", api.requiresPermission(auth.PermissionManageSeasons, api.createInstallmentPlan))
+	e.PUT("/seasons/:id/installment-plans/:plan", api.requiresPermission(auth.PermissionManageSeasons, api.updateInstallmentPlan))
+	e.DELETE("/seasons/:id/installment-plans/:plan", api.requiresPermission(auth.PermissionManageSeasons, api.deleteInstallmentPlan))
+	e.GET("/registrations/:id/installments", api.requiresAuth(api.listInstallments))
+	e.POST("/registrations/:id/installments", api.requiresAuth(api.enrollInstallmentPlan))
+}
+
+func (api *API) createInstallmentPlan(c echo.Context) error {
+	seasonID, ok := api.pathSeason(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	plan := model.InstallmentPlan{}
+	// bind payload to model
+	if err := c.Bind(&plan); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(plan); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	plan.ID = util.SignedToken(10)
+	plan.ID = plan.ID[:len(plan.ID)-1]
+	plan.SeasonID = seasonID
+	if err := api.DB.CreateInstallmentPlan(plan); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusCreated,
+		map[string]string{
+			"status": "successful",
+		},
+	)
+}
+
+// deleteInstallmentPlan removes a plan. Registrations already on it keep
+// their schedule.
+func (api *API) deleteInstallmentPlan(c echo.Context) error {
+	seasonID, ok := api.pathSeason(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	planID := c.Param("plan")
+	if !util.VerifyToken(planID) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if err := api.DB.DeleteInstallmentPlan(seasonID, planID[:len(planID)-1]); err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// enrollInstallmentPlan puts a registration on one of its season's
+// installment plans, scheduling the first installment today. Account holders
+// choose a plan for their own registrations and accounts able to manage
+// registrations may choose one for any.
+func (api *API) enrollInstallmentPlan(c echo.Context) error {
+	registration, ok := api.pathRegistration(c)
+	account := getAccount(c)
+	if !ok || (registration.AccountID != account.ID &&
+		!api.hasPermission(account, auth.PermissionManageRegistrations)) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	payload := model.InstallmentEnrollment{}
+	// bind payload to model
+	if err := c.Bind(&payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	if !util.VerifyToken(payload.PlanID) {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid installment plan")
+	}
+	plan, err := api.DB.GetInstallmentPlan(registration.SeasonID, payload.PlanID[:len(payload.PlanID)-1])
+	if err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid installment plan")
+	}
+	// Begin Transaction
+	tx, err := api.DB.BeginTransaction()
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	defer tx.Rollback()
+	if registration, err = api.DB.GetRegistrationForUpdate(tx, registration.ID); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if registration.Balance <= 0 {
+		return util.SendStatus(http.StatusConflict, c, "registration has no balance")
+	}
+	existing, err := api.DB.ListInstallments(registration.ID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if len(existing) > 0 {
+		return util.SendStatus(http.StatusConflict, c, "registration is already on an installment plan")
+	}
+	now := time.Now()
+	schedule := installments.Schedule(plan, registration.ID, now)
+	for index := range schedule {
+		schedule[index].ID = util.SignedToken(10)
+		schedule[index].ID = schedule[index].ID[:len(schedule[index].ID)-1]
+	}
+	if err := api.DB.CreateInstallments(tx, schedule); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if err := tx.Commit(); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	schedule = installments.Apply(
+		schedule, registration.AmountDue, registration.AmountPaid, now.Format(time.DateOnly),
+	)
+	for index := range schedule {
+		schedule[index] = signedInstallment(schedule[index])
+	}
+	return c.JSON(http.StatusCreated, schedule)
+}
+
+func (api *API) listInstallmentPlans(c echo.Context) error {
+	seasonID, ok := api.pathSeason(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	plans, err := api.DB.ListInstallmentPlans(seasonID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	for index := range plans {
+		plans[index].ID = util.ReturnSignedToken(plans[index].ID)
+	}
+	return c.JSON(http.StatusOK, plans)
+}
+
+// listInstallments returns a registration's installment schedule with the
+// amount and status of each installment. Account holders may list their own
+// registration's schedule, and accounts able to view registrations may list
+// any.
+func (api *API) listInstallments(c echo.Context) error {
+	registration, ok := api.pathRegistration(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	account := getAccount(c)
+	if registration.AccountID != account.ID &&
+		!api.hasPermission(account, auth.PermissionViewRegistrations) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	schedule, err := api.DB.ListInstallments(registration.ID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	schedule = installments.Apply(
+		schedule, registration.AmountDue, registration.AmountPaid, time.Now().Format(time.DateOnly),
+	)
+	for index := range schedule {
+		schedule[index] = signedInstallment(schedule[index])
+	}
+	return c.JSON(http.StatusOK, schedule)
+}
+
+// updateInstallmentPlan replaces an installment plan. Registrations already
+// on it keep their schedule.
+func (api *API) updateInstallmentPlan(c echo.Context) error {
+	seasonID, ok := api.pathSeason(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	planID := c.Param("plan")
+	if !util.VerifyToken(planID) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	plan := model.InstallmentPlan{}
+	// bind payload to model
+	if err := c.Bind(&plan); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(plan); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	plan.ID = planID[:len(planID)-1]
+	plan.SeasonID = seasonID
+	if err := api.DB.UpdateInstallmentPlan(plan); err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	plan.ID = planID
+	return c.JSON(http.StatusOK, plan)
+}
+
+// signedInstallment signs the IDs of the installment before it is returned.
+func signedInstallment(installment model.Installment) model.Installment {
+	installment.ID = util.ReturnSignedToken(installment.ID)
+	if installment.PlanID != "" {
+		installment.PlanID = util.ReturnSignedToken(installment.PlanID)
+	}
+	return installment
+}
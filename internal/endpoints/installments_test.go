@@ -0,0 +1,280 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Leagueify/api/internal/database/postgres"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	installmentColumns     = []string{"id", "registration_id", "plan_id", "number", "due_on", "overdue_at", "reminded_at"}
+	installmentPlanColumns = []string{"id", "season_id", "name", "installments", "interval_days"}
+)
+
+// owingRegistrationRow is a registration with 200.00 of 300.00 left to pay.
+func owingRegistrationRow() *sqlmock.Rows {
+	return sqlmock.NewRows(registrationColumns).AddRow("6KQJ7ZPR2", "123ABC", "BJ7Q4NVRN", 30000, 10000, time.Now())
+}
+
+func TestCreateInstallmentPlan(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		SeasonID           string
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description: "Season Not Found",
+			SeasonID:    "BJ7Q4NVRNQ",
+			RequestBody: `{"name":"Three Payments","installments":3,"intervalDays":30}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Single Installment",
+			SeasonID:    "BJ7Q4NVRNQ",
+			RequestBody: `{"name":"One Payment","installments":1,"intervalDays":30}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			Description: "Missing Interval",
+			SeasonID:    "BJ7Q4NVRNQ",
+			RequestBody: `{"name":"Three Payments","installments":3}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			Description: "Plan Created",
+			SeasonID:    "BJ7Q4NVRNQ",
+			RequestBody: `{"name":"Three Payments","installments":3,"intervalDays":30}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+				mock.ExpectExec("INSERT INTO installment_plans (.+) VALUES (.+)").WithArgs(sqlmock.AnyArg(), "BJ7Q4NVRN", "Three Payments", 3, 30).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			ExpectedStatusCode: http.StatusCreated,
+			ExpectedContent:    `"status":"successful"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer([]byte(test.RequestBody)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/seasons/:id/installment-plans")
+		c.SetParamNames("id")
+		c.SetParamValues(test.SeasonID)
+		// Perform Request
+		if assert.NoError(t, api.createInstallmentPlan(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestEnrollInstallmentPlan(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	planID := util.ReturnSignedToken("PLAN12345")
+	today := time.Now().Format(time.DateOnly)
+	testCases := []struct {
+		Description        string
+		Account            model.Account
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description: "Another Account's Registration",
+			Account:     model.Account{ID: "ERCXNX5"},
+			RequestBody: fmt.Sprintf(`{"planId":"%s"}`, planID),
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(owingRegistrationRow())
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Plan From Another Season",
+			Account:     model.Account{ID: "123ABC"},
+			RequestBody: fmt.Sprintf(`{"planId":"%s"}`, planID),
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(owingRegistrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM installment_plans WHERE (.+)").WithArgs("BJ7Q4NVRN", "PLAN12345").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"invalid installment plan"`,
+		},
+		{
+			Description: "Registration Paid",
+			Account:     model.Account{ID: "123ABC"},
+			RequestBody: fmt.Sprintf(`{"planId":"%s"}`, planID),
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM installment_plans WHERE (.+)").WithArgs("BJ7Q4NVRN", "PLAN12345").WillReturnRows(sqlmock.NewRows(installmentPlanColumns).AddRow("PLAN12345", "BJ7Q4NVRN", "Three Payments", 3, 30))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+) FOR UPDATE").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusConflict,
+			ExpectedContent:    `"detail":"registration has no balance"`,
+		},
+		{
+			Description: "Already On a Plan",
+			Account:     model.Account{ID: "123ABC"},
+			RequestBody: fmt.Sprintf(`{"planId":"%s"}`, planID),
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(owingRegistrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM installment_plans WHERE (.+)").WithArgs("BJ7Q4NVRN", "PLAN12345").WillReturnRows(sqlmock.NewRows(installmentPlanColumns).AddRow("PLAN12345", "BJ7Q4NVRN", "Three Payments", 3, 30))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+) FOR UPDATE").WithArgs("6KQJ7ZPR2").WillReturnRows(owingRegistrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM registration_installments WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(installmentColumns).AddRow("INST12345", "6KQJ7ZPR2", "PLAN12345", 1, today, nil, nil))
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusConflict,
+			ExpectedContent:    `"detail":"registration is already on an installment plan"`,
+		},
+		{
+			Description: "Registrar Enrolls Registration",
+			Account:     model.Account{ID: "ERCXNX5", Roles: pq.StringArray{"registrar"}},
+			RequestBody: fmt.Sprintf(`{"planId":"%s"}`, planID),
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(owingRegistrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM installment_plans WHERE (.+)").WithArgs("BJ7Q4NVRN", "PLAN12345").WillReturnRows(sqlmock.NewRows(installmentPlanColumns).AddRow("PLAN12345", "BJ7Q4NVRN", "Three Payments", 3, 30))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+) FOR UPDATE").WithArgs("6KQJ7ZPR2").WillReturnRows(owingRegistrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM registration_installments WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(installmentColumns))
+				for number := 1; number <= 3; number++ {
+					dueOn := time.Now().AddDate(0, 0, (number-1)*30).Format(time.DateOnly)
+					mock.ExpectExec("INSERT INTO registration_installments (.+) VALUES (.+)").WithArgs(sqlmock.AnyArg(), "6KQJ7ZPR2", "PLAN12345", number, dueOn).WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+				mock.ExpectCommit()
+			},
+			ExpectedStatusCode: http.StatusCreated,
+			ExpectedContent:    fmt.Sprintf(`"number":1,"dueOn":"%s","amount":10000,"status":"paid"}.+"number":2,.+"amount":10000,"status":"due"`, today),
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer([]byte(test.RequestBody)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, test.Account)
+		c.SetPath("/api/registrations/:id/installments")
+		c.SetParamNames("id")
+		c.SetParamValues(util.ReturnSignedToken("6KQJ7ZPR2"))
+		// Perform Request
+		if assert.NoError(t, api.enrollInstallmentPlan(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestListInstallments(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	overdueAt := time.Now()
+	mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(owingRegistrationRow())
+	mock.ExpectQuery("SELECT (.+) FROM registration_installments WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(
+		sqlmock.NewRows(installmentColumns).
+			AddRow("INST12345", "6KQJ7ZPR2", "PLAN12345", 1, "2024-01-15", nil, nil).
+			AddRow("INST23456", "6KQJ7ZPR2", "PLAN12345", 2, "2024-02-14", overdueAt, overdueAt).
+			AddRow("INST34567", "6KQJ7ZPR2", "", 3, "2999-03-15", nil, nil),
+	)
+	// Initialize Echo
+	e := echo.New()
+	api := API{DB: db}
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	setAccount(c, model.Account{ID: "ERCXNX5", Roles: pq.StringArray{"registrar"}})
+	c.SetPath("/api/registrations/:id/installments")
+	c.SetParamNames("id")
+	c.SetParamValues(util.ReturnSignedToken("6KQJ7ZPR2"))
+	// Perform Request
+	if assert.NoError(t, api.listInstallments(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"number":1,"dueOn":"2024-01-15","amount":10000,"status":"paid"`)
+		assert.Contains(t, rec.Body.String(), `"number":2,"dueOn":"2024-02-14","amount":10000,"status":"overdue","overdueAt"`)
+		assert.Contains(t, rec.Body.String(), `"number":3,"dueOn":"2999-03-15","amount":10000,"status":"due"}`)
+	}
+	// Assert All Expectations Met
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Leagueify/api/internal/auth"
+	"github.com/Leagueify/api/internal/config"
+	"github.com/Leagueify/api/internal/invoices"
+	"github.com/Leagueify/api/internal/mail"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/labstack/echo/v4"
+)
+
+func (api *API) Invoices(e *echo.Group) {
+	e.GET("/registrations/:id/invoice", api.requiresAuth(api.getInvoice))
+	e.POST("/registrations/:id/invoice/email", api.requiresAuth(api.emailInvoice))
+}
+
+// emailInvoice sends the registration's invoice, or receipt once it is paid
+// in full, to the account holder as a PDF attachment. Account holders may
+// send their own and accounts able to view registrations may send any.
+func (api *API) emailInvoice(c echo.Context) error {
+	registration, ok := api.pathRegistration(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	account := getAccount(c)
+	if registration.AccountID != account.ID &&
+		!api.hasPermission(account, auth.PermissionViewRegistrations) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if registration.AccountID == "" {
+		return util.SendStatus(http.StatusConflict, c, "registration has no account holder to email")
+	}
+	invoice, err := api.registrationInvoice(registration)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	email, err := mail.Compose(invoice.Email, "invoice", map[string]any{
+		"Name":     invoice.BilledTo,
+		"Document": strings.ToLower(invoice.Title),
+		"League":   invoice.League,
+		"Season":   invoice.Season,
+		"Balance":  util.FormatAmount(invoice.Balance, invoice.Currency),
+	})
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	email.Attachments = []model.EmailAttachment{{
+		Filename:    invoice.Filename(),
+		ContentType: "application/pdf",
+		Content:     invoices.PDF(invoice),
+	}}
+	if err := api.DB.CreateOutboundEmail(email); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusOK,
+		map[string]string{
+			"status": "successful",
+		},
+	)
+}
+
+// getInvoice returns the registration's invoice, or receipt once it is paid
+// in full, as a PDF or, with format=html, as an HTML page. Account holders
+// may view their own and accounts able to view registrations may view any.
+func (api *API) getInvoice(c echo.Context) error {
+	registration, ok := api.pathRegistration(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	account := getAccount(c)
+	if registration.AccountID != account.ID &&
+		!api.hasPermission(account, auth.PermissionViewRegistrations) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	request := model.InvoiceRequest{}
+	// bind query parameters to model
+	if err := c.Bind(&request); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid query parameters")
+	}
+	// validate query parameters against model
+	if err := c.Validate(request); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	invoice, err := api.registrationInvoice(registration)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if request.Format == "html" {
+		html, err := invoices.HTML(invoice)
+		if err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+		return c.HTMLBlob(http.StatusOK, html)
+	}
+	c.Response().Header().Set(
+		echo.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", invoice.Filename()),
+	)
+	return c.Blob(http.StatusOK, "application/pdf", invoices.PDF(invoice))
+}
+
+// registrationInvoice gathers the league, season, account holder, players,
+// adjustments and payments of a registration and draws up its invoice.
+func (api *API) registrationInvoice(registration model.Registration) (invoices.Invoice, error) {
+	league, err := api.DB.GetLeague()
+	if err != nil {
+		return invoices.Invoice{}, err
+	}
+	// registrations kept from before seasons were tracked have no season,
+	// and those of deleted accounts no account holder, so their invoices
+	// leave those details out
+	season := model.Season{}
+	if registration.SeasonID != "" {
+		season, err = api.DB.GetSeason(util.ReturnSignedToken(registration.SeasonID))
+		if err != nil {
+			return invoices.Invoice{}, err
+		}
+	}
+	account := model.Account{}
+	if registration.AccountID != "" {
+		account, err = api.DB.GetAccount(registration.AccountID)
+		if err != nil {
+			return invoices.Invoice{}, err
+		}
+	}
+	players, err := api.DB.ListRegistrationPlayers(registration.ID)
+	if err != nil {
+		return invoices.Invoice{}, err
+	}
+	adjustments, err := api.DB.ListRegistrationAdjustments(registration.ID)
+	if err != nil {
+		return invoices.Invoice{}, err
+	}
+	payments, err := api.DB.ListPayments(registration.ID)
+	if err != nil {
+		return invoices.Invoice{}, err
+	}
+	return invoices.New(invoices.Records{
+		League:       league.Name,
+		Season:       season,
+		Account:      account,
+		Registration: registration,
+		Players:      players,
+		Adjustments:  adjustments,
+		Payments:     payments,
+	}, config.LoadConfig().PaymentsCurrency, time.Now()), nil
+}
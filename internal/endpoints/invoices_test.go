@@ -0,0 +1,177 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Leagueify/api/internal/database/postgres"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+// expectInvoiceRecords mocks the queries drawing up the invoice of the
+// owing registration.
+func expectInvoiceRecords(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("SELECT (.+) FROM leagues (.+)").WillReturnRows(
+		sqlmock.NewRows([]string{"id", "name", "sport_id"}).AddRow("LEAGUE123", "Leagueify Hockey League", "1"),
+	)
+	mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+	mock.ExpectQuery("SELECT (.+) FROM accounts WHERE id = (.+)").WithArgs("123ABC").WillReturnRows(
+		sqlmock.NewRows(accountColumns).AddRow("123ABC", "Leagueify", "Test", "test@leagueify.org", "$2a$12$hash", "+12085551234", "1990-08-31", pq.StringArray{"DW74MSY5X"}, false, false, true, false, pq.StringArray{}),
+	)
+	mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationPlayerRows())
+	mock.ExpectQuery("SELECT (.+) FROM registration_adjustments WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationAdjustmentColumns))
+	mock.ExpectQuery("SELECT (.+) FROM payment_ledger WHERE registration_id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(paymentRows())
+}
+
+func TestGetInvoice(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description         string
+		Query               string
+		Account             model.Account
+		Mock                func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode  int
+		ExpectedContentType string
+		ExpectedContent     string
+	}{
+		{
+			Description: "Another Account's Registration",
+			Account:     model.Account{ID: "ERCXNX5"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(owingRegistrationRow())
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Invalid Format",
+			Query:       "?format=docx",
+			Account:     model.Account{ID: "123ABC"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(owingRegistrationRow())
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			Description: "Invoice As PDF",
+			Account:     model.Account{ID: "123ABC"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(owingRegistrationRow())
+				expectInvoiceRecords(mock)
+			},
+			ExpectedStatusCode:  http.StatusOK,
+			ExpectedContentType: "application/pdf",
+			ExpectedContent:     `^%PDF-1\.4\n(.|\n)*\(Leagueify Hockey League\) Tj`,
+		},
+		{
+			Description: "Invoice As HTML For Registrar",
+			Query:       "?format=html",
+			Account:     model.Account{ID: "ERCXNX5", Roles: pq.StringArray{"registrar"}},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(owingRegistrationRow())
+				expectInvoiceRecords(mock)
+			},
+			ExpectedStatusCode:  http.StatusOK,
+			ExpectedContentType: echo.MIMETextHTMLCharsetUTF8,
+			ExpectedContent:     `<td>Leagueify Player</td><td>U12 pending</td><td class="amount">100.00 USD</td>`,
+		},
+		{
+			Description: "Invoice For Deleted Account",
+			Query:       "?format=html",
+			Account:     model.Account{ID: "ERCXNX5", Roles: pq.StringArray{"registrar"}},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(
+					sqlmock.NewRows(registrationColumns).AddRow("6KQJ7ZPR2", "", "BJ7Q4NVRN", 30000, 10000, time.Now()),
+				)
+				mock.ExpectQuery("SELECT (.+) FROM leagues (.+)").WillReturnRows(
+					sqlmock.NewRows([]string{"id", "name", "sport_id"}).AddRow("LEAGUE123", "Leagueify Hockey League", "1"),
+				)
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationPlayerRows())
+				mock.ExpectQuery("SELECT (.+) FROM registration_adjustments WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationAdjustmentColumns))
+				mock.ExpectQuery("SELECT (.+) FROM payment_ledger WHERE registration_id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(paymentRows())
+			},
+			ExpectedStatusCode:  http.StatusOK,
+			ExpectedContentType: echo.MIMETextHTMLCharsetUTF8,
+			ExpectedContent:     `Season: 2024-2025\s*</p>`,
+		},
+	}
+
+	for _, test := range testCases {
+		test.Mock(mock)
+		// Initialize Echo
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodGet, "/"+test.Query, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, test.Account)
+		c.SetPath("/api/registrations/:id/invoice")
+		c.SetParamNames("id")
+		c.SetParamValues(util.ReturnSignedToken("6KQJ7ZPR2"))
+		// Perform Request
+		if assert.NoError(t, api.getInvoice(c), test.Description) {
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			if test.ExpectedContentType != "" {
+				assert.Equal(t, test.ExpectedContentType, rec.Header().Get(echo.HeaderContentType), test.Description)
+			}
+			assert.Regexp(t, regexp.MustCompile(test.ExpectedContent), rec.Body.String(), test.Description)
+		}
+	}
+	// Assert All Expectations Met
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEmailInvoice(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(owingRegistrationRow())
+	expectInvoiceRecords(mock)
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO email_outbox (.+) VALUES (.+)").
+		WithArgs(sqlmock.AnyArg(), "test@leagueify.org", "Your Leagueify Hockey League invoice for 2024-2025", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO email_outbox_attachments (.+) VALUES (.+)").
+		WithArgs(sqlmock.AnyArg(), 0, "invoice-"+util.ReturnSignedToken("6KQJ7ZPR2")+".pdf", "application/pdf", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	// Initialize Echo
+	e := echo.New()
+	api := API{DB: db}
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	setAccount(c, model.Account{ID: "123ABC"})
+	c.SetPath("/api/registrations/:id/invoice/email")
+	c.SetParamNames("id")
+	c.SetParamValues(util.ReturnSignedToken("6KQJ7ZPR2"))
+	// Perform Request
+	if assert.NoError(t, api.emailInvoice(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `{"status":"successful"}`+"\n", rec.Body.String())
+	}
+	// Assert All Expectations Met
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -3,13 +3,14 @@ package api
 import (
 	"net/http"
 
+	"github.com/Leagueify/api/internal/auth"
 	"github.com/Leagueify/api/internal/model"
 	"github.com/Leagueify/api/internal/util"
 	"github.com/labstack/echo/v4"
 )
 
 func (api *API) Leagues(e *echo.Group) {
-	e.POST("/leagues", api.requiresAdmin(api.createLeague))
+	e.POST("/leagues", api.requiresPermission(auth.PermissionManageLeague, api.createLeague))
 }
 
 func (api *API) createLeague(c echo.Context) error {
@@ -40,7 +41,7 @@ func (api *API) createLeague(c echo.Context) error {
 
 	// Set league.ID overriding provided ID
 	league.ID = util.SignedToken(6)
-	league.MasterAdmin = api.Account.ID
+	league.MasterAdmin = getAccount(c).ID
 	// Insert league into database
 	if err := api.DB.CreateLeague(league); err != nil {
 		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
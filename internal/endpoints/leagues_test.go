@@ -88,13 +88,12 @@ func TestCreateLeague(t *testing.T) {
 		e := echo.New()
 		e.Validator = &API{Validator: validator.New()}
 		api := API{DB: db}
-		api.Account = model.Account{}
-		api.Account.ID = util.SignedToken(8)
 		reqBody := []byte(test.RequestBody)
 		req := httptest.NewRequest(http.MethodPost, "/api/leagues", bytes.NewBuffer(reqBody))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
+		setAccount(c, model.Account{ID: util.SignedToken(8)})
 		// Perform Request
 		if assert.NoError(t, api.createLeague(c)) {
 			// Assert Status Code
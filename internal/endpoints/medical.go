@@ -0,0 +1,213 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Leagueify/api/internal/auth"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// medicalAccessEventLimit is the number of recent medical access events
+	// listed.
+	medicalAccessEventLimit = 100
+	// medicalRecordContacts and medicalRecordInfo name what was read in the
+	// medical access log.
+	medicalRecordContacts = "emergency_contacts"
+	medicalRecordInfo     = "medical_info"
+)
+
+func (api *API) Medical(e *echo.Group) {
+	e.GET("/players/:id/contacts", api.requiresAuth(api.listEmergencyContacts))
+	e.POST("/players/:id/contacts", api.requiresAuth(api.createEmergencyContact))
+	e.DELETE("/players/:id/contacts/:contact", api.requiresAuth(api.deleteEmergencyContact))
+	e.GET("/players/:id/medical", api.requiresAuth(api.getMedicalInfo))
+	e.PUT("/players/:id/medical", api.requiresAuth(api.updateMedicalInfo))
+	e.GET("/players/:id/medical/access", api.requiresAuth(api.listMedicalAccess))
+}
+
+func (api *API) createEmergencyContact(c echo.Context) error {
+	guardian, ok := api.playerGuardian(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if !auth.GuardianPermission(guardian.Permission).CanUpdate() {
+		return util.SendStatus(http.StatusForbidden, c, "")
+	}
+	contact := model.EmergencyContact{}
+	// bind payload to model
+	if err := c.Bind(&contact); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(contact); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	contact.ID = util.SignedToken(10)
+	contact.PlayerID = guardian.PlayerID
+	if err := api.DB.CreateEmergencyContact(contact); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusCreated,
+		map[string]string{
+			"status": "successful",
+		},
+	)
+}
+
+func (api *API) deleteEmergencyContact(c echo.Context) error {
+	guardian, ok := api.playerGuardian(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if !auth.GuardianPermission(guardian.Permission).CanUpdate() {
+		return util.SendStatus(http.StatusForbidden, c, "")
+	}
+	contactID := c.Param("contact")
+	if !util.VerifyToken(contactID) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if err := api.DB.DeleteEmergencyContact(
+		guardian.PlayerID, contactID[:len(contactID)-1],
+	); err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// getMedicalInfo decrypts the player's medical information. Players without
+// any recorded return empty fields.
+func (api *API) getMedicalInfo(c echo.Context) error {
+	if api.Cipher == nil {
+		return util.SendStatus(http.StatusServiceUnavailable, c, "medical information is not configured")
+	}
+	playerID, ok := api.medicalReader(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if err := api.logMedicalAccess(c, playerID, medicalRecordInfo); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	info := model.MedicalInfo{}
+	record, err := api.DB.GetMedicalRecord(playerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.JSON(http.StatusOK, info)
+	}
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	data, err := api.Cipher.Decrypt(record.Data, []byte(playerID))
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusOK, info)
+}
+
+func (api *API) listEmergencyContacts(c echo.Context) error {
+	playerID, ok := api.medicalReader(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if err := api.logMedicalAccess(c, playerID, medicalRecordContacts); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	contacts, err := api.DB.ListEmergencyContacts(playerID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusOK, contacts)
+}
+
+// listMedicalAccess shows primary guardians who has read the player's
+// emergency contacts and medical information.
+func (api *API) listMedicalAccess(c echo.Context) error {
+	guardian, ok := api.playerGuardian(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if !auth.GuardianPermission(guardian.Permission).CanManage() {
+		return util.SendStatus(http.StatusForbidden, c, "")
+	}
+	events, err := api.DB.ListMedicalAccessEvents(guardian.PlayerID, medicalAccessEventLimit)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusOK, events)
+}
+
+// updateMedicalInfo replaces the player's medical information, encrypting it
+// before it is stored.
+func (api *API) updateMedicalInfo(c echo.Context) error {
+	if api.Cipher == nil {
+		return util.SendStatus(http.StatusServiceUnavailable, c, "medical information is not configured")
+	}
+	guardian, ok := api.playerGuardian(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if !auth.GuardianPermission(guardian.Permission).CanUpdate() {
+		return util.SendStatus(http.StatusForbidden, c, "")
+	}
+	info := model.MedicalInfo{}
+	// bind payload to model
+	if err := c.Bind(&info); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(info); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if err := api.DB.SetMedicalRecord(model.MedicalRecord{
+		PlayerID:  guardian.PlayerID,
+		Data:      api.Cipher.Encrypt(data, []byte(guardian.PlayerID)),
+		UpdatedBy: getAccount(c).ID,
+	}); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusOK, info)
+}
+
+// logMedicalAccess records that the current account read the player's
+// record. Reads are only answered once they have been logged.
+func (api *API) logMedicalAccess(c echo.Context, playerID, record string) error {
+	return api.DB.CreateMedicalAccessEvent(model.MedicalAccessEvent{
+		PlayerID:  playerID,
+		AccountID: getAccount(c).ID,
+		Record:    record,
+		IPAddress: c.RealIP(),
+	})
+}
+
+// medicalReader returns the player in the id path parameter when the current
+// account may read its emergency contacts and medical information. Guardians
+// of the player and coaches of the player's team may read them.
+func (api *API) medicalReader(c echo.Context) (string, bool) {
+	if guardian, ok := api.playerGuardian(c); ok {
+		return guardian.PlayerID, true
+	}
+	playerID := c.Param("id")
+	if !util.VerifyToken(playerID) {
+		return "", false
+	}
+	player, err := api.DB.GetPlayer(playerID[:len(playerID)-1])
+	if err != nil || player.Team == "" {
+		return "", false
+	}
+	isCoach, err := api.DB.IsTeamCoach(getAccount(c).ID, player.Team)
+	if err != nil || !isCoach {
+		return "", false
+	}
+	return player.ID, true
+}
@@ -0,0 +1,290 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Leagueify/api/internal/auth"
+	"github.com/Leagueify/api/internal/database/postgres"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// ciphertextArg matches stored medical data that does not reveal plaintext.
+type ciphertextArg struct {
+	plaintext string
+}
+
+func (a ciphertextArg) Match(v driver.Value) bool {
+	data, ok := v.(string)
+	return ok && data != "" && !strings.Contains(data, a.plaintext)
+}
+
+func testCipher(t *testing.T) *auth.Cipher {
+	cipher, err := auth.NewCipher(bytes.Repeat([]byte("k"), auth.EncryptionKeySize))
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating cipher", err)
+	}
+	return cipher
+}
+
+func TestGetMedicalInfo(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	cipher := testCipher(t)
+	record := cipher.Encrypt([]byte(`{"allergies":"peanuts","medications":"inhaler","conditions":"","notes":""}`), []byte("49QRBF09Y"))
+	recordColumns := []string{"player_id", "data", "updated_by", "updated_at"}
+	testCases := []struct {
+		Description        string
+		Cipher             *auth.Cipher
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description:        "Encryption Not Configured",
+			ExpectedStatusCode: http.StatusServiceUnavailable,
+		},
+		{
+			Description: "Not Guardian Or Coach",
+			Cipher:      cipher,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("COACH12", "49QRBF09Y").WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery("SELECT (.+) FROM players WHERE id = (.+)").WithArgs("49QRBF09Y").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("49QRBF09Y", "Leagueify", "Test", "2016-12-10", "goalie", "", "", nil, "", "", "Sharks", "", true))
+				mock.ExpectQuery("SELECT EXISTS (.+) FROM team_coaches (.+)").WithArgs("Sharks", "COACH12").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Team Coach Reads Record",
+			Cipher:      cipher,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery("SELECT (.+) FROM players WHERE id = (.+)").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("49QRBF09Y", "Leagueify", "Test", "2016-12-10", "goalie", "", "", nil, "", "", "Sharks", "", true))
+				mock.ExpectQuery("SELECT EXISTS (.+) FROM team_coaches (.+)").WithArgs("Sharks", "COACH12").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectExec("INSERT INTO medical_access_events (.+) VALUES (.+)").WithArgs("49QRBF09Y", "COACH12", "medical_info", "192.0.2.1").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("SELECT (.+) FROM medical_records WHERE player_id = (.+)").WithArgs("49QRBF09Y").WillReturnRows(sqlmock.NewRows(recordColumns).AddRow("49QRBF09Y", record, "ERCXNX5", time.Now()))
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"allergies":"peanuts","medications":"inhaler"`,
+		},
+		{
+			Description: "Access Not Logged",
+			Cipher:      cipher,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("COACH12", "49QRBF09Y", "view", time.Now()))
+				mock.ExpectExec("INSERT INTO medical_access_events (.+) VALUES (.+)").WillReturnError(sql.ErrConnDone)
+			},
+			ExpectedStatusCode: http.StatusInternalServerError,
+		},
+		{
+			Description: "Guardian Reads Missing Record",
+			Cipher:      cipher,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("COACH12", "49QRBF09Y", "view", time.Now()))
+				mock.ExpectExec("INSERT INTO medical_access_events (.+) VALUES (.+)").WithArgs("49QRBF09Y", "COACH12", "medical_info", "192.0.2.1").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("SELECT (.+) FROM medical_records WHERE player_id = (.+)").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"allergies":""`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db, Cipher: test.Cipher}
+		req := httptest.NewRequest(http.MethodGet, "/api/players/49QRBF09YA/medical", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, model.Account{ID: "COACH12"})
+		c.SetParamNames("id")
+		c.SetParamValues("49QRBF09YA")
+		// Perform Request
+		if assert.NoError(t, api.getMedicalInfo(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Assert Response Content
+			assert.Regexp(t, regexp.MustCompile(test.ExpectedContent), rec.Body.String(), test.Description)
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet(), test.Description)
+	}
+}
+
+func TestUpdateMedicalInfo(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description: "View Only Guardian",
+			RequestBody: `{"allergies":"peanuts"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("ERCXNX5", "49QRBF09Y").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("ERCXNX5", "49QRBF09Y", "view", time.Now()))
+			},
+			ExpectedStatusCode: http.StatusForbidden,
+		},
+		{
+			Description: "Notes Too Long",
+			RequestBody: `{"notes":"` + strings.Repeat("a", 1025) + `"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("ERCXNX5", "49QRBF09Y", "secondary", time.Now()))
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			Description: "Record Stored Encrypted",
+			RequestBody: `{"allergies":"peanuts","medications":"inhaler"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("ERCXNX5", "49QRBF09Y", "primary", time.Now()))
+				mock.ExpectExec("INSERT INTO medical_records (.+) VALUES (.+) ON CONFLICT (.+)").WithArgs("49QRBF09Y", ciphertextArg{plaintext: "peanuts"}, "ERCXNX5").WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"allergies":"peanuts","medications":"inhaler"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db, Cipher: testCipher(t)}
+		req := httptest.NewRequest(http.MethodPut, "/api/players/49QRBF09YA/medical", bytes.NewBufferString(test.RequestBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, model.Account{ID: "ERCXNX5"})
+		c.SetParamNames("id")
+		c.SetParamValues("49QRBF09YA")
+		// Perform Request
+		if assert.NoError(t, api.updateMedicalInfo(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Assert Response Content
+			assert.Regexp(t, regexp.MustCompile(test.ExpectedContent), rec.Body.String(), test.Description)
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet(), test.Description)
+	}
+}
+
+func TestEmergencyContacts(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		Method             string
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description: "Create Invalid Phone",
+			Method:      http.MethodPost,
+			RequestBody: `{"name":"Grandma Test","relationship":"grandmother","phone":"555-1234"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("ERCXNX5", "49QRBF09Y", "primary", time.Now()))
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			Description: "Create Contact",
+			Method:      http.MethodPost,
+			RequestBody: `{"name":"Grandma Test","relationship":"grandmother","phone":"+12085551234"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("ERCXNX5", "49QRBF09Y", "secondary", time.Now()))
+				mock.ExpectExec("INSERT INTO emergency_contacts (.+) VALUES (.+)").WithArgs(sqlmock.AnyArg(), "49QRBF09Y", "Grandma Test", "grandmother", "+12085551234", "").WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			ExpectedStatusCode: http.StatusCreated,
+			ExpectedContent:    `"status":"successful"`,
+		},
+		{
+			Description: "List Contacts",
+			Method:      http.MethodGet,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("ERCXNX5", "49QRBF09Y", "view", time.Now()))
+				mock.ExpectExec("INSERT INTO medical_access_events (.+) VALUES (.+)").WithArgs("49QRBF09Y", "ERCXNX5", "emergency_contacts", "192.0.2.1").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("SELECT (.+) FROM emergency_contacts WHERE player_id = (.+)").WithArgs("49QRBF09Y").WillReturnRows(sqlmock.NewRows([]string{"id", "player_id", "name", "relationship", "phone", "email", "created_at"}).AddRow("QP4RD39CE", "49QRBF09Y", "Grandma Test", "grandmother", "+12085551234", "", time.Now()))
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `\[{"id":"QP4RD39CEF","name":"Grandma Test"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(test.Method, "/api/players/49QRBF09YA/contacts", bytes.NewBufferString(test.RequestBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.RemoteAddr = "192.0.2.1:1234"
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, model.Account{ID: "ERCXNX5"})
+		c.SetParamNames("id")
+		c.SetParamValues("49QRBF09YA")
+		// Perform Request
+		handler := api.listEmergencyContacts
+		if test.Method == http.MethodPost {
+			handler = api.createEmergencyContact
+		}
+		if assert.NoError(t, handler(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Assert Response Content
+			assert.Regexp(t, regexp.MustCompile(test.ExpectedContent), rec.Body.String(), test.Description)
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet(), test.Description)
+	}
+}
@@ -0,0 +1,272 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/Leagueify/api/internal/auth"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/oidc"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/labstack/echo/v4"
+)
+
+func (api *API) OIDC(e *echo.Group) {
+	e.GET("/accounts/oidc", api.listOIDCProviders)
+	e.POST("/accounts/oidc/signup", api.completeOIDCSignup)
+	e.POST("/accounts/oidc/:provider/authorize", api.authorizeOIDC)
+	e.POST("/accounts/oidc/:provider/callback", api.oidcCallback)
+}
+
+// authorizeOIDC starts signing in with an identity provider. The state,
+// nonce and PKCE verifier are kept server side and the state is returned so
+// the frontend can match the provider's redirect to this request.
+func (api *API) authorizeOIDC(c echo.Context) error {
+	name := c.Param("provider")
+	provider, ok := api.Providers[name]
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	payload := model.OIDCAuthorization{}
+	if err := c.Bind(&payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	state := util.SecretToken()
+	nonce := util.SecretToken()
+	verifier := oidc.NewCodeVerifier()
+	url, err := provider.AuthCodeURL(
+		c.Request().Context(), state, nonce, oidc.CodeChallenge(verifier),
+	)
+	if err != nil {
+		return util.SendStatus(http.StatusBadGateway, c, "identity provider unavailable")
+	}
+	if err := api.DB.CreateOIDCState(model.OIDCState{
+		StateHash:    auth.HashToken(state),
+		Provider:     name,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		Label:        payload.Label,
+		ExpiresAt:    time.Now().Add(auth.OIDCStateTTL),
+	}); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusOK,
+		map[string]string{
+			"url":   url,
+			"state": state,
+		},
+	)
+}
+
+// completeOIDCSignup creates the account for a new account holder once they
+// have confirmed the details the identity provider could not supply.
+func (api *API) completeOIDCSignup(c echo.Context) error {
+	payload := model.OIDCSignupCompletion{}
+	// bind payload to model
+	if err := c.Bind(&payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	if !util.VerifySecret(payload.Token) {
+		return util.SendStatus(http.StatusUnauthorized, c, "")
+	}
+	// Account holders must be adults
+	if detail := checkAccountAge(payload.DateOfBirth); detail != "" {
+		return util.SendStatus(http.StatusBadRequest, c, detail)
+	}
+	// Begin Transaction
+	tx, err := api.DB.BeginTransaction()
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	defer tx.Rollback()
+	signup, err := api.DB.ConsumeOIDCSignup(tx, auth.HashToken(payload.Token))
+	if err != nil {
+		return util.SendStatus(http.StatusUnauthorized, c, "")
+	}
+	account := model.AccountCreation{
+		ID:          util.SignedToken(8),
+		FirstName:   payload.FirstName,
+		LastName:    payload.LastName,
+		Email:       signup.Email,
+		Phone:       payload.Phone,
+		DateOfBirth: payload.DateOfBirth,
+		Coach:       payload.Coach,
+		Volunteer:   payload.Volunteer,
+		IsActive:    signup.EmailVerified,
+	}
+	totalAccounts, err := api.DB.GetTotalAccounts()
+	if err != nil {
+		return util.SendStatus(http.StatusBadGateway, c, util.HandleError(err))
+	}
+	if totalAccounts < 1 {
+		account.IsAdmin = true
+	}
+	emailConfig, err := api.DB.GetTotalEmailConfigs()
+	if err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	if emailConfig == 0 {
+		account.IsActive = true
+	}
+	if err := api.DB.CreateOIDCAccount(tx, account, model.OIDCIdentity{
+		Provider: signup.Provider,
+		Subject:  signup.Subject,
+		Email:    signup.Email,
+	}); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	if err := tx.Commit(); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	// Addresses the provider has not verified are verified by email first
+	if !account.IsActive {
+		if err := api.sendVerification(account.ID, account.FirstName, account.Email); err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+		return c.JSON(http.StatusCreated,
+			map[string]string{
+				"status": "successful",
+			},
+		)
+	}
+	return api.oidcLogin(c, account.ID[:len(account.ID)-1], signup.Label)
+}
+
+func (api *API) listOIDCProviders(c echo.Context) error {
+	providers := make([]string, 0, len(api.Providers))
+	for name := range api.Providers {
+		providers = append(providers, name)
+	}
+	sort.Strings(providers)
+	return c.JSON(http.StatusOK, map[string][]string{"providers": providers})
+}
+
+// oidcCallback completes signing in with an identity provider. Identities
+// already linked to an account log in, a verified email address links the
+// identity to the existing account it belongs to, and anyone else is asked
+// to confirm their details through completeOIDCSignup.
+func (api *API) oidcCallback(c echo.Context) error {
+	name := c.Param("provider")
+	provider, ok := api.Providers[name]
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	payload := model.OIDCCallback{}
+	// bind payload to model
+	if err := c.Bind(&payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	if !util.VerifySecret(payload.State) {
+		return util.SendStatus(http.StatusUnauthorized, c, "")
+	}
+	state, err := api.DB.ConsumeOIDCState(auth.HashToken(payload.State), name)
+	if err != nil {
+		return util.SendStatus(http.StatusUnauthorized, c, "")
+	}
+	identity, err := provider.Exchange(
+		c.Request().Context(), payload.Code, state.CodeVerifier, state.Nonce,
+	)
+	if err != nil {
+		return util.SendStatus(http.StatusUnauthorized, c, "")
+	}
+	// Returning account holders
+	linked, err := api.DB.GetOIDCIdentity(name, identity.Subject)
+	if err == nil {
+		return api.oidcLogin(c, linked.AccountID, state.Label)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if identity.Email == "" {
+		return util.SendStatus(http.StatusBadRequest, c, "identity provider did not share an email address")
+	}
+	// Existing accounts are only linked when the provider vouches for the address
+	account, err := api.DB.GetAccountByEmail(identity.Email)
+	if err == nil {
+		if !identity.EmailVerified {
+			return util.SendStatus(http.StatusConflict, c, "an account already exists for this email address")
+		}
+		if err := api.DB.CreateOIDCIdentity(model.OIDCIdentity{
+			Provider:  name,
+			Subject:   identity.Subject,
+			AccountID: account.ID,
+			Email:     identity.Email,
+		}); err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+		return api.oidcLogin(c, account.ID, state.Label)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	// New account holders confirm their details before the account is created
+	token := util.SecretToken()
+	signup := model.OIDCSignup{
+		TokenHash:     auth.HashToken(token),
+		Provider:      name,
+		Subject:       identity.Subject,
+		Email:         identity.Email,
+		EmailVerified: identity.EmailVerified,
+		FirstName:     identity.GivenName,
+		LastName:      identity.FamilyName,
+		Phone:         identity.PhoneNumber,
+		DateOfBirth:   identity.Birthdate,
+		Label:         state.Label,
+		ExpiresAt:     time.Now().Add(auth.OIDCSignupTTL),
+	}
+	if err := api.DB.CreateOIDCSignup(signup); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusAccepted, model.OIDCSignupDetails{
+		Status:      "account details required",
+		Token:       token,
+		FirstName:   signup.FirstName,
+		LastName:    signup.LastName,
+		Email:       signup.Email,
+		Phone:       signup.Phone,
+		DateOfBirth: signup.DateOfBirth,
+	})
+}
+
+// oidcLogin logs in an account holder the identity provider has vouched for.
+// Deactivated accounts are refused, locked accounts stay locked and two-factor
+// authentication still applies.
+func (api *API) oidcLogin(c echo.Context, accountID, label string) error {
+	account, err := api.DB.GetAccount(accountID)
+	if err != nil || !account.IsActive {
+		return util.SendStatus(http.StatusUnauthorized, c, "")
+	}
+	attempt := model.LoginAttempt{
+		AccountID: accountID,
+		IPAddress: c.RealIP(),
+	}
+	lockout, err := api.DB.GetLoginLockout(accountID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if wait := time.Until(lockout.LockedUntil); wait > 0 {
+		if err := api.DB.RecordLoginAttempt(attempt); err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+		return tooManyLoginAttempts(c, wait)
+	}
+	twoFactor, err := api.DB.GetTwoFactor(accountID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if twoFactor.Enabled {
+		return api.challengeLogin(c, accountID, label)
+	}
+	return api.completeLogin(c, attempt, lockout.FailedAttempts, label)
+}
@@ -0,0 +1,383 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Leagueify/api/internal/database/postgres"
+	"github.com/Leagueify/api/internal/oidc"
+	"github.com/Leagueify/api/internal/oidc/oidctest"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	oidcStateColumns  = []string{"state_hash", "provider", "nonce", "code_verifier", "label", "expires_at"}
+	oidcSignupColumns = []string{"token_hash", "provider", "subject", "email", "email_verified", "first_name", "last_name", "phone", "date_of_birth", "label", "expires_at"}
+)
+
+func TestAuthorizeOIDC(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	// Start Identity Provider
+	server := oidctest.NewServer("leagueify", "secret", oidc.Identity{Subject: "subject"})
+	defer server.Close()
+	client := oidc.NewClient(server.URL, "leagueify", "secret", "http://localhost/oidc/test/callback")
+	testCases := []struct {
+		Description        string
+		Provider           string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description:        "Unknown Provider",
+			Provider:           "unknown",
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Authorization Started",
+			Provider:    "test",
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("INSERT INTO oidc_states (.+) VALUES (.+)").WithArgs(sqlmock.AnyArg(), "test", sqlmock.AnyArg(), sqlmock.AnyArg(), "laptop", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"url":"http://127.0.0.1:\d+/authorize\?client_id=leagueify\\u0026code_challenge=[\w-]{43}\\u0026code_challenge_method=S256`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db, Providers: map[string]oidc.Provider{"test": client}}
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"label":"laptop"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/accounts/oidc/:provider/authorize")
+		c.SetParamNames("provider")
+		c.SetParamValues(test.Provider)
+		// Perform Request
+		if assert.NoError(t, api.authorizeOIDC(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Assert Response Content
+			assert.Regexp(t, regexp.MustCompile(test.ExpectedContent), rec.Body.String(), test.Description)
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestOIDCCallback(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	// Start Identity Provider
+	server := oidctest.NewServer("leagueify", "secret", oidc.Identity{})
+	defer server.Close()
+	client := oidc.NewClient(server.URL, "leagueify", "secret", "http://localhost/oidc/test/callback")
+	state := util.SecretToken()
+	nonce := util.SecretToken()
+	verifier := oidc.NewCodeVerifier()
+	accountRow := []driver.Value{"ERCXNX5", "Leagueify", "Test", "test@leagueify.org", "", "+12085551234", "1990-08-31", pq.StringArray{}, false, false, true, false}
+	testCases := []struct {
+		Description        string
+		State              string
+		Identity           oidc.Identity
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description:        "Malformed State",
+			State:              "state",
+			ExpectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			Description: "Unknown State",
+			State:       state,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("DELETE FROM oidc_states WHERE (.+) RETURNING (.+)").WithArgs(sqlmock.AnyArg(), "test").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			Description: "Nonce Mismatch",
+			State:       state,
+			Identity:    oidc.Identity{Subject: "subject"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("DELETE FROM oidc_states WHERE (.+) RETURNING (.+)").WillReturnRows(sqlmock.NewRows(oidcStateColumns).AddRow("hash", "test", "other", verifier, "", time.Now().Add(time.Minute)))
+			},
+			ExpectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			Description: "Linked Identity Logs In",
+			State:       state,
+			Identity:    oidc.Identity{Subject: "subject"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("DELETE FROM oidc_states WHERE (.+) RETURNING (.+)").WillReturnRows(sqlmock.NewRows(oidcStateColumns).AddRow("hash", "test", nonce, verifier, "laptop", time.Now().Add(time.Minute)))
+				mock.ExpectQuery("SELECT (.+) FROM oidc_identities WHERE (.+)").WithArgs("test", "subject").WillReturnRows(sqlmock.NewRows([]string{"provider", "subject", "account_id", "email"}).AddRow("test", "subject", "ERCXNX5", ""))
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE id = (.+)").WithArgs("ERCXNX5").WillReturnRows(sqlmock.NewRows(accountColumns).AddRow(append(accountRow, pq.StringArray{})...))
+				mock.ExpectQuery("SELECT (.+) FROM account_lockouts WHERE account_id = (.+)").WithArgs("ERCXNX5").WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery("SELECT (.+) FROM two_factor WHERE (.+)").WithArgs("ERCXNX5").WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec("INSERT INTO login_attempts (.+) VALUES (.+)").WithArgs("ERCXNX5", "", "192.0.2.1", true).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO sessions (.+) VALUES (.+)").WithArgs(sqlmock.AnyArg(), "ERCXNX5", sqlmock.AnyArg(), "laptop", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"apikey":"lfy_`,
+		},
+		{
+			Description: "Linked Identity With Two-Factor",
+			State:       state,
+			Identity:    oidc.Identity{Subject: "subject"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("DELETE FROM oidc_states WHERE (.+) RETURNING (.+)").WillReturnRows(sqlmock.NewRows(oidcStateColumns).AddRow("hash", "test", nonce, verifier, "", time.Now().Add(time.Minute)))
+				mock.ExpectQuery("SELECT (.+) FROM oidc_identities WHERE (.+)").WillReturnRows(sqlmock.NewRows([]string{"provider", "subject", "account_id", "email"}).AddRow("test", "subject", "ERCXNX5", ""))
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE id = (.+)").WithArgs("ERCXNX5").WillReturnRows(sqlmock.NewRows(accountColumns).AddRow(append(accountRow, pq.StringArray{})...))
+				mock.ExpectQuery("SELECT (.+) FROM account_lockouts WHERE account_id = (.+)").WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery("SELECT (.+) FROM two_factor WHERE (.+)").WillReturnRows(sqlmock.NewRows(twoFactorColumns).AddRow("ERCXNX5", "secret", true, 0, 10))
+				mock.ExpectExec("INSERT INTO login_challenges (.+) VALUES (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			ExpectedStatusCode: http.StatusAccepted,
+			ExpectedContent:    `"challenge":"lfy_`,
+		},
+		{
+			Description: "Linked Identity Of Deactivated Account",
+			State:       state,
+			Identity:    oidc.Identity{Subject: "subject"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("DELETE FROM oidc_states WHERE (.+) RETURNING (.+)").WillReturnRows(sqlmock.NewRows(oidcStateColumns).AddRow("hash", "test", nonce, verifier, "", time.Now().Add(time.Minute)))
+				mock.ExpectQuery("SELECT (.+) FROM oidc_identities WHERE (.+)").WillReturnRows(sqlmock.NewRows([]string{"provider", "subject", "account_id", "email"}).AddRow("test", "subject", "ERCXNX5", ""))
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE id = (.+)").WithArgs("ERCXNX5").WillReturnRows(sqlmock.NewRows(accountColumns).AddRow("ERCXNX5", "Leagueify", "Test", "test@leagueify.org", "", "+12085551234", "1990-08-31", pq.StringArray{}, false, false, false, false, pq.StringArray{}))
+			},
+			ExpectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			Description: "Missing Email Address",
+			State:       state,
+			Identity:    oidc.Identity{Subject: "subject"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("DELETE FROM oidc_states WHERE (.+) RETURNING (.+)").WillReturnRows(sqlmock.NewRows(oidcStateColumns).AddRow("hash", "test", nonce, verifier, "", time.Now().Add(time.Minute)))
+				mock.ExpectQuery("SELECT (.+) FROM oidc_identities WHERE (.+)").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"identity provider did not share an email address"`,
+		},
+		{
+			Description: "Unverified Email Of Existing Account",
+			State:       state,
+			Identity:    oidc.Identity{Subject: "subject", Email: "test@leagueify.org"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("DELETE FROM oidc_states WHERE (.+) RETURNING (.+)").WillReturnRows(sqlmock.NewRows(oidcStateColumns).AddRow("hash", "test", nonce, verifier, "", time.Now().Add(time.Minute)))
+				mock.ExpectQuery("SELECT (.+) FROM oidc_identities WHERE (.+)").WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE email = (.+)$").WithArgs("test@leagueify.org").WillReturnRows(sqlmock.NewRows(accountColumns[:12]).AddRow(accountRow...))
+			},
+			ExpectedStatusCode: http.StatusConflict,
+		},
+		{
+			Description: "Verified Email Links Existing Account",
+			State:       state,
+			Identity:    oidc.Identity{Subject: "subject", Email: "test@leagueify.org", EmailVerified: true},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("DELETE FROM oidc_states WHERE (.+) RETURNING (.+)").WillReturnRows(sqlmock.NewRows(oidcStateColumns).AddRow("hash", "test", nonce, verifier, "", time.Now().Add(time.Minute)))
+				mock.ExpectQuery("SELECT (.+) FROM oidc_identities WHERE (.+)").WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE email = (.+)$").WillReturnRows(sqlmock.NewRows(accountColumns[:12]).AddRow(accountRow...))
+				mock.ExpectExec("INSERT INTO oidc_identities (.+) VALUES (.+)").WithArgs("test", "subject", "ERCXNX5", "test@leagueify.org").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE id = (.+)").WithArgs("ERCXNX5").WillReturnRows(sqlmock.NewRows(accountColumns).AddRow(append(accountRow, pq.StringArray{})...))
+				mock.ExpectQuery("SELECT (.+) FROM account_lockouts WHERE account_id = (.+)").WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery("SELECT (.+) FROM two_factor WHERE (.+)").WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec("INSERT INTO login_attempts (.+) VALUES (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO sessions (.+) VALUES (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"apikey":"lfy_`,
+		},
+		{
+			Description: "New Account Holder Confirms Details",
+			State:       state,
+			Identity:    oidc.Identity{Subject: "subject", Email: "new@leagueify.org", EmailVerified: true, GivenName: "Leagueify", FamilyName: "Test"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("DELETE FROM oidc_states WHERE (.+) RETURNING (.+)").WillReturnRows(sqlmock.NewRows(oidcStateColumns).AddRow("hash", "test", nonce, verifier, "laptop", time.Now().Add(time.Minute)))
+				mock.ExpectQuery("SELECT (.+) FROM oidc_identities WHERE (.+)").WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE email = (.+)$").WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec("INSERT INTO oidc_signups (.+) VALUES (.+)").WithArgs(sqlmock.AnyArg(), "test", "subject", "new@leagueify.org", true, "Leagueify", "Test", "", "", "laptop", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			ExpectedStatusCode: http.StatusAccepted,
+			ExpectedContent:    `"status":"account details required","token":"lfy_[^"]+","firstName":"Leagueify","lastName":"Test","email":"new@leagueify.org"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Sign in at the identity provider
+		server.SetIdentity(test.Identity)
+		authURL, err := client.AuthCodeURL(context.Background(), state, nonce, oidc.CodeChallenge(verifier))
+		if err != nil {
+			t.Fatalf("ERROR: '%s' was not expected when building authorization URL", err)
+		}
+		code, _, err := server.Authorize(authURL)
+		if err != nil {
+			t.Fatalf("ERROR: '%s' was not expected when authorizing", err)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db, Providers: map[string]oidc.Provider{"test": client}}
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"code":"`+code+`","state":"`+test.State+`"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.RemoteAddr = "192.0.2.1:1234"
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/accounts/oidc/:provider/callback")
+		c.SetParamNames("provider")
+		c.SetParamValues("test")
+		// Perform Request
+		if assert.NoError(t, api.oidcCallback(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Assert Response Content
+			assert.Regexp(t, regexp.MustCompile(test.ExpectedContent), rec.Body.String(), test.Description)
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet(), test.Description)
+	}
+}
+
+func TestCompleteOIDCSignup(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	token := util.SecretToken()
+	details := `"firstName":"Leagueify","lastName":"Test","phone":"+12085551234","dateOfBirth":"1990-08-31"`
+	testCases := []struct {
+		Description        string
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description:        "Missing Phone",
+			RequestBody:        `{"token":"` + token + `","firstName":"Leagueify","lastName":"Test","dateOfBirth":"1990-08-31"}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"missing required field\(s\): \[Phone\]"`,
+		},
+		{
+			Description:        "Under 18",
+			RequestBody:        `{"token":"` + token + `","firstName":"Leagueify","lastName":"Test","phone":"+12085551234","dateOfBirth":"` + time.Now().AddDate(-17, 0, 0).Format(time.DateOnly) + `"}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"must be 18 or older to create an account"`,
+		},
+		{
+			Description: "Unknown Token",
+			RequestBody: `{"token":"` + token + `",` + details + `}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("DELETE FROM oidc_signups WHERE (.+) RETURNING (.+)").WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			Description: "Verified Email Logs In",
+			RequestBody: `{"token":"` + token + `",` + details + `}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("DELETE FROM oidc_signups WHERE (.+) RETURNING (.+)").WillReturnRows(sqlmock.NewRows(oidcSignupColumns).AddRow("hash", "test", "subject", "new@leagueify.org", true, "", "", "", "", "laptop", time.Now().Add(time.Minute)))
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM accounts").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM email").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectExec("INSERT INTO accounts (.+) VALUES (.+)$").WithArgs(sqlmock.AnyArg(), "Leagueify", "Test", "new@leagueify.org", "", "+12085551234", "1990-08-31", false, false, true, false).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO oidc_identities (.+) VALUES (.+)").WithArgs("test", "subject", sqlmock.AnyArg(), "new@leagueify.org").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE id = (.+)").WillReturnRows(sqlmock.NewRows(accountColumns).AddRow("RMQ4XBV", "Leagueify", "Test", "new@leagueify.org", "", "+12085551234", "1990-08-31", pq.StringArray{}, false, false, true, false, pq.StringArray{}))
+				mock.ExpectQuery("SELECT (.+) FROM account_lockouts WHERE account_id = (.+)").WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery("SELECT (.+) FROM two_factor WHERE (.+)").WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec("INSERT INTO login_attempts (.+) VALUES (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO sessions (.+) VALUES (.+)").WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "laptop", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"apikey":"lfy_`,
+		},
+		{
+			Description: "Unverified Email Sends Verification",
+			RequestBody: `{"token":"` + token + `",` + details + `}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("DELETE FROM oidc_signups WHERE (.+) RETURNING (.+)").WillReturnRows(sqlmock.NewRows(oidcSignupColumns).AddRow("hash", "test", "subject", "new@leagueify.org", false, "", "", "", "", "", time.Now().Add(time.Minute)))
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM accounts").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM email").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectExec("INSERT INTO accounts (.+) VALUES (.+)$").WithArgs(sqlmock.AnyArg(), "Leagueify", "Test", "new@leagueify.org", "", "+12085551234", "1990-08-31", false, false, false, false).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO oidc_identities (.+) VALUES (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE account_verifications SET used_at = now\\(\\) WHERE account_id = (.+) AND used_at IS NULL").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO account_verifications (.+) VALUES (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+				mock.ExpectExec("INSERT INTO email_outbox (.+) VALUES (.+)").WithArgs(sqlmock.AnyArg(), "new@leagueify.org", "Verify your Leagueify account", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			ExpectedStatusCode: http.StatusCreated,
+			ExpectedContent:    `"status":"successful"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodPost, "/api/accounts/oidc/signup", bytes.NewBufferString(test.RequestBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.RemoteAddr = "192.0.2.1:1234"
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		// Perform Request
+		if assert.NoError(t, api.completeOIDCSignup(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Assert Response Content
+			assert.Regexp(t, regexp.MustCompile(test.ExpectedContent), rec.Body.String(), test.Description)
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet(), test.Description)
+	}
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Leagueify/api/internal/auth"
+	"github.com/Leagueify/api/internal/config"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/labstack/echo/v4"
+)
+
+func (api *API) Passwords(e *echo.Group) {
+	e.POST("/accounts/password/forgot", api.forgotPassword)
+	e.POST("/accounts/password/reset", api.resetPassword)
+	e.POST("/accounts/:id/password/reset", api.requiresPermission(auth.PermissionManageAccounts, api.issuePasswordReset))
+}
+
+func (api *API) forgotPassword(c echo.Context) error {
+	payload := model.PasswordForgot{}
+	// bind payload to model
+	if err := c.Bind(&payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	// without email an administrator must issue the reset token
+	if _, err := api.DB.GetEmailConfig(); errors.Is(err, sql.ErrNoRows) {
+		return util.SendStatus(
+			http.StatusServiceUnavailable, c,
+			"email is not configured, contact a league administrator",
+		)
+	} else if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	// unknown emails receive the same response to avoid account enumeration
+	account, err := api.DB.GetAccountByEmail(payload.Email)
+	if err != nil {
+		return c.JSON(http.StatusOK,
+			map[string]string{
+				"status": "successful",
+			},
+		)
+	}
+	token, _, err := api.createPasswordReset(account.ID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	cfg := config.LoadConfig()
+	if err := api.queueEmail(account.Email, "password_reset", map[string]any{
+		"FirstName": account.FirstName,
+		"Link":      fmt.Sprintf("%s/reset-password?token=%s", cfg.BaseURL, token),
+		"Expires":   auth.PasswordResetTTL,
+	}); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusOK,
+		map[string]string{
+			"status": "successful",
+		},
+	)
+}
+
+func (api *API) issuePasswordReset(c echo.Context) error {
+	account, code := api.pathManagedAccount(c)
+	if code != http.StatusOK {
+		return util.SendStatus(code, c, "")
+	}
+	token, expiresAt, err := api.createPasswordReset(account.ID)
+	if err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	// token is handed to the account holder by the administrator
+	return c.JSON(http.StatusCreated,
+		map[string]string{
+			"status":    "successful",
+			"token":     token,
+			"expiresAt": expiresAt.Format(time.RFC3339),
+		},
+	)
+}
+
+func (api *API) resetPassword(c echo.Context) error {
+	payload := model.PasswordReset{}
+	// bind payload to model
+	if err := c.Bind(&payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	if !util.VerifySecret(payload.Token) {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid or expired token")
+	}
+	// hash password before spending the token
+	if err := auth.HashPassword(&payload.Password); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, err.Error())
+	}
+	// begin transaction
+	tx, err := api.DB.BeginTransaction()
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	defer tx.Rollback()
+	accountID, err := api.DB.ConsumePasswordReset(tx, auth.HashToken(payload.Token))
+	if err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid or expired token")
+	}
+	// updating the password also ends every account session
+	if err := api.DB.UpdatePassword(tx, accountID, payload.Password); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if err := tx.Commit(); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusOK,
+		map[string]string{
+			"status": "successful",
+		},
+	)
+}
+
+// createPasswordReset stores a hashed reset token and returns the raw token.
+func (api *API) createPasswordReset(accountID string) (string, time.Time, error) {
+	token := util.SecretToken()
+	reset := model.PasswordResetToken{
+		ID:        util.SignedToken(10),
+		AccountID: accountID,
+		TokenHash: auth.HashToken(token),
+		ExpiresAt: time.Now().Add(auth.PasswordResetTTL),
+	}
+	if err := api.DB.CreatePasswordReset(reset); err != nil {
+		return "", time.Time{}, err
+	}
+	return token, reset.ExpiresAt, nil
+}
@@ -0,0 +1,276 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Leagueify/api/internal/database/postgres"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForgotPassword(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description:        "Invalid JSON Payload",
+			RequestBody:        `{`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"invalid json payload"`,
+		},
+		{
+			Description:        "Invalid Email",
+			RequestBody:        `{"email":"test@leagueify"}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"invalid email"`,
+		},
+		{
+			Description: "Email Not Configured",
+			RequestBody: `{"email":"test@leagueify.org"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM email WHERE is_active = true").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectedStatusCode: http.StatusServiceUnavailable,
+			ExpectedContent:    `"detail":"email is not configured, contact a league administrator"`,
+		},
+		{
+			Description: "Unknown Account Email",
+			RequestBody: `{"email":"test@leagueify.org"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM email WHERE is_active = true").WillReturnRows(sqlmock.NewRows([]string{"id", "email", "smtp_host", "smtp_port", "smtp_user", "smtp_pass", "is_active", "has_error"}).AddRow("ABC", "noreply@leagueify.org", "smtp.leagueify.org", 465, "leagueify", "password", true, false))
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE email = (.+)$").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"status":"successful"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		reqBody := []byte(test.RequestBody)
+		req := httptest.NewRequest(http.MethodPost, "/api/accounts/password/forgot", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		// Perform Request
+		if assert.NoError(t, api.forgotPassword(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestIssuePasswordReset(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		ID                 string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description:        "Invalid Account ID",
+			ID:                 "12345678",
+			ExpectedStatusCode: http.StatusNotFound,
+			ExpectedContent:    `"status":"not found"`,
+		},
+		{
+			Description: "Account ID not in Database",
+			ID:          "ERCXNX57",
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE id = (.+)").WithArgs("ERCXNX5").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+			ExpectedContent:    `"status":"not found"`,
+		},
+		{
+			Description: "Administrator Account Issued By Role Holder",
+			ID:          "ERCXNX57",
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE id = (.+)").WithArgs("ERCXNX5").WillReturnRows(sqlmock.NewRows(accountColumns).AddRow("ERCXNX5", "Leagueify", "Test", "test@leagueify.org", "", "+12085551234", "1990-08-31", pq.StringArray{}, false, false, true, true, pq.StringArray{}))
+			},
+			ExpectedStatusCode: http.StatusForbidden,
+		},
+		{
+			Description: "Valid Account ID",
+			ID:          "ERCXNX57",
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM accounts WHERE id = (.+)").WithArgs("ERCXNX5").WillReturnRows(sqlmock.NewRows(accountColumns).AddRow("ERCXNX5", "Leagueify", "Test", "test@leagueify.org", "", "+12085551234", "1990-08-31", pq.StringArray{}, false, false, true, false, pq.StringArray{}))
+				mock.ExpectExec("INSERT INTO password_resets (.+) VALUES (.+)").WithArgs(sqlmock.AnyArg(), "ERCXNX5", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			ExpectedStatusCode: http.StatusCreated,
+			ExpectedContent:    `"token":"(.+)"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/accounts/%s/password/reset", test.ID), nil)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(test.ID)
+		// Perform Request
+		if assert.NoError(t, api.issuePasswordReset(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description:        "Invalid JSON Payload",
+			RequestBody:        `{`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"invalid json payload"`,
+		},
+		{
+			Description:        "Missing Valid Payload",
+			RequestBody:        `{}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"missing required field\(s\): \[Token Password\]"`,
+		},
+		{
+			Description:        "Invalid Token Checksum",
+			RequestBody:        `{"token":"A1B2C3D4","password":"Test123!"}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"invalid or expired token"`,
+		},
+		{
+			Description:        "Invalid Password Missing Special Character",
+			RequestBody:        `{"token":"lfy_0XP5ECFVVJFWQAM7QG5WW1A43A8YS5E9VM5S3342P09AZND4BNAYB","password":"Test1234"}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"missing special character"`,
+		},
+		{
+			Description: "Expired or Used Token",
+			RequestBody: `{"token":"lfy_0XP5ECFVVJFWQAM7QG5WW1A43A8YS5E9VM5S3342P09AZND4BNAYB","password":"Test123!"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("UPDATE password_resets SET used_at = now\\(\\) (.+) RETURNING account_id").WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"invalid or expired token"`,
+		},
+		{
+			Description: "Valid Token",
+			RequestBody: `{"token":"lfy_0XP5ECFVVJFWQAM7QG5WW1A43A8YS5E9VM5S3342P09AZND4BNAYB","password":"Test123!"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery("UPDATE password_resets SET used_at = now\\(\\) (.+) RETURNING account_id").WillReturnRows(sqlmock.NewRows([]string{"account_id"}).AddRow("ERCXNX5"))
+				mock.ExpectExec("UPDATE password_resets SET used_at = now\\(\\) WHERE account_id = (.+)").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("UPDATE accounts SET password = (.+) WHERE id = (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("DELETE FROM sessions WHERE account_id = (.+)").WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"status":"successful"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		reqBody := []byte(test.RequestBody)
+		req := httptest.NewRequest(http.MethodPost, "/api/accounts/password/reset", bytes.NewBuffer(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		// Perform Request
+		if assert.NoError(t, api.resetPassword(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
@@ -0,0 +1,278 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Leagueify/api/internal/auth"
+	"github.com/Leagueify/api/internal/config"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/payments"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/labstack/echo/v4"
+)
+
+// maxWebhookSize caps the size of payment provider webhooks.
+const maxWebhookSize = 64 << 10
+
+func (api *API) Payments(e *echo.Group) {
+	e.POST("/payments/webhook", api.paymentWebhook)
+	e.POST("/registrations/:id/checkout", api.requiresAuth(api.createCheckout))
+	e.GET("/registrations/:id/payments", api.requiresAuth(api.listPayments))
+	e.POST("/registrations/:id/refunds", api.requiresPermission(auth.PermissionManageRegistrations, api.refundPayment))
+}
+
+// createCheckout starts paying the balance of the account's registration, or
+// part of it, through the payment provider's hosted checkout.
+func (api *API) createCheckout(c echo.Context) error {
+	if api.PaymentProvider == nil {
+		return util.SendStatus(http.StatusServiceUnavailable, c, "payments are not configured")
+	}
+	registration, ok := api.pathRegistration(c)
+	account := getAccount(c)
+	if !ok || registration.AccountID != account.ID {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	payload := model.PaymentCheckout{}
+	// bind payload to model
+	if err := c.Bind(&payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	if registration.Balance <= 0 {
+		return util.SendStatus(http.StatusConflict, c, "registration has no balance")
+	}
+	if payload.Amount == 0 {
+		payload.Amount = registration.Balance
+	}
+	if payload.Amount > registration.Balance {
+		return util.SendStatus(http.StatusBadRequest, c, "amount exceeds the registration balance")
+	}
+	cfg := config.LoadConfig()
+	registrationID := util.ReturnSignedToken(registration.ID)
+	session, err := api.PaymentProvider.CreateCheckout(c.Request().Context(), payments.Checkout{
+		RegistrationID: registration.ID,
+		Amount:         payload.Amount,
+		Description:    fmt.Sprintf("Registration %s", registrationID),
+		Email:          account.Email,
+		SuccessURL:     fmt.Sprintf("%s/registrations/%s?checkout=success", cfg.BaseURL, registrationID),
+		CancelURL:      fmt.Sprintf("%s/registrations/%s?checkout=cancelled", cfg.BaseURL, registrationID),
+	})
+	if err != nil {
+		return util.SendStatus(http.StatusBadGateway, c, "payment provider unavailable")
+	}
+	return c.JSON(http.StatusCreated,
+		map[string]string{
+			"id":  session.ID,
+			"url": session.URL,
+		},
+	)
+}
+
+// listPayments returns the registration's payment ledger. Account holders may
+// read their own registration's payments, and accounts able to view
+// registrations may read any.
+func (api *API) listPayments(c echo.Context) error {
+	registration, ok := api.pathRegistration(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	account := getAccount(c)
+	if registration.AccountID != account.ID &&
+		!api.hasPermission(account, auth.PermissionViewRegistrations) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	ledger, err := api.DB.ListPayments(registration.ID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	for index := range ledger {
+		ledger[index].ID = util.ReturnSignedToken(ledger[index].ID)
+		if ledger[index].PaymentID != "" {
+			ledger[index].PaymentID = util.ReturnSignedToken(ledger[index].PaymentID)
+		}
+	}
+	return c.JSON(http.StatusOK, ledger)
+}
+
+// paymentWebhook records payments and refunds reported by the payment
+// provider. Providers retry webhooks until they succeed, so events already in
+// the ledger are acknowledged without being recorded again.
+func (api *API) paymentWebhook(c echo.Context) error {
+	if api.PaymentProvider == nil {
+		return util.SendStatus(http.StatusServiceUnavailable, c, "payments are not configured")
+	}
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxWebhookSize))
+	if err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid webhook payload")
+	}
+	event, err := api.PaymentProvider.VerifyWebhook(body, c.Request().Header)
+	if errors.Is(err, payments.ErrInvalidSignature) {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid webhook signature")
+	}
+	if err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid webhook payload")
+	}
+	payment := model.Payment{
+		Amount:    event.Amount,
+		Provider:  api.PaymentProvider.Name(),
+		Reference: event.Reference,
+	}
+	switch event.Type {
+	case payments.EventPaymentSucceeded:
+		registration, err := api.DB.GetRegistration(event.RegistrationID)
+		if err != nil {
+			return util.SendStatus(http.StatusNotFound, c, "")
+		}
+		payment.Kind = model.PaymentKindPayment
+		payment.RegistrationID = registration.ID
+	case payments.EventRefundSucceeded:
+		refunded, err := api.DB.GetPaymentByReference(payment.Provider, event.PaymentReference)
+		if err != nil {
+			return util.SendStatus(http.StatusNotFound, c, "")
+		}
+		payment.Kind = model.PaymentKindRefund
+		payment.RegistrationID = refunded.RegistrationID
+		payment.PaymentID = refunded.ID
+	default:
+		// events other than payments and refunds are acknowledged and ignored
+		return util.SendStatus(http.StatusOK, c, "")
+	}
+	if payment.Amount <= 0 || payment.Reference == "" {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid webhook payload")
+	}
+	if _, err := api.recordPayment(payment); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return util.SendStatus(http.StatusOK, c, "")
+}
+
+// refundPayment returns part or all of a payment through the payment provider
+// and records the refund in the registration's ledger.
+func (api *API) refundPayment(c echo.Context) error {
+	if api.PaymentProvider == nil {
+		return util.SendStatus(http.StatusServiceUnavailable, c, "payments are not configured")
+	}
+	registration, ok := api.pathRegistration(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	payload := model.PaymentRefund{}
+	// bind payload to model
+	if err := c.Bind(&payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	if !util.VerifyToken(payload.PaymentID) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	payment, err := api.DB.GetPayment(payload.PaymentID[:len(payload.PaymentID)-1])
+	if err != nil || payment.RegistrationID != registration.ID ||
+		payment.Kind != model.PaymentKindPayment {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if payment.Provider != api.PaymentProvider.Name() {
+		return util.SendStatus(http.StatusConflict, c, "payment was not made through the payment provider")
+	}
+	// Begin Transaction
+	tx, err := api.DB.BeginTransaction()
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	defer tx.Rollback()
+	// lock the registration so concurrent refunds see each other's entries
+	if _, err := api.DB.GetRegistrationForUpdate(tx, registration.ID); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	ledger, err := api.DB.ListPayments(registration.ID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	refundable := payment.Amount
+	for _, entry := range ledger {
+		if entry.Kind == model.PaymentKindRefund && entry.PaymentID == payment.ID {
+			refundable -= entry.Amount
+		}
+	}
+	if refundable <= 0 {
+		return util.SendStatus(http.StatusConflict, c, "payment has been refunded")
+	}
+	if payload.Amount == 0 {
+		payload.Amount = refundable
+	}
+	if payload.Amount > refundable {
+		return util.SendStatus(http.StatusBadRequest, c, "amount exceeds the refundable amount")
+	}
+	entry := model.Payment{
+		ID:             util.SignedToken(10),
+		RegistrationID: registration.ID,
+		Kind:           model.PaymentKindRefund,
+		Provider:       payment.Provider,
+		PaymentID:      payment.ID,
+	}
+	entry.ID = entry.ID[:len(entry.ID)-1]
+	refund, err := api.PaymentProvider.Refund(
+		c.Request().Context(), payment.Reference, payload.Amount, "refund_"+entry.ID,
+	)
+	if err != nil {
+		return util.SendStatus(http.StatusBadGateway, c, "payment provider unavailable")
+	}
+	entry.Amount = refund.Amount
+	entry.Reference = refund.Reference
+	recorded, err := api.DB.CreatePayment(tx, entry)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if err := tx.Commit(); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	// the provider may have reported the refund through the webhook first
+	if !recorded {
+		if entry, err = api.DB.GetPaymentByReference(entry.Provider, entry.Reference); err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+	}
+	return c.JSON(http.StatusCreated,
+		map[string]string{
+			"status": "successful",
+			"id":     util.ReturnSignedToken(entry.ID),
+		},
+	)
+}
+
+// recordPayment adds the payment to the ledger and returns its ID. Payments
+// the provider has already reported are not recorded again, and the ID of
+// the recorded entry is returned.
+func (api *API) recordPayment(payment model.Payment) (string, error) {
+	payment.ID = util.SignedToken(10)
+	payment.ID = payment.ID[:len(payment.ID)-1]
+	// Begin Transaction
+	tx, err := api.DB.BeginTransaction()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+	recorded, err := api.DB.CreatePayment(tx, payment)
+	if err != nil {
+		return "", err
+	}
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+	if !recorded {
+		existing, err := api.DB.GetPaymentByReference(payment.Provider, payment.Reference)
+		if err != nil {
+			return "", err
+		}
+		return existing.ID, nil
+	}
+	return payment.ID, nil
+}
@@ -0,0 +1,530 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Leagueify/api/internal/database/postgres"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/payments"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var paymentColumns = []string{"id", "registration_id", "kind", "amount", "provider", "reference", "payment_id", "created_at"}
+
+func paymentRows() *sqlmock.Rows {
+	return sqlmock.NewRows(paymentColumns).
+		AddRow("7PWXQ2M4K", "6KQJ7ZPR2", "payment", 10000, "fake", "fake_pi_1", "", time.Now()).
+		AddRow("9HT3VW6NB", "6KQJ7ZPR2", "refund", 4000, "fake", "fake_re_1", "7PWXQ2M4K", time.Now())
+}
+
+func TestCreateCheckout(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	owing := func() *sqlmock.Rows {
+		return sqlmock.NewRows(registrationColumns).AddRow("6KQJ7ZPR2", "123ABC", "BJ7Q4NVRN", 15200, 5000, time.Now())
+	}
+	testCases := []struct {
+		Description        string
+		RequestBody        string
+		Account            model.Account
+		Unconfigured       bool
+		ProviderError      error
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description:        "Payments Not Configured",
+			RequestBody:        `{}`,
+			Account:            model.Account{ID: "123ABC"},
+			Unconfigured:       true,
+			ExpectedStatusCode: http.StatusServiceUnavailable,
+			ExpectedContent:    `"detail":"payments are not configured"`,
+		},
+		{
+			Description: "Another Account's Registration",
+			RequestBody: `{}`,
+			Account:     model.Account{ID: "ERCXNX5", IsAdmin: true},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(owing())
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Registration Without Balance",
+			RequestBody: `{}`,
+			Account:     model.Account{ID: "123ABC"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+			},
+			ExpectedStatusCode: http.StatusConflict,
+			ExpectedContent:    `"detail":"registration has no balance"`,
+		},
+		{
+			Description: "Amount Exceeds Balance",
+			RequestBody: `{"amount":10201}`,
+			Account:     model.Account{ID: "123ABC"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(owing())
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"amount exceeds the registration balance"`,
+		},
+		{
+			Description:   "Provider Unavailable",
+			RequestBody:   `{}`,
+			Account:       model.Account{ID: "123ABC"},
+			ProviderError: errors.New("connection refused"),
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(owing())
+			},
+			ExpectedStatusCode: http.StatusBadGateway,
+		},
+		{
+			Description: "Partial Payment",
+			RequestBody: `{"amount":5000}`,
+			Account:     model.Account{ID: "123ABC"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(owing())
+			},
+			ExpectedStatusCode: http.StatusCreated,
+			ExpectedContent:    `"url":"http://localhost/checkout/fake_cs_1"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		fake := payments.NewFake("http://localhost", "fake_secret")
+		fake.Err = test.ProviderError
+		api := API{DB: db, PaymentProvider: fake}
+		if test.Unconfigured {
+			api.PaymentProvider = nil
+		}
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer([]byte(test.RequestBody)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, test.Account)
+		c.SetPath("/api/registrations/:id/checkout")
+		c.SetParamNames("id")
+		c.SetParamValues(util.ReturnSignedToken("6KQJ7ZPR2"))
+		// Perform Request
+		if assert.NoError(t, api.createCheckout(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+			// Assert Checkout Amount
+			if checkout, ok := fake.Checkout("fake_cs_1"); ok {
+				assert.Equal(t, 5000, checkout.Amount, test.Description)
+				assert.Equal(t, "6KQJ7ZPR2", checkout.RegistrationID, test.Description)
+			}
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestListPayments(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		Account            model.Account
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description: "Another Account's Registration",
+			Account:     model.Account{ID: "ERCXNX5"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Own Registration",
+			Account:     model.Account{ID: "123ABC"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM payment_ledger WHERE registration_id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(paymentRows())
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    fmt.Sprintf(`"kind":"refund","amount":4000(.+)"paymentId":"%s"`, util.ReturnSignedToken("7PWXQ2M4K")),
+		},
+		{
+			Description: "Registrar Reads Any Registration",
+			Account:     model.Account{ID: "ERCXNX5", Roles: pq.StringArray{"registrar"}},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM payment_ledger WHERE registration_id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(paymentColumns))
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `\[\]`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, test.Account)
+		c.SetPath("/api/registrations/:id/payments")
+		c.SetParamNames("id")
+		c.SetParamValues(util.ReturnSignedToken("6KQJ7ZPR2"))
+		// Perform Request
+		if assert.NoError(t, api.listPayments(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestPaymentWebhook(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	fake := payments.NewFake("http://localhost", "fake_secret")
+	paid := payments.Event{
+		ID:             "evt_1",
+		Type:           payments.EventPaymentSucceeded,
+		RegistrationID: "6KQJ7ZPR2",
+		Reference:      "fake_pi_2",
+		Amount:         5000,
+	}
+	testCases := []struct {
+		Description        string
+		Event              payments.Event
+		Signature          string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description:        "Invalid Signature",
+			Event:              paid,
+			Signature:          "forged",
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"invalid webhook signature"`,
+		},
+		{
+			Description:        "Ignored Event",
+			Event:              payments.Event{ID: "evt_2", Type: "checkout.session.expired"},
+			ExpectedStatusCode: http.StatusOK,
+		},
+		{
+			Description: "Registration Not Found",
+			Event:       paid,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Payment Recorded",
+			Event:       paid,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO payment_ledger (.+) ON CONFLICT").WithArgs(sqlmock.AnyArg(), "6KQJ7ZPR2", "payment", 5000, "fake", "fake_pi_2", "").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE registrations SET amount_paid = (.+) WHERE id = (.+)").WithArgs(5000, "6KQJ7ZPR2").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			ExpectedStatusCode: http.StatusOK,
+		},
+		{
+			Description: "Payment Already Recorded",
+			Event:       paid,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO payment_ledger (.+) ON CONFLICT").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+				mock.ExpectQuery("SELECT (.+) FROM payment_ledger WHERE provider = (.+) AND reference = (.+)").WithArgs("fake", "fake_pi_2").WillReturnRows(sqlmock.NewRows(paymentColumns).AddRow("7PWXQ2M4K", "6KQJ7ZPR2", "payment", 5000, "fake", "fake_pi_2", "", time.Now()))
+			},
+			ExpectedStatusCode: http.StatusOK,
+		},
+		{
+			Description: "Refund Recorded",
+			Event: payments.Event{
+				ID:               "evt_3",
+				Type:             payments.EventRefundSucceeded,
+				Reference:        "fake_re_1",
+				PaymentReference: "fake_pi_1",
+				Amount:           2000,
+			},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM payment_ledger WHERE provider = (.+) AND reference = (.+)").WithArgs("fake", "fake_pi_1").WillReturnRows(sqlmock.NewRows(paymentColumns).AddRow("7PWXQ2M4K", "6KQJ7ZPR2", "payment", 10000, "fake", "fake_pi_1", "", time.Now()))
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO payment_ledger (.+) ON CONFLICT").WithArgs(sqlmock.AnyArg(), "6KQJ7ZPR2", "refund", 2000, "fake", "fake_re_1", "7PWXQ2M4K").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE registrations SET amount_paid = (.+) WHERE id = (.+)").WithArgs(-2000, "6KQJ7ZPR2").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			ExpectedStatusCode: http.StatusOK,
+		},
+		{
+			Description: "Refund of Unknown Payment",
+			Event: payments.Event{
+				ID:               "evt_4",
+				Type:             payments.EventRefundSucceeded,
+				Reference:        "fake_re_2",
+				PaymentReference: "fake_pi_9",
+				Amount:           2000,
+			},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM payment_ledger WHERE provider = (.+) AND reference = (.+)").WithArgs("fake", "fake_pi_9").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db, PaymentProvider: fake}
+		payload, header, err := fake.Sign(test.Event)
+		assert.NoError(t, err)
+		if test.Signature != "" {
+			header.Set("Fake-Signature", test.Signature)
+		}
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(payload))
+		req.Header = header
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/payments/webhook")
+		// Perform Request
+		if assert.NoError(t, api.paymentWebhook(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestRefundPayment(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	payment := func(registrationID, provider string) *sqlmock.Rows {
+		return sqlmock.NewRows(paymentColumns).AddRow("7PWXQ2M4K", registrationID, "payment", 10000, provider, "fake_pi_1", "", time.Now())
+	}
+	paymentID := util.ReturnSignedToken("7PWXQ2M4K")
+	testCases := []struct {
+		Description        string
+		RequestBody        string
+		ProviderError      error
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description: "Payment Not Found",
+			RequestBody: fmt.Sprintf(`{"paymentId":"%s"}`, paymentID),
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM payment_ledger WHERE id = (.+)").WithArgs("7PWXQ2M4K").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Another Registration's Payment",
+			RequestBody: fmt.Sprintf(`{"paymentId":"%s"}`, paymentID),
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM payment_ledger WHERE id = (.+)").WithArgs("7PWXQ2M4K").WillReturnRows(payment("W4SBH35WV", "fake"))
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Payment Made Before the Ledger",
+			RequestBody: fmt.Sprintf(`{"paymentId":"%s"}`, paymentID),
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM payment_ledger WHERE id = (.+)").WithArgs("7PWXQ2M4K").WillReturnRows(payment("6KQJ7ZPR2", "legacy"))
+			},
+			ExpectedStatusCode: http.StatusConflict,
+		},
+		{
+			Description: "Amount Exceeds Refundable Amount",
+			RequestBody: fmt.Sprintf(`{"paymentId":"%s","amount":6001}`, paymentID),
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM payment_ledger WHERE id = (.+)").WithArgs("7PWXQ2M4K").WillReturnRows(payment("6KQJ7ZPR2", "fake"))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+) FOR UPDATE").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM payment_ledger WHERE registration_id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(paymentRows())
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"amount exceeds the refundable amount"`,
+		},
+		{
+			Description: "Payment Already Refunded",
+			RequestBody: fmt.Sprintf(`{"paymentId":"%s"}`, paymentID),
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM payment_ledger WHERE id = (.+)").WithArgs("7PWXQ2M4K").WillReturnRows(payment("6KQJ7ZPR2", "fake"))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+) FOR UPDATE").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM payment_ledger WHERE registration_id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(paymentRows().AddRow("B2KD8RFWQ", "6KQJ7ZPR2", "refund", 6000, "fake", "fake_re_2", "7PWXQ2M4K", time.Now()))
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusConflict,
+			ExpectedContent:    `"detail":"payment has been refunded"`,
+		},
+		{
+			Description:   "Provider Unavailable",
+			RequestBody:   fmt.Sprintf(`{"paymentId":"%s"}`, paymentID),
+			ProviderError: errors.New("connection refused"),
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM payment_ledger WHERE id = (.+)").WithArgs("7PWXQ2M4K").WillReturnRows(payment("6KQJ7ZPR2", "fake"))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+) FOR UPDATE").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM payment_ledger WHERE registration_id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(paymentRows())
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusBadGateway,
+		},
+		{
+			Description: "Remaining Amount Refunded",
+			RequestBody: fmt.Sprintf(`{"paymentId":"%s"}`, paymentID),
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM payment_ledger WHERE id = (.+)").WithArgs("7PWXQ2M4K").WillReturnRows(payment("6KQJ7ZPR2", "fake"))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+) FOR UPDATE").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM payment_ledger WHERE registration_id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(paymentRows())
+				mock.ExpectExec("INSERT INTO payment_ledger (.+) ON CONFLICT").WithArgs(sqlmock.AnyArg(), "6KQJ7ZPR2", "refund", 6000, "fake", "fake_re_1", "7PWXQ2M4K").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE registrations SET amount_paid = (.+) WHERE id = (.+)").WithArgs(-6000, "6KQJ7ZPR2").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			ExpectedStatusCode: http.StatusCreated,
+			ExpectedContent:    `"status":"successful"`,
+		},
+		{
+			Description: "Refund Reported Before It Was Recorded",
+			RequestBody: fmt.Sprintf(`{"paymentId":"%s"}`, paymentID),
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM payment_ledger WHERE id = (.+)").WithArgs("7PWXQ2M4K").WillReturnRows(payment("6KQJ7ZPR2", "fake"))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+) FOR UPDATE").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM payment_ledger WHERE registration_id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(paymentRows())
+				mock.ExpectExec("INSERT INTO payment_ledger (.+) ON CONFLICT").WithArgs(sqlmock.AnyArg(), "6KQJ7ZPR2", "refund", 6000, "fake", "fake_re_1", "7PWXQ2M4K").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+				mock.ExpectQuery("SELECT (.+) FROM payment_ledger WHERE provider = (.+) AND reference = (.+)").WithArgs("fake", "fake_re_1").WillReturnRows(sqlmock.NewRows(paymentColumns).AddRow("B2KD8RFWQ", "6KQJ7ZPR2", "refund", 6000, "fake", "fake_re_1", "7PWXQ2M4K", time.Now()))
+			},
+			ExpectedStatusCode: http.StatusCreated,
+			ExpectedContent:    `"id":"B2KD8RFWQ.","status":"successful"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		fake := payments.NewFake("http://localhost", "fake_secret")
+		fake.Err = test.ProviderError
+		api := API{DB: db, PaymentProvider: fake}
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer([]byte(test.RequestBody)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, model.Account{ID: "ERCXNX5", IsAdmin: true})
+		c.SetPath("/api/registrations/:id/refunds")
+		c.SetParamNames("id")
+		c.SetParamValues(util.ReturnSignedToken("6KQJ7ZPR2"))
+		// Perform Request
+		if assert.NoError(t, api.refundPayment(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
@@ -1,12 +1,15 @@
 package api
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
+	"time"
 
+	"github.com/Leagueify/api/internal/auth"
 	"github.com/Leagueify/api/internal/model"
 	"github.com/Leagueify/api/internal/util"
 	"github.com/labstack/echo/v4"
-	"github.com/lib/pq"
 )
 
 func (api *API) Players(e *echo.Group) {
@@ -14,6 +17,8 @@ func (api *API) Players(e *echo.Group) {
 	e.POST("/players", api.requiresAuth(api.createPlayer))
 	e.DELETE("/players/:id", api.requiresAuth(api.deletePlayer))
 	e.GET("/players/:id", api.requiresAuth(api.getPlayer))
+	e.PATCH("/players/:id", api.requiresAuth(api.updatePlayer))
+	e.PUT("/players/:id/division", api.requiresPermission(auth.PermissionManageLeague, api.setPlayerDivision))
 	e.POST("/players/register", api.requiresAuth(api.registerPlayer))
 }
 
@@ -36,8 +41,7 @@ func (api *API) createPlayer(c echo.Context) error {
 	if err != nil {
 		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
 	}
-	// check for existing players
-	playerIDs := api.Account.Players
+	account := getAccount(c)
 	// begin transaction
 	tx, err := api.DB.BeginTransaction()
 	if err != nil {
@@ -50,24 +54,21 @@ func (api *API) createPlayer(c echo.Context) error {
 			return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
 		}
 		// validate player position in positions
-		var validPosition = false
-		for _, position := range leaguePositions {
-			if position.Name == player.Position {
-				validPosition = true
-				break
-			}
-		}
-		if !validPosition {
+		if !isLeaguePosition(leaguePositions, player.Position) {
 			return util.SendStatus(http.StatusBadRequest, c, "invalid position")
 		}
 		player.ID = util.SignedToken(10)
-		playerIDs = append(playerIDs, player.ID[:len(player.ID)-1])
 		if err := api.DB.CreatePlayer(player, tx); err != nil {
 			return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
 		}
-	}
-	if err := api.DB.SetPlayerIDs(&playerIDs, api.Account.ID, tx); err != nil {
-		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+		// the creating account is the player's primary guardian
+		if err := api.DB.CreateGuardian(tx, model.Guardian{
+			AccountID:  account.ID,
+			PlayerID:   player.ID[:len(player.ID)-1],
+			Permission: string(auth.GuardianPrimary),
+		}); err != nil {
+			return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+		}
 	}
 	if err := tx.Commit(); err != nil {
 		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
@@ -79,86 +80,88 @@ func (api *API) createPlayer(c echo.Context) error {
 	)
 }
 
+// deletePlayer deletes a player the account is the primary guardian of.
+// Players the account is not a guardian of are treated as already deleted,
+// and players registered for a current season must be withdrawn first.
 func (api *API) deletePlayer(c echo.Context) error {
-	playerID := c.Param("id")
-	if !util.VerifyToken(playerID) {
-		return c.JSON(http.StatusNoContent, nil)
-	}
-	// Remove checksum from playerID
-	playerID = playerID[:len(playerID)-1]
-	// Get account players
-	players := api.Account.Players
-	// Delete playerID if in players
-	for playerIndex, player := range players {
-		if player == playerID {
-			// Begin Transaction
-			tx, err := api.DB.BeginTransaction()
-			if err != nil {
-				return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
-			}
-			// delete player record
-			if err := api.DB.DeletePlayer(playerID, tx); err != nil {
-				return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
-			}
-			// Remove playerID from account Players
-			players = append(players[:playerIndex], players[playerIndex+1:]...)
-			if err := api.DB.SetPlayerIDs(&players, api.Account.ID, tx); err != nil {
-				return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
-			}
-			// Commit Transaction
-			if err := tx.Commit(); err != nil {
-				return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
-			}
-		}
+	guardian, ok := api.playerGuardian(c)
+	if !ok {
+		return c.NoContent(http.StatusNoContent)
+	}
+	if !auth.GuardianPermission(guardian.Permission).CanManage() {
+		return util.SendStatus(http.StatusForbidden, c, "")
+	}
+	player, err := api.DB.GetPlayer(guardian.PlayerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.NoContent(http.StatusNoContent)
 	}
-	return c.JSON(http.StatusNoContent, nil)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if player.IsRegistered {
+		return util.SendStatus(
+			http.StatusConflict, c,
+			"registered players must be withdrawn before they can be deleted, request a withdrawal from the player's registration",
+		)
+	}
+	// Begin Transaction
+	tx, err := api.DB.BeginTransaction()
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	defer tx.Rollback()
+	// delete player record, guardians are removed by their foreign key
+	if err := api.DB.DeletePlayer(guardian.PlayerID, tx); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	// Commit Transaction
+	if err := tx.Commit(); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	return c.NoContent(http.StatusNoContent)
 }
 
 func (api *API) getPlayer(c echo.Context) error {
-	playerID := c.Param("id")
-	if !util.VerifyToken(playerID) {
+	guardian, ok := api.playerGuardian(c)
+	if !ok {
 		return c.JSON(http.StatusNotFound,
 			map[string]string{
 				"status": "not found",
 			},
 		)
 	}
-	// Remove checksum from playerID
-	playerID = playerID[:len(playerID)-1]
-	// Get account players
-	players := api.Account.Players
-	for _, player := range players {
-		if player == playerID {
-			playerInfo, err := api.DB.GetPlayer(player)
-			if err != nil {
-				return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
-			}
-			playerInfo.ID = util.ReturnSignedToken(playerInfo.ID)
-			return c.JSON(http.StatusOK, playerInfo)
-		}
+	playerInfo, err := api.DB.GetPlayer(guardian.PlayerID)
+	if err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
 	}
-	return c.JSON(http.StatusNotFound,
-		map[string]string{
-			"status": "not found",
-		},
-	)
+	playerInfo.ID = util.ReturnSignedToken(playerInfo.ID)
+	return c.JSON(http.StatusOK, playerInfo)
 }
 
+// getPlayers returns every player the account is a guardian of.
 func (api *API) getPlayers(c echo.Context) error {
-	players := api.Account.Players
-	if len(players) == 0 {
+	account := getAccount(c)
+	if len(account.Players) == 0 {
 		return util.SendStatus(http.StatusNotFound, c, "")
 	}
-	for index, player := range players {
-		players[index] = util.ReturnSignedToken(player)
+	players, err := api.DB.ListPlayers(account.ID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	for index := range players {
+		players[index].ID = util.ReturnSignedToken(players[index].ID)
 	}
 	return c.JSON(http.StatusOK,
-		map[string]pq.StringArray{
+		map[string][]model.Player{
 			"players": players,
 		},
 	)
 }
 
+// registerPlayer registers players for a season while its registration window
+// is open. An account holds one registration per season, which players are
+// added to as they are registered, and its amount due is priced from the
+// season's fee schedules.
 func (api *API) registerPlayer(c echo.Context) error {
 	payload := model.PlayerRegistration{}
 	// Bind payload to model
@@ -173,75 +176,229 @@ func (api *API) registerPlayer(c echo.Context) error {
 	if len(payload.Players) < 1 {
 		return util.SendStatus(http.StatusBadRequest, c, "payload contains no players")
 	}
-	// Generate Players to register
-	var registerPlayers pq.StringArray
+	// Verify season is open for registration
+	if !util.VerifyToken(payload.SeasonID) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	season, err := api.DB.GetSeason(payload.SeasonID)
+	if err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	isOpen, err := util.IsDateInRange(
+		time.Now().Format(time.DateOnly),
+		season.RegistrationOpens, season.RegistrationCloses,
+	)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if !isOpen {
+		return util.SendStatus(http.StatusBadRequest, c, "registration is not open for this season")
+	}
+	seasonID := payload.SeasonID[:len(payload.SeasonID)-1]
+	account := getAccount(c)
+	players, code := api.registrablePlayers(account.ID, payload.Players)
+	if code != http.StatusOK {
+		return util.SendStatus(code, c, "")
+	}
 	// Begin Transaction
 	tx, err := api.DB.BeginTransaction()
 	if err != nil {
 		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
 	}
 	defer tx.Rollback()
-	// Generate Registration Code
-	updateRegistration := false
-	registrationCode := util.SignedToken(10)
-	if api.Account.RegistrationCode != "" {
-		updateRegistration = true
-		registrationCode = util.ReturnSignedToken(api.Account.RegistrationCode)
-	}
-	storedRegistrationCode := registrationCode[:len(registrationCode)-1]
-	if err := api.DB.SetRegistrationCode(tx, storedRegistrationCode, api.Account.ID); err != nil {
-		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
-	}
-	// Add players to registration
-	for _, player := range payload.Players {
-		if !util.VerifyToken(player) {
-			return util.SendStatus(http.StatusNotFound, c, "")
-		}
-		// Update Player ID
-		player = player[:len(player)-1]
-		// Validate player in Account
-		if !util.IsInArray(api.Account.Players, player) {
-			return util.SendStatus(http.StatusNotFound, c, "")
-		}
-		// Add Player to registerPlayers array
-		registerPlayers = append(registerPlayers, player)
-		if err := api.DB.RegisterPlayer(tx, player); err != nil {
-			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	// Retrieve or create the account's registration for the season
+	registration, err := api.DB.GetSeasonRegistration(tx, account.ID, seasonID)
+	if errors.Is(err, sql.ErrNoRows) {
+		registration = model.Registration{
+			ID:        util.SignedToken(10),
+			AccountID: account.ID,
+			SeasonID:  seasonID,
 		}
+		registration.ID = registration.ID[:len(registration.ID)-1]
+		err = api.DB.CreateRegistration(tx, registration)
 	}
-	if updateRegistration {
-		registeredPlayers, err := api.DB.GetRegistration(tx, registrationCode)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	// Price and add players to registration
+	quote, err := api.priceRegistration(registration, players)
+	if err != nil {
+		return sendQuoteError(c, err)
+	}
+	statuses, err := api.placePlayers(tx, seasonID, quote.Players)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	amountDue := quote.AmountDue
+	for _, line := range quote.Players {
+		added, err := api.DB.AddRegistrationPlayer(tx, model.RegistrationPlayer{
+			RegistrationID: registration.ID,
+			PlayerID:       line.PlayerID,
+			SeasonID:       seasonID,
+			Status:         statuses[line.PlayerID],
+			Amount:         line.Amount,
+		})
 		if err != nil {
-			return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
-		}
-
-		for _, player := range registerPlayers {
-			if !util.IsInArray(registeredPlayers, player) {
-				registeredPlayers = append(registeredPlayers, player)
-			}
-		}
-
-		if err := api.DB.SetRegistration(tx, registeredPlayers, registrationCode); err != nil {
 			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
 		}
-	}
-	if !updateRegistration {
-		registration := model.Registration{
-			ID:         storedRegistrationCode,
-			PlayerIDs:  registerPlayers,
-			AmountDue:  0,
-			AmountPaid: 0,
+		if !added {
+			return util.SendStatus(http.StatusConflict, c, "player is already registered for this season")
 		}
-		if err := api.DB.CreateRegistration(tx, registration); err != nil {
-			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		// waitlisted players are not charged until they take a place
+		if statuses[line.PlayerID] == model.RegistrationWaitlisted {
+			amountDue -= line.Amount
 		}
 	}
+	if err := api.DB.SetRegistrationAmountDue(tx, registration.ID, max(amountDue, 0)); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
 	if err := tx.Commit(); err != nil {
 		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
 	}
 	return c.JSON(http.StatusOK,
 		map[string]string{
 			"status": "successful",
+			"id":     util.ReturnSignedToken(registration.ID),
 		},
 	)
 }
+
+// setPlayerDivision places a player in the division their fees and place in
+// a season are worked out from. Players holding or waiting for a place in a
+// current season keep their division until they are withdrawn.
+func (api *API) setPlayerDivision(c echo.Context) error {
+	playerID := c.Param("id")
+	if !util.VerifyToken(playerID) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	payload := model.PlayerDivisionAssignment{}
+	// bind payload to model
+	if err := c.Bind(&payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	player, err := api.DB.GetPlayer(playerID[:len(playerID)-1])
+	if err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	updated, err := api.DB.SetPlayerDivision(player.ID, payload.Division)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if !updated {
+		return util.SendStatus(http.StatusConflict, c, "player is registered for a season")
+	}
+	return c.JSON(http.StatusOK,
+		map[string]string{
+			"status": "successful",
+		},
+	)
+}
+
+// updatePlayer updates the details of a player the account is a primary or
+// secondary guardian of. The date of birth and gender place a registered
+// player in their division, so they cannot change once the player is
+// registered.
+func (api *API) updatePlayer(c echo.Context) error {
+	guardian, ok := api.playerGuardian(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if !auth.GuardianPermission(guardian.Permission).CanUpdate() {
+		return util.SendStatus(http.StatusForbidden, c, "")
+	}
+	payload := model.PlayerUpdate{}
+	// bind payload to model
+	if err := c.Bind(&payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	player, err := api.DB.GetPlayer(guardian.PlayerID)
+	if err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if player.IsRegistered {
+		if (payload.DateOfBirth != nil && *payload.DateOfBirth != player.DateOfBirth) ||
+			(payload.Gender != nil && *payload.Gender != player.Gender) {
+			return util.SendStatus(http.StatusConflict, c, "date of birth and gender cannot change once a player is registered")
+		}
+	}
+	if payload.Position != nil && *payload.Position != player.Position {
+		leaguePositions, err := api.DB.GetAllPositions()
+		if err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+		if !isLeaguePosition(leaguePositions, *payload.Position) {
+			return util.SendStatus(http.StatusBadRequest, c, "invalid position")
+		}
+		player.Position = *payload.Position
+	}
+	if payload.FirstName != nil {
+		player.FirstName = *payload.FirstName
+	}
+	if payload.LastName != nil {
+		player.LastName = *payload.LastName
+	}
+	if payload.DateOfBirth != nil {
+		player.DateOfBirth = *payload.DateOfBirth
+	}
+	if payload.Gender != nil {
+		player.Gender = *payload.Gender
+	}
+	if payload.JerseySize != nil {
+		player.JerseySize = *payload.JerseySize
+	}
+	if payload.JerseyNumber != nil {
+		player.JerseyNumber = payload.JerseyNumber
+	}
+	if payload.School != nil {
+		player.School = *payload.School
+	}
+	if payload.Grade != nil {
+		player.Grade = *payload.Grade
+	}
+	// Begin Transaction
+	tx, err := api.DB.BeginTransaction()
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	defer tx.Rollback()
+	if err := api.DB.UpdatePlayer(tx, player); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	if err := tx.Commit(); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	player.ID = util.ReturnSignedToken(player.ID)
+	return c.JSON(http.StatusOK, player)
+}
+
+// isLeaguePosition reports whether name is one of the league's positions.
+func isLeaguePosition(positions []model.Position, name string) bool {
+	for _, position := range positions {
+		if position.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// playerGuardian returns the current account's guardianship of the player in
+// the id path parameter.
+func (api *API) playerGuardian(c echo.Context) (model.Guardian, bool) {
+	playerID := c.Param("id")
+	if !util.VerifyToken(playerID) {
+		return model.Guardian{}, false
+	}
+	guardian, err := api.DB.GetGuardian(getAccount(c).ID, playerID[:len(playerID)-1])
+	if err != nil {
+		return model.Guardian{}, false
+	}
+	return guardian, true
+}
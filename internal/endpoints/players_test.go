@@ -2,11 +2,13 @@ package api
 
 import (
 	"bytes"
+	"database/sql"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/Leagueify/api/internal/database/postgres"
@@ -17,6 +19,10 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+var playerColumns = []string{"id", "first_name", "last_name", "date_of_birth", "position", "gender", "jersey_size", "jersey_number", "school", "grade", "team", "division", "is_registered"}
+
+var guardianColumns = []string{"account_id", "player_id", "permission", "created_at"}
+
 func TestCreatePlayer(t *testing.T) {
 	// run test in parallel
 	t.Parallel()
@@ -113,7 +119,7 @@ func TestCreatePlayer(t *testing.T) {
 				mock.ExpectQuery("SELECT \\* FROM positions").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("1", "skater").AddRow("2", "goalie"))
 				mock.ExpectBegin()
 				mock.ExpectExec("INSERT INTO players (.+) VALUES (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
-				mock.ExpectExec("UPDATE accounts SET player_ids = (.+) WHERE id = (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO guardians (.+) VALUES (.+)").WithArgs("123ABC", sqlmock.AnyArg(), "primary").WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectCommit()
 			},
 			ExpectedStatusCode: http.StatusCreated,
@@ -126,6 +132,7 @@ func TestCreatePlayer(t *testing.T) {
 				mock.ExpectQuery("SELECT \\* FROM positions").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("1", "skater").AddRow("2", "goalie"))
 				mock.ExpectBegin()
 				mock.ExpectExec("INSERT INTO players (.+) VALUES (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO guardians (.+) VALUES (.+)").WithArgs("123ABC", sqlmock.AnyArg(), "primary").WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectRollback()
 			},
 			ExpectedStatusCode: http.StatusBadRequest,
@@ -138,6 +145,7 @@ func TestCreatePlayer(t *testing.T) {
 				mock.ExpectQuery("SELECT \\* FROM positions").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("1", "skater").AddRow("2", "goalie"))
 				mock.ExpectBegin()
 				mock.ExpectExec("INSERT INTO players (.+) VALUES (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO guardians (.+) VALUES (.+)").WithArgs("123ABC", sqlmock.AnyArg(), "primary").WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectRollback()
 			},
 			ExpectedStatusCode: http.StatusBadRequest,
@@ -150,6 +158,7 @@ func TestCreatePlayer(t *testing.T) {
 				mock.ExpectQuery("SELECT \\* FROM positions").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("1", "skater").AddRow("2", "goalie"))
 				mock.ExpectBegin()
 				mock.ExpectExec("INSERT INTO players (.+) VALUES (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO guardians (.+) VALUES (.+)").WithArgs("123ABC", sqlmock.AnyArg(), "primary").WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectRollback()
 			},
 			ExpectedStatusCode: http.StatusBadRequest,
@@ -162,6 +171,7 @@ func TestCreatePlayer(t *testing.T) {
 				mock.ExpectQuery("SELECT \\* FROM positions").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("1", "skater").AddRow("2", "goalie"))
 				mock.ExpectBegin()
 				mock.ExpectExec("INSERT INTO players (.+) VALUES (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO guardians (.+) VALUES (.+)").WithArgs("123ABC", sqlmock.AnyArg(), "primary").WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectRollback()
 			},
 			ExpectedStatusCode: http.StatusBadRequest,
@@ -174,6 +184,7 @@ func TestCreatePlayer(t *testing.T) {
 				mock.ExpectQuery("SELECT \\* FROM positions").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("1", "skater").AddRow("2", "goalie"))
 				mock.ExpectBegin()
 				mock.ExpectExec("INSERT INTO players (.+) VALUES (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO guardians (.+) VALUES (.+)").WithArgs("123ABC", sqlmock.AnyArg(), "primary").WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectRollback()
 			},
 			ExpectedStatusCode: http.StatusBadRequest,
@@ -186,8 +197,9 @@ func TestCreatePlayer(t *testing.T) {
 				mock.ExpectQuery("SELECT \\* FROM positions").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("1", "skater").AddRow("2", "goalie"))
 				mock.ExpectBegin()
 				mock.ExpectExec("INSERT INTO players (.+) VALUES (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO guardians (.+) VALUES (.+)").WithArgs("123ABC", sqlmock.AnyArg(), "primary").WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectExec("INSERT INTO players (.+) VALUES (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
-				mock.ExpectExec("UPDATE accounts SET player_ids = (.+) WHERE id = (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO guardians (.+) VALUES (.+)").WithArgs("123ABC", sqlmock.AnyArg(), "primary").WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectCommit()
 			},
 			ExpectedStatusCode: http.StatusCreated,
@@ -201,16 +213,14 @@ func TestCreatePlayer(t *testing.T) {
 		}
 		// Initialize Echo and the Echo validator
 		e := echo.New()
-		account := model.Account{
-			ID: "123ABC",
-		}
 		e.Validator = &API{Validator: validator.New()}
-		api := &API{DB: db, Account: account}
+		api := &API{DB: db}
 		reqBody := []byte(test.RequestBody)
 		req := httptest.NewRequest(http.MethodPost, "/api/players", bytes.NewBuffer(reqBody))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
+		setAccount(c, model.Account{ID: "123ABC"})
 		// Perform Request
 		if assert.NoError(t, api.createPlayer(c)) {
 			// Assert Status Code
@@ -251,37 +261,42 @@ func TestDeletePlayer(t *testing.T) {
 			ExpectedStatusCode: http.StatusNoContent,
 		},
 		{
-			Description:        "Valid Player ID no Player ID in Account",
-			ID:                 "QP4RD39CEF",
-			Account:            model.Account{ID: "123ABC"},
+			Description: "Valid Player ID not Guardian",
+			ID:          "QP4RD39CEF",
+			Account:     model.Account{ID: "123ABC"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("123ABC", "QP4RD39CE").WillReturnError(sql.ErrNoRows)
+			},
 			ExpectedStatusCode: http.StatusNoContent,
 		},
 		{
-			Description:        "Valid Player ID not in Account",
-			ID:                 "49QRBF09YA",
-			Account:            model.Account{ID: "123ABC", Players: pq.StringArray{"QP4RD39CE"}},
-			ExpectedStatusCode: http.StatusNoContent,
+			Description: "Secondary Guardian",
+			ID:          "49QRBF09YA",
+			Account:     model.Account{ID: "123ABC", Players: pq.StringArray{"49QRBF09Y"}},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("123ABC", "49QRBF09Y").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("123ABC", "49QRBF09Y", "secondary", time.Now()))
+			},
+			ExpectedStatusCode: http.StatusForbidden,
 		},
 		{
-			Description: "Valid Player ID in Account",
+			Description: "Registered Player",
 			ID:          "49QRBF09YA",
 			Account:     model.Account{ID: "123ABC", Players: pq.StringArray{"49QRBF09Y"}},
 			Mock: func(mock sqlmock.Sqlmock) {
-				mock.ExpectBegin()
-				mock.ExpectExec("DELETE FROM players WHERE id = (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
-				mock.ExpectExec("UPDATE accounts SET player_ids = (.+) WHERE id = (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
-				mock.ExpectCommit()
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("123ABC", "49QRBF09Y").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("123ABC", "49QRBF09Y", "primary", time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM players WHERE id = (.+)").WithArgs("49QRBF09Y").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("49QRBF09Y", "Leagueify", "Test", "2016-12-10", "goalie", "", "", nil, "", "", "", "U12", true))
 			},
-			ExpectedStatusCode: http.StatusNoContent,
+			ExpectedStatusCode: http.StatusConflict,
 		},
 		{
-			Description: "Valid Player ID in Account with Multiple Player IDs",
+			Description: "Primary Guardian",
 			ID:          "49QRBF09YA",
 			Account:     model.Account{ID: "123ABC", Players: pq.StringArray{"12345ABCD", "49QRBF09Y"}},
 			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("123ABC", "49QRBF09Y").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("123ABC", "49QRBF09Y", "primary", time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM players WHERE id = (.+)").WithArgs("49QRBF09Y").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("49QRBF09Y", "Leagueify", "Test", "2016-12-10", "goalie", "", "", nil, "", "", "", "", false))
 				mock.ExpectBegin()
-				mock.ExpectExec("DELETE FROM players WHERE id = (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
-				mock.ExpectExec("UPDATE accounts SET player_ids = (.+) WHERE id = (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("DELETE FROM players WHERE id = (.+)").WithArgs("49QRBF09Y").WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectCommit()
 			},
 			ExpectedStatusCode: http.StatusNoContent,
@@ -295,11 +310,12 @@ func TestDeletePlayer(t *testing.T) {
 		// Initialize Echo and the Echo validator
 		e := echo.New()
 		e.Validator = &API{Validator: validator.New()}
-		api := &API{DB: db, Account: test.Account}
+		api := &API{DB: db}
 		req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/players/%s", test.ID), nil)
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
+		setAccount(c, test.Account)
 		c.SetParamNames("id")
 		c.SetParamValues(test.ID)
 		// Perform Request
@@ -335,32 +351,31 @@ func TestGetPlayer(t *testing.T) {
 			ExpectedStatusCode: http.StatusNotFound,
 		},
 		{
-			Description:        "Valid Player ID no Player ID in Account",
-			ID:                 "QP4RD39CEF",
-			Account:            model.Account{ID: "123ABC"},
-			ExpectedStatusCode: http.StatusNotFound,
-		},
-		{
-			Description:        "Valid Player ID not in Account",
-			ID:                 "49QRBF09YA",
-			Account:            model.Account{ID: "123ABC", Players: pq.StringArray{"QP4RD39CE"}},
+			Description: "Valid Player ID not Guardian",
+			ID:          "QP4RD39CEF",
+			Account:     model.Account{ID: "123ABC"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("123ABC", "QP4RD39CE").WillReturnError(sql.ErrNoRows)
+			},
 			ExpectedStatusCode: http.StatusNotFound,
 		},
 		{
-			Description: "Valid Player ID in Account",
+			Description: "Primary Guardian",
 			ID:          "49QRBF09YA",
 			Account:     model.Account{ID: "123ABC", Players: pq.StringArray{"49QRBF09Y"}},
 			Mock: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery("SELECT \\* FROM players WHERE id = (.+)").WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "date_of_birth", "position", "team", "division", "is_registered"}).AddRow("49QRBF09YA", "Leagueify", "Test", "1990-08-31", "goalie", "", "", false))
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("123ABC", "49QRBF09Y").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("123ABC", "49QRBF09Y", "primary", time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM players WHERE id = (.+)").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("49QRBF09Y", "Leagueify", "Test", "1990-08-31", "goalie", "", "", nil, "", "", "", "", false))
 			},
 			ExpectedStatusCode: http.StatusOK,
 		},
 		{
-			Description: "Valid Player ID in Account with Multiple Player IDs",
+			Description: "View Only Guardian",
 			ID:          "49QRBF09YA",
 			Account:     model.Account{ID: "123ABC", Players: pq.StringArray{"12345ABCD", "49QRBF09Y"}},
 			Mock: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery("SELECT \\* FROM players WHERE id = (.+)").WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "date_of_birth", "position", "team", "division", "is_registered"}).AddRow("49QRBF09YA", "Leagueify", "Test", "1990-08-31", "goalie", "", "", false))
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("123ABC", "49QRBF09Y").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("123ABC", "49QRBF09Y", "view", time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM players WHERE id = (.+)").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("49QRBF09Y", "Leagueify", "Test", "1990-08-31", "goalie", "", "", nil, "", "", "", "", false))
 			},
 			ExpectedStatusCode: http.StatusOK,
 		},
@@ -373,11 +388,12 @@ func TestGetPlayer(t *testing.T) {
 		// Initialize Echo and the Echo validator
 		e := echo.New()
 		e.Validator = &API{Validator: validator.New()}
-		api := &API{DB: db, Account: test.Account}
+		api := &API{DB: db}
 		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/players/%s", test.ID), nil)
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
+		setAccount(c, test.Account)
 		c.SetParamNames("id")
 		c.SetParamValues(test.ID)
 		// Perform Request
@@ -403,7 +419,9 @@ func TestGetPlayers(t *testing.T) {
 	testCases := []struct {
 		Description        string
 		Account            model.Account
+		Mock               func(mock sqlmock.Sqlmock)
 		ExpectedStatusCode int
+		ExpectedContent    string
 	}{
 		{
 			Description:        "No Results",
@@ -411,31 +429,59 @@ func TestGetPlayers(t *testing.T) {
 			ExpectedStatusCode: http.StatusNotFound,
 		},
 		{
-			Description:        "Result Found",
-			Account:            model.Account{ID: "123ABC", Players: pq.StringArray{"QP4RD39CEF"}},
+			Description: "Result Found",
+			Account:     model.Account{ID: "123ABC", Players: pq.StringArray{"QP4RD39CE"}},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM players JOIN guardians (.+) WHERE guardians.account_id = (.+)").WithArgs("123ABC").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("QP4RD39CE", "Leagueify", "Test", "2016-12-10", "goalie", "female", "YM", 7, "Leagueify Elementary", "3", "", "", false))
+			},
 			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"players":\[{"ID":"QP4RD39CEF","firstName":"Leagueify",(.+),"jerseyNumber":7,`,
 		},
 	}
 	// Execute Test Cases
 	for _, test := range testCases {
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
 		// Initialize Echo and the Echo validator
 		e := echo.New()
 		e.Validator = &API{Validator: validator.New()}
-		api := &API{DB: db, Account: test.Account}
+		api := &API{DB: db}
 		req := httptest.NewRequest(http.MethodGet, "/api/players", nil)
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
+		setAccount(c, test.Account)
 		// Perform Request
 		if assert.NoError(t, api.getPlayers(c)) {
 			// Assert Status Code
 			assert.Equal(t, test.ExpectedStatusCode, rec.Code)
+			// Assert Response Content
+			assert.Regexp(t, regexp.MustCompile(test.ExpectedContent), rec.Body.String())
 		}
 		// Assert All Expectations Met
 		assert.NoError(t, mock.ExpectationsWereMet())
 	}
 }
 
+// registration window dates relative to when the tests run
+var (
+	today     = time.Now().Format(time.DateOnly)
+	yesterday = time.Now().AddDate(0, 0, -1).Format(time.DateOnly)
+	tomorrow  = time.Now().AddDate(0, 0, 1).Format(time.DateOnly)
+	lastMonth = time.Now().AddDate(0, -1, 0).Format(time.DateOnly)
+	nextMonth = time.Now().AddDate(0, 1, 0).Format(time.DateOnly)
+)
+
+func seasonRow(registrationOpens, registrationCloses string) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "name", "startDate", "endDate", "registrationOpens", "registrationCloses"}).
+		AddRow("BJ7Q4NVRN", "2024-2025", nextMonth, nextMonth, registrationOpens, registrationCloses)
+}
+
+func registrationRow() *sqlmock.Rows {
+	return sqlmock.NewRows(registrationColumns).AddRow("6KQJ7ZPR2", "123ABC", "BJ7Q4NVRN", 0, 0, time.Now())
+}
+
 func TestRegisterPlayer(t *testing.T) {
 	// run test in parallel
 	t.Parallel()
@@ -463,95 +509,212 @@ func TestRegisterPlayer(t *testing.T) {
 		{
 			Description:        "Missing Players",
 			Account:            model.Account{ID: "123ABC"},
-			RequestBody:        `{}`,
+			RequestBody:        `{"seasonId":"BJ7Q4NVRNQ"}`,
 			ExpectedStatusCode: http.StatusBadRequest,
 			ExpectedContent:    `"detail":"missing required field\(s\): \[Players\]"`,
 		},
+		{
+			Description:        "Missing Season",
+			Account:            model.Account{ID: "123ABC"},
+			RequestBody:        `{"players":["DW74MSY5XQ"]}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"missing required field\(s\): \[SeasonID\]"`,
+		},
 		{
 			Description:        "No Players in payload",
 			Account:            model.Account{ID: "123ABC"},
-			RequestBody:        `{"players":[]}`,
+			RequestBody:        `{"seasonId":"BJ7Q4NVRNQ","players":[]}`,
 			ExpectedStatusCode: http.StatusBadRequest,
 			ExpectedContent:    `"detail":"payload contains no players"`,
 		},
 		{
-			Description: "Invalid Player ID",
+			Description:        "Invalid Season ID",
+			Account:            model.Account{ID: "123ABC"},
+			RequestBody:        `{"seasonId":"BJ7Q4NVRN","players":["DW74MSY5XQ"]}`,
+			ExpectedStatusCode: http.StatusNotFound,
+			ExpectedContent:    `"status":"not found"`,
+		},
+		{
+			Description: "Season Not Found",
 			Account:     model.Account{ID: "123ABC"},
-			RequestBody: `{"players":["ABD123"]}`,
+			RequestBody: `{"seasonId":"BJ7Q4NVRNQ","players":["DW74MSY5XQ"]}`,
 			Mock: func(mock sqlmock.Sqlmock) {
-				mock.ExpectBegin()
-				mock.ExpectExec("UPDATE accounts SET registration_code = (.+) WHERE id = (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
-				mock.ExpectRollback()
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnError(sql.ErrNoRows)
 			},
 			ExpectedStatusCode: http.StatusNotFound,
 			ExpectedContent:    `"status":"not found"`,
 		},
 		{
-			Description: "Valid Player ID no Player ID in Account",
-			Account:     model.Account{ID: "123ABC", Players: pq.StringArray{}},
-			RequestBody: `{"players":["W4SBH35WV8"]}`,
+			Description: "Registration Not Yet Open",
+			Account:     model.Account{ID: "123ABC"},
+			RequestBody: `{"seasonId":"BJ7Q4NVRNQ","players":["DW74MSY5XQ"]}`,
 			Mock: func(mock sqlmock.Sqlmock) {
-				mock.ExpectBegin()
-				mock.ExpectExec("UPDATE accounts SET registration_code = (.+) WHERE id = (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
-				mock.ExpectRollback()
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(tomorrow, nextMonth))
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"registration is not open for this season"`,
+		},
+		{
+			Description: "Registration Closed",
+			Account:     model.Account{ID: "123ABC"},
+			RequestBody: `{"seasonId":"BJ7Q4NVRNQ","players":["DW74MSY5XQ"]}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(lastMonth, yesterday))
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"registration is not open for this season"`,
+		},
+		{
+			Description: "Invalid Player ID",
+			Account:     model.Account{ID: "123ABC"},
+			RequestBody: `{"seasonId":"BJ7Q4NVRNQ","players":["ABD123"]}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
 			},
 			ExpectedStatusCode: http.StatusNotFound,
 			ExpectedContent:    `"status":"not found"`,
 		},
 		{
-			Description: "Valid Player ID not in Account",
+			Description: "Valid Player ID not Guardian",
 			Account:     model.Account{ID: "123ABC", Players: pq.StringArray{"49QRBF09Y"}},
-			RequestBody: `{"players":["DW74MSY5XQ"]}`,
+			RequestBody: `{"seasonId":"BJ7Q4NVRNQ","players":["DW74MSY5XQ"]}`,
 			Mock: func(mock sqlmock.Sqlmock) {
-				mock.ExpectBegin()
-				mock.ExpectExec("UPDATE accounts SET registration_code = (.+) WHERE id = (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
-				mock.ExpectRollback()
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("123ABC", "DW74MSY5X").WillReturnError(sql.ErrNoRows)
 			},
 			ExpectedStatusCode: http.StatusNotFound,
 			ExpectedContent:    `"status":"not found"`,
 		},
 		{
-			Description: "Valid Player ID in Account",
+			Description: "View Only Guardian",
 			Account:     model.Account{ID: "123ABC", Players: pq.StringArray{"DW74MSY5X"}},
-			RequestBody: `{"players":["DW74MSY5XQ"]}`,
+			RequestBody: `{"seasonId":"BJ7Q4NVRNQ","players":["DW74MSY5XQ"]}`,
 			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("123ABC", "DW74MSY5X").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("123ABC", "DW74MSY5X", "view", time.Now()))
+			},
+			ExpectedStatusCode: http.StatusForbidden,
+		},
+		{
+			Description: "Valid Player ID creates Season Registration",
+			Account:     model.Account{ID: "123ABC", Players: pq.StringArray{"DW74MSY5X"}},
+			RequestBody: `{"seasonId":"BJ7Q4NVRNQ","players":["DW74MSY5XQ"]}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(today, tomorrow))
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("123ABC", "DW74MSY5X").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("123ABC", "DW74MSY5X", "secondary", time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM players WHERE id").WithArgs("DW74MSY5X").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("DW74MSY5X", "Leagueify", "Player", "2014-08-31", "Goalie", "", "", nil, "", "", "", "U12", false))
 				mock.ExpectBegin()
-				mock.ExpectExec("UPDATE accounts SET registration_code = (.+) WHERE id = (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
-				mock.ExpectExec("UPDATE players SET is_registered = true WHERE id = (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
-				mock.ExpectExec("INSERT INTO registrations (.+) VALUES (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE (.+) FOR UPDATE").WithArgs("123ABC", "BJ7Q4NVRN").WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec("INSERT INTO registrations (.+) VALUES (.+)").WithArgs(sqlmock.AnyArg(), "123ABC", "BJ7Q4NVRN", 0, 0).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WillReturnRows(sqlmock.NewRows(registrationPlayerColumns))
+				mock.ExpectQuery("SELECT (.+) FROM registration_adjustments WHERE (.+)").WillReturnRows(sqlmock.NewRows(registrationAdjustmentColumns))
+				mock.ExpectQuery("SELECT (.+) FROM fee_schedules WHERE season_id = (.+)").WithArgs("BJ7Q4NVRN").WillReturnRows(sqlmock.NewRows(feeScheduleColumns).AddRow("FEE1234", "BJ7Q4NVRN", "", 12000, nil, "", 0, "", 10, 0))
+				mock.ExpectQuery(divisionCapacityLock).WithArgs("BJ7Q4NVRN", "U12").WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec("INSERT INTO registration_players (.+) VALUES (.+) ON CONFLICT").WithArgs(sqlmock.AnyArg(), "DW74MSY5X", "BJ7Q4NVRN", "pending", 12000).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE registrations SET amount_due = (.+) WHERE id = (.+)").WithArgs(12000, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
 				mock.ExpectCommit()
 			},
 			ExpectedStatusCode: http.StatusOK,
 			ExpectedContent:    `"status":"successful"`,
 		},
 		{
-			Description: "Valid Player ID in Account with Multiple Player IDs",
-			Account:     model.Account{ID: "123ABC", Players: pq.StringArray{"W4SBH35WV", "DW74MSY5X"}},
-			RequestBody: `{"players":["DW74MSY5XQ"]}`,
+			Description: "Full Division Waitlists Player",
+			Account:     model.Account{ID: "123ABC", Players: pq.StringArray{"DW74MSY5X"}},
+			RequestBody: `{"seasonId":"BJ7Q4NVRNQ","players":["DW74MSY5XQ"]}`,
 			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(today, tomorrow))
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("123ABC", "DW74MSY5X").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("123ABC", "DW74MSY5X", "primary", time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM players WHERE id").WithArgs("DW74MSY5X").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("DW74MSY5X", "Leagueify", "Player", "2014-08-31", "Goalie", "", "", nil, "", "", "", "U12", false))
 				mock.ExpectBegin()
-				mock.ExpectExec("UPDATE accounts SET registration_code = (.+) WHERE id = (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
-				mock.ExpectExec("UPDATE players SET is_registered = true WHERE id = (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
-				mock.ExpectExec("INSERT INTO registrations (.+) VALUES (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE (.+) FOR UPDATE").WithArgs("123ABC", "BJ7Q4NVRN").WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec("INSERT INTO registrations (.+) VALUES (.+)").WithArgs(sqlmock.AnyArg(), "123ABC", "BJ7Q4NVRN", 0, 0).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WillReturnRows(sqlmock.NewRows(registrationPlayerColumns))
+				mock.ExpectQuery("SELECT (.+) FROM registration_adjustments WHERE (.+)").WillReturnRows(sqlmock.NewRows(registrationAdjustmentColumns))
+				mock.ExpectQuery("SELECT (.+) FROM fee_schedules WHERE season_id = (.+)").WithArgs("BJ7Q4NVRN").WillReturnRows(sqlmock.NewRows(feeScheduleColumns).AddRow("FEE1234", "BJ7Q4NVRN", "", 12000, nil, "", 0, "", 10, 0))
+				expectDivisionCapacity(mock, "U12", 12, 12, 0, 0)
+				mock.ExpectExec("INSERT INTO registration_players (.+) VALUES (.+) ON CONFLICT").WithArgs(sqlmock.AnyArg(), "DW74MSY5X", "BJ7Q4NVRN", "waitlisted", 12000).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE registrations SET amount_due = (.+) WHERE id = (.+)").WithArgs(0, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
 				mock.ExpectCommit()
 			},
 			ExpectedStatusCode: http.StatusOK,
 			ExpectedContent:    `"status":"successful"`,
 		},
 		{
-			Description: "Valid Player ID add to Existing Registration",
-			Account:     model.Account{ID: "123ABC", Players: pq.StringArray{"W4SBH35WV", "DW74MSY5X"}, RegistrationCode: "123ABC"},
-			RequestBody: `{"players":["DW74MSY5XQ"]}`,
+			Description: "Valid Player ID add to Existing Season Registration",
+			Account:     model.Account{ID: "123ABC", Players: pq.StringArray{"W4SBH35WV", "DW74MSY5X"}},
+			RequestBody: `{"seasonId":"BJ7Q4NVRNQ","players":["DW74MSY5XQ"]}`,
 			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, today))
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("123ABC", "DW74MSY5X").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("123ABC", "DW74MSY5X", "primary", time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM players WHERE id").WithArgs("DW74MSY5X").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("DW74MSY5X", "Leagueify", "Player", "2014-08-31", "Goalie", "", "", nil, "", "", "", "U12", false))
 				mock.ExpectBegin()
-				mock.ExpectExec("UPDATE accounts SET registration_code = (.+) WHERE id = (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
-				mock.ExpectExec("UPDATE players SET is_registered = true WHERE id = (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
-				mock.ExpectQuery("SELECT player_ids FROM registrations WHERE id = (.+)").WillReturnRows(sqlmock.NewRows([]string{"player_ids"}).AddRow("{'W4SBH35WV'}"))
-				mock.ExpectExec("UPDATE registrations SET player_ids = (.+) WHERE id = (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE (.+) FOR UPDATE").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationPlayerColumns).AddRow("6KQJ7ZPR2", "W4SBH35WV", "BJ7Q4NVRN", "Leagueify", "Sibling", "U12", "confirmed", 12000, 0, nil, time.Now(), time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM registration_adjustments WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationAdjustmentColumns))
+				mock.ExpectQuery("SELECT (.+) FROM fee_schedules WHERE season_id = (.+)").WithArgs("BJ7Q4NVRN").WillReturnRows(sqlmock.NewRows(feeScheduleColumns).AddRow("FEE1234", "BJ7Q4NVRN", "", 12000, nil, "", 0, "", 10, 20000))
+				mock.ExpectQuery(divisionCapacityLock).WithArgs("BJ7Q4NVRN", "U12").WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec("INSERT INTO registration_players (.+) VALUES (.+) ON CONFLICT").WithArgs("6KQJ7ZPR2", "DW74MSY5X", "BJ7Q4NVRN", "pending", 8000).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE registrations SET amount_due = (.+) WHERE id = (.+)").WithArgs(20000, "6KQJ7ZPR2").WillReturnResult(sqlmock.NewResult(0, 1))
 				mock.ExpectCommit()
 			},
 			ExpectedStatusCode: http.StatusOK,
-			ExpectedContent:    `"status":"successful"`,
+			ExpectedContent:    `"id":"6KQJ7ZPR2.","status":"successful"`,
+		},
+		{
+			Description: "Player Already on Season Registration",
+			Account:     model.Account{ID: "123ABC", Players: pq.StringArray{"DW74MSY5X"}},
+			RequestBody: `{"seasonId":"BJ7Q4NVRNQ","players":["DW74MSY5XQ"]}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("123ABC", "DW74MSY5X").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("123ABC", "DW74MSY5X", "primary", time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM players WHERE id").WithArgs("DW74MSY5X").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("DW74MSY5X", "Leagueify", "Player", "2014-08-31", "Goalie", "", "", nil, "", "", "", "U12", false))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE (.+) FOR UPDATE").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationPlayerRows())
+				mock.ExpectQuery("SELECT (.+) FROM registration_adjustments WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationAdjustmentColumns))
+				mock.ExpectQuery("SELECT (.+) FROM fee_schedules WHERE season_id = (.+)").WithArgs("BJ7Q4NVRN").WillReturnRows(sqlmock.NewRows(feeScheduleColumns))
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusConflict,
+			ExpectedContent:    `"detail":"player is already registered for this season"`,
+		},
+		{
+			Description: "Player Registered for Season by Another Account",
+			Account:     model.Account{ID: "123ABC", Players: pq.StringArray{"DW74MSY5X"}},
+			RequestBody: `{"seasonId":"BJ7Q4NVRNQ","players":["DW74MSY5XQ"]}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("123ABC", "DW74MSY5X").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("123ABC", "DW74MSY5X", "primary", time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM players WHERE id").WithArgs("DW74MSY5X").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("DW74MSY5X", "Leagueify", "Player", "2014-08-31", "Goalie", "", "", nil, "", "", "", "U12", false))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE (.+) FOR UPDATE").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationPlayerColumns))
+				mock.ExpectQuery("SELECT (.+) FROM registration_adjustments WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationAdjustmentColumns))
+				mock.ExpectQuery("SELECT (.+) FROM fee_schedules WHERE season_id = (.+)").WithArgs("BJ7Q4NVRN").WillReturnRows(sqlmock.NewRows(feeScheduleColumns))
+				mock.ExpectQuery(divisionCapacityLock).WithArgs("BJ7Q4NVRN", "U12").WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec("INSERT INTO registration_players (.+) VALUES (.+) ON CONFLICT").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusConflict,
+			ExpectedContent:    `"detail":"player is already registered for this season"`,
+		},
+		{
+			Description: "No Fee Schedule for Division",
+			Account:     model.Account{ID: "123ABC", Players: pq.StringArray{"DW74MSY5X"}},
+			RequestBody: `{"seasonId":"BJ7Q4NVRNQ","players":["DW74MSY5XQ"]}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("123ABC", "DW74MSY5X").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("123ABC", "DW74MSY5X", "primary", time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM players WHERE id").WithArgs("DW74MSY5X").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("DW74MSY5X", "Leagueify", "Player", "2014-08-31", "Goalie", "", "", nil, "", "", "", "U12", false))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE (.+) FOR UPDATE").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationPlayerColumns))
+				mock.ExpectQuery("SELECT (.+) FROM registration_adjustments WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationAdjustmentColumns))
+				mock.ExpectQuery("SELECT (.+) FROM fee_schedules WHERE season_id = (.+)").WithArgs("BJ7Q4NVRN").WillReturnRows(sqlmock.NewRows(feeScheduleColumns).AddRow("FEE1234", "BJ7Q4NVRN", "U8", 6000, nil, "", 0, "", 0, 0))
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusConflict,
+			ExpectedContent:    `"detail":"no fee schedule applies to the player's division"`,
 		},
 		// TODO: Add more tests
 	}
@@ -563,12 +726,13 @@ func TestRegisterPlayer(t *testing.T) {
 		// Initialize Echo and the Echo validator
 		e := echo.New()
 		e.Validator = &API{Validator: validator.New()}
-		api := &API{DB: db, Account: test.Account}
+		api := &API{DB: db}
 		reqBody := []byte(test.RequestBody)
 		req := httptest.NewRequest(http.MethodPost, "/api/players/register", bytes.NewBuffer(reqBody))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
+		setAccount(c, test.Account)
 		// Perform Request
 		if assert.NoError(t, api.registerPlayer(c)) {
 			// Assert Status Code
@@ -585,3 +749,247 @@ func TestRegisterPlayer(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	}
 }
+
+func TestRegisterPlayerInAssignedDivision(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	e := echo.New()
+	e.Validator = &API{Validator: validator.New()}
+	api := &API{DB: db}
+	// Assign the player's division, keeping the division written to the player
+	var division string
+	mock.ExpectQuery("SELECT (.+) FROM players WHERE id").WithArgs("DW74MSY5X").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("DW74MSY5X", "Leagueify", "Player", "2014-08-31", "Goalie", "", "", nil, "", "", "", "", false))
+	mock.ExpectExec("UPDATE players SET division = (.+) WHERE id = (.+) AND NOT EXISTS (.+)").WithArgs(storedArg{&division}, "DW74MSY5X").WillReturnResult(sqlmock.NewResult(0, 1))
+	req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer([]byte(`{"division":"U12"}`)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("DW74MSY5XQ")
+	if assert.NoError(t, api.setPlayerDivision(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+	// Registering the player prices them from their division's fee schedule
+	mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+	mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("123ABC", "DW74MSY5X").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("123ABC", "DW74MSY5X", "primary", time.Now()))
+	mock.ExpectQuery("SELECT (.+) FROM players WHERE id").WithArgs("DW74MSY5X").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("DW74MSY5X", "Leagueify", "Player", "2014-08-31", "Goalie", "", "", nil, "", "", "", division, false))
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM registrations WHERE (.+) FOR UPDATE").WillReturnRows(registrationRow())
+	mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationPlayerColumns))
+	mock.ExpectQuery("SELECT (.+) FROM registration_adjustments WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationAdjustmentColumns))
+	mock.ExpectQuery("SELECT (.+) FROM fee_schedules WHERE season_id = (.+)").WithArgs("BJ7Q4NVRN").WillReturnRows(
+		sqlmock.NewRows(feeScheduleColumns).
+			AddRow("FEE1234", "BJ7Q4NVRN", "", 12000, nil, "", 0, "", 0, 0).
+			AddRow("FEE5678", "BJ7Q4NVRN", "U12", 9000, nil, "", 0, "", 0, 0),
+	)
+	mock.ExpectQuery(divisionCapacityLock).WithArgs("BJ7Q4NVRN", "U12").WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec("INSERT INTO registration_players (.+) VALUES (.+) ON CONFLICT").WithArgs("6KQJ7ZPR2", "DW74MSY5X", "BJ7Q4NVRN", "pending", 9000).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE registrations SET amount_due = (.+) WHERE id = (.+)").WithArgs(9000, "6KQJ7ZPR2").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	req = httptest.NewRequest(http.MethodPost, "/api/players/register", bytes.NewBuffer([]byte(`{"seasonId":"BJ7Q4NVRNQ","players":["DW74MSY5XQ"]}`)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	setAccount(c, model.Account{ID: "123ABC", Players: pq.StringArray{"DW74MSY5X"}})
+	if assert.NoError(t, api.registerPlayer(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+	// Assert All Expectations Met
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetPlayerDivision(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		PlayerID           string
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description:        "Invalid Player ID",
+			PlayerID:           "DW74MSY5X",
+			RequestBody:        `{"division":"U12"}`,
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Player Not Found",
+			PlayerID:    "DW74MSY5XQ",
+			RequestBody: `{"division":"U12"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM players WHERE id").WithArgs("DW74MSY5X").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Player Registered For Season",
+			PlayerID:    "DW74MSY5XQ",
+			RequestBody: `{"division":"U12"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM players WHERE id").WithArgs("DW74MSY5X").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("DW74MSY5X", "Leagueify", "Player", "2014-08-31", "Goalie", "", "", nil, "", "", "", "U10", true))
+				mock.ExpectExec("UPDATE players SET division = (.+) WHERE id = (.+) AND NOT EXISTS (.+)").WithArgs("U12", "DW74MSY5X").WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			ExpectedStatusCode: http.StatusConflict,
+			ExpectedContent:    `"detail":"player is registered for a season"`,
+		},
+		{
+			Description: "Division Assigned",
+			PlayerID:    "DW74MSY5XQ",
+			RequestBody: `{"division":"U12"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM players WHERE id").WithArgs("DW74MSY5X").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("DW74MSY5X", "Leagueify", "Player", "2014-08-31", "Goalie", "", "", nil, "", "", "", "", false))
+				mock.ExpectExec("UPDATE players SET division = (.+) WHERE id = (.+) AND NOT EXISTS (.+)").WithArgs("U12", "DW74MSY5X").WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"status":"successful"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := &API{DB: db}
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer([]byte(test.RequestBody)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/players/:id/division")
+		c.SetParamNames("id")
+		c.SetParamValues(test.PlayerID)
+		// Perform Request
+		if assert.NoError(t, api.setPlayerDivision(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestUpdatePlayer(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Error: '%s' was not expected when creating the mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description: "Not Guardian",
+			RequestBody: `{"school":"Leagueify Elementary"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("123ABC", "49QRBF09Y").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "View Only Guardian",
+			RequestBody: `{"school":"Leagueify Elementary"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("123ABC", "49QRBF09Y", "view", time.Now()))
+			},
+			ExpectedStatusCode: http.StatusForbidden,
+		},
+		{
+			Description: "Invalid Jersey Size",
+			RequestBody: `{"jerseySize":"XXXXL"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("123ABC", "49QRBF09Y", "secondary", time.Now()))
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			Description: "Registered Player Date Of Birth",
+			RequestBody: `{"dateOfBirth":"2015-01-01"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("123ABC", "49QRBF09Y", "primary", time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM players WHERE id = (.+)").WithArgs("49QRBF09Y").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("49QRBF09Y", "Leagueify", "Test", "2016-12-10", "goalie", "female", "", nil, "", "", "", "", true))
+			},
+			ExpectedStatusCode: http.StatusConflict,
+			ExpectedContent:    `"detail":"date of birth and gender cannot change once a player is registered"`,
+		},
+		{
+			Description: "Invalid Position",
+			RequestBody: `{"position":"skate"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("123ABC", "49QRBF09Y", "primary", time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM players WHERE id = (.+)").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("49QRBF09Y", "Leagueify", "Test", "2016-12-10", "goalie", "", "", nil, "", "", "", "", false))
+				mock.ExpectQuery("SELECT \\* FROM positions").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("1", "skater").AddRow("2", "goalie"))
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"invalid position"`,
+		},
+		{
+			Description: "Registered Player Details Updated",
+			RequestBody: `{"dateOfBirth":"2016-12-10","jerseySize":"YM","jerseyNumber":7,"school":"Leagueify Elementary","grade":"3"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("123ABC", "49QRBF09Y", "secondary", time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM players WHERE id = (.+)").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("49QRBF09Y", "Leagueify", "Test", "2016-12-10", "goalie", "", "", nil, "", "", "", "", true))
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE players SET (.+) WHERE id = (.+)").WithArgs("Leagueify", "Test", "2016-12-10", "goalie", "", "YM", 7, "Leagueify Elementary", "3", "49QRBF09Y").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"ID":"49QRBF09YA",(.+),"jerseySize":"YM","jerseyNumber":7,"school":"Leagueify Elementary","grade":"3"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := &API{DB: db}
+		req := httptest.NewRequest(http.MethodPatch, "/api/players/49QRBF09YA", bytes.NewBufferString(test.RequestBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, model.Account{ID: "123ABC", Players: pq.StringArray{"49QRBF09Y"}})
+		c.SetParamNames("id")
+		c.SetParamValues("49QRBF09YA")
+		// Perform Request
+		if assert.NoError(t, api.updatePlayer(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Assert Response Content
+			assert.Regexp(t, regexp.MustCompile(test.ExpectedContent), rec.Body.String(), test.Description)
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet(), test.Description)
+	}
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"net/http"
 
+	"github.com/Leagueify/api/internal/auth"
 	"github.com/Leagueify/api/internal/model"
 	"github.com/Leagueify/api/internal/util"
 	"github.com/labstack/echo/v4"
@@ -10,7 +11,7 @@ import (
 
 func (api *API) Positions(e *echo.Group) {
 	e.GET("/positions", api.requiresAuth(api.listPositions))
-	e.POST("/positions", api.requiresAdmin(api.createPosition))
+	e.POST("/positions", api.requiresPermission(auth.PermissionManageLeague, api.createPosition))
 }
 
 func (api *API) createPosition(c echo.Context) (err error) {
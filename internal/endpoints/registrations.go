@@ -0,0 +1,398 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Leagueify/api/internal/auth"
+	"github.com/Leagueify/api/internal/fees"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// defaultRegistrationLimit is the page size used when no limit is requested.
+	defaultRegistrationLimit = 50
+	// maxRegistrationLimit caps the page size of registration listings.
+	maxRegistrationLimit = 100
+)
+
+func (api *API) Registrations(e *echo.Group) {
+	e.GET("/accounts/me/registrations", api.requiresAuth(api.listAccountRegistrations))
+	e.GET("/registrations", api.requiresPermission(auth.PermissionViewRegistrations, api.listRegistrations))
+	e.POST("/registrations/quote", api.requiresAuth(api.quoteRegistration))
+	e.GET("/registrations/:id", api.requiresAuth(api.getRegistration))
+	e.POST("/registrations/:id/players", api.requiresPermission(auth.PermissionManageRegistrations, api.addRegistrationPlayer))
+	e.DELETE("/registrations/:id/players/:player", api.requiresPermission(auth.PermissionManageRegistrations, api.removeRegistrationPlayer))
+}
+
+// addRegistrationPlayer adds a player to a registration on behalf of the
+// account holder, who must be a guardian able to register the player. The
+// player is priced as if registered today, but the season's registration
+// window is not enforced.
+func (api *API) addRegistrationPlayer(c echo.Context) error {
+	registration, ok := api.pathRegistration(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	payload := model.RegistrationPlayerAddition{}
+	// bind payload to model
+	if err := c.Bind(&payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	// the account holder must be a guardian able to register the player
+	players, code := api.registrablePlayers(registration.AccountID, []string{payload.PlayerID})
+	if code != http.StatusOK {
+		return util.SendStatus(code, c, "")
+	}
+	player := players[0]
+	// Begin Transaction
+	tx, err := api.DB.BeginTransaction()
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	defer tx.Rollback()
+	// quote from the locked registration so concurrent changes to its
+	// players cannot be overwritten by the amount due written below
+	registration, err = api.DB.GetRegistrationForUpdate(tx, registration.ID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	quote, err := api.priceRegistration(registration, []model.Player{player})
+	if err != nil {
+		return sendQuoteError(c, err)
+	}
+	// players added without a status are placed or waitlisted like any
+	// other registration
+	if payload.Status == "" {
+		statuses, err := api.placePlayers(tx, registration.SeasonID, quote.Players)
+		if err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+		payload.Status = statuses[player.ID]
+	}
+	amountDue := quote.AmountDue
+	if payload.Status == model.RegistrationWaitlisted {
+		amountDue = max(amountDue-quote.Players[0].Amount, 0)
+	}
+	added, err := api.DB.AddRegistrationPlayer(tx, model.RegistrationPlayer{
+		RegistrationID: registration.ID,
+		PlayerID:       player.ID,
+		SeasonID:       registration.SeasonID,
+		Status:         payload.Status,
+		Amount:         quote.Players[0].Amount,
+	})
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if !added {
+		return util.SendStatus(http.StatusConflict, c, "player is already registered for this season")
+	}
+	if err := api.DB.SetRegistrationAmountDue(tx, registration.ID, amountDue); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if err := tx.Commit(); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusCreated,
+		map[string]string{
+			"status": "successful",
+		},
+	)
+}
+
+// getRegistration returns a registration with its players and adjustments.
+// Account holders may read their own registrations, and accounts able to view
+// registrations may read any.
+func (api *API) getRegistration(c echo.Context) error {
+	registration, ok := api.pathRegistration(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	account := getAccount(c)
+	if registration.AccountID != account.ID &&
+		!api.hasPermission(account, auth.PermissionViewRegistrations) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if err := api.loadRegistrationPlayers(&registration); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	adjustments, err := api.DB.ListRegistrationAdjustments(registration.ID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	registration.Adjustments = adjustments
+	return c.JSON(http.StatusOK, signedRegistration(registration))
+}
+
+// listAccountRegistrations returns the current account's registrations with
+// their players.
+func (api *API) listAccountRegistrations(c echo.Context) error {
+	registrations, err := api.DB.ListAccountRegistrations(getAccount(c).ID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return api.sendRegistrations(c, registrations)
+}
+
+func (api *API) listRegistrations(c echo.Context) error {
+	filter := model.RegistrationFilter{}
+	// bind query parameters to model
+	if err := c.Bind(&filter); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid query parameters")
+	}
+	// validate query parameters against model
+	if err := c.Validate(filter); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	if filter.SeasonID != "" {
+		if !util.VerifyToken(filter.SeasonID) {
+			return util.SendStatus(http.StatusBadRequest, c, "invalid season")
+		}
+		filter.SeasonID = filter.SeasonID[:len(filter.SeasonID)-1]
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultRegistrationLimit
+	}
+	if filter.Limit > maxRegistrationLimit {
+		filter.Limit = maxRegistrationLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	registrations, err := api.DB.ListRegistrations(filter)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return api.sendRegistrations(c, registrations)
+}
+
+// quoteRegistration prices registering players for a season today without
+// registering them, so families can see the price before committing.
+func (api *API) quoteRegistration(c echo.Context) error {
+	payload := model.PlayerRegistration{}
+	// bind payload to model
+	if err := c.Bind(&payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	if len(payload.Players) < 1 {
+		return util.SendStatus(http.StatusBadRequest, c, "payload contains no players")
+	}
+	if !util.VerifyToken(payload.SeasonID) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if _, err := api.DB.GetSeason(payload.SeasonID); err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	seasonID := payload.SeasonID[:len(payload.SeasonID)-1]
+	account := getAccount(c)
+	players, code := api.registrablePlayers(account.ID, payload.Players)
+	if code != http.StatusOK {
+		return util.SendStatus(code, c, "")
+	}
+	// Begin Transaction, the quote is never committed
+	tx, err := api.DB.BeginTransaction()
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	defer tx.Rollback()
+	registration, err := api.DB.GetSeasonRegistration(tx, account.ID, seasonID)
+	if errors.Is(err, sql.ErrNoRows) {
+		registration = model.Registration{SeasonID: seasonID}
+		err = nil
+	}
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	quote, err := api.priceRegistration(registration, players)
+	if err != nil {
+		return sendQuoteError(c, err)
+	}
+	quote.SeasonID = payload.SeasonID
+	for index := range quote.Players {
+		quote.Players[index].PlayerID = util.ReturnSignedToken(quote.Players[index].PlayerID)
+	}
+	return c.JSON(http.StatusOK, quote)
+}
+
+// removeRegistrationPlayer withdraws a player from a registration without a
+// refund request. The player stays on the registration as withdrawn, their
+// fee is taken off the amount due and their place is offered to the next
+// player on the waitlist.
+func (api *API) removeRegistrationPlayer(c echo.Context) error {
+	registration, ok := api.pathRegistration(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	playerID := c.Param("player")
+	if !util.VerifyToken(playerID) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	// Begin Transaction
+	tx, err := api.DB.BeginTransaction()
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	defer tx.Rollback()
+	if _, err := api.DB.GetRegistrationForUpdate(tx, registration.ID); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	players, err := api.DB.ListRegistrationPlayers(registration.ID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	adjustments, err := api.DB.ListRegistrationAdjustments(registration.ID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	player, ok := registrationPlayer(players, playerID[:len(playerID)-1])
+	if !ok || !fees.Active(player.Status) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	player.Status = model.RegistrationWithdrawn
+	for index := range players {
+		if players[index].PlayerID == player.PlayerID {
+			players[index] = player
+		}
+	}
+	offers, withdrawn, err := api.releasePlace(
+		tx, registration, player, fees.AmountDue(players, adjustments),
+	)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if !withdrawn {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if err := tx.Commit(); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if err := api.notifyWaitlistOffers(offers); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// loadRegistrationPlayers fills in the players on the registration.
+func (api *API) loadRegistrationPlayers(registration *model.Registration) error {
+	players, err := api.DB.ListRegistrationPlayers(registration.ID)
+	if err != nil {
+		return err
+	}
+	registration.Players = players
+	return nil
+}
+
+// priceRegistration quotes players being added to the registration today,
+// less its coupons and financial aid. Registrations without an ID have not
+// been created and hold no players or adjustments.
+func (api *API) priceRegistration(registration model.Registration, players []model.Player) (model.RegistrationQuote, error) {
+	registered := []model.RegistrationPlayer{}
+	adjustments := []model.RegistrationAdjustment{}
+	if registration.ID != "" {
+		var err error
+		if registered, err = api.DB.ListRegistrationPlayers(registration.ID); err != nil {
+			return model.RegistrationQuote{}, err
+		}
+		if adjustments, err = api.DB.ListRegistrationAdjustments(registration.ID); err != nil {
+			return model.RegistrationQuote{}, err
+		}
+	}
+	schedules, err := api.DB.ListFeeSchedules(registration.SeasonID)
+	if err != nil {
+		return model.RegistrationQuote{}, err
+	}
+	quote, err := fees.Quote(schedules, time.Now().Format(time.DateOnly), registered, players)
+	if err != nil {
+		return quote, err
+	}
+	quote = fees.Discount(quote, adjustments)
+	quote.SeasonID = registration.SeasonID
+	return quote, nil
+}
+
+// pathRegistration returns the registration in the id path parameter.
+func (api *API) pathRegistration(c echo.Context) (model.Registration, bool) {
+	registrationID := c.Param("id")
+	if !util.VerifyToken(registrationID) {
+		return model.Registration{}, false
+	}
+	registration, err := api.DB.GetRegistration(registrationID[:len(registrationID)-1])
+	if err != nil {
+		return model.Registration{}, false
+	}
+	return registration, true
+}
+
+// registrablePlayers returns the players the account may register, or the
+// status to respond with when it may not register one of them.
+func (api *API) registrablePlayers(accountID string, playerIDs []string) ([]model.Player, int) {
+	players := []model.Player{}
+	for _, playerID := range playerIDs {
+		if !util.VerifyToken(playerID) {
+			return nil, http.StatusNotFound
+		}
+		playerID = playerID[:len(playerID)-1]
+		// Validate account is a guardian who can register the player
+		guardian, err := api.DB.GetGuardian(accountID, playerID)
+		if err != nil {
+			return nil, http.StatusNotFound
+		}
+		if !auth.GuardianPermission(guardian.Permission).CanRegister() {
+			return nil, http.StatusForbidden
+		}
+		player, err := api.DB.GetPlayer(playerID)
+		if err != nil {
+			return nil, http.StatusNotFound
+		}
+		players = append(players, player)
+	}
+	return players, http.StatusOK
+}
+
+func (api *API) sendRegistrations(c echo.Context, registrations []model.Registration) error {
+	for index := range registrations {
+		if err := api.loadRegistrationPlayers(&registrations[index]); err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+		registrations[index] = signedRegistration(registrations[index])
+	}
+	return c.JSON(http.StatusOK, registrations)
+}
+
+// sendQuoteError responds to an error pricing a registration.
+func sendQuoteError(c echo.Context, err error) error {
+	if errors.Is(err, fees.ErrAlreadyRegistered) || errors.Is(err, fees.ErrNoSchedule) {
+		return util.SendStatus(http.StatusConflict, c, err.Error())
+	}
+	return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+}
+
+// signedRegistration signs the IDs of the registration before it is returned.
+func signedRegistration(registration model.Registration) model.Registration {
+	registration.ID = util.ReturnSignedToken(registration.ID)
+	if registration.AccountID != "" {
+		registration.AccountID = util.ReturnSignedToken(registration.AccountID)
+	}
+	if registration.SeasonID != "" {
+		registration.SeasonID = util.ReturnSignedToken(registration.SeasonID)
+	}
+	for index := range registration.Players {
+		registration.Players[index].PlayerID = util.ReturnSignedToken(registration.Players[index].PlayerID)
+	}
+	for index := range registration.Adjustments {
+		registration.Adjustments[index] = signedAdjustment(registration.Adjustments[index])
+	}
+	return registration
+}
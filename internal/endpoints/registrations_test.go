@@ -0,0 +1,610 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Leagueify/api/internal/database/postgres"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var registrationColumns = []string{"id", "account_id", "season_id", "amount_due", "amount_paid", "created_at"}
+
+var registrationPlayerColumns = []string{"registration_id", "player_id", "season_id", "first_name", "last_name", "division", "status", "amount", "retained", "offer_expires_at", "created_at", "updated_at"}
+
+var registrationAdjustmentColumns = []string{"id", "registration_id", "kind", "coupon_id", "financial_aid_id", "description", "amount", "created_at"}
+
+var feeScheduleColumns = []string{"id", "season_id", "division", "base_fee", "early_bird_fee", "early_bird_ends", "late_fee", "late_fee_starts", "sibling_discount", "family_max"}
+
+func registrationPlayerRows() *sqlmock.Rows {
+	return sqlmock.NewRows(registrationPlayerColumns).AddRow("6KQJ7ZPR2", "DW74MSY5X", "BJ7Q4NVRN", "Leagueify", "Player", "U12", "pending", 10000, 0, nil, time.Now(), time.Now())
+}
+
+func TestListAccountRegistrations(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description: "No Registrations",
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE account_id = (.+)").WithArgs("ERCXNX5").WillReturnRows(sqlmock.NewRows(registrationColumns))
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `^\[\]`,
+		},
+		{
+			Description: "Registration With Balance",
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE account_id = (.+)").WithArgs("ERCXNX5").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationPlayerRows())
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    fmt.Sprintf(`"id":"%s","accountId":"%s","seasonId":"%s",(.+)"balance":0,(.+)"players":\[{"playerId":"%s","firstName":"Leagueify","lastName":"Player","division":"U12","status":"pending","amount":10000`, util.ReturnSignedToken("6KQJ7ZPR2"), util.ReturnSignedToken("123ABC"), util.ReturnSignedToken("BJ7Q4NVRN"), util.ReturnSignedToken("DW74MSY5X")),
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodGet, "/api/accounts/me/registrations", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, model.Account{ID: "ERCXNX5"})
+		// Perform Request
+		if assert.NoError(t, api.listAccountRegistrations(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestListRegistrations(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		Query              string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description:        "Invalid Limit",
+			Query:              "limit=many",
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"invalid query parameters"`,
+		},
+		{
+			Description:        "Invalid Status",
+			Query:              "status=lapsed",
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			Description:        "Invalid Balance",
+			Query:              "balance=some",
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			Description:        "Invalid Season",
+			Query:              "season=BJ7Q4NVRN",
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"invalid season"`,
+		},
+		{
+			Description: "No Filters",
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations (.+) LIMIT (.+) OFFSET (.+)").WithArgs("", "", "", "", defaultRegistrationLimit, 0).WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationPlayerRows())
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"status":"pending"`,
+		},
+		{
+			Description: "Filters and Search",
+			Query:       fmt.Sprintf("season=%s&status=waitlisted&balance=owing&search=smith&limit=500&offset=10", util.ReturnSignedToken("BJ7Q4NVRN")),
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations (.+) LIMIT (.+) OFFSET (.+)").WithArgs("BJ7Q4NVRN", "waitlisted", "owing", "smith", maxRegistrationLimit, 10).WillReturnRows(sqlmock.NewRows(registrationColumns))
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `^\[\]`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/registrations?%s", test.Query), nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		// Perform Request
+		if assert.NoError(t, api.listRegistrations(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestGetRegistration(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		ID                 string
+		Account            model.Account
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description:        "Invalid Registration ID",
+			ID:                 "6KQJ7ZPR2",
+			Account:            model.Account{ID: "123ABC"},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Registration Not Found",
+			ID:          util.ReturnSignedToken("6KQJ7ZPR2"),
+			Account:     model.Account{ID: "123ABC"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Another Account's Registration",
+			ID:          util.ReturnSignedToken("6KQJ7ZPR2"),
+			Account:     model.Account{ID: "ERCXNX5"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Own Registration",
+			ID:          util.ReturnSignedToken("6KQJ7ZPR2"),
+			Account:     model.Account{ID: "123ABC"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationPlayerRows())
+				mock.ExpectQuery("SELECT (.+) FROM registration_adjustments WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationAdjustmentColumns))
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"players":\[{(.+)"status":"pending"`,
+		},
+		{
+			Description: "Registrar Reads Any Registration",
+			ID:          util.ReturnSignedToken("6KQJ7ZPR2"),
+			Account:     model.Account{ID: "ERCXNX5", Roles: pq.StringArray{"registrar"}},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationColumns).AddRow("6KQJ7ZPR2", "123ABC", "BJ7Q4NVRN", 15000, 5000, time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationPlayerColumns))
+				mock.ExpectQuery("SELECT (.+) FROM registration_adjustments WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationAdjustmentColumns))
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"amountDue":15000,"amountPaid":5000,"balance":10000`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, test.Account)
+		c.SetPath("/api/registrations/:id")
+		c.SetParamNames("id")
+		c.SetParamValues(test.ID)
+		// Perform Request
+		if assert.NoError(t, api.getRegistration(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestAddRegistrationPlayer(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description: "Invalid Status",
+			RequestBody: `{"playerId":"DW74MSY5XQ","status":"refunded"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			Description: "Account Holder Not Guardian",
+			RequestBody: `{"playerId":"DW74MSY5XQ"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("123ABC", "DW74MSY5X").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Account Holder Cannot Register Player",
+			RequestBody: `{"playerId":"DW74MSY5XQ"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("123ABC", "DW74MSY5X").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("123ABC", "DW74MSY5X", "view", time.Now()))
+			},
+			ExpectedStatusCode: http.StatusForbidden,
+		},
+		{
+			Description: "Player Not Found",
+			RequestBody: `{"playerId":"DW74MSY5XQ"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("123ABC", "DW74MSY5X").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("123ABC", "DW74MSY5X", "primary", time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM players WHERE id").WithArgs("DW74MSY5X").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Player Already Registered",
+			RequestBody: `{"playerId":"DW74MSY5XQ"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("123ABC", "DW74MSY5X").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("123ABC", "DW74MSY5X", "primary", time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM players WHERE id").WithArgs("DW74MSY5X").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("DW74MSY5X", "Leagueify", "Player", "2014-08-31", "Goalie", "", "", nil, "", "", "", "", true))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+) FOR UPDATE").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationPlayerRows())
+				mock.ExpectQuery("SELECT (.+) FROM registration_adjustments WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationAdjustmentColumns))
+				mock.ExpectQuery("SELECT (.+) FROM fee_schedules WHERE season_id = (.+)").WithArgs("BJ7Q4NVRN").WillReturnRows(sqlmock.NewRows(feeScheduleColumns))
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusConflict,
+			ExpectedContent:    `"detail":"player is already registered for this season"`,
+		},
+		{
+			Description: "Player Registered Under Another Account",
+			RequestBody: `{"playerId":"DW74MSY5XQ"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("123ABC", "DW74MSY5X").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("123ABC", "DW74MSY5X", "primary", time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM players WHERE id").WithArgs("DW74MSY5X").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("DW74MSY5X", "Leagueify", "Player", "2014-08-31", "Goalie", "", "", nil, "", "", "", "", true))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+) FOR UPDATE").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationPlayerColumns))
+				mock.ExpectQuery("SELECT (.+) FROM registration_adjustments WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationAdjustmentColumns))
+				mock.ExpectQuery("SELECT (.+) FROM fee_schedules WHERE season_id = (.+)").WithArgs("BJ7Q4NVRN").WillReturnRows(sqlmock.NewRows(feeScheduleColumns))
+				mock.ExpectQuery(divisionCapacityLock).WithArgs("BJ7Q4NVRN", "").WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec("INSERT INTO registration_players (.+) ON CONFLICT").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusConflict,
+			ExpectedContent:    `"detail":"player is already registered for this season"`,
+		},
+		{
+			Description: "Player Added as Confirmed",
+			RequestBody: `{"playerId":"DW74MSY5XQ","status":"confirmed"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("123ABC", "DW74MSY5X").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("123ABC", "DW74MSY5X", "primary", time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM players WHERE id").WithArgs("DW74MSY5X").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("DW74MSY5X", "Leagueify", "Player", "2014-08-31", "Goalie", "", "", nil, "", "", "", "", false))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+) FOR UPDATE").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationPlayerColumns).AddRow("6KQJ7ZPR2", "W4SBH35WV", "BJ7Q4NVRN", "Leagueify", "Sibling", "", "confirmed", 10000, 0, nil, time.Now(), time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM registration_adjustments WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationAdjustmentColumns))
+				mock.ExpectQuery("SELECT (.+) FROM fee_schedules WHERE season_id = (.+)").WithArgs("BJ7Q4NVRN").WillReturnRows(sqlmock.NewRows(feeScheduleColumns).AddRow("FEE1234", "BJ7Q4NVRN", "", 10000, nil, "", 0, "", 20, 0))
+				mock.ExpectExec("INSERT INTO registration_players (.+) ON CONFLICT").WithArgs("6KQJ7ZPR2", "DW74MSY5X", "BJ7Q4NVRN", "confirmed", 8000).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE registrations SET amount_due = (.+) WHERE id = (.+)").WithArgs(18000, "6KQJ7ZPR2").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			ExpectedStatusCode: http.StatusCreated,
+			ExpectedContent:    `"status":"successful"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer([]byte(test.RequestBody)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, model.Account{ID: "ERCXNX5", IsAdmin: true})
+		c.SetPath("/api/registrations/:id/players")
+		c.SetParamNames("id")
+		c.SetParamValues(util.ReturnSignedToken("6KQJ7ZPR2"))
+		// Perform Request
+		if assert.NoError(t, api.addRegistrationPlayer(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestRemoveRegistrationPlayer(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		PlayerID           string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+	}{
+		{
+			Description: "Invalid Player ID",
+			PlayerID:    "DW74MSY5X",
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Player Not On Registration",
+			PlayerID:    "DW74MSY5XQ",
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+) FOR UPDATE").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationPlayerColumns).AddRow("6KQJ7ZPR2", "W4SBH35WV", "BJ7Q4NVRN", "Leagueify", "Sibling", "U12", "confirmed", 8000, 0, nil, time.Now(), time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM registration_adjustments WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationAdjustmentColumns))
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Player Withdrawn And Place Offered",
+			PlayerID:    "DW74MSY5XQ",
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+) FOR UPDATE").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationPlayerRows().AddRow("6KQJ7ZPR2", "W4SBH35WV", "BJ7Q4NVRN", "Leagueify", "Sibling", "U12", "confirmed", 8000, 0, nil, time.Now(), time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM registration_adjustments WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationAdjustmentColumns).AddRow("8AJD3KXN5", "6KQJ7ZPR2", "aid", "", "2FQ9HRB7T", "Financial aid", 2000, time.Now()))
+				expectDivisionCapacity(mock, "U12", 12, 12, 0, 1)
+				mock.ExpectExec("UPDATE registration_players SET status = (.+) WHERE (.+)").WithArgs("withdrawn", 0, "6KQJ7ZPR2", "DW74MSY5X").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE players SET team = '' WHERE id = (.+)").WithArgs("DW74MSY5X").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE registrations SET amount_due = (.+) WHERE id = (.+)").WithArgs(6000, "6KQJ7ZPR2").WillReturnResult(sqlmock.NewResult(0, 1))
+				expectDivisionCapacity(mock, "U12", 12, 11, 0, 1)
+				mock.ExpectQuery("WITH offered AS (.+) FROM offered").WithArgs("BJ7Q4NVRN", "U12", 48, 1).WillReturnRows(waitlistOfferRows())
+				mock.ExpectCommit()
+				mock.ExpectExec("INSERT INTO email_outbox (.+) VALUES (.+)").WithArgs(sqlmock.AnyArg(), "test@leagueify.org", "A place has opened up for Leagueify Sibling in 2024-2025", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			ExpectedStatusCode: http.StatusNoContent,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodDelete, "/", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, model.Account{ID: "ERCXNX5", IsAdmin: true})
+		c.SetPath("/api/registrations/:id/players/:player")
+		c.SetParamNames("id", "player")
+		c.SetParamValues(util.ReturnSignedToken("6KQJ7ZPR2"), test.PlayerID)
+		// Perform Request
+		if assert.NoError(t, api.removeRegistrationPlayer(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestQuoteRegistration(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description:        "No Players in payload",
+			RequestBody:        `{"seasonId":"BJ7Q4NVRNQ","players":[]}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"payload contains no players"`,
+		},
+		{
+			Description: "Season Not Found",
+			RequestBody: `{"seasonId":"BJ7Q4NVRNQ","players":["DW74MSY5XQ"]}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "View Only Guardian",
+			RequestBody: `{"seasonId":"BJ7Q4NVRNQ","players":["DW74MSY5XQ"]}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(tomorrow, nextMonth))
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("123ABC", "DW74MSY5X").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("123ABC", "DW74MSY5X", "view", time.Now()))
+			},
+			ExpectedStatusCode: http.StatusForbidden,
+		},
+		{
+			Description: "Quote Before Registration Opens",
+			RequestBody: `{"seasonId":"BJ7Q4NVRNQ","players":["DW74MSY5XQ"]}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(tomorrow, nextMonth))
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("123ABC", "DW74MSY5X").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("123ABC", "DW74MSY5X", "secondary", time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM players WHERE id").WithArgs("DW74MSY5X").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("DW74MSY5X", "Leagueify", "Player", "2014-08-31", "Goalie", "", "", nil, "", "", "", "U12", false))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE (.+) FOR UPDATE").WithArgs("123ABC", "BJ7Q4NVRN").WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery("SELECT (.+) FROM fee_schedules WHERE season_id = (.+)").WithArgs("BJ7Q4NVRN").WillReturnRows(sqlmock.NewRows(feeScheduleColumns).AddRow("FEE1234", "BJ7Q4NVRN", "", 10000, 8000, nextMonth, 0, "", 10, 0))
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"players":\[{"playerId":"DW74MSY5XQ",(.+)"division":"U12","fee":8000,"siblingDiscount":0,"familyDiscount":0,"amount":8000}\],"subtotal":8000,"previousAmountDue":0,"discounts":0,"amountDue":8000`,
+		},
+		{
+			Description: "Quote Sibling for Existing Registration",
+			RequestBody: `{"seasonId":"BJ7Q4NVRNQ","players":["DW74MSY5XQ"]}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+				mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("123ABC", "DW74MSY5X").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("123ABC", "DW74MSY5X", "primary", time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM players WHERE id").WithArgs("DW74MSY5X").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("DW74MSY5X", "Leagueify", "Player", "2014-08-31", "Goalie", "", "", nil, "", "", "", "U12", false))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE (.+) FOR UPDATE").WithArgs("123ABC", "BJ7Q4NVRN").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationPlayerColumns).AddRow("6KQJ7ZPR2", "W4SBH35WV", "BJ7Q4NVRN", "Leagueify", "Sibling", "U12", "confirmed", 10000, 0, nil, time.Now(), time.Now()))
+				mock.ExpectQuery("SELECT (.+) FROM registration_adjustments WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationAdjustmentColumns))
+				mock.ExpectQuery("SELECT (.+) FROM fee_schedules WHERE season_id = (.+)").WithArgs("BJ7Q4NVRN").WillReturnRows(sqlmock.NewRows(feeScheduleColumns).AddRow("FEE1234", "BJ7Q4NVRN", "", 10000, nil, "", 0, "", 10, 0))
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"fee":10000,"siblingDiscount":1000,"familyDiscount":0,"amount":9000}\],"subtotal":9000,"previousAmountDue":10000,"discounts":0,"amountDue":19000`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodPost, "/api/registrations/quote", bytes.NewBuffer([]byte(test.RequestBody)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, model.Account{ID: "123ABC"})
+		// Perform Request
+		if assert.NoError(t, api.quoteRegistration(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Leagueify/api/internal/auth"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/labstack/echo/v4"
+)
+
+func (api *API) Roles(e *echo.Group) {
+	e.GET("/roles", api.requiresAuth(api.listRoles))
+	e.GET("/accounts/:id/roles", api.requiresAdmin(api.listAccountRoles))
+	e.POST("/accounts/:id/roles", api.requiresAdmin(api.grantRole))
+	e.DELETE("/accounts/:id/roles/:role", api.requiresAdmin(api.revokeRole))
+}
+
+func (api *API) grantRole(c echo.Context) error {
+	accountID := c.Param("id")
+	if !util.VerifyToken(accountID) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	payload := model.RoleGrant{}
+	// bind payload to model
+	if err := c.Bind(&payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	if !auth.ValidRole(payload.Role) {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid role")
+	}
+	if err := api.DB.GrantRole(
+		accountID[:len(accountID)-1], payload.Role, getAccount(c).ID,
+	); err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	return c.JSON(http.StatusCreated,
+		map[string]string{
+			"status": "successful",
+		},
+	)
+}
+
+func (api *API) listAccountRoles(c echo.Context) error {
+	accountID := c.Param("id")
+	if !util.VerifyToken(accountID) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	roles, err := api.DB.ListAccountRoles(accountID[:len(accountID)-1])
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusOK, roles)
+}
+
+func (api *API) listRoles(c echo.Context) error {
+	return c.JSON(http.StatusOK, auth.Roles())
+}
+
+func (api *API) revokeRole(c echo.Context) error {
+	accountID := c.Param("id")
+	if !util.VerifyToken(accountID) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if err := api.DB.RevokeRole(
+		accountID[:len(accountID)-1], c.Param("role"),
+	); err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Leagueify/api/internal/database/postgres"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrantRole(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		ID                 string
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description:        "Invalid Account ID",
+			ID:                 "12345678",
+			RequestBody:        `{"role":"coach"}`,
+			ExpectedStatusCode: http.StatusNotFound,
+			ExpectedContent:    `"status":"not found"`,
+		},
+		{
+			Description:        "Missing Role",
+			ID:                 "ERCXNX57",
+			RequestBody:        `{}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"missing required field\(s\): \[Role\]"`,
+		},
+		{
+			Description:        "Invalid Role",
+			ID:                 "ERCXNX57",
+			RequestBody:        `{"role":"superuser"}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"invalid role"`,
+		},
+		{
+			Description: "Account Not Found",
+			ID:          "ERCXNX57",
+			RequestBody: `{"role":"coach"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("INSERT INTO account_roles (.+) SELECT (.+) FROM accounts WHERE id = (.+)").WithArgs("ERCXNX5", "coach", "ADMIN01").WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+			ExpectedContent:    `"status":"not found"`,
+		},
+		{
+			Description: "Role Granted",
+			ID:          "ERCXNX57",
+			RequestBody: `{"role":"registrar"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("INSERT INTO account_roles (.+) SELECT (.+) FROM accounts WHERE id = (.+)").WithArgs("ERCXNX5", "registrar", "ADMIN01").WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			ExpectedStatusCode: http.StatusCreated,
+			ExpectedContent:    `"status":"successful"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/accounts/%s/roles", test.ID), strings.NewReader(test.RequestBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(test.ID)
+		setAccount(c, model.Account{ID: "ADMIN01", IsAdmin: true})
+		// Perform Request
+		if assert.NoError(t, api.grantRole(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestRevokeRole(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		ID                 string
+		Role               string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+	}{
+		{
+			Description:        "Invalid Account ID",
+			ID:                 "12345678",
+			Role:               "coach",
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Role Not Held",
+			ID:          "ERCXNX57",
+			Role:        "coach",
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("DELETE FROM account_roles WHERE account_id = (.+) AND role = (.+)").WithArgs("ERCXNX5", "coach").WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Role Revoked",
+			ID:          "ERCXNX57",
+			Role:        "coach",
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("DELETE FROM account_roles WHERE account_id = (.+) AND role = (.+)").WithArgs("ERCXNX5", "coach").WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			ExpectedStatusCode: http.StatusNoContent,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/accounts/%s/roles/%s", test.ID, test.Role), nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id", "role")
+		c.SetParamValues(test.ID, test.Role)
+		setAccount(c, model.Account{ID: "ADMIN01", IsAdmin: true})
+		// Perform Request
+		if assert.NoError(t, api.revokeRole(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code)
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
@@ -4,16 +4,17 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/Leagueify/api/internal/auth"
 	"github.com/Leagueify/api/internal/model"
 	"github.com/Leagueify/api/internal/util"
 	"github.com/labstack/echo/v4"
 )
 
 func (api *API) Seasons(e *echo.Group) {
-	e.POST("/seasons", api.requiresAdmin(api.createSeason))
+	e.POST("/seasons", api.requiresPermission(auth.PermissionManageSeasons, api.createSeason))
 	e.GET("/seasons", api.listSeasons)
 	e.GET("/seasons/:id", api.getSeason)
-	e.PATCH("/seasons/:id", api.requiresAdmin(api.updateSeason))
+	e.PATCH("/seasons/:id", api.requiresPermission(auth.PermissionManageSeasons, api.updateSeason))
 }
 
 func (api *API) createSeason(c echo.Context) error {
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Leagueify/api/internal/auth"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/labstack/echo/v4"
+)
+
+func (api *API) Sessions(e *echo.Group) {
+	e.GET("/accounts/sessions", api.requiresAuth(api.listSessions))
+	e.DELETE("/accounts/sessions/:id", api.requiresAuth(api.deleteSession))
+}
+
+func (api *API) deleteSession(c echo.Context) error {
+	sessionID := c.Param("id")
+	if !util.VerifyToken(sessionID) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	// sessions can only be revoked by the account they belong to
+	if err := api.DB.DeleteSession(
+		getAccount(c).ID, sessionID[:len(sessionID)-1],
+	); err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (api *API) listSessions(c echo.Context) error {
+	account := getAccount(c)
+	sessions, err := api.DB.ListSessions(account.ID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	current := util.ReturnSignedToken(account.SessionID)
+	for index := range sessions {
+		sessions[index].IsCurrent = sessions[index].ID == current
+	}
+	return c.JSON(http.StatusOK, sessions)
+}
+
+// createSession stores a hashed API key for the account and returns the key.
+func (api *API) createSession(c echo.Context, accountID, label string) (string, error) {
+	apikey := util.SecretToken()
+	session := model.Session{
+		ID:        util.SignedToken(10),
+		AccountID: accountID,
+		KeyHash:   auth.HashToken(apikey),
+		Label:     label,
+		UserAgent: c.Request().UserAgent(),
+		ExpiresAt: time.Now().Add(auth.SessionTTL),
+	}
+	if err := api.DB.CreateSession(session); err != nil {
+		return "", err
+	}
+	return apikey, nil
+}
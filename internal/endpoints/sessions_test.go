@@ -0,0 +1,148 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Leagueify/api/internal/database/postgres"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListSessions(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	now := time.Now()
+	testCases := []struct {
+		Description        string
+		Account            model.Account
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description: "Current Session Flagged",
+			Account:     model.Account{ID: "ERCXNX5", SessionID: "49QRBF09Y"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM sessions WHERE account_id = (.+)").WithArgs("ERCXNX5").WillReturnRows(sqlmock.NewRows([]string{"id", "label", "user_agent", "created_at", "last_used_at", "expires_at"}).AddRow("49QRBF09Y", "laptop", "Firefox", now, now, now.Add(time.Hour)).AddRow("QP4RD39CE", "phone", "Safari", now, now, now.Add(time.Hour)))
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"id":"49QRBF09YA","label":"laptop",(.+)"isCurrent":true},{"id":"QP4RD39CEF","label":"phone",(.+)"isCurrent":false}`,
+		},
+		{
+			Description: "Database Error",
+			Account:     model.Account{ID: "ERCXNX5", SessionID: "49QRBF09Y"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM sessions WHERE account_id = (.+)").WillReturnError(fmt.Errorf("connection refused"))
+			},
+			ExpectedStatusCode: http.StatusInternalServerError,
+			ExpectedContent:    `"status":"internal server error"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodGet, "/api/accounts/sessions", nil)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, test.Account)
+		// Perform Request
+		if assert.NoError(t, api.listSessions(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestDeleteSession(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		ID                 string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+	}{
+		{
+			Description:        "Invalid Session ID",
+			ID:                 "ABD1234",
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Session Belongs to Another Account",
+			ID:          "QP4RD39CEF",
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("DELETE FROM sessions WHERE id = (.+) AND account_id = (.+)").WithArgs("QP4RD39CE", "ERCXNX5").WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Session Revoked",
+			ID:          "QP4RD39CEF",
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("DELETE FROM sessions WHERE id = (.+) AND account_id = (.+)").WithArgs("QP4RD39CE", "ERCXNX5").WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			ExpectedStatusCode: http.StatusNoContent,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/accounts/sessions/%s", test.ID), nil)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(test.ID)
+		setAccount(c, model.Account{ID: "ERCXNX5"})
+		// Perform Request
+		if assert.NoError(t, api.deleteSession(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code)
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/Leagueify/api/internal/auth"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/labstack/echo/v4"
+)
+
+func (api *API) Teams(e *echo.Group) {
+	e.GET("/teams/:team/coaches", api.requiresPermission(auth.PermissionManageLeague, api.listTeamCoaches))
+	e.POST("/teams/:team/coaches", api.requiresPermission(auth.PermissionManageLeague, api.assignTeamCoach))
+	e.DELETE("/teams/:team/coaches/:account", api.requiresPermission(auth.PermissionManageLeague, api.removeTeamCoach))
+	e.POST("/teams/:team/players", api.requiresPermission(auth.PermissionManageLeague, api.assignTeamPlayer))
+	e.DELETE("/teams/:team/players/:player", api.requiresPermission(auth.PermissionManageLeague, api.removeTeamPlayer))
+}
+
+// assignTeamCoach makes an account holding the coach role a coach of the
+// team, giving it access to the medical information of the team's players.
+func (api *API) assignTeamCoach(c echo.Context) error {
+	team, err := url.PathUnescape(c.Param("team"))
+	if err != nil || team == "" {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	payload := model.TeamCoachAssignment{}
+	// bind payload to model
+	if err := c.Bind(&payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	if !util.VerifyToken(payload.AccountID) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if err := api.DB.AssignTeamCoach(
+		team, payload.AccountID[:len(payload.AccountID)-1], getAccount(c).ID,
+	); err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	return c.JSON(http.StatusCreated,
+		map[string]string{
+			"status": "successful",
+		},
+	)
+}
+
+// assignTeamPlayer places a registered player on the team, moving them off
+// any team they were on. Coaches of the team can then read the player's
+// medical information.
+func (api *API) assignTeamPlayer(c echo.Context) error {
+	team, err := url.PathUnescape(c.Param("team"))
+	if err != nil || team == "" {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	payload := model.TeamPlayerAssignment{}
+	// bind payload to model
+	if err := c.Bind(&payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	if !util.VerifyToken(payload.PlayerID) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if err := api.DB.AssignTeamPlayer(
+		team, payload.PlayerID[:len(payload.PlayerID)-1],
+	); err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	return c.JSON(http.StatusCreated,
+		map[string]string{
+			"status": "successful",
+		},
+	)
+}
+
+func (api *API) listTeamCoaches(c echo.Context) error {
+	team, err := url.PathUnescape(c.Param("team"))
+	if err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	coaches, err := api.DB.ListTeamCoaches(team)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusOK, coaches)
+}
+
+func (api *API) removeTeamCoach(c echo.Context) error {
+	team, err := url.PathUnescape(c.Param("team"))
+	if err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	accountID := c.Param("account")
+	if !util.VerifyToken(accountID) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if err := api.DB.RemoveTeamCoach(team, accountID[:len(accountID)-1]); err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (api *API) removeTeamPlayer(c echo.Context) error {
+	team, err := url.PathUnescape(c.Param("team"))
+	if err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	playerID := c.Param("player")
+	if !util.VerifyToken(playerID) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if err := api.DB.RemoveTeamPlayer(team, playerID[:len(playerID)-1]); err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Leagueify/api/internal/database/postgres"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// storedArg matches any string and keeps it, standing in for the column it
+// is written to.
+type storedArg struct {
+	value *string
+}
+
+func (a storedArg) Match(v driver.Value) bool {
+	data, ok := v.(string)
+	*a.value = data
+	return ok
+}
+
+func TestAssignTeamPlayer(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description:        "Missing Player",
+			RequestBody:        `{}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			Description:        "Invalid Player ID",
+			RequestBody:        `{"playerId":"49QRBF09YB"}`,
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Player Not Registered",
+			RequestBody: `{"playerId":"49QRBF09YA"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE players SET team = (.+) WHERE id = (.+) AND EXISTS (.+)").WithArgs("North Stars", "49QRBF09Y").WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Player Assigned",
+			RequestBody: `{"playerId":"49QRBF09YA"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("UPDATE players SET team = (.+) WHERE id = (.+) AND EXISTS (.+)").WithArgs("North Stars", "49QRBF09Y").WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			ExpectedStatusCode: http.StatusCreated,
+			ExpectedContent:    `"status":"successful"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer([]byte(test.RequestBody)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/teams/:team/players")
+		c.SetParamNames("team")
+		c.SetParamValues("North%20Stars")
+		// Perform Request
+		if assert.NoError(t, api.assignTeamPlayer(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Assert Response Content
+			assert.Regexp(t, regexp.MustCompile(test.ExpectedContent), rec.Body.String(), test.Description)
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet(), test.Description)
+	}
+}
+
+func TestTeamCoachReadsAssignedPlayer(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	cipher := testCipher(t)
+	record := cipher.Encrypt([]byte(`{"allergies":"peanuts","medications":"","conditions":"","notes":""}`), []byte("49QRBF09Y"))
+	e := echo.New()
+	e.Validator = &API{Validator: validator.New()}
+	api := API{DB: db, Cipher: cipher}
+	// Assign the player to the team, keeping the team written to the player
+	var team string
+	mock.ExpectExec("UPDATE players SET team = (.+) WHERE id = (.+) AND EXISTS (.+)").WithArgs(storedArg{&team}, "49QRBF09Y").WillReturnResult(sqlmock.NewResult(0, 1))
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer([]byte(`{"playerId":"49QRBF09YA"}`)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("team")
+	c.SetParamValues("North%20Stars")
+	if assert.NoError(t, api.assignTeamPlayer(c)) {
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	}
+	// The coach of that team reads the player's medical information
+	mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("COACH12", "49QRBF09Y").WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("SELECT (.+) FROM players WHERE id = (.+)").WithArgs("49QRBF09Y").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("49QRBF09Y", "Leagueify", "Test", "2016-12-10", "goalie", "", "", nil, "", "", team, "", true))
+	mock.ExpectQuery("SELECT EXISTS (.+) FROM team_coaches (.+)").WithArgs("North Stars", "COACH12").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectExec("INSERT INTO medical_access_events (.+) VALUES (.+)").WithArgs("49QRBF09Y", "COACH12", "medical_info", "192.0.2.1").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT (.+) FROM medical_records WHERE player_id = (.+)").WithArgs("49QRBF09Y").WillReturnRows(sqlmock.NewRows([]string{"player_id", "data", "updated_by", "updated_at"}).AddRow("49QRBF09Y", record, "ERCXNX5", time.Now()))
+	req = httptest.NewRequest(http.MethodGet, "/api/players/49QRBF09YA/medical", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	setAccount(c, model.Account{ID: "COACH12"})
+	c.SetParamNames("id")
+	c.SetParamValues("49QRBF09YA")
+	if assert.NoError(t, api.getMedicalInfo(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Regexp(t, regexp.MustCompile(`"allergies":"peanuts"`), rec.Body.String())
+	}
+	// Assert All Expectations Met
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
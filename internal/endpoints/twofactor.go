@@ -0,0 +1,300 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Leagueify/api/internal/auth"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/labstack/echo/v4"
+)
+
+func (api *API) TwoFactor(e *echo.Group) {
+	e.POST("/accounts/login/verify", api.verifyLogin)
+	e.GET("/accounts/me/2fa", api.requiresAuth(api.getTwoFactorStatus))
+	e.POST("/accounts/me/2fa", api.requiresAuth(api.enrollTwoFactor))
+	e.DELETE("/accounts/me/2fa", api.requiresAuth(api.disableTwoFactor))
+	e.POST("/accounts/me/2fa/verify", api.requiresAuth(api.confirmTwoFactor))
+	e.POST("/accounts/me/2fa/recovery", api.requiresAuth(api.regenerateRecoveryCodes))
+	e.DELETE("/accounts/:id/2fa", api.requiresAdmin(api.resetTwoFactor))
+}
+
+// confirmTwoFactor enables a pending enrollment once the account holder
+// proves their authenticator app produces matching codes, and returns the
+// recovery codes. Recovery codes are only ever shown once.
+func (api *API) confirmTwoFactor(c echo.Context) error {
+	account := getAccount(c)
+	payload := model.TwoFactorCode{}
+	// bind payload to model
+	if err := c.Bind(&payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	twoFactor, err := api.DB.GetTwoFactor(account.ID)
+	if err != nil || twoFactor.Enabled {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	counter, ok := auth.ValidateTOTP(twoFactor.Secret, payload.Code, time.Now(), 0)
+	if !ok {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid two-factor code")
+	}
+	recoveryCodes := auth.GenerateRecoveryCodes()
+	// Begin Transaction
+	tx, err := api.DB.BeginTransaction()
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	defer tx.Rollback()
+	if err := api.DB.EnableTwoFactor(tx, account.ID, counter); err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if err := api.DB.ReplaceRecoveryCodes(
+		tx, account.ID, hashRecoveryCodes(recoveryCodes),
+	); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if err := tx.Commit(); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusOK, model.RecoveryCodes{RecoveryCodes: recoveryCodes})
+}
+
+// disableTwoFactor turns off two-factor authentication after checking the
+// password and a current or recovery code. Administrators cannot turn it off
+// while it is required for them.
+func (api *API) disableTwoFactor(c echo.Context) error {
+	account := getAccount(c)
+	payload := model.TwoFactorDisable{}
+	// bind payload to model
+	if err := c.Bind(&payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	if api.RequireAdmin2FA && account.IsAdmin {
+		return util.SendStatus(http.StatusForbidden, c, "two-factor authentication is required for administrators")
+	}
+	if !auth.ComparePasswords(payload.Password, account.Password) {
+		return util.SendStatus(http.StatusUnauthorized, c, "")
+	}
+	twoFactor, err := api.DB.GetTwoFactor(account.ID)
+	if err != nil || !twoFactor.Enabled {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if !api.verifyTwoFactorCode(twoFactor, payload.Code) {
+		return util.SendStatus(http.StatusUnauthorized, c, "")
+	}
+	// Begin Transaction
+	tx, err := api.DB.BeginTransaction()
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	defer tx.Rollback()
+	if err := api.DB.DeleteTwoFactor(tx, account.ID); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if err := tx.Commit(); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// enrollTwoFactor issues a new secret for the account holder to add to their
+// authenticator app. The secret is not used for logins until confirmed.
+func (api *API) enrollTwoFactor(c echo.Context) error {
+	account := getAccount(c)
+	payload := model.TwoFactorSetup{}
+	// bind payload to model
+	if err := c.Bind(&payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	if !auth.ComparePasswords(payload.Password, account.Password) {
+		return util.SendStatus(http.StatusUnauthorized, c, "")
+	}
+	secret := auth.GenerateTOTPSecret()
+	if err := api.DB.CreateTwoFactor(account.ID, secret); err != nil {
+		return util.SendStatus(http.StatusConflict, c, "two-factor authentication already enabled")
+	}
+	return c.JSON(http.StatusCreated,
+		model.TwoFactorEnrollment{
+			Secret: secret,
+			URI:    auth.TOTPProvisioningURI(secret, account.Email),
+		},
+	)
+}
+
+func (api *API) getTwoFactorStatus(c echo.Context) error {
+	twoFactor, err := api.DB.GetTwoFactor(getAccount(c).ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	status := model.TwoFactorStatus{Enabled: twoFactor.Enabled}
+	if twoFactor.Enabled {
+		status.RecoveryCodesRemaining = twoFactor.RecoveryCodes
+	}
+	return c.JSON(http.StatusOK, status)
+}
+
+// regenerateRecoveryCodes replaces every recovery code for the account after
+// checking a current or recovery code.
+func (api *API) regenerateRecoveryCodes(c echo.Context) error {
+	account := getAccount(c)
+	payload := model.TwoFactorCode{}
+	// bind payload to model
+	if err := c.Bind(&payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	twoFactor, err := api.DB.GetTwoFactor(account.ID)
+	if err != nil || !twoFactor.Enabled {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if !api.verifyTwoFactorCode(twoFactor, payload.Code) {
+		return util.SendStatus(http.StatusUnauthorized, c, "")
+	}
+	recoveryCodes := auth.GenerateRecoveryCodes()
+	// Begin Transaction
+	tx, err := api.DB.BeginTransaction()
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	defer tx.Rollback()
+	if err := api.DB.ReplaceRecoveryCodes(
+		tx, account.ID, hashRecoveryCodes(recoveryCodes),
+	); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if err := tx.Commit(); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusOK, model.RecoveryCodes{RecoveryCodes: recoveryCodes})
+}
+
+// resetTwoFactor removes two-factor authentication from an account whose
+// holder has lost both their authenticator and recovery codes.
+func (api *API) resetTwoFactor(c echo.Context) error {
+	accountID := c.Param("id")
+	if !util.VerifyToken(accountID) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	// Begin Transaction
+	tx, err := api.DB.BeginTransaction()
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	defer tx.Rollback()
+	if err := api.DB.DeleteTwoFactor(tx, accountID[:len(accountID)-1]); err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if err := tx.Commit(); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// verifyLogin completes a login challenge with a current or recovery code.
+// Wrong codes count towards the account's failed logins so codes cannot be
+// guessed faster than passwords.
+func (api *API) verifyLogin(c echo.Context) error {
+	payload := model.LoginVerification{}
+	// bind payload to model
+	if err := c.Bind(&payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	if !util.VerifySecret(payload.Challenge) {
+		return util.SendStatus(http.StatusUnauthorized, c, "")
+	}
+	challenge, err := api.DB.GetLoginChallenge(auth.HashToken(payload.Challenge))
+	if err != nil {
+		return util.SendStatus(http.StatusUnauthorized, c, "")
+	}
+	attempt := model.LoginAttempt{
+		AccountID: challenge.AccountID,
+		IPAddress: c.RealIP(),
+	}
+	// Refuse accounts that are backing off or locked
+	lockout, err := api.DB.GetLoginLockout(challenge.AccountID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if wait := time.Until(lockout.LockedUntil); wait > 0 {
+		if err := api.DB.RecordLoginAttempt(attempt); err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+		return tooManyLoginAttempts(c, wait)
+	}
+	twoFactor, err := api.DB.GetTwoFactor(challenge.AccountID)
+	if err != nil {
+		return util.SendStatus(http.StatusUnauthorized, c, "")
+	}
+	if !api.verifyTwoFactorCode(twoFactor, payload.Code) {
+		return api.failLogin(c, attempt)
+	}
+	// A challenge completes a single login
+	if err := api.DB.DeleteLoginChallenge(challenge.ID); err != nil {
+		return util.SendStatus(http.StatusUnauthorized, c, "")
+	}
+	return api.completeLogin(c, attempt, lockout.FailedAttempts, challenge.Label)
+}
+
+// challengeLogin responds with a short-lived challenge that verifyLogin
+// exchanges for an API key once a two-factor code is supplied.
+func (api *API) challengeLogin(c echo.Context, accountID, label string) error {
+	token := util.SecretToken()
+	if err := api.DB.CreateLoginChallenge(model.LoginChallenge{
+		ID:        util.SignedToken(10),
+		AccountID: accountID,
+		TokenHash: auth.HashToken(token),
+		Label:     label,
+		ExpiresAt: time.Now().Add(auth.TwoFactorChallengeTTL),
+	}); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusAccepted,
+		map[string]string{
+			"status":    "two-factor authentication required",
+			"challenge": token,
+		},
+	)
+}
+
+// hashRecoveryCodes returns the digests stored in place of recovery codes.
+func hashRecoveryCodes(recoveryCodes []string) []string {
+	codeHashes := make([]string, len(recoveryCodes))
+	for index, code := range recoveryCodes {
+		codeHashes[index] = auth.HashToken(auth.NormalizeRecoveryCode(code))
+	}
+	return codeHashes
+}
+
+// verifyTwoFactorCode accepts a current authenticator code or an unused
+// recovery code. Either is accepted only once.
+func (api *API) verifyTwoFactorCode(twoFactor model.TwoFactor, code string) bool {
+	if counter, ok := auth.ValidateTOTP(
+		twoFactor.Secret, code, time.Now(), twoFactor.LastCounter,
+	); ok {
+		return api.DB.UpdateTwoFactorCounter(twoFactor.AccountID, counter) == nil
+	}
+	return api.DB.UseRecoveryCode(
+		twoFactor.AccountID, auth.HashToken(auth.NormalizeRecoveryCode(code)),
+	) == nil
+}
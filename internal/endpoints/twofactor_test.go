@@ -0,0 +1,389 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Leagueify/api/internal/auth"
+	"github.com/Leagueify/api/internal/database/postgres"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var twoFactorColumns = []string{"account_id", "secret", "enabled", "last_counter", "count"}
+
+func TestEnrollTwoFactor(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	// Setup Password
+	validPassword := "Test123!"
+	if err := auth.HashPassword(&validPassword); err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when hashing password", err)
+	}
+	testCases := []struct {
+		Description        string
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description:        "Missing Password",
+			RequestBody:        `{}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			Description:        "Incorrect Password",
+			RequestBody:        `{"password":"Test1234!"}`,
+			ExpectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			Description: "Already Enabled",
+			RequestBody: `{"password":"Test123!"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("INSERT INTO two_factor (.+) ON CONFLICT (.+) WHERE two_factor.enabled = false").WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			ExpectedStatusCode: http.StatusConflict,
+			ExpectedContent:    `"detail":"two-factor authentication already enabled"`,
+		},
+		{
+			Description: "Enrollment Started",
+			RequestBody: `{"password":"Test123!"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("INSERT INTO two_factor (.+) ON CONFLICT (.+) WHERE two_factor.enabled = false").WithArgs("ERCXNX5", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			ExpectedStatusCode: http.StatusCreated,
+			ExpectedContent:    `"uri":"otpauth://totp/Leagueify:test@leagueify.org\?algorithm=SHA1\\u0026digits=6\\u0026issuer=Leagueify\\u0026period=30\\u0026secret=[A-Z2-7]{32}"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodPost, "/api/accounts/me/2fa", bytes.NewBufferString(test.RequestBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, model.Account{ID: "ERCXNX5", Email: "test@leagueify.org", Password: validPassword})
+		// Perform Request
+		if assert.NoError(t, api.enrollTwoFactor(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Assert Response Content
+			assert.Regexp(t, regexp.MustCompile(test.ExpectedContent), rec.Body.String(), test.Description)
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestConfirmTwoFactor(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	secret := auth.GenerateTOTPSecret()
+	code, err := auth.TOTPCode(secret, time.Now())
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when generating code", err)
+	}
+	testCases := []struct {
+		Description        string
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description:        "Missing Code",
+			RequestBody:        `{}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			Description: "No Pending Enrollment",
+			RequestBody: `{"code":"` + code + `"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM two_factor WHERE (.+)").WithArgs("ERCXNX5").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Invalid Code",
+			RequestBody: `{"code":"abcdef"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM two_factor WHERE (.+)").WillReturnRows(sqlmock.NewRows(twoFactorColumns).AddRow("ERCXNX5", secret, false, 0, 0))
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"invalid two-factor code"`,
+		},
+		{
+			Description: "Two-Factor Enabled",
+			RequestBody: `{"code":"` + code + `"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM two_factor WHERE (.+)").WillReturnRows(sqlmock.NewRows(twoFactorColumns).AddRow("ERCXNX5", secret, false, 0, 0))
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE two_factor SET enabled = true, (.+) WHERE account_id = (.+) AND enabled = false").WithArgs("ERCXNX5", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM recovery_codes WHERE account_id = (.+)").WithArgs("ERCXNX5").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO recovery_codes (.+) SELECT (.+)").WithArgs("ERCXNX5", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 10))
+				mock.ExpectCommit()
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"recoveryCodes":\["[A-Z2-7]{4}-[A-Z2-7]{4}-[A-Z2-7]{4}-[A-Z2-7]{4}"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodPost, "/api/accounts/me/2fa/verify", bytes.NewBufferString(test.RequestBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, model.Account{ID: "ERCXNX5"})
+		// Perform Request
+		if assert.NoError(t, api.confirmTwoFactor(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Assert Response Content
+			assert.Regexp(t, regexp.MustCompile(test.ExpectedContent), rec.Body.String(), test.Description)
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestDisableTwoFactor(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	// Setup Password
+	validPassword := "Test123!"
+	if err := auth.HashPassword(&validPassword); err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when hashing password", err)
+	}
+	secret := auth.GenerateTOTPSecret()
+	testCases := []struct {
+		Description        string
+		RequestBody        string
+		IsAdmin            bool
+		RequireAdmin2FA    bool
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+	}{
+		{
+			Description:        "Required For Administrators",
+			RequestBody:        `{"password":"Test123!","code":"ABCD-EFGH-IJKL-MNOP"}`,
+			IsAdmin:            true,
+			RequireAdmin2FA:    true,
+			ExpectedStatusCode: http.StatusForbidden,
+		},
+		{
+			Description:        "Incorrect Password",
+			RequestBody:        `{"password":"Test1234!","code":"ABCD-EFGH-IJKL-MNOP"}`,
+			ExpectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			Description: "Used Recovery Code",
+			RequestBody: `{"password":"Test123!","code":"ABCD-EFGH-IJKL-MNOP"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM two_factor WHERE (.+)").WillReturnRows(sqlmock.NewRows(twoFactorColumns).AddRow("ERCXNX5", secret, true, 0, 0))
+				mock.ExpectExec("UPDATE recovery_codes SET used_at = now\\(\\) WHERE (.+)").WithArgs("ERCXNX5", auth.HashToken("ABCDEFGHIJKLMNOP")).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			ExpectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			Description: "Two-Factor Disabled With Recovery Code",
+			RequestBody: `{"password":"Test123!","code":"abcd-efgh-ijkl-mnop"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM two_factor WHERE (.+)").WillReturnRows(sqlmock.NewRows(twoFactorColumns).AddRow("ERCXNX5", secret, true, 0, 3))
+				mock.ExpectExec("UPDATE recovery_codes SET used_at = now\\(\\) WHERE (.+)").WithArgs("ERCXNX5", auth.HashToken("ABCDEFGHIJKLMNOP")).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM two_factor WHERE account_id = (.+)").WithArgs("ERCXNX5").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM recovery_codes WHERE account_id = (.+)").WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectExec("DELETE FROM login_challenges WHERE account_id = (.+)").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			ExpectedStatusCode: http.StatusNoContent,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db, RequireAdmin2FA: test.RequireAdmin2FA}
+		req := httptest.NewRequest(http.MethodDelete, "/api/accounts/me/2fa", bytes.NewBufferString(test.RequestBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, model.Account{ID: "ERCXNX5", Password: validPassword, IsAdmin: test.IsAdmin})
+		// Perform Request
+		if assert.NoError(t, api.disableTwoFactor(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestVerifyLogin(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	challenge := "lfy_0XP5ECFVVJFWQAM7QG5WW1A43A8YS5E9VM5S3342P09AZND4BNAYB"
+	secret := auth.GenerateTOTPSecret()
+	code, err := auth.TOTPCode(secret, time.Now())
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when generating code", err)
+	}
+	challengeColumns := []string{"id", "account_id", "token_hash", "label", "expires_at"}
+	lockoutColumns := []string{"account_id", "failed_attempts", "locked_until"}
+	testCases := []struct {
+		Description        string
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description:        "Missing Code",
+			RequestBody:        `{"challenge":"` + challenge + `"}`,
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			Description:        "Malformed Challenge",
+			RequestBody:        `{"challenge":"TEST1234","code":"` + code + `"}`,
+			ExpectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			Description: "Expired Challenge",
+			RequestBody: `{"challenge":"` + challenge + `","code":"` + code + `"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM login_challenges WHERE token_hash = (.+) AND expires_at > now\\(\\)").WithArgs(auth.HashToken(challenge)).WillReturnError(sql.ErrNoRows)
+			},
+			ExpectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			Description: "Incorrect Code",
+			RequestBody: `{"challenge":"` + challenge + `","code":"000000"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM login_challenges WHERE (.+)").WillReturnRows(sqlmock.NewRows(challengeColumns).AddRow("49QRBF09Y", "TEST1234", auth.HashToken(challenge), "", time.Now().Add(time.Minute)))
+				mock.ExpectQuery("SELECT (.+) FROM account_lockouts WHERE account_id = (.+)").WithArgs("TEST1234").WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery("SELECT (.+) FROM two_factor WHERE (.+)").WithArgs("TEST1234").WillReturnRows(sqlmock.NewRows(twoFactorColumns).AddRow("TEST1234", secret, true, 0, 10))
+				mock.ExpectExec("UPDATE recovery_codes SET used_at = now\\(\\) WHERE (.+)").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO login_attempts (.+) VALUES (.+)").WithArgs("TEST1234", "", "192.0.2.1", false).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("INSERT INTO account_lockouts (.+) RETURNING failed_attempts").WithArgs("TEST1234").WillReturnRows(sqlmock.NewRows([]string{"failed_attempts"}).AddRow(1))
+			},
+			ExpectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			Description: "Locked Account",
+			RequestBody: `{"challenge":"` + challenge + `","code":"` + code + `"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM login_challenges WHERE (.+)").WillReturnRows(sqlmock.NewRows(challengeColumns).AddRow("49QRBF09Y", "TEST1234", auth.HashToken(challenge), "", time.Now().Add(time.Minute)))
+				mock.ExpectQuery("SELECT (.+) FROM account_lockouts WHERE account_id = (.+)").WillReturnRows(sqlmock.NewRows(lockoutColumns).AddRow("TEST1234", 10, time.Now().Add(time.Minute)))
+				mock.ExpectExec("INSERT INTO login_attempts (.+) VALUES (.+)").WithArgs("TEST1234", "", "192.0.2.1", false).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			ExpectedStatusCode: http.StatusTooManyRequests,
+		},
+		{
+			Description: "Replayed Code",
+			RequestBody: `{"challenge":"` + challenge + `","code":"` + code + `"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM login_challenges WHERE (.+)").WillReturnRows(sqlmock.NewRows(challengeColumns).AddRow("49QRBF09Y", "TEST1234", auth.HashToken(challenge), "", time.Now().Add(time.Minute)))
+				mock.ExpectQuery("SELECT (.+) FROM account_lockouts WHERE account_id = (.+)").WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery("SELECT (.+) FROM two_factor WHERE (.+)").WillReturnRows(sqlmock.NewRows(twoFactorColumns).AddRow("TEST1234", secret, true, 0, 10))
+				mock.ExpectExec("UPDATE two_factor SET last_counter = (.+) WHERE (.+) AND last_counter < (.+)").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO login_attempts (.+) VALUES (.+)").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("INSERT INTO account_lockouts (.+) RETURNING failed_attempts").WillReturnRows(sqlmock.NewRows([]string{"failed_attempts"}).AddRow(1))
+			},
+			ExpectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			Description: "Valid Code Resets Previous Failures",
+			RequestBody: `{"challenge":"` + challenge + `","code":"` + code + `"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM login_challenges WHERE (.+)").WillReturnRows(sqlmock.NewRows(challengeColumns).AddRow("49QRBF09Y", "TEST1234", auth.HashToken(challenge), "Laptop", time.Now().Add(time.Minute)))
+				mock.ExpectQuery("SELECT (.+) FROM account_lockouts WHERE account_id = (.+)").WillReturnRows(sqlmock.NewRows(lockoutColumns).AddRow("TEST1234", 2, time.Now().Add(-time.Minute)))
+				mock.ExpectQuery("SELECT (.+) FROM two_factor WHERE (.+)").WillReturnRows(sqlmock.NewRows(twoFactorColumns).AddRow("TEST1234", secret, true, 0, 10))
+				mock.ExpectExec("UPDATE two_factor SET last_counter = (.+) WHERE (.+) AND last_counter < (.+)").WithArgs("TEST1234", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM login_challenges WHERE id = (.+)").WithArgs("49QRBF09Y").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO login_attempts (.+) VALUES (.+)").WithArgs("TEST1234", "", "192.0.2.1", true).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("DELETE FROM account_lockouts WHERE account_id = (.+)").WithArgs("TEST1234").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO sessions (.+) VALUES (.+)").WithArgs(sqlmock.AnyArg(), "TEST1234", sqlmock.AnyArg(), "Laptop", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"apikey":"lfy_`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodPost, "/api/accounts/login/verify", bytes.NewBufferString(test.RequestBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.RemoteAddr = "192.0.2.1:1234"
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		// Perform Request
+		if assert.NoError(t, api.verifyLogin(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Assert Response Content
+			assert.Regexp(t, regexp.MustCompile(test.ExpectedContent), rec.Body.String(), test.Description)
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet(), test.Description)
+	}
+}
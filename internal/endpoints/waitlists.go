@@ -0,0 +1,429 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"github.com/Leagueify/api/internal/auth"
+	"github.com/Leagueify/api/internal/config"
+	"github.com/Leagueify/api/internal/fees"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/Leagueify/api/internal/waitlist"
+	"github.com/labstack/echo/v4"
+)
+
+func (api *API) Waitlists(e *echo.Group) {
+	e.GET("/seasons/:id/capacities", api.listDivisionCapacities)
+	e.PUT("/seasons/:id/capacities/:division", api.requiresPermission(auth.PermissionManageSeasons, api.setDivisionCapacity))
+	e.GET("/seasons/:id/waitlist", api.requiresPermission(auth.PermissionViewRegistrations, api.listWaitlist))
+	e.PUT("/seasons/:id/waitlist/:player", api.requiresPermission(auth.PermissionManageRegistrations, api.moveWaitlistEntry))
+	e.POST("/seasons/:id/waitlist/:player/promote", api.requiresPermission(auth.PermissionManageRegistrations, api.promoteWaitlistedPlayer))
+	e.POST("/registrations/:id/players/:player/accept", api.requiresAuth(api.acceptWaitlistOffer))
+	e.POST("/registrations/:id/players/:player/decline", api.requiresAuth(api.declineWaitlistPlace))
+}
+
+// acceptWaitlistOffer gives a waitlisted player the place they were offered
+// and adds their fee to the registration's amount due. Account holders accept
+// offers made to their own registrations and accounts able to manage
+// registrations may accept any.
+func (api *API) acceptWaitlistOffer(c echo.Context) error {
+	registration, ok := api.pathRegistration(c)
+	account := getAccount(c)
+	if !ok || (registration.AccountID != account.ID &&
+		!api.hasPermission(account, auth.PermissionManageRegistrations)) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	playerID := c.Param("player")
+	if !util.VerifyToken(playerID) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	playerID = playerID[:len(playerID)-1]
+	// Begin Transaction
+	tx, err := api.DB.BeginTransaction()
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	defer tx.Rollback()
+	if _, err := api.DB.GetRegistrationForUpdate(tx, registration.ID); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	accepted, err := api.DB.AcceptWaitlistOffer(tx, registration.ID, playerID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if !accepted {
+		return util.SendStatus(http.StatusConflict, c, "no place is on offer for the player")
+	}
+	if err := api.chargePlacedPlayer(tx, registration, playerID); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if err := tx.Commit(); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusOK,
+		map[string]string{
+			"status": "successful",
+		},
+	)
+}
+
+// declineWaitlistPlace takes a waitlisted player off the waitlist, offering
+// any place they were offered to the next player in line. Account holders
+// decline for their own registrations and accounts able to manage
+// registrations may decline for any.
+func (api *API) declineWaitlistPlace(c echo.Context) error {
+	registration, ok := api.pathRegistration(c)
+	account := getAccount(c)
+	if !ok || (registration.AccountID != account.ID &&
+		!api.hasPermission(account, auth.PermissionManageRegistrations)) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	playerID := c.Param("player")
+	if !util.VerifyToken(playerID) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	players, err := api.DB.ListRegistrationPlayers(registration.ID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	player, ok := registrationPlayer(players, playerID[:len(playerID)-1])
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	// Begin Transaction
+	tx, err := api.DB.BeginTransaction()
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	defer tx.Rollback()
+	if err := api.lockDivision(tx, registration.SeasonID, player.Division); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	declined, err := api.DB.DeclineWaitlistPlace(tx, registration.ID, player.PlayerID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if !declined {
+		return util.SendStatus(http.StatusConflict, c, "player is not waitlisted")
+	}
+	offers, err := api.DB.OfferWaitlistPlaces(tx, registration.SeasonID, player.Division)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if err := tx.Commit(); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if err := api.notifyWaitlistOffers(offers); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusOK,
+		map[string]string{
+			"status": "successful",
+		},
+	)
+}
+
+func (api *API) listDivisionCapacities(c echo.Context) error {
+	seasonID, ok := api.pathSeason(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	capacities, err := api.DB.ListDivisionCapacities(seasonID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusOK, capacities)
+}
+
+// listWaitlist returns a season's waitlisted players in the order they will
+// be offered a place, optionally within a single division.
+func (api *API) listWaitlist(c echo.Context) error {
+	seasonID, ok := api.pathSeason(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	filter := model.WaitlistFilter{}
+	// bind query parameters to model
+	if err := c.Bind(&filter); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid query parameters")
+	}
+	entries, err := api.DB.ListWaitlist(seasonID, filter)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	for index := range entries {
+		entries[index] = signedWaitlistEntry(entries[index])
+	}
+	return c.JSON(http.StatusOK, entries)
+}
+
+// moveWaitlistEntry moves a waitlisted player to a position within their
+// division's waitlist. Places already offered stay with the players they
+// were offered to.
+func (api *API) moveWaitlistEntry(c echo.Context) error {
+	seasonID, ok := api.pathSeason(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	payload := model.WaitlistMove{}
+	// bind payload to model
+	if err := c.Bind(&payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(payload); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	entry, ok := api.pathWaitlistEntry(c, seasonID)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	// Begin Transaction
+	tx, err := api.DB.BeginTransaction()
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	defer tx.Rollback()
+	if err := api.lockDivision(tx, seasonID, entry.Division); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	moved, err := api.DB.MoveWaitlistEntry(tx, entry, payload.Position)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if !moved {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if err := tx.Commit(); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusOK,
+		map[string]string{
+			"status": "successful",
+		},
+	)
+}
+
+// promoteWaitlistedPlayer gives a waitlisted player a place whether or not
+// their division has room, and adds their fee to the registration's amount
+// due.
+func (api *API) promoteWaitlistedPlayer(c echo.Context) error {
+	seasonID, ok := api.pathSeason(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	entry, ok := api.pathWaitlistEntry(c, seasonID)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	// Begin Transaction
+	tx, err := api.DB.BeginTransaction()
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	defer tx.Rollback()
+	registration, err := api.DB.GetRegistrationForUpdate(tx, entry.RegistrationID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if err := api.lockDivision(tx, seasonID, entry.Division); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	promoted, err := api.DB.PromoteWaitlistedPlayer(tx, registration.ID, entry.PlayerID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if !promoted {
+		return util.SendStatus(http.StatusConflict, c, "player is not waitlisted")
+	}
+	if err := api.chargePlacedPlayer(tx, registration, entry.PlayerID); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if err := tx.Commit(); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusOK,
+		map[string]string{
+			"status": "successful",
+		},
+	)
+}
+
+// setDivisionCapacity creates or replaces the capacity of a season's
+// division. Players already placed keep their places when it is lowered, and
+// places opened by raising it are offered to the waitlist.
+func (api *API) setDivisionCapacity(c echo.Context) error {
+	seasonID, ok := api.pathSeason(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	division, err := url.PathUnescape(c.Param("division"))
+	if err != nil || division == "" || len(division) > 64 {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	capacity := model.DivisionCapacity{}
+	// bind payload to model
+	if err := c.Bind(&capacity); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(capacity); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	capacity.SeasonID = seasonID
+	capacity.Division = division
+	// Begin Transaction
+	tx, err := api.DB.BeginTransaction()
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	defer tx.Rollback()
+	if err := api.DB.SetDivisionCapacity(tx, capacity); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	offers, err := api.DB.OfferWaitlistPlaces(tx, seasonID, division)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if err := tx.Commit(); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if err := api.notifyWaitlistOffers(offers); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusOK,
+		map[string]string{
+			"status": "successful",
+		},
+	)
+}
+
+// chargePlacedPlayer adds the fee of a waitlisted player who has just taken a
+// place to the locked registration's amount due.
+func (api *API) chargePlacedPlayer(tx *sql.Tx, registration model.Registration, playerID string) error {
+	players, err := api.DB.ListRegistrationPlayers(registration.ID)
+	if err != nil {
+		return err
+	}
+	adjustments, err := api.DB.ListRegistrationAdjustments(registration.ID)
+	if err != nil {
+		return err
+	}
+	for index := range players {
+		if players[index].PlayerID == playerID {
+			players[index].Status = model.RegistrationPending
+		}
+	}
+	return api.DB.SetRegistrationAmountDue(tx, registration.ID, fees.AmountDue(players, adjustments))
+}
+
+// lockDivision locks the capacity of the season's division until tx ends.
+// Divisions without a capacity have nothing to lock.
+func (api *API) lockDivision(tx *sql.Tx, seasonID, division string) error {
+	_, err := api.DB.GetDivisionCapacityForUpdate(tx, seasonID, division)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	return err
+}
+
+// notifyWaitlistOffers tells the families offered places about them.
+func (api *API) notifyWaitlistOffers(offers []model.WaitlistOffer) error {
+	_, err := waitlist.Notify(api.DB, config.LoadConfig().BaseURL, offers)
+	return err
+}
+
+// pathWaitlistEntry returns the season's waitlisted player in the player path
+// parameter.
+func (api *API) pathWaitlistEntry(c echo.Context, seasonID string) (model.WaitlistEntry, bool) {
+	playerID := c.Param("player")
+	if !util.VerifyToken(playerID) {
+		return model.WaitlistEntry{}, false
+	}
+	entries, err := api.DB.ListWaitlist(seasonID, model.WaitlistFilter{})
+	if err != nil {
+		return model.WaitlistEntry{}, false
+	}
+	for _, entry := range entries {
+		if entry.PlayerID == playerID[:len(playerID)-1] {
+			return entry, true
+		}
+	}
+	return model.WaitlistEntry{}, false
+}
+
+// placePlayers returns the status each quoted player is registered with.
+// Players take a place while their division has room and are waitlisted once
+// it is full. The capacities of their divisions are locked in order until tx
+// ends, so concurrent registrations cannot both take the last place.
+func (api *API) placePlayers(tx *sql.Tx, seasonID string, lines []model.QuoteLine) (map[string]string, error) {
+	divisions := []string{}
+	for _, line := range lines {
+		if !slices.Contains(divisions, line.Division) {
+			divisions = append(divisions, line.Division)
+		}
+	}
+	slices.Sort(divisions)
+	capacities := map[string]model.DivisionCapacity{}
+	for _, division := range divisions {
+		capacity, err := api.DB.GetDivisionCapacityForUpdate(tx, seasonID, division)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		capacities[division] = capacity
+	}
+	statuses := map[string]string{}
+	for _, line := range lines {
+		capacity, limited := capacities[line.Division]
+		switch {
+		case !limited:
+			statuses[line.PlayerID] = model.RegistrationPending
+		case waitlist.HasRoom(capacity):
+			statuses[line.PlayerID] = model.RegistrationPending
+			capacity.Placed++
+		default:
+			statuses[line.PlayerID] = model.RegistrationWaitlisted
+			capacity.Waitlisted++
+		}
+		if limited {
+			capacities[line.Division] = capacity
+		}
+	}
+	return statuses, nil
+}
+
+// releasePlace withdraws the player from the locked registration, sets its
+// amount due, and offers the place the player held to the next players on
+// their division's waitlist. False is returned when the player no longer
+// holds or waits for a place.
+func (api *API) releasePlace(tx *sql.Tx, registration model.Registration, player model.RegistrationPlayer, amountDue int) ([]model.WaitlistOffer, bool, error) {
+	if err := api.lockDivision(tx, registration.SeasonID, player.Division); err != nil {
+		return nil, false, err
+	}
+	withdrawn, err := api.DB.WithdrawRegistrationPlayer(tx, player)
+	if err != nil || !withdrawn {
+		return nil, false, err
+	}
+	if err := api.DB.SetRegistrationAmountDue(tx, registration.ID, amountDue); err != nil {
+		return nil, false, err
+	}
+	offers, err := api.DB.OfferWaitlistPlaces(tx, registration.SeasonID, player.Division)
+	if err != nil {
+		return nil, false, err
+	}
+	return offers, true, nil
+}
+
+// signedWaitlistEntry signs the IDs of the entry before it is returned.
+func signedWaitlistEntry(entry model.WaitlistEntry) model.WaitlistEntry {
+	entry.RegistrationID = util.ReturnSignedToken(entry.RegistrationID)
+	entry.PlayerID = util.ReturnSignedToken(entry.PlayerID)
+	return entry
+}
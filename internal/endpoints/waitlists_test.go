@@ -0,0 +1,381 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Leagueify/api/internal/database/postgres"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var divisionCapacityLock = "SELECT (.+) FROM division_capacities WHERE (.+) FOR UPDATE"
+
+var divisionCapacityColumns = []string{"season_id", "division", "capacity", "offer_hours", "placed", "offered", "waitlisted"}
+
+var waitlistColumns = []string{"registration_id", "player_id", "season_id", "first_name", "last_name", "division", "position", "offer_expires_at", "created_at"}
+
+var waitlistOfferColumns = []string{"registration_id", "player_id", "player_name", "division", "season_name", "email", "first_name", "offer_expires_at"}
+
+// expectDivisionCapacity expects the capacity of the division to be locked
+// and counted.
+func expectDivisionCapacity(mock sqlmock.Sqlmock, division string, capacity, placed, offered, waitlisted int) {
+	mock.ExpectQuery(divisionCapacityLock).WithArgs("BJ7Q4NVRN", division).WillReturnRows(sqlmock.NewRows([]string{"season_id"}).AddRow("BJ7Q4NVRN"))
+	mock.ExpectQuery("SELECT (.+) FROM division_capacities LEFT JOIN (.+)").WithArgs("BJ7Q4NVRN", division).WillReturnRows(
+		sqlmock.NewRows(divisionCapacityColumns).AddRow("BJ7Q4NVRN", division, capacity, 48, placed, offered, waitlisted),
+	)
+}
+
+func waitlistOfferRows() *sqlmock.Rows {
+	return sqlmock.NewRows(waitlistOfferColumns).AddRow("8NQW2HJ5R", "W4SBH35WV", "Leagueify Sibling", "U12", "2024-2025", "test@leagueify.org", "Leagueify", time.Now().Add(48*time.Hour))
+}
+
+func TestSetDivisionCapacity(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description: "Season Not Found",
+			RequestBody: `{"capacity":12,"offerHours":48}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Offer Hours Required",
+			RequestBody: `{"capacity":12}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			Description: "Capacity Set Without Waitlist",
+			RequestBody: `{"capacity":12,"offerHours":48}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO division_capacities (.+) ON CONFLICT").WithArgs("BJ7Q4NVRN", "U12", 12, 48).WillReturnResult(sqlmock.NewResult(0, 1))
+				expectDivisionCapacity(mock, "U12", 12, 10, 0, 0)
+				mock.ExpectQuery("WITH offered AS (.+) FROM offered").WithArgs("BJ7Q4NVRN", "U12", 48, 2).WillReturnRows(sqlmock.NewRows(waitlistOfferColumns))
+				mock.ExpectCommit()
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"status":"successful"`,
+		},
+		{
+			Description: "Capacity Raised Offers Place",
+			RequestBody: `{"capacity":12,"offerHours":48}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO division_capacities (.+) ON CONFLICT").WithArgs("BJ7Q4NVRN", "U12", 12, 48).WillReturnResult(sqlmock.NewResult(0, 1))
+				expectDivisionCapacity(mock, "U12", 12, 11, 0, 3)
+				mock.ExpectQuery("WITH offered AS (.+) FROM offered").WithArgs("BJ7Q4NVRN", "U12", 48, 1).WillReturnRows(waitlistOfferRows())
+				mock.ExpectCommit()
+				mock.ExpectExec("INSERT INTO email_outbox (.+) VALUES (.+)").WithArgs(sqlmock.AnyArg(), "test@leagueify.org", "A place has opened up for Leagueify Sibling in 2024-2025", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"status":"successful"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer([]byte(test.RequestBody)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/seasons/:id/capacities/:division")
+		c.SetParamNames("id", "division")
+		c.SetParamValues(util.ReturnSignedToken("BJ7Q4NVRN"), "U12")
+		// Perform Request
+		if assert.NoError(t, api.setDivisionCapacity(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestRegistrationWaitlistedInFullDivision(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	e := echo.New()
+	e.Validator = &API{Validator: validator.New()}
+	api := API{DB: db}
+	// Assign the player's division, keeping the division written to the player
+	var division string
+	mock.ExpectQuery("SELECT (.+) FROM players WHERE id").WithArgs("DW74MSY5X").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("DW74MSY5X", "Leagueify", "Player", "2014-08-31", "Goalie", "", "", nil, "", "", "", "", false))
+	mock.ExpectExec("UPDATE players SET division = (.+) WHERE id = (.+) AND NOT EXISTS (.+)").WithArgs(storedArg{&division}, "DW74MSY5X").WillReturnResult(sqlmock.NewResult(0, 1))
+	req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer([]byte(`{"division":"U12"}`)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("DW74MSY5XQ")
+	if assert.NoError(t, api.setPlayerDivision(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+	// Limit the division to one place, which is already taken
+	mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO division_capacities (.+) ON CONFLICT").WithArgs("BJ7Q4NVRN", division, 1, 48).WillReturnResult(sqlmock.NewResult(0, 1))
+	expectDivisionCapacity(mock, division, 1, 1, 0, 0)
+	mock.ExpectCommit()
+	req = httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer([]byte(`{"capacity":1,"offerHours":48}`)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id", "division")
+	c.SetParamValues(util.ReturnSignedToken("BJ7Q4NVRN"), "U12")
+	if assert.NoError(t, api.setDivisionCapacity(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+	// Registering the player waitlists them without charging their fee
+	mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+	mock.ExpectQuery("SELECT (.+) FROM guardians WHERE (.+)").WithArgs("123ABC", "DW74MSY5X").WillReturnRows(sqlmock.NewRows(guardianColumns).AddRow("123ABC", "DW74MSY5X", "primary", time.Now()))
+	mock.ExpectQuery("SELECT (.+) FROM players WHERE id").WithArgs("DW74MSY5X").WillReturnRows(sqlmock.NewRows(playerColumns).AddRow("DW74MSY5X", "Leagueify", "Player", "2014-08-31", "Goalie", "", "", nil, "", "", "", division, false))
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM registrations WHERE (.+) FOR UPDATE").WillReturnRows(registrationRow())
+	mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationPlayerColumns))
+	mock.ExpectQuery("SELECT (.+) FROM registration_adjustments WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationAdjustmentColumns))
+	mock.ExpectQuery("SELECT (.+) FROM fee_schedules WHERE season_id = (.+)").WithArgs("BJ7Q4NVRN").WillReturnRows(sqlmock.NewRows(feeScheduleColumns).AddRow("FEE1234", "BJ7Q4NVRN", "", 12000, nil, "", 0, "", 0, 0))
+	expectDivisionCapacity(mock, division, 1, 1, 0, 0)
+	mock.ExpectExec("INSERT INTO registration_players (.+) VALUES (.+) ON CONFLICT").WithArgs("6KQJ7ZPR2", "DW74MSY5X", "BJ7Q4NVRN", "waitlisted", 12000).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE registrations SET amount_due = (.+) WHERE id = (.+)").WithArgs(0, "6KQJ7ZPR2").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	req = httptest.NewRequest(http.MethodPost, "/api/players/register", bytes.NewBuffer([]byte(`{"seasonId":"BJ7Q4NVRNQ","players":["DW74MSY5XQ"]}`)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	setAccount(c, model.Account{ID: "123ABC", Players: pq.StringArray{"DW74MSY5X"}})
+	if assert.NoError(t, api.registerPlayer(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+	// Assert All Expectations Met
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAcceptWaitlistOffer(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		Account            model.Account
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description: "Another Account's Registration",
+			Account:     model.Account{ID: "ERCXNX5"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Offer Expired",
+			Account:     model.Account{ID: "123ABC"},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+) FOR UPDATE").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectExec("UPDATE registration_players SET (.+) AND offer_expires_at > now()").WithArgs("6KQJ7ZPR2", "DW74MSY5X").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusConflict,
+			ExpectedContent:    `"detail":"no place is on offer for the player"`,
+		},
+		{
+			Description: "Offer Accepted By Registrar",
+			Account:     model.Account{ID: "ERCXNX5", Roles: pq.StringArray{"registrar"}},
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+) FOR UPDATE").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectExec("UPDATE registration_players SET (.+) AND offer_expires_at > now()").WithArgs("6KQJ7ZPR2", "DW74MSY5X").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(
+					sqlmock.NewRows(registrationPlayerColumns).AddRow("6KQJ7ZPR2", "DW74MSY5X", "BJ7Q4NVRN", "Leagueify", "Player", "U12", "waitlisted", 10000, 0, time.Now().Add(time.Hour), time.Now(), time.Now()),
+				)
+				mock.ExpectQuery("SELECT (.+) FROM registration_adjustments WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationAdjustmentColumns))
+				mock.ExpectExec("UPDATE registrations SET amount_due = (.+) WHERE id = (.+)").WithArgs(10000, "6KQJ7ZPR2").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"status":"successful"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, test.Account)
+		c.SetPath("/api/registrations/:id/players/:player/accept")
+		c.SetParamNames("id", "player")
+		c.SetParamValues(util.ReturnSignedToken("6KQJ7ZPR2"), util.ReturnSignedToken("DW74MSY5X"))
+		// Perform Request
+		if assert.NoError(t, api.acceptWaitlistOffer(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestMoveWaitlistEntry(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	waitlistRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows(waitlistColumns).
+			AddRow("6KQJ7ZPR2", "DW74MSY5X", "BJ7Q4NVRN", "Leagueify", "Player", "U12", 1, nil, time.Now()).
+			AddRow("8NQW2HJ5R", "W4SBH35WV", "BJ7Q4NVRN", "Leagueify", "Sibling", "U12", 2, nil, time.Now())
+	}
+	testCases := []struct {
+		Description        string
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description: "Position Required",
+			RequestBody: `{"position":0}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			Description: "Player Not Waitlisted",
+			RequestBody: `{"position":1}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+				mock.ExpectQuery("SELECT (.+)ROW_NUMBER(.+) FROM registration_players (.+)").WithArgs("BJ7Q4NVRN", "").WillReturnRows(sqlmock.NewRows(waitlistColumns))
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Player Moved To Front",
+			RequestBody: `{"position":1}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+				mock.ExpectQuery("SELECT (.+)ROW_NUMBER(.+) FROM registration_players (.+)").WithArgs("BJ7Q4NVRN", "").WillReturnRows(waitlistRows())
+				mock.ExpectBegin()
+				mock.ExpectQuery(divisionCapacityLock).WithArgs("BJ7Q4NVRN", "U12").WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) FOR UPDATE OF registration_players").WithArgs("BJ7Q4NVRN", "U12").WillReturnRows(
+					sqlmock.NewRows([]string{"player_id", "waitlist_position"}).AddRow("DW74MSY5X", 7).AddRow("W4SBH35WV", 9),
+				)
+				mock.ExpectExec("UPDATE registration_players SET waitlist_position = (.+)").WithArgs(7, "BJ7Q4NVRN", "W4SBH35WV").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE registration_players SET waitlist_position = (.+)").WithArgs(9, "BJ7Q4NVRN", "DW74MSY5X").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"status":"successful"`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer([]byte(test.RequestBody)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/seasons/:id/waitlist/:player")
+		c.SetParamNames("id", "player")
+		c.SetParamValues(util.ReturnSignedToken("BJ7Q4NVRN"), util.ReturnSignedToken("W4SBH35WV"))
+		// Perform Request
+		if assert.NoError(t, api.moveWaitlistEntry(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
@@ -0,0 +1,356 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Leagueify/api/internal/auth"
+	"github.com/Leagueify/api/internal/fees"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/labstack/echo/v4"
+)
+
+func (api *API) Withdrawals(e *echo.Group) {
+	e.GET("/seasons/:id/refund-policy", api.getRefundPolicy)
+	e.PUT("/seasons/:id/refund-policy", api.requiresPermission(auth.PermissionManageSeasons, api.setRefundPolicy))
+	e.GET("/withdrawals", api.requiresPermission(auth.PermissionViewRegistrations, api.listWithdrawals))
+	e.PUT("/withdrawals/:id", api.requiresPermission(auth.PermissionManageRegistrations, api.reviewWithdrawal))
+	e.GET("/registrations/:id/withdrawals", api.requiresAuth(api.listRegistrationWithdrawals))
+	e.POST("/registrations/:id/withdrawals", api.requiresAuth(api.requestWithdrawal))
+}
+
+func (api *API) getRefundPolicy(c echo.Context) error {
+	seasonID, ok := api.pathSeason(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	policy, err := api.DB.GetRefundPolicy(seasonID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusOK, policy)
+}
+
+func (api *API) listWithdrawals(c echo.Context) error {
+	filter := model.WithdrawalFilter{}
+	// bind query parameters to model
+	if err := c.Bind(&filter); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid query parameters")
+	}
+	// validate query parameters against model
+	if err := c.Validate(filter); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	withdrawals, err := api.DB.ListWithdrawals(filter)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	for index := range withdrawals {
+		withdrawals[index] = signedWithdrawal(withdrawals[index])
+	}
+	return c.JSON(http.StatusOK, withdrawals)
+}
+
+// listRegistrationWithdrawals returns a registration's withdrawals. Account
+// holders may list their own registration's withdrawals, and accounts able to
+// view registrations may list any.
+func (api *API) listRegistrationWithdrawals(c echo.Context) error {
+	registration, ok := api.pathRegistration(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	account := getAccount(c)
+	if registration.AccountID != account.ID &&
+		!api.hasPermission(account, auth.PermissionViewRegistrations) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	withdrawals, err := api.DB.ListRegistrationWithdrawals(registration.ID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	for index := range withdrawals {
+		withdrawals[index] = signedWithdrawal(withdrawals[index])
+	}
+	return c.JSON(http.StatusOK, withdrawals)
+}
+
+// requestWithdrawal asks to withdraw a player from a registration, quoting
+// the refund the season's refund policy allows today. The request awaits
+// review by an account able to manage registrations. Account holders request
+// withdrawals from their own registrations and accounts able to manage
+// registrations may request one from any.
+func (api *API) requestWithdrawal(c echo.Context) error {
+	registration, ok := api.pathRegistration(c)
+	account := getAccount(c)
+	if !ok || (registration.AccountID != account.ID &&
+		!api.hasPermission(account, auth.PermissionManageRegistrations)) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	withdrawal := model.Withdrawal{}
+	// bind payload to model
+	if err := c.Bind(&withdrawal); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(withdrawal); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	if !util.VerifyToken(withdrawal.PlayerID) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	players, err := api.DB.ListRegistrationPlayers(registration.ID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	player, ok := registrationPlayer(players, withdrawal.PlayerID[:len(withdrawal.PlayerID)-1])
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	if !fees.Active(player.Status) {
+		return util.SendStatus(http.StatusConflict, c, "player has already withdrawn")
+	}
+	policy, err := api.DB.GetRefundPolicy(registration.SeasonID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	withdrawal.ID = util.SignedToken(10)
+	withdrawal.ID = withdrawal.ID[:len(withdrawal.ID)-1]
+	withdrawal.RegistrationID = registration.ID
+	withdrawal.PlayerID = player.PlayerID
+	withdrawal.Status = model.WithdrawalPending
+	// waitlisted players were never charged, so they have nothing to refund
+	if fees.Placed(player.Status) {
+		withdrawal.RefundAmount = fees.Refund(policy, player.Amount, time.Now().Format(time.DateOnly))
+	}
+	created, err := api.DB.CreateWithdrawal(withdrawal)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if !created {
+		return util.SendStatus(http.StatusConflict, c, "player already has a withdrawal awaiting review")
+	}
+	withdrawal.CreatedAt = time.Now()
+	return c.JSON(http.StatusCreated, signedWithdrawal(withdrawal))
+}
+
+// reviewWithdrawal approves or denies a withdrawal. Approving it withdraws
+// the player, releasing their place in the season to the next player on the
+// waitlist, and takes the refunded part of their fee off the registration's
+// amount due. Whatever the registration has paid beyond what it now owes, up
+// to the refund, is returned through the payment provider and recorded in the
+// ledger.
+func (api *API) reviewWithdrawal(c echo.Context) error {
+	withdrawalID := c.Param("id")
+	if !util.VerifyToken(withdrawalID) {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	withdrawal, err := api.DB.GetWithdrawal(withdrawalID[:len(withdrawalID)-1])
+	if err != nil {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	review := model.WithdrawalReview{}
+	// bind payload to model
+	if err := c.Bind(&review); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(review); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	if withdrawal.Status != model.WithdrawalPending {
+		return util.SendStatus(http.StatusConflict, c, "withdrawal has already been reviewed")
+	}
+	withdrawal.Status = review.Status
+	withdrawal.Note = review.Note
+	withdrawal.ReviewedBy = getAccount(c).ID
+	// Begin Transaction
+	tx, err := api.DB.BeginTransaction()
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	defer tx.Rollback()
+	registration, err := api.DB.GetRegistrationForUpdate(tx, withdrawal.RegistrationID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if withdrawal.Status == model.WithdrawalDenied {
+		reviewed, err := api.DB.ReviewWithdrawal(tx, withdrawal)
+		if err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+		if !reviewed {
+			return util.SendStatus(http.StatusConflict, c, "withdrawal has already been reviewed")
+		}
+		if err := tx.Commit(); err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+		return c.JSON(http.StatusOK, signedWithdrawal(withdrawal))
+	}
+	players, err := api.DB.ListRegistrationPlayers(registration.ID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	adjustments, err := api.DB.ListRegistrationAdjustments(registration.ID)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	player, ok := registrationPlayer(players, withdrawal.PlayerID)
+	if !ok || !fees.Active(player.Status) {
+		return util.SendStatus(http.StatusConflict, c, "player has already withdrawn")
+	}
+	if review.RefundAmount != nil {
+		withdrawal.RefundAmount = *review.RefundAmount
+	}
+	withdrawal.RefundAmount = min(withdrawal.RefundAmount, player.Amount)
+	if fees.Placed(player.Status) {
+		player.Retained += player.Amount - withdrawal.RefundAmount
+	} else {
+		// waitlisted players were never charged, so nothing is refunded or
+		// retained
+		withdrawal.RefundAmount = 0
+	}
+	player.Status = model.RegistrationWithdrawn
+	for index := range players {
+		if players[index].PlayerID == player.PlayerID {
+			players[index] = player
+		}
+	}
+	amountDue := fees.AmountDue(players, adjustments)
+	// only what was paid beyond the new amount due is returned, the rest of
+	// the refund is taken off what the registration still owes
+	refunds := []model.Payment{}
+	if overpaid := min(registration.AmountPaid-amountDue, withdrawal.RefundAmount); overpaid > 0 && api.PaymentProvider != nil {
+		ledger, err := api.DB.ListPayments(registration.ID)
+		if err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+		refunds = refundablePayments(ledger, api.PaymentProvider.Name(), overpaid)
+	}
+	for _, payment := range refunds {
+		withdrawal.Refunded += payment.Amount
+	}
+	if withdrawal.Refunded > 0 {
+		player.Status = model.RegistrationRefunded
+	}
+	reviewed, err := api.DB.ReviewWithdrawal(tx, withdrawal)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if !reviewed {
+		return util.SendStatus(http.StatusConflict, c, "withdrawal has already been reviewed")
+	}
+	offers, withdrawn, err := api.releasePlace(tx, registration, player, amountDue)
+	if err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if !withdrawn {
+		return util.SendStatus(http.StatusConflict, c, "player has already withdrawn")
+	}
+	// refunds the provider makes before a later step fails are still
+	// recorded once the provider reports them through the webhook, and are
+	// not made again when the review is retried
+	for _, payment := range refunds {
+		refund, err := api.PaymentProvider.Refund(
+			c.Request().Context(), payment.Reference, payment.Amount,
+			"withdrawal_"+withdrawal.ID+"_"+payment.ID,
+		)
+		if err != nil {
+			return util.SendStatus(http.StatusBadGateway, c, "payment provider unavailable")
+		}
+		entry := model.Payment{
+			ID:             util.SignedToken(10),
+			RegistrationID: registration.ID,
+			Kind:           model.PaymentKindRefund,
+			Amount:         refund.Amount,
+			Provider:       payment.Provider,
+			Reference:      refund.Reference,
+			PaymentID:      payment.ID,
+		}
+		entry.ID = entry.ID[:len(entry.ID)-1]
+		if _, err := api.DB.CreatePayment(tx, entry); err != nil {
+			return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	if err := api.notifyWaitlistOffers(offers); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusOK, signedWithdrawal(withdrawal))
+}
+
+// setRefundPolicy creates or replaces a season's refund policy. Withdrawals
+// already requested keep the refund quoted when they were requested.
+func (api *API) setRefundPolicy(c echo.Context) error {
+	seasonID, ok := api.pathSeason(c)
+	if !ok {
+		return util.SendStatus(http.StatusNotFound, c, "")
+	}
+	policy := model.RefundPolicy{}
+	// bind payload to model
+	if err := c.Bind(&policy); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, "invalid json payload")
+	}
+	// validate payload against model
+	if err := c.Validate(policy); err != nil {
+		return util.SendStatus(http.StatusBadRequest, c, util.HandleError(err))
+	}
+	if policy.FullRefundUntil != "" && policy.PartialRefundUntil != "" &&
+		policy.PartialRefundUntil < policy.FullRefundUntil {
+		return util.SendStatus(http.StatusBadRequest, c, "partial refunds must end after full refunds")
+	}
+	policy.SeasonID = seasonID
+	if err := api.DB.SetRefundPolicy(policy); err != nil {
+		return util.SendStatus(http.StatusInternalServerError, c, util.HandleError(err))
+	}
+	return c.JSON(http.StatusOK, policy)
+}
+
+// refundablePayments returns the payments made through the provider that
+// amount can be refunded from, latest first, each with the amount to refund
+// from it. Payments made outside the provider cannot be refunded through it,
+// so less than amount may be refundable.
+func refundablePayments(ledger []model.Payment, provider string, amount int) []model.Payment {
+	refundable := map[string]int{}
+	for _, entry := range ledger {
+		if entry.Kind == model.PaymentKindRefund {
+			refundable[entry.PaymentID] -= entry.Amount
+		} else if entry.Provider == provider {
+			refundable[entry.ID] += entry.Amount
+		}
+	}
+	payments := []model.Payment{}
+	for index := len(ledger) - 1; index >= 0 && amount > 0; index-- {
+		payment := ledger[index]
+		if payment.Kind != model.PaymentKindPayment || refundable[payment.ID] <= 0 {
+			continue
+		}
+		payment.Amount = min(refundable[payment.ID], amount)
+		amount -= payment.Amount
+		payments = append(payments, payment)
+	}
+	return payments
+}
+
+// registrationPlayer returns the player on the registration.
+func registrationPlayer(players []model.RegistrationPlayer, playerID string) (model.RegistrationPlayer, bool) {
+	for _, player := range players {
+		if player.PlayerID == playerID {
+			return player, true
+		}
+	}
+	return model.RegistrationPlayer{}, false
+}
+
+// signedWithdrawal signs the IDs of the withdrawal before it is returned.
+func signedWithdrawal(withdrawal model.Withdrawal) model.Withdrawal {
+	withdrawal.ID = util.ReturnSignedToken(withdrawal.ID)
+	withdrawal.RegistrationID = util.ReturnSignedToken(withdrawal.RegistrationID)
+	withdrawal.PlayerID = util.ReturnSignedToken(withdrawal.PlayerID)
+	if withdrawal.ReviewedBy != "" {
+		withdrawal.ReviewedBy = util.ReturnSignedToken(withdrawal.ReviewedBy)
+	}
+	return withdrawal
+}
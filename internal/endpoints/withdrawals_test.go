@@ -0,0 +1,431 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Leagueify/api/internal/database/postgres"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/payments"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var withdrawalColumns = []string{"id", "registration_id", "player_id", "reason", "status", "refund_amount", "refunded", "note", "reviewed_by", "reviewed_at", "created_at"}
+
+func withdrawalRow(status string, refundAmount int) *sqlmock.Rows {
+	return sqlmock.NewRows(withdrawalColumns).AddRow("5MXR8TQ2D", "6KQJ7ZPR2", "DW74MSY5X", "Moving away", status, refundAmount, 0, "", "", nil, time.Now())
+}
+
+func TestSetRefundPolicy(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	testCases := []struct {
+		Description        string
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description: "Season Not Found",
+			RequestBody: `{"fullRefundUntil":"2024-02-01"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Partial Refund Without End",
+			RequestBody: `{"fullRefundUntil":"2024-02-01","partialRefundPercent":50}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			Description: "Partial Refund Ends Before Full Refund",
+			RequestBody: `{"fullRefundUntil":"2024-02-01","partialRefundUntil":"2024-01-15","partialRefundPercent":50}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+			},
+			ExpectedStatusCode: http.StatusBadRequest,
+			ExpectedContent:    `"detail":"partial refunds must end after full refunds"`,
+		},
+		{
+			Description: "Policy Set",
+			RequestBody: `{"fullRefundUntil":"2024-02-01","partialRefundUntil":"2024-03-01","partialRefundPercent":50}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM seasons WHERE id = (.+)").WillReturnRows(seasonRow(yesterday, tomorrow))
+				mock.ExpectExec("INSERT INTO refund_policies (.+) ON CONFLICT").WithArgs("BJ7Q4NVRN", "2024-02-01", "2024-03-01", 50).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `{"fullRefundUntil":"2024-02-01","partialRefundUntil":"2024-03-01","partialRefundPercent":50}`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer([]byte(test.RequestBody)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/api/seasons/:id/refund-policy")
+		c.SetParamNames("id")
+		c.SetParamValues(util.ReturnSignedToken("BJ7Q4NVRN"))
+		// Perform Request
+		if assert.NoError(t, api.setRefundPolicy(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestRequestWithdrawal(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	policyColumns := []string{"full_refund_until", "partial_refund_until", "partial_refund_percent"}
+	testCases := []struct {
+		Description        string
+		AccountID          string
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description: "Another Account's Registration",
+			AccountID:   "ERCXNX5",
+			RequestBody: fmt.Sprintf(`{"playerId":"%s"}`, util.ReturnSignedToken("DW74MSY5X")),
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Player Not On Registration",
+			AccountID:   "123ABC",
+			RequestBody: fmt.Sprintf(`{"playerId":"%s"}`, util.ReturnSignedToken("W4SBH35WV")),
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationPlayerRows())
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Description: "Player Already Withdrawn",
+			AccountID:   "123ABC",
+			RequestBody: fmt.Sprintf(`{"playerId":"%s"}`, util.ReturnSignedToken("DW74MSY5X")),
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(
+					sqlmock.NewRows(registrationPlayerColumns).AddRow("6KQJ7ZPR2", "DW74MSY5X", "BJ7Q4NVRN", "Leagueify", "Player", "U12", "withdrawn", 10000, 0, nil, time.Now(), time.Now()),
+				)
+			},
+			ExpectedStatusCode: http.StatusConflict,
+			ExpectedContent:    `"detail":"player has already withdrawn"`,
+		},
+		{
+			Description: "Withdrawal Awaiting Review",
+			AccountID:   "123ABC",
+			RequestBody: fmt.Sprintf(`{"playerId":"%s"}`, util.ReturnSignedToken("DW74MSY5X")),
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationPlayerRows())
+				mock.ExpectQuery("SELECT (.+) FROM refund_policies WHERE season_id = (.+)").WithArgs("BJ7Q4NVRN").WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec("INSERT INTO withdrawals (.+) ON CONFLICT").WithArgs(sqlmock.AnyArg(), "6KQJ7ZPR2", "DW74MSY5X", "", 0).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			ExpectedStatusCode: http.StatusConflict,
+			ExpectedContent:    `"detail":"player already has a withdrawal awaiting review"`,
+		},
+		{
+			Description: "Withdrawal Requested",
+			AccountID:   "123ABC",
+			RequestBody: fmt.Sprintf(`{"playerId":"%s","reason":"Moving away"}`, util.ReturnSignedToken("DW74MSY5X")),
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationRow())
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationPlayerRows())
+				mock.ExpectQuery("SELECT (.+) FROM refund_policies WHERE season_id = (.+)").WithArgs("BJ7Q4NVRN").WillReturnRows(
+					sqlmock.NewRows(policyColumns).AddRow("2000-01-01", "2999-01-01", 40),
+				)
+				mock.ExpectExec("INSERT INTO withdrawals (.+) ON CONFLICT").WithArgs(sqlmock.AnyArg(), "6KQJ7ZPR2", "DW74MSY5X", "Moving away", 4000).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			ExpectedStatusCode: http.StatusCreated,
+			ExpectedContent:    `"status":"pending","refundAmount":4000,"refunded":0`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db}
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer([]byte(test.RequestBody)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, model.Account{ID: test.AccountID})
+		c.SetPath("/api/registrations/:id/withdrawals")
+		c.SetParamNames("id")
+		c.SetParamValues(util.ReturnSignedToken("6KQJ7ZPR2"))
+		// Perform Request
+		if assert.NoError(t, api.requestWithdrawal(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestReviewWithdrawal(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	registration := func(amountDue, amountPaid int) *sqlmock.Rows {
+		return sqlmock.NewRows(registrationColumns).AddRow("6KQJ7ZPR2", "123ABC", "BJ7Q4NVRN", amountDue, amountPaid, time.Now())
+	}
+	testCases := []struct {
+		Description        string
+		RequestBody        string
+		Mock               func(mock sqlmock.Sqlmock)
+		ExpectedStatusCode int
+		ExpectedContent    string
+	}{
+		{
+			Description: "Already Reviewed",
+			RequestBody: `{"status":"approved"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM withdrawals WHERE id = (.+)").WithArgs("5MXR8TQ2D").WillReturnRows(withdrawalRow("denied", 0))
+			},
+			ExpectedStatusCode: http.StatusConflict,
+			ExpectedContent:    `"detail":"withdrawal has already been reviewed"`,
+		},
+		{
+			Description: "Withdrawal Denied",
+			RequestBody: `{"status":"denied","note":"Season has started"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM withdrawals WHERE id = (.+)").WithArgs("5MXR8TQ2D").WillReturnRows(withdrawalRow("pending", 4000))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+) FOR UPDATE").WithArgs("6KQJ7ZPR2").WillReturnRows(registration(10000, 0))
+				mock.ExpectExec("UPDATE withdrawals SET (.+) WHERE id = (.+) AND status = 'pending'").WithArgs("denied", 4000, 0, "Season has started", "ERCXNX5", "5MXR8TQ2D").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"status":"denied","refundAmount":4000,"refunded":0,"note":"Season has started"`,
+		},
+		{
+			Description: "Player Removed From Registration",
+			RequestBody: `{"status":"approved"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM withdrawals WHERE id = (.+)").WithArgs("5MXR8TQ2D").WillReturnRows(withdrawalRow("pending", 4000))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+) FOR UPDATE").WithArgs("6KQJ7ZPR2").WillReturnRows(registration(10000, 0))
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationPlayerColumns))
+				mock.ExpectQuery("SELECT (.+) FROM registration_adjustments WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationAdjustmentColumns))
+				mock.ExpectRollback()
+			},
+			ExpectedStatusCode: http.StatusConflict,
+			ExpectedContent:    `"detail":"player has already withdrawn"`,
+		},
+		{
+			Description: "Unpaid Fee Partly Retained",
+			RequestBody: `{"status":"approved"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM withdrawals WHERE id = (.+)").WithArgs("5MXR8TQ2D").WillReturnRows(withdrawalRow("pending", 4000))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+) FOR UPDATE").WithArgs("6KQJ7ZPR2").WillReturnRows(registration(10000, 0))
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationPlayerRows())
+				mock.ExpectQuery("SELECT (.+) FROM registration_adjustments WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationAdjustmentColumns))
+				mock.ExpectExec("UPDATE withdrawals SET (.+) WHERE id = (.+) AND status = 'pending'").WithArgs("approved", 4000, 0, "", "ERCXNX5", "5MXR8TQ2D").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(divisionCapacityLock).WithArgs("BJ7Q4NVRN", "U12").WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec("UPDATE registration_players SET (.+) WHERE (.+)").WithArgs("withdrawn", 6000, "6KQJ7ZPR2", "DW74MSY5X").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE players SET team = '' WHERE id = (.+)").WithArgs("DW74MSY5X").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE registrations SET amount_due = (.+) WHERE id = (.+)").WithArgs(6000, "6KQJ7ZPR2").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(divisionCapacityLock).WithArgs("BJ7Q4NVRN", "U12").WillReturnError(sql.ErrNoRows)
+				mock.ExpectCommit()
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"status":"approved","refundAmount":4000,"refunded":0`,
+		},
+		{
+			Description: "Waitlisted Player Withdrawn Without Refund",
+			RequestBody: `{"status":"approved"}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM withdrawals WHERE id = (.+)").WithArgs("5MXR8TQ2D").WillReturnRows(withdrawalRow("pending", 4000))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+) FOR UPDATE").WithArgs("6KQJ7ZPR2").WillReturnRows(registration(0, 0))
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(
+					sqlmock.NewRows(registrationPlayerColumns).AddRow("6KQJ7ZPR2", "DW74MSY5X", "BJ7Q4NVRN", "Leagueify", "Player", "U12", "waitlisted", 10000, 0, nil, time.Now(), time.Now()),
+				)
+				mock.ExpectQuery("SELECT (.+) FROM registration_adjustments WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationAdjustmentColumns))
+				mock.ExpectExec("UPDATE withdrawals SET (.+) WHERE id = (.+) AND status = 'pending'").WithArgs("approved", 0, 0, "", "ERCXNX5", "5MXR8TQ2D").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(divisionCapacityLock).WithArgs("BJ7Q4NVRN", "U12").WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec("UPDATE registration_players SET (.+) WHERE (.+)").WithArgs("withdrawn", 0, "6KQJ7ZPR2", "DW74MSY5X").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE players SET team = '' WHERE id = (.+)").WithArgs("DW74MSY5X").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE registrations SET amount_due = (.+) WHERE id = (.+)").WithArgs(0, "6KQJ7ZPR2").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(divisionCapacityLock).WithArgs("BJ7Q4NVRN", "U12").WillReturnError(sql.ErrNoRows)
+				mock.ExpectCommit()
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"status":"approved","refundAmount":0,"refunded":0`,
+		},
+		{
+			Description: "Paid Fee Refunded Through Provider",
+			RequestBody: `{"status":"approved","refundAmount":10000}`,
+			Mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT (.+) FROM withdrawals WHERE id = (.+)").WithArgs("5MXR8TQ2D").WillReturnRows(withdrawalRow("pending", 0))
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+) FOR UPDATE").WithArgs("6KQJ7ZPR2").WillReturnRows(registration(10000, 6000))
+				mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationPlayerRows())
+				mock.ExpectQuery("SELECT (.+) FROM registration_adjustments WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationAdjustmentColumns))
+				mock.ExpectQuery("SELECT (.+) FROM payment_ledger WHERE registration_id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(paymentRows())
+				mock.ExpectExec("UPDATE withdrawals SET (.+) WHERE id = (.+) AND status = 'pending'").WithArgs("approved", 10000, 6000, "", "ERCXNX5", "5MXR8TQ2D").WillReturnResult(sqlmock.NewResult(0, 1))
+				expectDivisionCapacity(mock, "U12", 12, 12, 0, 1)
+				mock.ExpectExec("UPDATE registration_players SET (.+) WHERE (.+)").WithArgs("refunded", 0, "6KQJ7ZPR2", "DW74MSY5X").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE players SET team = '' WHERE id = (.+)").WithArgs("DW74MSY5X").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE registrations SET amount_due = (.+) WHERE id = (.+)").WithArgs(0, "6KQJ7ZPR2").WillReturnResult(sqlmock.NewResult(0, 1))
+				expectDivisionCapacity(mock, "U12", 12, 11, 0, 1)
+				mock.ExpectQuery("WITH offered AS (.+) FROM offered").WithArgs("BJ7Q4NVRN", "U12", 48, 1).WillReturnRows(waitlistOfferRows())
+				mock.ExpectExec("INSERT INTO payment_ledger (.+) ON CONFLICT").WithArgs(sqlmock.AnyArg(), "6KQJ7ZPR2", "refund", 6000, "fake", "fake_re_1", "7PWXQ2M4K").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE registrations SET amount_paid = (.+) WHERE id = (.+)").WithArgs(-6000, "6KQJ7ZPR2").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				mock.ExpectExec("INSERT INTO email_outbox (.+) VALUES (.+)").WithArgs(sqlmock.AnyArg(), "test@leagueify.org", "A place has opened up for Leagueify Sibling in 2024-2025", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedContent:    `"status":"approved","refundAmount":10000,"refunded":6000`,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		// Determine if tests should have mock DB
+		if test.Mock != nil {
+			test.Mock(mock)
+		}
+		// Initialize Echo and the Echo validator
+		e := echo.New()
+		e.Validator = &API{Validator: validator.New()}
+		api := API{DB: db, PaymentProvider: payments.NewFake("http://localhost", "fake_secret")}
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer([]byte(test.RequestBody)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, model.Account{ID: "ERCXNX5", Roles: pq.StringArray{"registrar"}})
+		c.SetPath("/api/withdrawals/:id")
+		c.SetParamNames("id")
+		c.SetParamValues(util.ReturnSignedToken("5MXR8TQ2D"))
+		// Perform Request
+		if assert.NoError(t, api.reviewWithdrawal(c)) {
+			// Assert Status Code
+			assert.Equal(t, test.ExpectedStatusCode, rec.Code, test.Description)
+			// Validate Response Body
+			match, err := regexp.MatchString(test.ExpectedContent, rec.Body.String())
+			assert.NoError(t, err)
+			assert.True(t, match, fmt.Sprintf("%v: Expected %v but received %v",
+				test.Description, test.ExpectedContent, rec.Body.String(),
+			))
+		}
+		// Assert All Expectations Met
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestReviewWithdrawalRetried(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	// Create Mock DB
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when creating mock DB", err)
+	}
+	db := postgres.Postgres{DB: mockDB}
+	fake := payments.NewFake("http://localhost", "fake_secret")
+	e := echo.New()
+	e.Validator = &API{Validator: validator.New()}
+	api := API{DB: db, PaymentProvider: fake}
+	expectApproval := func(commit error) {
+		mock.ExpectQuery("SELECT (.+) FROM withdrawals WHERE id = (.+)").WithArgs("5MXR8TQ2D").WillReturnRows(withdrawalRow("pending", 0))
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT (.+) FROM registrations WHERE id = (.+) FOR UPDATE").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationColumns).AddRow("6KQJ7ZPR2", "123ABC", "BJ7Q4NVRN", 10000, 6000, time.Now()))
+		mock.ExpectQuery("SELECT (.+) FROM registration_players (.+) WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(registrationPlayerRows())
+		mock.ExpectQuery("SELECT (.+) FROM registration_adjustments WHERE (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(sqlmock.NewRows(registrationAdjustmentColumns))
+		mock.ExpectQuery("SELECT (.+) FROM payment_ledger WHERE registration_id = (.+)").WithArgs("6KQJ7ZPR2").WillReturnRows(paymentRows())
+		mock.ExpectExec("UPDATE withdrawals SET (.+) WHERE id = (.+) AND status = 'pending'").WithArgs("approved", 10000, 6000, "", "ERCXNX5", "5MXR8TQ2D").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(divisionCapacityLock).WithArgs("BJ7Q4NVRN", "U12").WillReturnError(sql.ErrNoRows)
+		mock.ExpectExec("UPDATE registration_players SET (.+) WHERE (.+)").WithArgs("refunded", 0, "6KQJ7ZPR2", "DW74MSY5X").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE players SET team = '' WHERE id = (.+)").WithArgs("DW74MSY5X").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE registrations SET amount_due = (.+) WHERE id = (.+)").WithArgs(0, "6KQJ7ZPR2").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(divisionCapacityLock).WithArgs("BJ7Q4NVRN", "U12").WillReturnError(sql.ErrNoRows)
+		mock.ExpectExec("INSERT INTO payment_ledger (.+) ON CONFLICT").WithArgs(sqlmock.AnyArg(), "6KQJ7ZPR2", "refund", 6000, "fake", "fake_re_1", "7PWXQ2M4K").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE registrations SET amount_paid = (.+) WHERE id = (.+)").WithArgs(-6000, "6KQJ7ZPR2").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit().WillReturnError(commit)
+	}
+	// the first review fails after the provider refunded the payment, and
+	// the retried review is given the same refund
+	for _, commit := range []error{sql.ErrConnDone, nil} {
+		expectApproval(commit)
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer([]byte(`{"status":"approved","refundAmount":10000}`)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		setAccount(c, model.Account{ID: "ERCXNX5", Roles: pq.StringArray{"registrar"}})
+		c.SetParamNames("id")
+		c.SetParamValues(util.ReturnSignedToken("5MXR8TQ2D"))
+		assert.NoError(t, api.reviewWithdrawal(c))
+	}
+	assert.Len(t, fake.Refunds(), 1)
+	// Assert All Expectations Met
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
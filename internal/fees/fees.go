@@ -0,0 +1,189 @@
+package fees
+
+import (
+	"errors"
+
+	"github.com/Leagueify/api/internal/model"
+)
+
+var (
+	// ErrAlreadyRegistered is returned when a quoted player is already active
+	// on the registration.
+	ErrAlreadyRegistered = errors.New("player is already registered for this season")
+	// ErrNoSchedule is returned when a season has fee schedules but none
+	// applies to a quoted player's division.
+	ErrNoSchedule = errors.New("no fee schedule applies to the player's division")
+)
+
+// Active reports whether a registration status holds or is waiting for a
+// place in the season. Active players count as siblings and towards the
+// family maximum.
+func Active(status string) bool {
+	return Placed(status) || status == model.RegistrationWaitlisted
+}
+
+// Placed reports whether a registration status holds a place in the season.
+// Only placed players are charged their fee, waitlisted players are charged
+// once they take a place.
+func Placed(status string) bool {
+	switch status {
+	case model.RegistrationPending, model.RegistrationConfirmed:
+		return true
+	}
+	return false
+}
+
+// Quote prices players being added to a registration on date, a
+// time.DateOnly string. Players already on the registration keep what they
+// were charged, or retained when they withdrew. Every player after the first
+// active one receives their schedule's sibling discount. The total never
+// exceeds the lowest family maximum of the schedules involved, even once
+// waitlisted players take a place. Seasons without fee schedules are free.
+func Quote(schedules []model.FeeSchedule, date string, registered []model.RegistrationPlayer, players []model.Player) (model.RegistrationQuote, error) {
+	quote := model.RegistrationQuote{Players: []model.QuoteLine{}}
+	familyMax := 0
+	held := 0
+	retained := 0
+	siblings := 0
+	for _, player := range registered {
+		retained += player.Retained
+		if !Active(player.Status) {
+			continue
+		}
+		if Placed(player.Status) {
+			quote.PreviousAmountDue += player.Amount
+		}
+		held += player.Amount
+		siblings++
+		if schedule, ok := schedule(schedules, player.Division); ok {
+			familyMax = lowerMax(familyMax, schedule.FamilyMax)
+		}
+	}
+	for _, player := range players {
+		for _, existing := range registered {
+			if existing.PlayerID == player.ID && Active(existing.Status) {
+				return quote, ErrAlreadyRegistered
+			}
+		}
+		line := model.QuoteLine{
+			PlayerID:  player.ID,
+			FirstName: player.FirstName,
+			LastName:  player.LastName,
+			Division:  player.Division,
+		}
+		if len(schedules) > 0 {
+			schedule, ok := schedule(schedules, player.Division)
+			if !ok {
+				return quote, ErrNoSchedule
+			}
+			line.Fee = fee(schedule, date)
+			if siblings > 0 {
+				line.SiblingDiscount = line.Fee * schedule.SiblingDiscount / 100
+			}
+			familyMax = lowerMax(familyMax, schedule.FamilyMax)
+		}
+		line.Amount = line.Fee - line.SiblingDiscount
+		quote.Subtotal += line.Amount
+		quote.Players = append(quote.Players, line)
+		siblings++
+	}
+	// reduce the latest players first so earlier players keep their price
+	excess := held + quote.Subtotal - familyMax
+	for index := len(quote.Players) - 1; familyMax > 0 && excess > 0 && index >= 0; index-- {
+		line := &quote.Players[index]
+		line.FamilyDiscount = min(line.Amount, excess)
+		line.Amount -= line.FamilyDiscount
+		quote.Subtotal -= line.FamilyDiscount
+		excess -= line.FamilyDiscount
+	}
+	quote.PreviousAmountDue += retained
+	quote.AmountDue = quote.PreviousAmountDue + quote.Subtotal
+	return quote, nil
+}
+
+// AmountDue returns the amount due for a registration's players less its
+// adjustments. Adjustments never make a registration owe less than nothing.
+func AmountDue(players []model.RegistrationPlayer, adjustments []model.RegistrationAdjustment) int {
+	amountDue := 0
+	for _, player := range players {
+		amountDue += player.Retained
+		if Placed(player.Status) {
+			amountDue += player.Amount
+		}
+	}
+	return max(amountDue-total(adjustments), 0)
+}
+
+// CouponDiscount returns the discount the coupon takes off amountDue.
+func CouponDiscount(coupon model.Coupon, amountDue int) int {
+	if coupon.Kind == model.CouponPercent {
+		return amountDue * coupon.Value / 100
+	}
+	return min(coupon.Value, amountDue)
+}
+
+// Discount takes the registration's adjustments off a quote.
+func Discount(quote model.RegistrationQuote, adjustments []model.RegistrationAdjustment) model.RegistrationQuote {
+	quote.Discounts = total(adjustments)
+	quote.AmountDue = max(quote.PreviousAmountDue+quote.Subtotal-quote.Discounts, 0)
+	quote.PreviousAmountDue = max(quote.PreviousAmountDue-quote.Discounts, 0)
+	return quote
+}
+
+// Refund returns the part of a player's fee refunded under the season's
+// refund policy when they withdraw on date, a time.DateOnly string.
+func Refund(policy model.RefundPolicy, amount int, date string) int {
+	switch {
+	case policy.FullRefundUntil != "" && date <= policy.FullRefundUntil:
+		return amount
+	case policy.PartialRefundUntil != "" && date <= policy.PartialRefundUntil:
+		return amount * policy.PartialRefundPercent / 100
+	}
+	return 0
+}
+
+// fee returns the schedule's fee on date. Dates are time.DateOnly strings,
+// which order the same as the dates they represent.
+func fee(schedule model.FeeSchedule, date string) int {
+	fee := schedule.BaseFee
+	if schedule.EarlyBirdFee != nil && date <= schedule.EarlyBirdEnds {
+		fee = *schedule.EarlyBirdFee
+	}
+	if schedule.LateFeeStarts != "" && date >= schedule.LateFeeStarts {
+		fee += schedule.LateFee
+	}
+	return fee
+}
+
+// lowerMax returns the lower of two family maximums, where zero is no maximum.
+func lowerMax(current, next int) int {
+	if current == 0 || (next > 0 && next < current) {
+		return next
+	}
+	return current
+}
+
+// schedule returns the schedule for the division, falling back to the
+// season's schedule without a division.
+func schedule(schedules []model.FeeSchedule, division string) (model.FeeSchedule, bool) {
+	var fallback model.FeeSchedule
+	found := false
+	for _, schedule := range schedules {
+		if schedule.Division == division {
+			return schedule, true
+		}
+		if schedule.Division == "" {
+			fallback, found = schedule, true
+		}
+	}
+	return fallback, found
+}
+
+// total returns the sum of the adjustments.
+func total(adjustments []model.RegistrationAdjustment) int {
+	total := 0
+	for _, adjustment := range adjustments {
+		total += adjustment.Amount
+	}
+	return total
+}
@@ -0,0 +1,315 @@
+package fees
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Leagueify/api/internal/model"
+)
+
+func TestQuote(t *testing.T) {
+	earlyBird := 8000
+	schedules := []model.FeeSchedule{
+		{
+			Division:        "",
+			BaseFee:         10000,
+			EarlyBirdFee:    &earlyBird,
+			EarlyBirdEnds:   "2024-02-01",
+			LateFee:         2500,
+			LateFeeStarts:   "2024-03-01",
+			SiblingDiscount: 10,
+			FamilyMax:       25000,
+		},
+		{
+			Division: "U8",
+			BaseFee:  6000,
+		},
+	}
+	players := []model.Player{
+		{ID: "DW74MSY5X", Division: "U12"},
+		{ID: "49QRBF09Y", Division: "U12"},
+	}
+
+	testCases := []struct {
+		Description       string
+		Schedules         []model.FeeSchedule
+		Date              string
+		Registered        []model.RegistrationPlayer
+		Players           []model.Player
+		ExpectedAmounts   []int
+		ExpectedAmountDue int
+		ExpectedError     error
+	}{
+		{
+			Description:       "No Schedules",
+			Date:              "2024-02-15",
+			Players:           players,
+			ExpectedAmounts:   []int{0, 0},
+			ExpectedAmountDue: 0,
+		},
+		{
+			Description:       "Early Bird With Sibling Discount",
+			Schedules:         schedules,
+			Date:              "2024-02-01",
+			Players:           players,
+			ExpectedAmounts:   []int{8000, 7200},
+			ExpectedAmountDue: 15200,
+		},
+		{
+			Description:       "Base Fee",
+			Schedules:         schedules,
+			Date:              "2024-02-02",
+			Players:           players[:1],
+			ExpectedAmounts:   []int{10000},
+			ExpectedAmountDue: 10000,
+		},
+		{
+			Description:       "Late Fee",
+			Schedules:         schedules,
+			Date:              "2024-03-01",
+			Players:           players[:1],
+			ExpectedAmounts:   []int{12500},
+			ExpectedAmountDue: 12500,
+		},
+		{
+			Description:       "Division Schedule",
+			Schedules:         schedules,
+			Date:              "2024-03-01",
+			Players:           []model.Player{{ID: "DW74MSY5X", Division: "U8"}},
+			ExpectedAmounts:   []int{6000},
+			ExpectedAmountDue: 6000,
+		},
+		{
+			Description: "Sibling Already Registered",
+			Schedules:   schedules,
+			Date:        "2024-02-15",
+			Registered: []model.RegistrationPlayer{
+				{PlayerID: "W4SBH35WV", Status: model.RegistrationConfirmed, Amount: 10000},
+				{PlayerID: "QP4RD39CE", Status: model.RegistrationWithdrawn, Amount: 10000},
+			},
+			Players:           players[:1],
+			ExpectedAmounts:   []int{9000},
+			ExpectedAmountDue: 19000,
+		},
+		{
+			Description: "Family Maximum",
+			Schedules:   schedules,
+			Date:        "2024-02-15",
+			Registered: []model.RegistrationPlayer{
+				{PlayerID: "W4SBH35WV", Status: model.RegistrationConfirmed, Amount: 10000},
+			},
+			Players:           players,
+			ExpectedAmounts:   []int{9000, 6000},
+			ExpectedAmountDue: 25000,
+		},
+		{
+			Description: "Withdrawn Sibling Retained Fee",
+			Schedules:   schedules,
+			Date:        "2024-02-15",
+			Registered: []model.RegistrationPlayer{
+				{PlayerID: "W4SBH35WV", Status: model.RegistrationConfirmed, Amount: 10000},
+				{PlayerID: "QP4RD39CE", Status: model.RegistrationWithdrawn, Amount: 10000, Retained: 5000},
+			},
+			Players:           players,
+			ExpectedAmounts:   []int{9000, 6000},
+			ExpectedAmountDue: 30000,
+		},
+		{
+			Description: "Waitlisted Sibling",
+			Schedules:   schedules,
+			Date:        "2024-02-15",
+			Registered: []model.RegistrationPlayer{
+				{PlayerID: "W4SBH35WV", Status: model.RegistrationConfirmed, Amount: 10000},
+				{PlayerID: "QP4RD39CE", Status: model.RegistrationWaitlisted, Amount: 9000},
+			},
+			Players:           players,
+			ExpectedAmounts:   []int{6000, 0},
+			ExpectedAmountDue: 16000,
+		},
+		{
+			Description: "Player Already Registered",
+			Schedules:   schedules,
+			Date:        "2024-02-15",
+			Registered: []model.RegistrationPlayer{
+				{PlayerID: "DW74MSY5X", Status: model.RegistrationPending, Amount: 10000},
+			},
+			Players:       players[:1],
+			ExpectedError: ErrAlreadyRegistered,
+		},
+		{
+			Description:   "No Schedule For Division",
+			Schedules:     schedules[1:],
+			Date:          "2024-02-15",
+			Players:       players[:1],
+			ExpectedError: ErrNoSchedule,
+		},
+	}
+
+	for _, test := range testCases {
+		quote, err := Quote(test.Schedules, test.Date, test.Registered, test.Players)
+		if !errors.Is(err, test.ExpectedError) {
+			t.Errorf("%v: Expected error %v received %v", test.Description, test.ExpectedError, err)
+			continue
+		}
+		if test.ExpectedError != nil {
+			continue
+		}
+		if len(quote.Players) != len(test.ExpectedAmounts) {
+			t.Fatalf("%v: Expected %v players received %v", test.Description, len(test.ExpectedAmounts), len(quote.Players))
+		}
+		for index, line := range quote.Players {
+			if line.Amount != test.ExpectedAmounts[index] {
+				t.Errorf("%v: Expected player %v to cost %v received %v", test.Description, index, test.ExpectedAmounts[index], line.Amount)
+			}
+			if line.Fee-line.SiblingDiscount-line.FamilyDiscount != line.Amount {
+				t.Errorf("%v: Expected player %v discounts to add up to its amount", test.Description, index)
+			}
+		}
+		if quote.AmountDue != test.ExpectedAmountDue {
+			t.Errorf("%v: Expected amount due %v received %v", test.Description, test.ExpectedAmountDue, quote.AmountDue)
+		}
+	}
+}
+
+func TestAmountDue(t *testing.T) {
+	players := []model.RegistrationPlayer{
+		{PlayerID: "DW74MSY5X", Status: model.RegistrationConfirmed, Amount: 10000},
+		{PlayerID: "49QRBF09Y", Status: model.RegistrationWithdrawn, Amount: 9000},
+		{PlayerID: "W4SBH35WV", Status: model.RegistrationPending, Amount: 9000},
+	}
+
+	testCases := []struct {
+		Description       string
+		Adjustments       []model.RegistrationAdjustment
+		ExpectedAmountDue int
+	}{
+		{
+			Description:       "No Adjustments",
+			ExpectedAmountDue: 19000,
+		},
+		{
+			Description: "Coupon and Financial Aid",
+			Adjustments: []model.RegistrationAdjustment{
+				{Kind: model.AdjustmentCoupon, Amount: 1900},
+				{Kind: model.AdjustmentAid, Amount: 5000},
+			},
+			ExpectedAmountDue: 12100,
+		},
+		{
+			Description: "Adjustments Exceed Fees",
+			Adjustments: []model.RegistrationAdjustment{
+				{Kind: model.AdjustmentAid, Amount: 25000},
+			},
+			ExpectedAmountDue: 0,
+		},
+	}
+
+	for _, test := range testCases {
+		if amountDue := AmountDue(players, test.Adjustments); amountDue != test.ExpectedAmountDue {
+			t.Errorf("%v: Expected amount due %v received %v", test.Description, test.ExpectedAmountDue, amountDue)
+		}
+		quote := Discount(model.RegistrationQuote{PreviousAmountDue: 19000, Subtotal: 0}, test.Adjustments)
+		if quote.AmountDue != test.ExpectedAmountDue {
+			t.Errorf("%v: Expected quoted amount due %v received %v", test.Description, test.ExpectedAmountDue, quote.AmountDue)
+		}
+	}
+
+	withdrawn := append(players, model.RegistrationPlayer{
+		PlayerID: "QP4RD39CE", Status: model.RegistrationRefunded, Amount: 9000, Retained: 2700,
+	})
+	if amountDue := AmountDue(withdrawn, nil); amountDue != 21700 {
+		t.Errorf("Retained Fee: Expected amount due %v received %v", 21700, amountDue)
+	}
+
+	waitlisted := append(players, model.RegistrationPlayer{
+		PlayerID: "QP4RD39CE", Status: model.RegistrationWaitlisted, Amount: 9000,
+	})
+	if amountDue := AmountDue(waitlisted, nil); amountDue != 19000 {
+		t.Errorf("Waitlisted Player: Expected amount due %v received %v", 19000, amountDue)
+	}
+}
+
+func TestCouponDiscount(t *testing.T) {
+	testCases := []struct {
+		Description      string
+		Coupon           model.Coupon
+		AmountDue        int
+		ExpectedDiscount int
+	}{
+		{
+			Description:      "Percent",
+			Coupon:           model.Coupon{Kind: model.CouponPercent, Value: 15},
+			AmountDue:        19000,
+			ExpectedDiscount: 2850,
+		},
+		{
+			Description:      "Fixed",
+			Coupon:           model.Coupon{Kind: model.CouponFixed, Value: 2500},
+			AmountDue:        19000,
+			ExpectedDiscount: 2500,
+		},
+		{
+			Description:      "Fixed Exceeds Amount Due",
+			Coupon:           model.Coupon{Kind: model.CouponFixed, Value: 2500},
+			AmountDue:        1000,
+			ExpectedDiscount: 1000,
+		},
+	}
+
+	for _, test := range testCases {
+		if discount := CouponDiscount(test.Coupon, test.AmountDue); discount != test.ExpectedDiscount {
+			t.Errorf("%v: Expected discount %v received %v", test.Description, test.ExpectedDiscount, discount)
+		}
+	}
+}
+
+func TestRefund(t *testing.T) {
+	policy := model.RefundPolicy{
+		FullRefundUntil:      "2024-02-01",
+		PartialRefundUntil:   "2024-03-01",
+		PartialRefundPercent: 40,
+	}
+
+	testCases := []struct {
+		Description    string
+		Policy         model.RefundPolicy
+		Date           string
+		ExpectedRefund int
+	}{
+		{
+			Description:    "No Policy",
+			Date:           "2024-01-15",
+			ExpectedRefund: 0,
+		},
+		{
+			Description:    "Full Refund",
+			Policy:         policy,
+			Date:           "2024-02-01",
+			ExpectedRefund: 12500,
+		},
+		{
+			Description:    "Partial Refund",
+			Policy:         policy,
+			Date:           "2024-03-01",
+			ExpectedRefund: 5000,
+		},
+		{
+			Description:    "No Refund",
+			Policy:         policy,
+			Date:           "2024-03-02",
+			ExpectedRefund: 0,
+		},
+		{
+			Description:    "Partial Refund Only",
+			Policy:         model.RefundPolicy{PartialRefundUntil: "2024-03-01", PartialRefundPercent: 50},
+			Date:           "2024-01-15",
+			ExpectedRefund: 6250,
+		},
+	}
+
+	for _, test := range testCases {
+		if refund := Refund(test.Policy, 12500, test.Date); refund != test.ExpectedRefund {
+			t.Errorf("%v: Expected refund %v received %v", test.Description, test.ExpectedRefund, refund)
+		}
+	}
+}
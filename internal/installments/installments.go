@@ -0,0 +1,55 @@
+// Package installments schedules registrations paid over time and reminds
+// account holders of installments they have missed.
+package installments
+
+import (
+	"time"
+
+	"github.com/Leagueify/api/internal/model"
+)
+
+// Schedule returns the installments of a registration joining the plan on
+// start. The first installment is due on start and each after it
+// plan.IntervalDays later.
+func Schedule(plan model.InstallmentPlan, registrationID string, start time.Time) []model.Installment {
+	installments := []model.Installment{}
+	for number := 1; number <= plan.Installments; number++ {
+		installments = append(installments, model.Installment{
+			RegistrationID: registrationID,
+			PlanID:         plan.ID,
+			Number:         number,
+			DueOn:          start.AddDate(0, 0, (number-1)*plan.IntervalDays).Format(time.DateOnly),
+		})
+	}
+	return installments
+}
+
+// Apply works out the amount and status of each installment on today from
+// the registration's amount due and amount paid. Payments are applied to the
+// earliest installments first and any remainder of splitting the amount due
+// falls on the later installments.
+func Apply(installments []model.Installment, amountDue, amountPaid int, today string) []model.Installment {
+	for index := range installments {
+		installment := &installments[index]
+		installment.Amount = Covered(amountDue, installment.Number, len(installments)) -
+			Covered(amountDue, installment.Number-1, len(installments))
+		switch {
+		case amountPaid >= Covered(amountDue, installment.Number, len(installments)):
+			installment.Status = model.InstallmentPaid
+		case installment.DueOn < today:
+			installment.Status = model.InstallmentOverdue
+		default:
+			installment.Status = model.InstallmentDue
+		}
+	}
+	return installments
+}
+
+// Covered returns the amount that must be paid for the first number of count
+// installments to be paid.
+func Covered(amountDue, number, count int) int {
+	if count == 0 {
+		return 0
+	}
+	return amountDue * number / count
+}
@@ -0,0 +1,88 @@
+package installments
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Leagueify/api/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedule(t *testing.T) {
+	plan := model.InstallmentPlan{ID: "PLAN1234", Installments: 3, IntervalDays: 30}
+	start := time.Date(2024, time.January, 15, 18, 0, 0, 0, time.UTC)
+
+	installments := Schedule(plan, "6KQJ7ZPR2", start)
+
+	assert.Equal(t, []model.Installment{
+		{RegistrationID: "6KQJ7ZPR2", PlanID: "PLAN1234", Number: 1, DueOn: "2024-01-15"},
+		{RegistrationID: "6KQJ7ZPR2", PlanID: "PLAN1234", Number: 2, DueOn: "2024-02-14"},
+		{RegistrationID: "6KQJ7ZPR2", PlanID: "PLAN1234", Number: 3, DueOn: "2024-03-15"},
+	}, installments)
+}
+
+func TestApply(t *testing.T) {
+	testCases := []struct {
+		Description      string
+		AmountDue        int
+		AmountPaid       int
+		Today            string
+		ExpectedAmounts  []int
+		ExpectedStatuses []string
+	}{
+		{
+			Description:      "Nothing Paid",
+			AmountDue:        30000,
+			Today:            "2024-01-15",
+			ExpectedAmounts:  []int{10000, 10000, 10000},
+			ExpectedStatuses: []string{"due", "due", "due"},
+		},
+		{
+			Description:      "First Installment Missed",
+			AmountDue:        30000,
+			AmountPaid:       5000,
+			Today:            "2024-02-01",
+			ExpectedAmounts:  []int{10000, 10000, 10000},
+			ExpectedStatuses: []string{"overdue", "due", "due"},
+		},
+		{
+			Description:      "Paid Ahead",
+			AmountDue:        30000,
+			AmountPaid:       20000,
+			Today:            "2024-03-20",
+			ExpectedAmounts:  []int{10000, 10000, 10000},
+			ExpectedStatuses: []string{"paid", "paid", "overdue"},
+		},
+		{
+			Description:      "Remainder on Later Installments",
+			AmountDue:        10000,
+			AmountPaid:       3333,
+			Today:            "2024-01-15",
+			ExpectedAmounts:  []int{3333, 3333, 3334},
+			ExpectedStatuses: []string{"paid", "due", "due"},
+		},
+		{
+			Description:      "Amount Due Lowered",
+			AmountDue:        0,
+			Today:            "2024-04-01",
+			ExpectedAmounts:  []int{0, 0, 0},
+			ExpectedStatuses: []string{"paid", "paid", "paid"},
+		},
+	}
+	for _, test := range testCases {
+		installments := Apply([]model.Installment{
+			{Number: 1, DueOn: "2024-01-15"},
+			{Number: 2, DueOn: "2024-02-14"},
+			{Number: 3, DueOn: "2024-03-15"},
+		}, test.AmountDue, test.AmountPaid, test.Today)
+
+		amounts := []int{}
+		statuses := []string{}
+		for _, installment := range installments {
+			amounts = append(amounts, installment.Amount)
+			statuses = append(statuses, installment.Status)
+		}
+		assert.Equal(t, test.ExpectedAmounts, amounts, test.Description)
+		assert.Equal(t, test.ExpectedStatuses, statuses, test.Description)
+	}
+}
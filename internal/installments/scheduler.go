@@ -0,0 +1,100 @@
+package installments
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Leagueify/api/internal/mail"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+)
+
+const (
+	// DefaultInterval is how often the Scheduler looks for overdue
+	// installments.
+	DefaultInterval = time.Hour
+	// DefaultRemindEvery is how long a registration that has not caught up
+	// waits between reminders.
+	DefaultRemindEvery = 7 * 24 * time.Hour
+)
+
+// Store is the persistence the Scheduler reads overdue installments from and
+// queues reminders in.
+type Store interface {
+	CreateOutboundEmail(email model.OutboundEmail) error
+	ListOverdueInstallments(today string, remindedBefore time.Time) ([]model.InstallmentReminder, error)
+	MarkInstallmentsReminded(registrationID string, number int) error
+}
+
+type Scheduler struct {
+	Store       Store
+	BaseURL     string
+	Currency    string
+	Interval    time.Duration
+	RemindEvery time.Duration
+	Now         func() time.Time
+}
+
+func NewScheduler(store Store, baseURL, currency string) *Scheduler {
+	return &Scheduler{
+		Store:       store,
+		BaseURL:     baseURL,
+		Currency:    currency,
+		Interval:    DefaultInterval,
+		RemindEvery: DefaultRemindEvery,
+		Now:         time.Now,
+	}
+}
+
+// Run flags overdue installments every Interval until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	util.RunEvery(ctx, s.Interval, func() error {
+		_, err := s.Remind()
+		return err
+	})
+}
+
+// Remind flags the unpaid installments past their due date as overdue and
+// queues a reminder for each registration with one, returning how many were
+// queued. Registrations are reminded again every RemindEvery until they catch
+// up.
+func (s *Scheduler) Remind() (int, error) {
+	now := s.Now()
+	reminders, err := s.Store.ListOverdueInstallments(
+		now.Format(time.DateOnly), now.Add(-s.RemindEvery),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var queued int
+	for _, reminder := range reminders {
+		email, err := mail.Compose(reminder.Email, "installment_reminder", map[string]any{
+			"FirstName":    reminder.FirstName,
+			"Season":       reminder.SeasonName,
+			"Number":       reminder.Number,
+			"Installments": reminder.Installments,
+			"DueOn":        reminder.DueOn,
+			"Overdue": util.FormatAmount(
+				Covered(reminder.AmountDue, reminder.Number, reminder.Installments)-reminder.AmountPaid,
+				s.Currency,
+			),
+			"Balance": util.FormatAmount(reminder.AmountDue-reminder.AmountPaid, s.Currency),
+			"Link": fmt.Sprintf(
+				"%s/registrations/%s", s.BaseURL, util.ReturnSignedToken(reminder.RegistrationID),
+			),
+		})
+		if err != nil {
+			return queued, err
+		}
+		if err := s.Store.CreateOutboundEmail(email); err != nil {
+			return queued, err
+		}
+		queued++
+		if err := s.Store.MarkInstallmentsReminded(reminder.RegistrationID, reminder.Number); err != nil {
+			return queued, err
+		}
+	}
+	return queued, nil
+}
@@ -0,0 +1,101 @@
+package installments
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Leagueify/api/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeStore struct {
+	reminders      []model.InstallmentReminder
+	listErr        error
+	today          string
+	remindedBefore time.Time
+	queued         []model.OutboundEmail
+	reminded       map[string]int
+}
+
+func (s *fakeStore) CreateOutboundEmail(email model.OutboundEmail) error {
+	s.queued = append(s.queued, email)
+	return nil
+}
+
+func (s *fakeStore) ListOverdueInstallments(today string, remindedBefore time.Time) ([]model.InstallmentReminder, error) {
+	s.today = today
+	s.remindedBefore = remindedBefore
+	return s.reminders, s.listErr
+}
+
+func (s *fakeStore) MarkInstallmentsReminded(registrationID string, number int) error {
+	s.reminded[registrationID] = number
+	return nil
+}
+
+func TestRemind(t *testing.T) {
+	now := time.Date(2024, time.March, 2, 9, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		Description      string
+		Store            *fakeStore
+		ExpectedQueued   int
+		ExpectedReminded map[string]int
+		ExpectedText     string
+		ExpectError      bool
+	}{
+		{
+			Description:      "Nothing Overdue",
+			Store:            &fakeStore{},
+			ExpectedReminded: map[string]int{},
+		},
+		{
+			Description: "Overdue Installment",
+			Store: &fakeStore{
+				reminders: []model.InstallmentReminder{
+					{
+						InstallmentID:  "INST12345",
+						RegistrationID: "6KQJ7ZPR2",
+						Number:         2,
+						Installments:   3,
+						DueOn:          "2024-02-14",
+						AmountDue:      30000,
+						AmountPaid:     12500,
+						Email:          "test@leagueify.org",
+						FirstName:      "Leagueify",
+						SeasonName:     "Spring 2024",
+					},
+				},
+			},
+			ExpectedQueued:   1,
+			ExpectedReminded: map[string]int{"6KQJ7ZPR2": 2},
+			ExpectedText:     "75.00 USD is overdue and your remaining balance is 175.00 USD.",
+		},
+		{
+			Description: "Store Unavailable",
+			Store: &fakeStore{
+				listErr: errors.New("connection refused"),
+			},
+			ExpectedReminded: map[string]int{},
+			ExpectError:      true,
+		},
+	}
+	for _, test := range testCases {
+		test.Store.reminded = map[string]int{}
+		scheduler := NewScheduler(test.Store, "http://localhost", "usd")
+		scheduler.Now = func() time.Time { return now }
+
+		queued, err := scheduler.Remind()
+
+		assert.Equal(t, test.ExpectError, err != nil, test.Description)
+		assert.Equal(t, test.ExpectedQueued, queued, test.Description)
+		assert.Len(t, test.Store.queued, test.ExpectedQueued, test.Description)
+		assert.Equal(t, test.ExpectedReminded, test.Store.reminded, test.Description)
+		assert.Equal(t, "2024-03-02", test.Store.today, test.Description)
+		assert.Equal(t, now.Add(-DefaultRemindEvery), test.Store.remindedBefore, test.Description)
+		if test.ExpectedText != "" {
+			assert.Equal(t, "test@leagueify.org", test.Store.queued[0].Recipient, test.Description)
+			assert.Contains(t, test.Store.queued[0].TextBody, test.ExpectedText, test.Description)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package invoices
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// US Letter in points, with the margin kept clear on every side.
+const (
+	pageWidth  = 612.0
+	pageHeight = 792.0
+	margin     = 50.0
+)
+
+// helveticaWidths are the widths of the printable ASCII characters, from
+// space to tilde, in thousandths of the font size. Helvetica-Bold is measured
+// with them as well; its digits and punctuation, which are all that is right
+// aligned, share them.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// winAnsi maps the characters outside Latin-1 that WinAnsiEncoding supports.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92,
+	'“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// document is a minimal PDF writer for text documents set in the standard
+// Helvetica fonts, which PDF readers provide without them being embedded.
+// Text is placed top down from the cursor y, and pages are added as it
+// reaches the bottom margin.
+type document struct {
+	pages []*bytes.Buffer
+	y     float64
+}
+
+func newDocument() *document {
+	d := &document{}
+	d.addPage()
+	return d
+}
+
+func (d *document) addPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pageHeight - margin
+}
+
+// advance moves the cursor down by height, starting a new page when there
+// is no room left on this one.
+func (d *document) advance(height float64) {
+	if d.y-height < margin {
+		d.addPage()
+	}
+	d.y -= height
+}
+
+// text writes s with its left edge at x on the cursor's line.
+func (d *document) text(x, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.pages[len(d.pages)-1], "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n",
+		font, size, x, d.y, escape(encode(s)),
+	)
+}
+
+// textRight writes s with its right edge at x on the cursor's line.
+func (d *document) textRight(x, size float64, bold bool, s string) {
+	d.text(x-width(s, size), size, bold, s)
+}
+
+// rule draws a horizontal line across the page just below the cursor.
+func (d *document) rule() {
+	fmt.Fprintf(d.pages[len(d.pages)-1], "0.5 w %.2f %.2f m %.2f %.2f l S\n",
+		margin, d.y-4, pageWidth-margin, d.y-4,
+	)
+}
+
+// bytes assembles the PDF file: the catalog, the page tree, both fonts and
+// each page followed by its content stream, then the cross-reference table
+// locating every object.
+func (d *document) bytes() []byte {
+	var file bytes.Buffer
+	offsets := []int{}
+	object := func(body string) {
+		offsets = append(offsets, file.Len())
+		fmt.Fprintf(&file, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	file.WriteString("%PDF-1.4\n")
+	kids := []string{}
+	for index := range d.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 5+2*index))
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for index, page := range d.pages {
+		object(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] "+
+				"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 6+2*index,
+		))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := file.Len()
+	fmt.Fprintf(&file, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&file, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&file, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return file.Bytes()
+}
+
+// encode converts s to WinAnsiEncoding, replacing characters it cannot
+// represent with a question mark.
+func encode(s string) string {
+	encoded := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			encoded = append(encoded, byte(r))
+		case winAnsi[r] != 0:
+			encoded = append(encoded, winAnsi[r])
+		default:
+			encoded = append(encoded, '?')
+		}
+	}
+	return string(encoded)
+}
+
+// escape makes encoded text safe within a PDF string literal.
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`).Replace(s)
+}
+
+// fit shortens s with an ellipsis until it is no wider than maxWidth.
+func fit(s string, size, maxWidth float64) string {
+	if width(s, size) <= maxWidth {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && width(string(runes)+"...", size) > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
+
+// width measures s set at size, counting characters outside printable ASCII
+// as wide as a digit.
+func width(s string, size float64) float64 {
+	var total int
+	for _, r := range s {
+		if r >= 0x20 && r < 0x7f {
+			total += helveticaWidths[r-0x20]
+			continue
+		}
+		total += 556
+	}
+	return float64(total) * size / 1000
+}
@@ -0,0 +1,25 @@
+package invoices
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+)
+
+//go:embed templates/*
+var templateFS embed.FS
+
+// HTML renders the invoice as a standalone HTML page.
+func HTML(invoice Invoice) ([]byte, error) {
+	page, err := template.New("invoice.html").Funcs(template.FuncMap{
+		"amount": invoice.amount,
+	}).ParseFS(templateFS, "templates/invoice.html")
+	if err != nil {
+		return nil, err
+	}
+	var html bytes.Buffer
+	if err := page.Execute(&html, invoice); err != nil {
+		return nil, err
+	}
+	return html.Bytes(), nil
+}
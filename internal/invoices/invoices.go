@@ -0,0 +1,130 @@
+// Package invoices renders registration invoices and receipts as HTML and
+// PDF documents.
+package invoices
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Leagueify/api/internal/fees"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+)
+
+// Titles of the document, a registration paid in full gets a receipt.
+const (
+	TitleInvoice = "Invoice"
+	TitleReceipt = "Receipt"
+)
+
+type (
+	// Records are what an invoice is drawn up from.
+	Records struct {
+		League       string
+		Season       model.Season
+		Account      model.Account
+		Registration model.Registration
+		Players      []model.RegistrationPlayer
+		Adjustments  []model.RegistrationAdjustment
+		Payments     []model.Payment
+	}
+
+	Invoice struct {
+		Title      string
+		Number     string
+		IssuedOn   string
+		League     string
+		Season     string
+		BilledTo   string
+		Email      string
+		Currency   string
+		Players    []Line
+		Discounts  []Line
+		Payments   []Line
+		Subtotal   int
+		Discount   int
+		AmountDue  int
+		AmountPaid int
+		Balance    int
+	}
+
+	// Line is an itemized entry of an invoice, amounts are in cents.
+	Line struct {
+		Date        string
+		Description string
+		Detail      string
+		Amount      int
+	}
+)
+
+// New draws up the invoice of a registration on issued. Players no longer
+// holding a place in the season are charged only the fee they retained and
+// refunds are listed as negative payments.
+func New(records Records, currency string, issued time.Time) Invoice {
+	registration := records.Registration
+	invoice := Invoice{
+		Title:      TitleInvoice,
+		Number:     util.ReturnSignedToken(registration.ID),
+		IssuedOn:   issued.Format(time.DateOnly),
+		League:     records.League,
+		Season:     records.Season.Name,
+		BilledTo:   strings.TrimSpace(records.Account.FirstName + " " + records.Account.LastName),
+		Email:      records.Account.Email,
+		Currency:   currency,
+		Players:    []Line{},
+		Discounts:  []Line{},
+		Payments:   []Line{},
+		AmountDue:  registration.AmountDue,
+		AmountPaid: registration.AmountPaid,
+		Balance:    registration.AmountDue - registration.AmountPaid,
+	}
+	if invoice.Balance <= 0 && invoice.AmountPaid > 0 {
+		invoice.Title = TitleReceipt
+	}
+	for _, player := range records.Players {
+		line := Line{
+			Date:        player.CreatedAt.Format(time.DateOnly),
+			Description: strings.TrimSpace(player.FirstName + " " + player.LastName),
+			Detail:      strings.TrimSpace(fmt.Sprintf("%s %s", player.Division, player.Status)),
+			Amount:      player.Retained,
+		}
+		if fees.Placed(player.Status) {
+			line.Amount += player.Amount
+		}
+		invoice.Subtotal += line.Amount
+		invoice.Players = append(invoice.Players, line)
+	}
+	for _, adjustment := range records.Adjustments {
+		invoice.Discount += adjustment.Amount
+		invoice.Discounts = append(invoice.Discounts, Line{
+			Date:        adjustment.CreatedAt.Format(time.DateOnly),
+			Description: adjustment.Description,
+			Amount:      adjustment.Amount,
+		})
+	}
+	for _, payment := range records.Payments {
+		line := Line{
+			Date:        payment.CreatedAt.Format(time.DateOnly),
+			Description: "Payment",
+			Detail:      payment.Reference,
+			Amount:      payment.Amount,
+		}
+		if payment.Kind == model.PaymentKindRefund {
+			line.Description = "Refund"
+			line.Amount = -payment.Amount
+		}
+		invoice.Payments = append(invoice.Payments, line)
+	}
+	return invoice
+}
+
+// Filename names the invoice's PDF, e.g. receipt-6KQJ7ZPR2Q.pdf.
+func (i Invoice) Filename() string {
+	return fmt.Sprintf("%s-%s.pdf", strings.ToLower(i.Title), i.Number)
+}
+
+// amount renders an amount in the invoice's currency.
+func (i Invoice) amount(amount int) string {
+	return util.FormatAmount(amount, i.Currency)
+}
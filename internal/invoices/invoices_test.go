@@ -0,0 +1,123 @@
+package invoices
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Leagueify/api/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+var issued = time.Date(2024, time.March, 2, 9, 0, 0, 0, time.UTC)
+
+func records(amountDue, amountPaid int) Records {
+	registered := time.Date(2024, time.January, 15, 12, 0, 0, 0, time.UTC)
+	return Records{
+		League:  "Leagueify Hockey League",
+		Season:  model.Season{Name: "Spring 2024"},
+		Account: model.Account{FirstName: "Leagueify", LastName: "Test", Email: "test@leagueify.org"},
+		Registration: model.Registration{
+			ID: "6KQJ7ZPR2", AmountDue: amountDue, AmountPaid: amountPaid,
+		},
+		Players: []model.RegistrationPlayer{
+			{FirstName: "Michael", LastName: "duBois", Division: "U12", Status: "confirmed", Amount: 20000, CreatedAt: registered},
+			{FirstName: "Anna (Jr)", LastName: "duBois", Division: "U8", Status: "withdrawn", Amount: 12000, CreatedAt: registered},
+		},
+		Adjustments: []model.RegistrationAdjustment{
+			{Description: "Coupon SPRING25", Amount: 5000, CreatedAt: registered},
+		},
+		Payments: []model.Payment{
+			{Kind: "payment", Amount: 15000, Reference: "pi_123", CreatedAt: registered},
+			{Kind: "refund", Amount: 1000, Reference: "re_456", CreatedAt: issued},
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+	invoice := New(records(15000, 14000), "usd", issued)
+
+	assert.Equal(t, TitleInvoice, invoice.Title)
+	assert.Equal(t, "2024-03-02", invoice.IssuedOn)
+	assert.Equal(t, "Leagueify Test", invoice.BilledTo)
+	assert.Equal(t, "Spring 2024", invoice.Season)
+	assert.Equal(t, []Line{
+		{Date: "2024-01-15", Description: "Michael duBois", Detail: "U12 confirmed", Amount: 20000},
+		{Date: "2024-01-15", Description: "Anna (Jr) duBois", Detail: "U8 withdrawn", Amount: 0},
+	}, invoice.Players)
+	assert.Equal(t, 20000, invoice.Subtotal)
+	assert.Equal(t, 5000, invoice.Discount)
+	assert.Equal(t, -1000, invoice.Payments[1].Amount)
+	assert.Equal(t, "Refund", invoice.Payments[1].Description)
+	assert.Equal(t, 1000, invoice.Balance)
+	assert.Equal(t, "invoice-"+invoice.Number+".pdf", invoice.Filename())
+
+	receipt := New(records(15000, 15000), "usd", issued)
+	assert.Equal(t, TitleReceipt, receipt.Title)
+	assert.Equal(t, "receipt-"+receipt.Number+".pdf", receipt.Filename())
+}
+
+func TestHTML(t *testing.T) {
+	invoice := New(records(15000, 14000), "usd", issued)
+	invoice.League = "<Leagueify>"
+
+	html, err := HTML(invoice)
+
+	assert.NoError(t, err)
+	assert.Contains(t, string(html), "<h1>&lt;Leagueify&gt;</h1>")
+	assert.Contains(t, string(html), `<td>Michael duBois</td><td>U12 confirmed</td><td class="amount">200.00 USD</td>`)
+	assert.Contains(t, string(html), `<td>Coupon SPRING25</td><td class="amount">50.00 USD</td>`)
+	assert.Contains(t, string(html), `<td>Refund</td><td>re_456</td><td class="amount">-10.00 USD</td>`)
+	assert.Contains(t, string(html), `<tr class="balance"><td>Balance</td><td class="amount">10.00 USD</td></tr>`)
+}
+
+func TestPDF(t *testing.T) {
+	invoice := New(records(15000, 14000), "usd", issued)
+	invoice.League = "Ligue de Hockey Montréal"
+
+	pdf := PDF(invoice)
+
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(pdf, []byte("%%EOF\n")))
+	assert.Contains(t, string(pdf), "(Ligue de Hockey Montr\xe9al) Tj")
+	assert.Contains(t, string(pdf), `(Anna \(Jr\) duBois) Tj`)
+	assert.Contains(t, string(pdf), "(-10.00 USD) Tj")
+	assertCrossReferences(t, pdf)
+}
+
+func TestPDFPages(t *testing.T) {
+	source := records(15000, 14000)
+	for number := 0; number < 60; number++ {
+		source.Payments = append(source.Payments, model.Payment{
+			Kind: "payment", Amount: 100, Reference: fmt.Sprintf("pi_%d", number), CreatedAt: issued,
+		})
+	}
+
+	pdf := PDF(New(source, "usd", issued))
+
+	assert.Contains(t, string(pdf), "/Count 2")
+	assert.Contains(t, string(pdf), "(pi_59) Tj")
+	assertCrossReferences(t, pdf)
+}
+
+// assertCrossReferences checks every object is where the cross-reference
+// table says it is.
+func assertCrossReferences(t *testing.T, pdf []byte) {
+	t.Helper()
+	start := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(pdf)
+	if !assert.NotNil(t, start) {
+		return
+	}
+	xref, _ := strconv.Atoi(string(start[1]))
+	assert.True(t, bytes.HasPrefix(pdf[xref:], []byte("xref\n")))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n \n`).FindAllSubmatch(pdf[xref:], -1)
+	assert.NotEmpty(t, entries)
+	for index, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		assert.True(t, strings.HasPrefix(string(pdf[offset:]), fmt.Sprintf("%d 0 obj\n", index+1)))
+	}
+}
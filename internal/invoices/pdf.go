@@ -0,0 +1,81 @@
+package invoices
+
+// Left edges of the detail and reference columns, amounts are right aligned
+// against the right margin.
+const (
+	dateColumn   = margin
+	detailColumn = 260.0
+	rightEdge    = pageWidth - margin
+)
+
+// PDF renders the invoice as a PDF document.
+func PDF(invoice Invoice) []byte {
+	d := newDocument()
+	d.advance(20)
+	d.text(margin, 20, true, fit(invoice.League, 20, 360))
+	d.textRight(rightEdge, 16, true, invoice.Title)
+	d.advance(28)
+	d.text(margin, 10, false, "Number: "+invoice.Number)
+	d.textRight(rightEdge, 10, false, "Issued: "+invoice.IssuedOn)
+	if invoice.Season != "" {
+		d.advance(14)
+		d.text(margin, 10, false, fit("Season: "+invoice.Season, 10, rightEdge-margin))
+	}
+	if invoice.Email != "" {
+		d.advance(14)
+		d.text(margin, 10, false, fit("Billed to: "+invoice.BilledTo+" <"+invoice.Email+">", 10, rightEdge-margin))
+	}
+
+	section(d, invoice, "Player", "Division", invoice.Players, false)
+	if len(invoice.Discounts) > 0 {
+		section(d, invoice, "Discount", "", invoice.Discounts, true)
+	}
+	if len(invoice.Payments) > 0 {
+		section(d, invoice, "Payment", "Reference", invoice.Payments, true)
+	}
+
+	d.advance(28)
+	for _, total := range []struct {
+		Label  string
+		Amount int
+	}{
+		{"Subtotal", invoice.Subtotal},
+		{"Discounts", invoice.Discount},
+		{"Amount due", invoice.AmountDue},
+		{"Amount paid", invoice.AmountPaid},
+	} {
+		d.text(detailColumn+100, 10, false, total.Label)
+		d.textRight(rightEdge, 10, false, invoice.amount(total.Amount))
+		d.advance(14)
+	}
+	d.text(detailColumn+100, 11, true, "Balance")
+	d.textRight(rightEdge, 11, true, invoice.amount(invoice.Balance))
+	return d.bytes()
+}
+
+// section writes a table of invoice lines under a header row, with the date
+// of each line in the first column when dated.
+func section(d *document, invoice Invoice, title, detail string, lines []Line, dated bool) {
+	descriptionColumn := margin
+	if dated {
+		descriptionColumn = dateColumn + 80
+	}
+	d.advance(30)
+	if dated {
+		d.text(dateColumn, 10, true, "Date")
+	}
+	d.text(descriptionColumn, 10, true, title)
+	d.text(detailColumn, 10, true, detail)
+	d.textRight(rightEdge, 10, true, "Amount")
+	d.rule()
+	d.advance(4)
+	for _, line := range lines {
+		d.advance(14)
+		if dated {
+			d.text(dateColumn, 10, false, line.Date)
+		}
+		d.text(descriptionColumn, 10, false, fit(line.Description, 10, detailColumn-descriptionColumn-10))
+		d.text(detailColumn, 10, false, fit(line.Detail, 10, 180))
+		d.textRight(rightEdge, 10, false, invoice.amount(line.Amount))
+	}
+}
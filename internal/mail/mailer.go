@@ -0,0 +1,120 @@
+package mail
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+)
+
+const (
+	DefaultBatchSize   = 20
+	DefaultInterval    = 30 * time.Second
+	DefaultMaxAttempts = 8
+	// leaseDuration hides claimed emails from other senders while in flight.
+	leaseDuration = 5 * time.Minute
+	maxBackoff    = 6 * time.Hour
+)
+
+// Outbox is the persistent queue the Mailer delivers from.
+type Outbox interface {
+	ClaimOutboundEmails(limit int, lease time.Duration) ([]model.OutboundEmail, error)
+	GetEmailConfig() (model.EmailConfig, error)
+	MarkOutboundEmailFailed(emailID, lastError string, nextAttemptAt time.Time, permanent bool) error
+	MarkOutboundEmailSent(emailID string) error
+	SetEmailConfigError(emailConfigID string, hasError bool) error
+}
+
+type Mailer struct {
+	Outbox      Outbox
+	Transport   Transport
+	BatchSize   int
+	Interval    time.Duration
+	MaxAttempts int
+}
+
+func NewMailer(outbox Outbox, transport Transport) *Mailer {
+	return &Mailer{
+		Outbox:      outbox,
+		Transport:   transport,
+		BatchSize:   DefaultBatchSize,
+		Interval:    DefaultInterval,
+		MaxAttempts: DefaultMaxAttempts,
+	}
+}
+
+// Run delivers queued email every Interval until ctx is cancelled.
+func (m *Mailer) Run(ctx context.Context) {
+	util.RunEvery(ctx, m.Interval, func() error {
+		_, err := m.Deliver()
+		return err
+	})
+}
+
+// Deliver sends one batch of due emails and returns how many were sent.
+func (m *Mailer) Deliver() (int, error) {
+	emailConfig, err := m.Outbox.GetEmailConfig()
+	if errors.Is(err, sql.ErrNoRows) {
+		// email stays queued until a configuration exists
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	emails, err := m.Outbox.ClaimOutboundEmails(m.BatchSize, leaseDuration)
+	if err != nil {
+		return 0, err
+	}
+
+	var sent, failed int
+	for _, email := range emails {
+		if err := m.Transport.Send(emailConfig, email); err != nil {
+			failed++
+			attempts := email.Attempts + 1
+			if err := m.Outbox.MarkOutboundEmailFailed(
+				email.ID, err.Error(), time.Now().Add(Backoff(attempts)),
+				attempts >= m.MaxAttempts,
+			); err != nil {
+				return sent, err
+			}
+			continue
+		}
+		sent++
+		if err := m.Outbox.MarkOutboundEmailSent(email.ID); err != nil {
+			return sent, err
+		}
+	}
+
+	// a single delivery proves the configuration works
+	hasError := emailConfig.HasError
+	if sent > 0 {
+		hasError = false
+	} else if failed > 0 {
+		hasError = true
+	}
+	if hasError != emailConfig.HasError {
+		if err := m.Outbox.SetEmailConfigError(emailConfig.ID, hasError); err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+// Backoff returns the delay before the next delivery attempt, doubling from
+// one minute after each failure up to six hours.
+func Backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		return 0
+	}
+	delay := time.Minute
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
@@ -0,0 +1,147 @@
+package mail
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Leagueify/api/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeOutbox struct {
+	config    model.EmailConfig
+	configErr error
+	queued    []model.OutboundEmail
+	sent      []string
+	failed    map[string]bool
+	hasError  *bool
+}
+
+func (o *fakeOutbox) ClaimOutboundEmails(limit int, lease time.Duration) ([]model.OutboundEmail, error) {
+	return o.queued, nil
+}
+
+func (o *fakeOutbox) GetEmailConfig() (model.EmailConfig, error) {
+	return o.config, o.configErr
+}
+
+func (o *fakeOutbox) MarkOutboundEmailFailed(emailID, lastError string, nextAttemptAt time.Time, permanent bool) error {
+	o.failed[emailID] = permanent
+	return nil
+}
+
+func (o *fakeOutbox) MarkOutboundEmailSent(emailID string) error {
+	o.sent = append(o.sent, emailID)
+	return nil
+}
+
+func (o *fakeOutbox) SetEmailConfigError(emailConfigID string, hasError bool) error {
+	o.hasError = &hasError
+	return nil
+}
+
+func TestDeliver(t *testing.T) {
+	hasError := true
+	noError := false
+	testCases := []struct {
+		Description       string
+		Outbox            *fakeOutbox
+		TransportErr      error
+		ExpectedSent      int
+		ExpectedFailed    map[string]bool
+		ExpectedHasError  *bool
+		ExpectedDelivered int
+	}{
+		{
+			Description: "No Email Configuration",
+			Outbox: &fakeOutbox{
+				configErr: sql.ErrNoRows,
+				queued:    []model.OutboundEmail{{ID: "1"}},
+			},
+			ExpectedFailed: map[string]bool{},
+		},
+		{
+			Description: "Successful Delivery Clears Error",
+			Outbox: &fakeOutbox{
+				config: model.EmailConfig{ID: "ABC", HasError: true},
+				queued: []model.OutboundEmail{{ID: "1"}, {ID: "2"}},
+			},
+			ExpectedSent:      2,
+			ExpectedFailed:    map[string]bool{},
+			ExpectedHasError:  &noError,
+			ExpectedDelivered: 2,
+		},
+		{
+			Description: "Failed Delivery Sets Error",
+			Outbox: &fakeOutbox{
+				config: model.EmailConfig{ID: "ABC"},
+				queued: []model.OutboundEmail{{ID: "1"}},
+			},
+			TransportErr:     errors.New("connection refused"),
+			ExpectedFailed:   map[string]bool{"1": false},
+			ExpectedHasError: &hasError,
+		},
+		{
+			Description: "Final Attempt Fails Permanently",
+			Outbox: &fakeOutbox{
+				config: model.EmailConfig{ID: "ABC", HasError: true},
+				queued: []model.OutboundEmail{{ID: "1", Attempts: DefaultMaxAttempts - 1}},
+			},
+			TransportErr:   errors.New("connection refused"),
+			ExpectedFailed: map[string]bool{"1": true},
+		},
+	}
+
+	for _, test := range testCases {
+		test.Outbox.failed = map[string]bool{}
+		transport := &MemoryTransport{Err: test.TransportErr}
+		mailer := NewMailer(test.Outbox, transport)
+		delivered, err := mailer.Deliver()
+		assert.NoError(t, err, test.Description)
+		assert.Equal(t, test.ExpectedDelivered, delivered, test.Description)
+		assert.Len(t, transport.Sent(), test.ExpectedSent, test.Description)
+		assert.Equal(t, test.ExpectedFailed, test.Outbox.failed, test.Description)
+		assert.Equal(t, test.ExpectedHasError, test.Outbox.hasError, test.Description)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	testCases := []struct {
+		Description    string
+		Attempts       int
+		ExpectedResult time.Duration
+	}{
+		{
+			Description:    "No Attempts",
+			Attempts:       0,
+			ExpectedResult: 0,
+		},
+		{
+			Description:    "First Attempt",
+			Attempts:       1,
+			ExpectedResult: time.Minute,
+		},
+		{
+			Description:    "Fourth Attempt",
+			Attempts:       4,
+			ExpectedResult: 8 * time.Minute,
+		},
+		{
+			Description:    "Capped Attempt",
+			Attempts:       20,
+			ExpectedResult: 6 * time.Hour,
+		},
+	}
+
+	for _, test := range testCases {
+		result := Backoff(test.Attempts)
+		if result != test.ExpectedResult {
+			t.Errorf(
+				"%v: Expected %v received %v",
+				test.Description, test.ExpectedResult, result,
+			)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package mail
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+)
+
+// buildMessage encodes the email as a multipart/alternative MIME message, or
+// as a multipart/mixed message wrapping it when the email has attachments.
+func buildMessage(from string, email model.OutboundEmail) []byte {
+	boundary := fmt.Sprintf("leagueify-%s", util.UnsignedToken(24))
+	var message strings.Builder
+	fmt.Fprintf(&message, "From: %s\r\n", from)
+	fmt.Fprintf(&message, "To: %s\r\n", email.Recipient)
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", email.Subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	if len(email.Attachments) > 0 {
+		mixed := fmt.Sprintf("leagueify-%s", util.UnsignedToken(24))
+		fmt.Fprintf(&message, "Content-Type: multipart/mixed; boundary=\"%s\"\r\n", mixed)
+		message.WriteString("\r\n")
+		fmt.Fprintf(&message, "--%s\r\n", mixed)
+		writeAlternative(&message, boundary, email)
+		for _, attachment := range email.Attachments {
+			writeAttachment(&message, mixed, attachment)
+		}
+		fmt.Fprintf(&message, "--%s--\r\n", mixed)
+		return []byte(message.String())
+	}
+	writeAlternative(&message, boundary, email)
+	return []byte(message.String())
+}
+
+// writeAlternative writes the plain text and HTML bodies as a
+// multipart/alternative entity, headers included.
+func writeAlternative(message *strings.Builder, boundary string, email model.OutboundEmail) {
+	fmt.Fprintf(message, "Content-Type: multipart/alternative; boundary=\"%s\"\r\n", boundary)
+	message.WriteString("\r\n")
+	writePart(message, boundary, "text/plain", email.TextBody)
+	writePart(message, boundary, "text/html", email.HTMLBody)
+	fmt.Fprintf(message, "--%s--\r\n", boundary)
+}
+
+// writeAttachment writes the attachment base64 encoded in lines of at most 76
+// characters.
+func writeAttachment(message *strings.Builder, boundary string, attachment model.EmailAttachment) {
+	fmt.Fprintf(message, "--%s\r\n", boundary)
+	fmt.Fprintf(message, "Content-Type: %s\r\n", attachment.ContentType)
+	message.WriteString("Content-Transfer-Encoding: base64\r\n")
+	fmt.Fprintf(message, "Content-Disposition: %s\r\n",
+		mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}),
+	)
+	message.WriteString("\r\n")
+	encoded := base64.StdEncoding.EncodeToString(attachment.Content)
+	for len(encoded) > 76 {
+		message.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	message.WriteString(encoded + "\r\n")
+}
+
+func writePart(message *strings.Builder, boundary, contentType, body string) {
+	fmt.Fprintf(message, "--%s\r\n", boundary)
+	fmt.Fprintf(message, "Content-Type: %s; charset=\"utf-8\"\r\n", contentType)
+	message.WriteString("\r\n")
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	message.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	message.WriteString("\r\n")
+}
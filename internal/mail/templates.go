@@ -0,0 +1,56 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+)
+
+//go:embed templates/*
+var templateFS embed.FS
+
+// Compose renders the named template into an email ready for the outbox.
+//
+// Each template is a pair of files: <name>.txt defines a "subject" block and
+// the plain text body, <name>.html defines the "content" block rendered
+// within the shared HTML layout.
+func Compose(recipient, name string, data any) (model.OutboundEmail, error) {
+	email := model.OutboundEmail{
+		ID:        util.SignedToken(10),
+		Recipient: recipient,
+	}
+
+	textTemplate, err := texttemplate.ParseFS(templateFS, fmt.Sprintf("templates/%s.txt", name))
+	if err != nil {
+		return email, err
+	}
+	var subject, text bytes.Buffer
+	if err := textTemplate.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return email, err
+	}
+	if err := textTemplate.Execute(&text, data); err != nil {
+		return email, err
+	}
+
+	htmlTemplate, err := htmltemplate.ParseFS(
+		templateFS, "templates/layout.html", fmt.Sprintf("templates/%s.html", name),
+	)
+	if err != nil {
+		return email, err
+	}
+	var html bytes.Buffer
+	if err := htmlTemplate.ExecuteTemplate(&html, "layout", data); err != nil {
+		return email, err
+	}
+
+	email.Subject = strings.TrimSpace(subject.String())
+	email.TextBody = strings.TrimSpace(text.String()) + "\n"
+	email.HTMLBody = html.String()
+	return email, nil
+}
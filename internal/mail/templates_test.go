@@ -0,0 +1,171 @@
+package mail
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompose(t *testing.T) {
+	testCases := []struct {
+		Description     string
+		Template        string
+		Data            map[string]any
+		ExpectedSubject string
+		ExpectedText    string
+		ExpectedHTML    string
+		ExpectError     bool
+	}{
+		{
+			Description: "Password Reset",
+			Template:    "password_reset",
+			Data: map[string]any{
+				"FirstName": "<Leagueify>",
+				"Link":      "http://localhost/reset-password?token=ABC",
+				"Expires":   "30m0s",
+			},
+			ExpectedSubject: "Reset your Leagueify password",
+			ExpectedText:    "Hi <Leagueify>,",
+			ExpectedHTML:    "Hi &lt;Leagueify&gt;,",
+		},
+		{
+			Description: "Account Verification",
+			Template:    "account_verification",
+			Data: map[string]any{
+				"FirstName": "Leagueify",
+				"Link":      "http://localhost/verify?id=ERCXNX57&token=ABC",
+				"Expires":   "24h0m0s",
+			},
+			ExpectedSubject: "Verify your Leagueify account",
+			ExpectedText:    "http://localhost/verify?id=ERCXNX57&token=ABC",
+			ExpectedHTML:    `href="http://localhost/verify?id=ERCXNX57&amp;token=ABC"`,
+		},
+		{
+			Description: "Guardian Invite",
+			Template:    "guardian_invite",
+			Data: map[string]any{
+				"InvitedBy":  "Leagueify Test",
+				"PlayerName": "<Player>",
+				"Link":       "http://localhost/guardians/accept?token=ABC",
+				"Expires":    "168h0m0s",
+			},
+			ExpectedSubject: "Leagueify Test invited you to Leagueify",
+			ExpectedText:    "guardian of <Player> on Leagueify",
+			ExpectedHTML:    "guardian of &lt;Player&gt; on Leagueify",
+		},
+		{
+			Description: "Installment Reminder",
+			Template:    "installment_reminder",
+			Data: map[string]any{
+				"FirstName":    "Leagueify",
+				"Season":       "Spring 2024",
+				"Number":       2,
+				"Installments": 3,
+				"DueOn":        "2024-03-01",
+				"Overdue":      "40.00 USD",
+				"Balance":      "80.00 USD",
+				"Link":         "http://localhost/registrations/6KQJ7ZPR2Q",
+			},
+			ExpectedSubject: "Your Spring 2024 payment is overdue",
+			ExpectedText:    "Payment 2 of 3 for your Spring 2024 registration was due on 2024-03-01.",
+			ExpectedHTML:    `href="http://localhost/registrations/6KQJ7ZPR2Q"`,
+		},
+		{
+			Description: "Invoice",
+			Template:    "invoice",
+			Data: map[string]any{
+				"Name":     "Leagueify Test",
+				"Document": "receipt",
+				"League":   "Leagueify Hockey League",
+				"Season":   "Spring 2024",
+				"Balance":  "0.00 USD",
+			},
+			ExpectedSubject: "Your Leagueify Hockey League receipt for Spring 2024",
+			ExpectedText:    "Your receipt for your Spring 2024 registration with Leagueify Hockey League is attached.",
+			ExpectedHTML:    "Your remaining balance is 0.00 USD.",
+		},
+		{
+			Description: "Waitlist Offer",
+			Template:    "waitlist_offer",
+			Data: map[string]any{
+				"FirstName":  "Leagueify",
+				"PlayerName": "Test Player",
+				"Division":   "U12",
+				"Season":     "Spring 2024",
+				"Expires":    "Saturday, March 2 at 9:00 AM UTC",
+				"Link":       "http://localhost/registrations/6KQJ7ZPR2Q",
+			},
+			ExpectedSubject: "A place has opened up for Test Player in Spring 2024",
+			ExpectedText:    "The place is held for you until Saturday, March 2 at 9:00 AM UTC.",
+			ExpectedHTML:    `href="http://localhost/registrations/6KQJ7ZPR2Q"`,
+		},
+		{
+			Description: "Unknown Template",
+			Template:    "unknown",
+			ExpectError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		email, err := Compose("test@leagueify.org", test.Template, test.Data)
+		if test.ExpectError {
+			assert.Error(t, err, test.Description)
+			continue
+		}
+		assert.NoError(t, err, test.Description)
+		assert.True(t, util.VerifyToken(email.ID), test.Description)
+		assert.Equal(t, "test@leagueify.org", email.Recipient, test.Description)
+		assert.Equal(t, test.ExpectedSubject, email.Subject, test.Description)
+		assert.Contains(t, email.TextBody, test.ExpectedText, test.Description)
+		assert.Contains(t, email.HTMLBody, test.ExpectedHTML, test.Description)
+	}
+}
+
+func TestBuildMessage(t *testing.T) {
+	email, err := Compose("test@leagueify.org", "password_reset", map[string]any{
+		"FirstName": "Leagueify",
+		"Link":      "http://localhost/reset-password?token=ABC",
+		"Expires":   "30m0s",
+	})
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when composing email", err)
+	}
+	message := string(buildMessage("noreply@leagueify.org", email))
+	assert.Contains(t, message, "From: noreply@leagueify.org\r\n")
+	assert.Contains(t, message, "To: test@leagueify.org\r\n")
+	assert.Contains(t, message, "Content-Type: multipart/alternative;")
+	assert.Contains(t, message, "Content-Type: text/plain;")
+	assert.Contains(t, message, "Content-Type: text/html;")
+	assert.False(t, strings.Contains(strings.ReplaceAll(message, "\r\n", ""), "\n"))
+}
+
+func TestBuildMessageWithAttachment(t *testing.T) {
+	email, err := Compose("test@leagueify.org", "password_reset", map[string]any{
+		"FirstName": "Leagueify",
+		"Link":      "http://localhost/reset-password?token=ABC",
+		"Expires":   "30m0s",
+	})
+	if err != nil {
+		t.Fatalf("ERROR: '%s' was not expected when composing email", err)
+	}
+	email.Attachments = []model.EmailAttachment{
+		{
+			Filename:    "invoice-6KQJ7ZPR2Q.pdf",
+			ContentType: "application/pdf",
+			Content:     []byte(strings.Repeat("%PDF-1.4 ", 20)),
+		},
+	}
+	message := string(buildMessage("noreply@leagueify.org", email))
+	assert.Contains(t, message, "Content-Type: multipart/mixed;")
+	assert.Contains(t, message, "Content-Type: multipart/alternative;")
+	assert.Contains(t, message, "Content-Type: application/pdf\r\n")
+	assert.Contains(t, message, "Content-Disposition: attachment; filename=invoice-6KQJ7ZPR2Q.pdf\r\n")
+	assert.Contains(t, message, "JVBERi0xLjQg")
+	for _, line := range strings.Split(message, "\r\n") {
+		assert.LessOrEqual(t, len(line), 998)
+	}
+	assert.False(t, strings.Contains(strings.ReplaceAll(message, "\r\n", ""), "\n"))
+}
@@ -0,0 +1,78 @@
+package mail
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/smtp"
+	"sync"
+
+	"github.com/Leagueify/api/internal/model"
+)
+
+// Transport delivers a rendered email using the stored email configuration.
+type Transport interface {
+	Send(emailConfig model.EmailConfig, email model.OutboundEmail) error
+}
+
+// SMTPTransport delivers email over an implicit TLS SMTP connection.
+type SMTPTransport struct{}
+
+func (SMTPTransport) Send(emailConfig model.EmailConfig, email model.OutboundEmail) error {
+	tlsConfig := &tls.Config{InsecureSkipVerify: false, ServerName: emailConfig.SMTPHost}
+	conn, err := tls.Dial("tcp", fmt.Sprintf("%s:%v", emailConfig.SMTPHost, emailConfig.SMTPPort), tlsConfig)
+	if err != nil {
+		return err
+	}
+	client, err := smtp.NewClient(conn, emailConfig.SMTPHost)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	auth := smtp.PlainAuth("", emailConfig.SMTPUser, emailConfig.SMTPPass, emailConfig.SMTPHost)
+	if err := client.Auth(auth); err != nil {
+		return err
+	}
+	if err := client.Mail(emailConfig.Email); err != nil {
+		return err
+	}
+	if err := client.Rcpt(email.Recipient); err != nil {
+		return err
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(buildMessage(emailConfig.Email, email)); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// MemoryTransport records emails in process instead of delivering them.
+type MemoryTransport struct {
+	// Err is returned from Send when set, simulating a delivery failure.
+	Err error
+
+	mu   sync.Mutex
+	sent []model.OutboundEmail
+}
+
+func (t *MemoryTransport) Send(emailConfig model.EmailConfig, email model.OutboundEmail) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.Err != nil {
+		return t.Err
+	}
+	t.sent = append(t.sent, email)
+	return nil
+}
+
+// Sent returns every email accepted by the transport.
+func (t *MemoryTransport) Sent() []model.OutboundEmail {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]model.OutboundEmail{}, t.sent...)
+}
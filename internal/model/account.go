@@ -1,25 +1,27 @@
 package model
 
 import (
+	"time"
+
 	"github.com/lib/pq"
 )
 
 type (
 	Account struct {
-		ID               string
-		FirstName        string
-		LastName         string
-		Email            string
-		Password         string
-		Phone            string
-		DateOfBirth      string
-		RegistrationCode string
-		Players          pq.StringArray
-		Coach            bool
-		Volunteer        bool
-		APIKey           string
-		IsActive         bool
-		IsAdmin          bool
+		ID          string
+		FirstName   string
+		LastName    string
+		Email       string
+		Password    string
+		Phone       string
+		DateOfBirth string
+		Players     pq.StringArray
+		Coach       bool
+		Volunteer   bool
+		IsActive    bool
+		IsAdmin     bool
+		SessionID   string
+		Roles       pq.StringArray
 	}
 
 	AccountCreation struct {
@@ -36,8 +38,90 @@ type (
 		IsAdmin     bool
 	}
 
+	AccountAccess struct {
+		IsActive *bool `json:"isActive"`
+		IsAdmin  *bool `json:"isAdmin"`
+	}
+
+	AccountDeletion struct {
+		Password string `json:"password" validate:"required"`
+	}
+
+	AccountDetail struct {
+		Account AccountProfile `json:"account"`
+		Players []Player       `json:"players"`
+	}
+
+	AccountFilter struct {
+		IsActive  *bool  `query:"active"`
+		IsAdmin   *bool  `query:"admin"`
+		Coach     *bool  `query:"coach"`
+		Volunteer *bool  `query:"volunteer"`
+		Search    string `query:"search"`
+		Limit     int    `query:"limit"`
+		Offset    int    `query:"offset"`
+	}
+
+	AccountProfile struct {
+		ID          string         `json:"id"`
+		FirstName   string         `json:"firstName"`
+		LastName    string         `json:"lastName"`
+		Email       string         `json:"email"`
+		Phone       string         `json:"phone"`
+		DateOfBirth string         `json:"dateOfBirth"`
+		Players     pq.StringArray `json:"players"`
+		Coach       bool           `json:"coach"`
+		Volunteer   bool           `json:"volunteer"`
+		IsActive    bool           `json:"isActive"`
+		IsAdmin     bool           `json:"isAdmin"`
+		Roles       pq.StringArray `json:"roles"`
+	}
+
+	AccountUpdate struct {
+		FirstName       *string `json:"firstName" validate:"omitnil,min=1"`
+		LastName        *string `json:"lastName" validate:"omitnil,min=1"`
+		Email           *string `json:"email" validate:"omitnil,email"`
+		Phone           *string `json:"phone" validate:"omitnil,e164"`
+		Coach           *bool   `json:"coach"`
+		Volunteer       *bool   `json:"volunteer"`
+		Password        *string `json:"password"`
+		CurrentPassword string  `json:"currentPassword"`
+	}
+
+	AccountVerification struct {
+		Token string `json:"token" validate:"required"`
+	}
+
+	AccountVerificationResend struct {
+		Email string `json:"email" validate:"required,email"`
+	}
+
+	AccountVerificationToken struct {
+		ID        string
+		AccountID string
+		TokenHash string
+		ExpiresAt time.Time
+	}
+
 	AccountLogin struct {
 		Email    string `json:"email" validate:"required,email"`
 		Password string `json:"password" validate:"required"`
+		Label    string `json:"label"`
+	}
+
+	PasswordForgot struct {
+		Email string `json:"email" validate:"required,email"`
+	}
+
+	PasswordReset struct {
+		Token    string `json:"token" validate:"required"`
+		Password string `json:"password" validate:"required"`
+	}
+
+	PasswordResetToken struct {
+		ID        string
+		AccountID string
+		TokenHash string
+		ExpiresAt time.Time
 	}
 )
@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// Financial aid application statuses.
+const (
+	AidPending  = "pending"
+	AidApproved = "approved"
+	AidDenied   = "denied"
+)
+
+type (
+	// FinancialAid is an account holder's application for help paying a
+	// registration. Approved applications take AwardedAmount cents off the
+	// registration's amount due.
+	FinancialAid struct {
+		ID              string     `json:"id"`
+		RegistrationID  string     `json:"registrationId"`
+		RequestedAmount int        `json:"requestedAmount" validate:"min=1"`
+		Reason          string     `json:"reason" validate:"required,max=2000"`
+		Status          string     `json:"status"`
+		AwardedAmount   int        `json:"awardedAmount"`
+		Note            string     `json:"note"`
+		ReviewedBy      string     `json:"reviewedBy,omitempty"`
+		ReviewedAt      *time.Time `json:"reviewedAt,omitempty"`
+		CreatedAt       time.Time  `json:"createdAt"`
+	}
+
+	FinancialAidFilter struct {
+		Status string `query:"status" validate:"omitempty,oneof=pending approved denied"`
+	}
+
+	// FinancialAidReview approves or denies an application. Approvals award
+	// the requested amount when no amount is given.
+	FinancialAidReview struct {
+		Status string `json:"status" validate:"required,oneof=approved denied"`
+		Amount int    `json:"amount" validate:"min=0"`
+		Note   string `json:"note" validate:"max=2000"`
+	}
+)
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// Kinds of coupon discount.
+const (
+	CouponPercent = "percent"
+	CouponFixed   = "fixed"
+)
+
+type (
+	// Coupon is a discount code account holders apply to their registrations.
+	// Percent coupons take Value percent off the amount due and fixed coupons
+	// take Value cents off. Coupons without a season apply to every season
+	// and a MaxUses of zero allows unlimited uses.
+	Coupon struct {
+		ID            string    `json:"id"`
+		Code          string    `json:"code" validate:"required,alphanum,max=32"`
+		Kind          string    `json:"kind" validate:"required,oneof=percent fixed"`
+		Value         int       `json:"value" validate:"min=1"`
+		SeasonID      string    `json:"seasonId"`
+		MaxUses       int       `json:"maxUses" validate:"min=0"`
+		OncePerFamily bool      `json:"oncePerFamily"`
+		ExpiresOn     string    `json:"expiresOn" validate:"omitempty,datetime=2006-01-02"`
+		Disabled      bool      `json:"disabled"`
+		Uses          int       `json:"uses"`
+		CreatedAt     time.Time `json:"createdAt"`
+	}
+
+	CouponRedemption struct {
+		Code string `json:"code" validate:"required,max=32"`
+	}
+)
@@ -1,12 +1,34 @@
 package model
 
-type EmailConfig struct {
-	ID        string
-	Email     string `json:"email" validate:"required,email"`
-	SMTPHost  string `json:"smtpHost" validate:"required"`
-	SMTPPort  int    `json:"smtpPort" validate:"required"`
-	SMTPUser  string `json:"smtpUser" validate:"required"`
-	SMTPPass  string `json:"smtpPass" validate:"required"`
-	IsEnabled bool
-	HasError  bool
-}
+import "time"
+
+type (
+	EmailConfig struct {
+		ID        string
+		Email     string `json:"email" validate:"required,email"`
+		SMTPHost  string `json:"smtpHost" validate:"required"`
+		SMTPPort  int    `json:"smtpPort" validate:"required"`
+		SMTPUser  string `json:"smtpUser" validate:"required"`
+		SMTPPass  string `json:"smtpPass" validate:"required"`
+		IsEnabled bool
+		HasError  bool
+	}
+
+	OutboundEmail struct {
+		ID            string
+		Recipient     string
+		Subject       string
+		TextBody      string
+		HTMLBody      string
+		Attempts      int
+		NextAttemptAt time.Time
+		LastError     string
+		Attachments   []EmailAttachment
+	}
+
+	EmailAttachment struct {
+		Filename    string
+		ContentType string
+		Content     []byte
+	}
+)
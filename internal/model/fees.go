@@ -0,0 +1,18 @@
+package model
+
+type (
+	// FeeSchedule prices players of a division within a season. Amounts are
+	// in cents and the sibling discount is a percentage.
+	FeeSchedule struct {
+		ID              string `json:"id"`
+		SeasonID        string `json:"-"`
+		Division        string `json:"division" validate:"max=64"`
+		BaseFee         int    `json:"baseFee" validate:"min=0"`
+		EarlyBirdFee    *int   `json:"earlyBirdFee" validate:"required_with=EarlyBirdEnds,omitnil,min=0"`
+		EarlyBirdEnds   string `json:"earlyBirdEnds" validate:"required_with=EarlyBirdFee,omitempty,datetime=2006-01-02"`
+		LateFee         int    `json:"lateFee" validate:"min=0"`
+		LateFeeStarts   string `json:"lateFeeStarts" validate:"required_unless=LateFee 0,omitempty,datetime=2006-01-02"`
+		SiblingDiscount int    `json:"siblingDiscount" validate:"min=0,max=100"`
+		FamilyMax       int    `json:"familyMax" validate:"min=0"`
+	}
+)
@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+type (
+	Guardian struct {
+		AccountID  string    `json:"accountId"`
+		PlayerID   string    `json:"-"`
+		Permission string    `json:"permission"`
+		FirstName  string    `json:"firstName"`
+		LastName   string    `json:"lastName"`
+		Email      string    `json:"email"`
+		CreatedAt  time.Time `json:"createdAt"`
+	}
+
+	GuardianInvite struct {
+		ID         string
+		PlayerID   string
+		InvitedBy  string
+		Email      string `json:"email" validate:"required,email"`
+		Permission string `json:"permission" validate:"required"`
+		TokenHash  string
+		ExpiresAt  time.Time
+	}
+
+	GuardianInviteAcceptance struct {
+		Token string `json:"token" validate:"required"`
+	}
+)
@@ -0,0 +1,56 @@
+package model
+
+import "time"
+
+// Statuses of an installment, worked out from the registration's balance.
+const (
+	InstallmentPaid    = "paid"
+	InstallmentDue     = "due"
+	InstallmentOverdue = "overdue"
+)
+
+type (
+	// InstallmentPlan splits a registration's amount due into Installments
+	// equal payments IntervalDays apart.
+	InstallmentPlan struct {
+		ID           string `json:"id"`
+		SeasonID     string `json:"-"`
+		Name         string `json:"name" validate:"required,max=64"`
+		Installments int    `json:"installments" validate:"min=2,max=12"`
+		IntervalDays int    `json:"intervalDays" validate:"min=1,max=365"`
+	}
+
+	InstallmentEnrollment struct {
+		PlanID string `json:"planId" validate:"required"`
+	}
+
+	// Installment is one due date of a registration on a plan. Amount and
+	// Status are not stored, they follow the registration's amount due and
+	// amount paid.
+	Installment struct {
+		ID             string     `json:"id"`
+		RegistrationID string     `json:"-"`
+		PlanID         string     `json:"planId,omitempty"`
+		Number         int        `json:"number"`
+		DueOn          string     `json:"dueOn"`
+		Amount         int        `json:"amount"`
+		Status         string     `json:"status"`
+		OverdueAt      *time.Time `json:"overdueAt,omitempty"`
+		RemindedAt     *time.Time `json:"remindedAt,omitempty"`
+	}
+
+	// InstallmentReminder is an unpaid installment past its due date along
+	// with what is needed to remind the account holder of it.
+	InstallmentReminder struct {
+		InstallmentID  string
+		RegistrationID string
+		Number         int
+		Installments   int
+		DueOn          string
+		AmountDue      int
+		AmountPaid     int
+		Email          string
+		FirstName      string
+		SeasonName     string
+	}
+)
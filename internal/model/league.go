@@ -1,5 +1,11 @@
 package model
 
+type League struct {
+	ID      string
+	Name    string
+	SportID string
+}
+
 type LeagueCreation struct {
 	ID          string
 	Name        string `json:"name" validate:"required,min=3"`
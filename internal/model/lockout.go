@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+type (
+	LockoutEvent struct {
+		ID        int64     `json:"id"`
+		AccountID string    `json:"accountId"`
+		IPAddress string    `json:"ipAddress"`
+		Event     string    `json:"event"`
+		Actor     string    `json:"actor"`
+		CreatedAt time.Time `json:"createdAt"`
+	}
+
+	LoginAttempt struct {
+		AccountID string
+		Email     string
+		IPAddress string
+		Succeeded bool
+	}
+
+	LoginLockout struct {
+		AccountID      string
+		FailedAttempts int
+		LockedUntil    time.Time
+	}
+)
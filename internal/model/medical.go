@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+type (
+	EmergencyContact struct {
+		ID           string    `json:"id"`
+		PlayerID     string    `json:"-"`
+		Name         string    `json:"name" validate:"required,max=64"`
+		Relationship string    `json:"relationship" validate:"required,max=32"`
+		Phone        string    `json:"phone" validate:"required,e164"`
+		Email        string    `json:"email" validate:"omitempty,email"`
+		CreatedAt    time.Time `json:"createdAt"`
+	}
+
+	MedicalAccessEvent struct {
+		ID        int64     `json:"id"`
+		PlayerID  string    `json:"-"`
+		AccountID string    `json:"accountId"`
+		Record    string    `json:"record"`
+		IPAddress string    `json:"ipAddress"`
+		CreatedAt time.Time `json:"createdAt"`
+	}
+
+	// MedicalInfo is stored encrypted as a MedicalRecord.
+	MedicalInfo struct {
+		Allergies   string `json:"allergies" validate:"max=1024"`
+		Medications string `json:"medications" validate:"max=1024"`
+		Conditions  string `json:"conditions" validate:"max=1024"`
+		Notes       string `json:"notes" validate:"max=1024"`
+	}
+
+	MedicalRecord struct {
+		PlayerID  string
+		Data      string
+		UpdatedBy string
+		UpdatedAt time.Time
+	}
+)
@@ -0,0 +1,8 @@
+package model
+
+type Migration struct {
+	Version   int
+	Name      string
+	IsApplied bool
+	AppliedAt string
+}
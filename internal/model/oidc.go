@@ -0,0 +1,64 @@
+package model
+
+import "time"
+
+type (
+	OIDCAuthorization struct {
+		Label string `json:"label"`
+	}
+
+	OIDCCallback struct {
+		Code  string `json:"code" validate:"required"`
+		State string `json:"state" validate:"required"`
+	}
+
+	OIDCIdentity struct {
+		Provider  string
+		Subject   string
+		AccountID string
+		Email     string
+	}
+
+	OIDCSignup struct {
+		TokenHash     string
+		Provider      string
+		Subject       string
+		Email         string
+		EmailVerified bool
+		FirstName     string
+		LastName      string
+		Phone         string
+		DateOfBirth   string
+		Label         string
+		ExpiresAt     time.Time
+	}
+
+	OIDCSignupCompletion struct {
+		Token       string `json:"token" validate:"required"`
+		FirstName   string `json:"firstName" validate:"required"`
+		LastName    string `json:"lastName" validate:"required"`
+		Phone       string `json:"phone" validate:"required,e164"`
+		DateOfBirth string `json:"dateOfBirth" validate:"required"`
+		Coach       bool   `json:"coach"`
+		Volunteer   bool   `json:"volunteer"`
+	}
+
+	OIDCSignupDetails struct {
+		Status      string `json:"status"`
+		Token       string `json:"token"`
+		FirstName   string `json:"firstName"`
+		LastName    string `json:"lastName"`
+		Email       string `json:"email"`
+		Phone       string `json:"phone"`
+		DateOfBirth string `json:"dateOfBirth"`
+	}
+
+	OIDCState struct {
+		StateHash    string
+		Provider     string
+		Nonce        string
+		CodeVerifier string
+		Label        string
+		ExpiresAt    time.Time
+	}
+)
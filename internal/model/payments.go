@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// Kinds of payment ledger entries.
+const (
+	PaymentKindPayment = "payment"
+	PaymentKindRefund  = "refund"
+)
+
+type (
+	// Payment is an entry in a registration's payment ledger. Refunds
+	// reference the payment they return in PaymentID.
+	Payment struct {
+		ID             string    `json:"id"`
+		RegistrationID string    `json:"-"`
+		Kind           string    `json:"kind"`
+		Amount         int       `json:"amount"`
+		Provider       string    `json:"provider"`
+		Reference      string    `json:"reference"`
+		PaymentID      string    `json:"paymentId,omitempty"`
+		CreatedAt      time.Time `json:"createdAt"`
+	}
+
+	// PaymentCheckout starts paying a registration. The balance is paid when
+	// no amount is given.
+	PaymentCheckout struct {
+		Amount int `json:"amount" validate:"omitempty,min=1"`
+	}
+
+	// PaymentRefund returns part of a payment. The amount not yet refunded is
+	// returned when no amount is given.
+	PaymentRefund struct {
+		PaymentID string `json:"paymentId" validate:"required"`
+		Amount    int    `json:"amount" validate:"omitempty,min=1"`
+	}
+)
@@ -7,6 +7,11 @@ type (
 		LastName     string `json:"lastName" validate:"required"`
 		DateOfBirth  string `json:"dateOfBirth" validate:"required"`
 		Position     string `json:"position" validate:"required"`
+		Gender       string `json:"gender" validate:"omitempty,oneof=female male nonbinary"`
+		JerseySize   string `json:"jerseySize" validate:"omitempty,oneof=YXS YS YM YL YXL AS AM AL AXL A2XL"`
+		JerseyNumber *int   `json:"jerseyNumber" validate:"omitnil,min=0,max=99"`
+		School       string `json:"school" validate:"omitempty,max=64"`
+		Grade        string `json:"grade" validate:"omitempty,oneof=PK K 1 2 3 4 5 6 7 8 9 10 11 12"`
 		Team         string
 		Division     string
 		IsRegistered bool
@@ -16,7 +21,24 @@ type (
 		Players []Player `json:"players" validate:"required"`
 	}
 
+	PlayerDivisionAssignment struct {
+		Division string `json:"division" validate:"max=64"`
+	}
+
 	PlayerRegistration struct {
-		Players []string `json:"players" validate:"required"`
+		SeasonID string   `json:"seasonId" validate:"required"`
+		Players  []string `json:"players" validate:"required"`
+	}
+
+	PlayerUpdate struct {
+		FirstName    *string `json:"firstName" validate:"omitnil,min=1"`
+		LastName     *string `json:"lastName" validate:"omitnil,min=1"`
+		DateOfBirth  *string `json:"dateOfBirth" validate:"omitnil,min=1"`
+		Position     *string `json:"position" validate:"omitnil,min=1"`
+		Gender       *string `json:"gender" validate:"omitnil,oneof=female male nonbinary"`
+		JerseySize   *string `json:"jerseySize" validate:"omitnil,oneof=YXS YS YM YL YXL AS AM AL AXL A2XL"`
+		JerseyNumber *int    `json:"jerseyNumber" validate:"omitnil,min=0,max=99"`
+		School       *string `json:"school" validate:"omitnil,max=64"`
+		Grade        *string `json:"grade" validate:"omitnil,oneof=PK K 1 2 3 4 5 6 7 8 9 10 11 12"`
 	}
 )
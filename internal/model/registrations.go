@@ -1,10 +1,111 @@
 package model
 
-import "github.com/lib/pq"
-
-type Registration struct {
-	ID         string
-	PlayerIDs  pq.StringArray
-	AmountDue  int
-	AmountPaid int
-}
+import "time"
+
+// Registration statuses of a player within a season.
+const (
+	RegistrationPending    = "pending"
+	RegistrationConfirmed  = "confirmed"
+	RegistrationWaitlisted = "waitlisted"
+	RegistrationWithdrawn  = "withdrawn"
+	RegistrationRefunded   = "refunded"
+)
+
+// Kinds of registration adjustment.
+const (
+	AdjustmentCoupon = "coupon"
+	AdjustmentAid    = "aid"
+)
+
+type (
+	Registration struct {
+		ID         string               `json:"id"`
+		AccountID  string               `json:"accountId"`
+		SeasonID   string               `json:"seasonId"`
+		AmountDue  int                  `json:"amountDue"`
+		AmountPaid int                  `json:"amountPaid"`
+		Balance    int                  `json:"balance"`
+		CreatedAt  time.Time            `json:"createdAt"`
+		Players    []RegistrationPlayer `json:"players"`
+		// Adjustments itemize the coupons and financial aid taken off the
+		// amount due. They are only returned for a single registration.
+		Adjustments []RegistrationAdjustment `json:"adjustments,omitempty"`
+	}
+
+	// RegistrationAdjustment is a coupon or financial aid award taken off a
+	// registration's amount due.
+	RegistrationAdjustment struct {
+		ID             string    `json:"id"`
+		RegistrationID string    `json:"-"`
+		Kind           string    `json:"kind"`
+		CouponID       string    `json:"couponId,omitempty"`
+		FinancialAidID string    `json:"financialAidId,omitempty"`
+		Description    string    `json:"description"`
+		Amount         int       `json:"amount"`
+		CreatedAt      time.Time `json:"createdAt"`
+	}
+
+	// InvoiceRequest selects the format a registration's invoice is served in.
+	InvoiceRequest struct {
+		Format string `query:"format" validate:"omitempty,oneof=html pdf"`
+	}
+
+	RegistrationFilter struct {
+		SeasonID string `query:"season"`
+		Status   string `query:"status" validate:"omitempty,oneof=pending confirmed waitlisted withdrawn refunded"`
+		Balance  string `query:"balance" validate:"omitempty,oneof=owing paid"`
+		Search   string `query:"search"`
+		Limit    int    `query:"limit"`
+		Offset   int    `query:"offset"`
+	}
+
+	// RegistrationPlayer is a player on a registration. Players holding a
+	// place in the season are charged Amount, and waitlisted players once they
+	// take one. Retained, the part of a fee kept under the season's refund
+	// policy when the player withdrew, is charged either way. Waitlisted
+	// players offered a place have until OfferExpiresAt to accept it.
+	RegistrationPlayer struct {
+		RegistrationID string     `json:"-"`
+		PlayerID       string     `json:"playerId"`
+		SeasonID       string     `json:"-"`
+		FirstName      string     `json:"firstName"`
+		LastName       string     `json:"lastName"`
+		Division       string     `json:"division"`
+		Status         string     `json:"status"`
+		Amount         int        `json:"amount"`
+		Retained       int        `json:"retained"`
+		OfferExpiresAt *time.Time `json:"offerExpiresAt,omitempty"`
+		CreatedAt      time.Time  `json:"createdAt"`
+		UpdatedAt      time.Time  `json:"updatedAt"`
+	}
+
+	// RegistrationQuote prices players being added to an account's season
+	// registration. AmountDue is the registration's total once they are added,
+	// less the coupons and financial aid in Discounts.
+	RegistrationQuote struct {
+		SeasonID          string      `json:"seasonId"`
+		Players           []QuoteLine `json:"players"`
+		Subtotal          int         `json:"subtotal"`
+		PreviousAmountDue int         `json:"previousAmountDue"`
+		Discounts         int         `json:"discounts"`
+		AmountDue         int         `json:"amountDue"`
+	}
+
+	// QuoteLine is the price of one player. Amount is the fee less the
+	// sibling discount and any reduction from the family maximum.
+	QuoteLine struct {
+		PlayerID        string `json:"playerId"`
+		FirstName       string `json:"firstName"`
+		LastName        string `json:"lastName"`
+		Division        string `json:"division"`
+		Fee             int    `json:"fee"`
+		SiblingDiscount int    `json:"siblingDiscount"`
+		FamilyDiscount  int    `json:"familyDiscount"`
+		Amount          int    `json:"amount"`
+	}
+
+	RegistrationPlayerAddition struct {
+		PlayerID string `json:"playerId" validate:"required"`
+		Status   string `json:"status" validate:"omitempty,oneof=pending confirmed waitlisted"`
+	}
+)
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+type (
+	AccountRole struct {
+		Role      string    `json:"role"`
+		GrantedBy string    `json:"grantedBy"`
+		GrantedAt time.Time `json:"grantedAt"`
+	}
+
+	RoleGrant struct {
+		Role string `json:"role" validate:"required"`
+	}
+)
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+type Session struct {
+	ID         string    `json:"id"`
+	AccountID  string    `json:"-"`
+	KeyHash    string    `json:"-"`
+	Label      string    `json:"label"`
+	UserAgent  string    `json:"userAgent"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	IsCurrent  bool      `json:"isCurrent"`
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+type (
+	TeamCoach struct {
+		Team       string    `json:"team"`
+		AccountID  string    `json:"accountId"`
+		AssignedBy string    `json:"assignedBy"`
+		AssignedAt time.Time `json:"assignedAt"`
+	}
+
+	TeamCoachAssignment struct {
+		AccountID string `json:"accountId" validate:"required"`
+	}
+
+	TeamPlayerAssignment struct {
+		PlayerID string `json:"playerId" validate:"required"`
+	}
+)
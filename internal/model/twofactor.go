@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+type (
+	LoginChallenge struct {
+		ID        string
+		AccountID string
+		TokenHash string
+		Label     string
+		ExpiresAt time.Time
+	}
+
+	LoginVerification struct {
+		Challenge string `json:"challenge" validate:"required"`
+		Code      string `json:"code" validate:"required"`
+	}
+
+	RecoveryCodes struct {
+		RecoveryCodes []string `json:"recoveryCodes"`
+	}
+
+	TwoFactor struct {
+		AccountID     string
+		Secret        string
+		Enabled       bool
+		LastCounter   int64
+		RecoveryCodes int
+	}
+
+	TwoFactorCode struct {
+		Code string `json:"code" validate:"required"`
+	}
+
+	TwoFactorDisable struct {
+		Password string `json:"password" validate:"required"`
+		Code     string `json:"code" validate:"required"`
+	}
+
+	TwoFactorEnrollment struct {
+		Secret string `json:"secret"`
+		URI    string `json:"uri"`
+	}
+
+	TwoFactorSetup struct {
+		Password string `json:"password" validate:"required"`
+	}
+
+	TwoFactorStatus struct {
+		Enabled                bool `json:"enabled"`
+		RecoveryCodesRemaining int  `json:"recoveryCodesRemaining"`
+	}
+)
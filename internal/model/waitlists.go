@@ -0,0 +1,56 @@
+package model
+
+import "time"
+
+type (
+	// DivisionCapacity limits how many players a division of a season holds.
+	// Players registered once it is full are waitlisted, and OfferHours is how
+	// long a waitlisted family has to accept a place that opens up. Placed,
+	// Offered and Waitlisted count the division's players and are not stored.
+	DivisionCapacity struct {
+		SeasonID   string `json:"-"`
+		Division   string `json:"division"`
+		Capacity   int    `json:"capacity" validate:"min=0"`
+		OfferHours int    `json:"offerHours" validate:"required,min=1,max=720"`
+		Placed     int    `json:"placed"`
+		Offered    int    `json:"offered"`
+		Waitlisted int    `json:"waitlisted"`
+	}
+
+	// WaitlistEntry is a waitlisted player. Players are offered places in
+	// Position order, and an offer holds a place until OfferExpiresAt.
+	WaitlistEntry struct {
+		RegistrationID string     `json:"registrationId"`
+		PlayerID       string     `json:"playerId"`
+		SeasonID       string     `json:"-"`
+		FirstName      string     `json:"firstName"`
+		LastName       string     `json:"lastName"`
+		Division       string     `json:"division"`
+		Position       int        `json:"position"`
+		OfferExpiresAt *time.Time `json:"offerExpiresAt,omitempty"`
+		CreatedAt      time.Time  `json:"createdAt"`
+	}
+
+	WaitlistFilter struct {
+		Division string `query:"division"`
+	}
+
+	// WaitlistMove moves a waitlisted player to Position within their
+	// division's waitlist.
+	WaitlistMove struct {
+		Position int `json:"position" validate:"required,min=1"`
+	}
+
+	// WaitlistOffer is a place offered to a waitlisted player along with what
+	// is needed to tell the account holder about it.
+	WaitlistOffer struct {
+		RegistrationID string
+		PlayerID       string
+		PlayerName     string
+		Division       string
+		SeasonName     string
+		Email          string
+		FirstName      string
+		ExpiresAt      time.Time
+	}
+)
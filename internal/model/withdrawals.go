@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+// Withdrawal request statuses.
+const (
+	WithdrawalPending  = "pending"
+	WithdrawalApproved = "approved"
+	WithdrawalDenied   = "denied"
+)
+
+type (
+	// RefundPolicy sets how much of a player's fee is refunded when they
+	// withdraw from a season. Players withdrawn on or before FullRefundUntil
+	// are refunded in full, on or before PartialRefundUntil
+	// PartialRefundPercent of their fee, and after that nothing.
+	RefundPolicy struct {
+		SeasonID             string `json:"-"`
+		FullRefundUntil      string `json:"fullRefundUntil" validate:"omitempty,datetime=2006-01-02"`
+		PartialRefundUntil   string `json:"partialRefundUntil" validate:"required_unless=PartialRefundPercent 0,omitempty,datetime=2006-01-02"`
+		PartialRefundPercent int    `json:"partialRefundPercent" validate:"min=0,max=100"`
+	}
+
+	// Withdrawal is a request to withdraw a player from a registration.
+	// RefundAmount is the part of the player's fee refunded under the
+	// season's refund policy on the day it was requested, and Refunded how
+	// much of it was returned through the payment provider once approved.
+	Withdrawal struct {
+		ID             string     `json:"id"`
+		RegistrationID string     `json:"registrationId"`
+		PlayerID       string     `json:"playerId" validate:"required"`
+		Reason         string     `json:"reason" validate:"max=2000"`
+		Status         string     `json:"status"`
+		RefundAmount   int        `json:"refundAmount"`
+		Refunded       int        `json:"refunded"`
+		Note           string     `json:"note"`
+		ReviewedBy     string     `json:"reviewedBy,omitempty"`
+		ReviewedAt     *time.Time `json:"reviewedAt,omitempty"`
+		CreatedAt      time.Time  `json:"createdAt"`
+	}
+
+	WithdrawalFilter struct {
+		Status string `query:"status" validate:"omitempty,oneof=pending approved denied"`
+	}
+
+	// WithdrawalReview approves or denies a withdrawal. Approvals refund the
+	// amount allowed by the refund policy when no refund amount is given.
+	WithdrawalReview struct {
+		Status       string `json:"status" validate:"required,oneof=approved denied"`
+		RefundAmount *int   `json:"refundAmount" validate:"omitnil,min=0"`
+		Note         string `json:"note" validate:"max=2000"`
+	}
+)
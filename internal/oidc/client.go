@@ -0,0 +1,301 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// clockSkew is the leeway allowed when checking token lifetimes.
+const clockSkew = time.Minute
+
+// Client is a Provider for any OpenID Connect issuer that publishes a
+// discovery document. The discovery document and signing keys are fetched
+// on first use and cached.
+type Client struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	HTTPClient   *http.Client
+
+	mu        sync.Mutex
+	discovery *discovery
+	keys      map[string]*rsa.PublicKey
+}
+
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+type claims struct {
+	Issuer        string    `json:"iss"`
+	Subject       string    `json:"sub"`
+	Audience      audience  `json:"aud"`
+	ExpiresAt     int64     `json:"exp"`
+	Nonce         string    `json:"nonce"`
+	Email         string    `json:"email"`
+	EmailVerified claimBool `json:"email_verified"`
+	GivenName     string    `json:"given_name"`
+	FamilyName    string    `json:"family_name"`
+	PhoneNumber   string    `json:"phone_number"`
+	Birthdate     string    `json:"birthdate"`
+}
+
+// Valid is checked by Exchange against the expected issuer, audience and
+// nonce once the signature has been verified.
+func (c *claims) Valid() error {
+	return nil
+}
+
+// NewClient returns a Client requesting DefaultScopes.
+func NewClient(issuer, clientID, clientSecret, redirectURL string) *Client {
+	return &Client{
+		Issuer:       issuer,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       DefaultScopes,
+		HTTPClient:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (c *Client) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	provider, err := c.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", c.ClientID)
+	query.Set("redirect_uri", c.RedirectURL)
+	query.Set("scope", strings.Join(c.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	separator := "?"
+	if strings.Contains(provider.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return provider.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+func (c *Client) Exchange(ctx context.Context, code, codeVerifier, nonce string) (Identity, error) {
+	provider, err := c.discover(ctx)
+	if err != nil {
+		return Identity{}, err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", c.RedirectURL)
+	form.Set("client_id", c.ClientID)
+	form.Set("code_verifier", codeVerifier)
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, provider.TokenEndpoint, strings.NewReader(form.Encode()),
+	)
+	if err != nil {
+		return Identity{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if c.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(c.ClientID), url.QueryEscape(c.ClientSecret))
+	}
+	token := struct {
+		AccessToken string `json:"access_token"`
+		IDToken     string `json:"id_token"`
+	}{}
+	if err := c.do(req, &token); err != nil {
+		return Identity{}, err
+	}
+	idToken, err := c.verify(ctx, provider, token.IDToken)
+	if err != nil {
+		return Identity{}, err
+	}
+	if idToken.Nonce != nonce {
+		return Identity{}, errors.New("id token nonce mismatch")
+	}
+	// Some providers only return profile claims from the userinfo endpoint
+	if provider.UserinfoEndpoint != "" && token.AccessToken != "" {
+		if err := c.userinfo(ctx, provider, token.AccessToken, idToken); err != nil {
+			return Identity{}, err
+		}
+	}
+	return Identity{
+		Subject:       idToken.Subject,
+		Email:         idToken.Email,
+		EmailVerified: bool(idToken.EmailVerified),
+		GivenName:     idToken.GivenName,
+		FamilyName:    idToken.FamilyName,
+		PhoneNumber:   idToken.PhoneNumber,
+		Birthdate:     idToken.Birthdate,
+	}, nil
+}
+
+func (c *Client) discover(ctx context.Context) (*discovery, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.discovery != nil {
+		return c.discovery, nil
+	}
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodGet,
+		strings.TrimSuffix(c.Issuer, "/")+"/.well-known/openid-configuration", nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	provider := &discovery{}
+	if err := c.do(req, provider); err != nil {
+		return nil, err
+	}
+	if strings.TrimSuffix(provider.Issuer, "/") != strings.TrimSuffix(c.Issuer, "/") {
+		return nil, fmt.Errorf("discovery issuer %q does not match %q", provider.Issuer, c.Issuer)
+	}
+	c.discovery = provider
+	return provider, nil
+}
+
+// do sends req and decodes a successful JSON response into v.
+func (c *Client) do(req *http.Request, v any) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		failure := struct {
+			Error string `json:"error"`
+		}{}
+		json.NewDecoder(resp.Body).Decode(&failure)
+		return fmt.Errorf("%s responded %d %s", req.URL.Path, resp.StatusCode, failure.Error)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// key returns the signing key kid, refreshing the provider's keys once when
+// it is not cached so key rotation is picked up.
+func (c *Client) key(ctx context.Context, provider *discovery, kid string) (*rsa.PublicKey, error) {
+	c.mu.Lock()
+	key, ok := c.keys[kid]
+	c.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, provider.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	jwks := struct {
+		Keys []struct {
+			KeyType string `json:"kty"`
+			KeyID   string `json:"kid"`
+			N       string `json:"n"`
+			E       string `json:"e"`
+		} `json:"keys"`
+	}{}
+	if err := c.do(req, &jwks); err != nil {
+		return nil, err
+	}
+	keys := map[string]*rsa.PublicKey{}
+	for _, jwk := range jwks.Keys {
+		if jwk.KeyType != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		keys[jwk.KeyID] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	c.mu.Lock()
+	c.keys = keys
+	c.mu.Unlock()
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("signing key %q not found", kid)
+}
+
+// userinfo fills claims missing from the ID token from the userinfo endpoint.
+func (c *Client) userinfo(ctx context.Context, provider *discovery, accessToken string, idToken *claims) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, provider.UserinfoEndpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	userinfo := &claims{}
+	if err := c.do(req, userinfo); err != nil {
+		return err
+	}
+	if userinfo.Subject != idToken.Subject {
+		return errors.New("userinfo subject mismatch")
+	}
+	if idToken.Email == "" {
+		idToken.Email = userinfo.Email
+		idToken.EmailVerified = userinfo.EmailVerified
+	}
+	if idToken.GivenName == "" {
+		idToken.GivenName = userinfo.GivenName
+	}
+	if idToken.FamilyName == "" {
+		idToken.FamilyName = userinfo.FamilyName
+	}
+	if idToken.PhoneNumber == "" {
+		idToken.PhoneNumber = userinfo.PhoneNumber
+	}
+	if idToken.Birthdate == "" {
+		idToken.Birthdate = userinfo.Birthdate
+	}
+	return nil
+}
+
+// verify checks the ID token signature, issuer, audience and expiry.
+func (c *Client) verify(ctx context.Context, provider *discovery, rawIDToken string) (*claims, error) {
+	idToken := &claims{}
+	parser := jwt.Parser{ValidMethods: []string{"RS256"}}
+	if _, err := parser.ParseWithClaims(rawIDToken, idToken, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return c.key(ctx, provider, kid)
+	}); err != nil {
+		return nil, err
+	}
+	if idToken.Issuer != provider.Issuer {
+		return nil, errors.New("id token issuer mismatch")
+	}
+	if !idToken.Audience.contains(c.ClientID) {
+		return nil, errors.New("id token audience mismatch")
+	}
+	if time.Unix(idToken.ExpiresAt, 0).Add(clockSkew).Before(time.Now()) {
+		return nil, errors.New("id token expired")
+	}
+	if idToken.Subject == "" {
+		return nil, errors.New("id token missing subject")
+	}
+	return idToken, nil
+}
@@ -0,0 +1,119 @@
+package oidc_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/Leagueify/api/internal/oidc"
+	"github.com/Leagueify/api/internal/oidc/oidctest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientExchange(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	identity := oidc.Identity{
+		Subject:       "248289761001",
+		Email:         "test@leagueify.org",
+		EmailVerified: true,
+		GivenName:     "Leagueify",
+		FamilyName:    "Test",
+		PhoneNumber:   "+12085551234",
+		Birthdate:     "1990-08-31",
+	}
+	server := oidctest.NewServer("leagueify", "s3cr3t+/=", identity)
+	defer server.Close()
+	testCases := []struct {
+		Description      string
+		ClientSecret     string
+		Nonce            string
+		CodeVerifier     string
+		ExpectedIdentity oidc.Identity
+		ExpectedError    bool
+	}{
+		{
+			Description:      "Valid Authorization Code",
+			ClientSecret:     "s3cr3t+/=",
+			ExpectedIdentity: identity,
+		},
+		{
+			Description:   "Incorrect Client Secret",
+			ClientSecret:  "incorrect",
+			ExpectedError: true,
+		},
+		{
+			Description:   "Nonce Mismatch",
+			ClientSecret:  "s3cr3t+/=",
+			Nonce:         "replayed",
+			ExpectedError: true,
+		},
+		{
+			Description:   "Code Verifier Mismatch",
+			ClientSecret:  "s3cr3t+/=",
+			CodeVerifier:  oidc.NewCodeVerifier(),
+			ExpectedError: true,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		client := oidc.NewClient(server.URL, "leagueify", test.ClientSecret, "http://localhost/oidc/mock/callback")
+		verifier := oidc.NewCodeVerifier()
+		authURL, err := client.AuthCodeURL(context.Background(), "state", "nonce", oidc.CodeChallenge(verifier))
+		if !assert.NoError(t, err, test.Description) {
+			continue
+		}
+		code, state, err := server.Authorize(authURL)
+		if !assert.NoError(t, err, test.Description) {
+			continue
+		}
+		assert.Equal(t, "state", state, test.Description)
+		if test.Nonce == "" {
+			test.Nonce = "nonce"
+		}
+		if test.CodeVerifier == "" {
+			test.CodeVerifier = verifier
+		}
+		result, err := client.Exchange(context.Background(), code, test.CodeVerifier, test.Nonce)
+		if test.ExpectedError {
+			assert.Error(t, err, test.Description)
+			continue
+		}
+		if assert.NoError(t, err, test.Description) {
+			assert.Equal(t, test.ExpectedIdentity, result, test.Description)
+		}
+	}
+}
+
+func TestClientAuthCodeURL(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	server := oidctest.NewServer("leagueify", "secret", oidc.Identity{Subject: "1"})
+	defer server.Close()
+	client := oidc.NewClient(server.URL+"/", "leagueify", "secret", "http://localhost/oidc/mock/callback")
+	authURL, err := client.AuthCodeURL(context.Background(), "state", "nonce", "challenge")
+	if !assert.NoError(t, err) {
+		return
+	}
+	parsed, err := url.Parse(authURL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	query := parsed.Query()
+	assert.Equal(t, server.URL+"/authorize", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, "openid email profile", query.Get("scope"))
+	assert.Equal(t, "challenge", query.Get("code_challenge"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	// Unknown issuers are refused
+	unknown := oidc.NewClient("http://127.0.0.1:1", "leagueify", "secret", "")
+	_, err = unknown.AuthCodeURL(context.Background(), "state", "nonce", "challenge")
+	assert.Error(t, err)
+}
+
+func TestCodeChallenge(t *testing.T) {
+	// RFC 7636 Appendix B example
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	assert.Equal(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", oidc.CodeChallenge(verifier))
+	assert.Len(t, oidc.NewCodeVerifier(), 43)
+}
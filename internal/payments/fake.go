@@ -0,0 +1,145 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// Fake is a Provider for local development and tests that never moves money.
+// Checkouts are recorded in process, and payments are simulated by posting
+// webhooks signed with Sign to the payments webhook.
+type Fake struct {
+	BaseURL       string
+	WebhookSecret string
+	// Err is returned from CreateCheckout and Refund when set, simulating a
+	// provider failure.
+	Err error
+
+	mu        sync.Mutex
+	checkouts map[string]Checkout
+	refunds   []Refund
+	requested map[string]Refund
+	issued    int
+}
+
+type fakeEvent struct {
+	ID               string `json:"id"`
+	Type             string `json:"type"`
+	RegistrationID   string `json:"registrationId"`
+	Reference        string `json:"reference"`
+	PaymentReference string `json:"paymentReference"`
+	Amount           int    `json:"amount"`
+}
+
+// NewFake returns a Fake with checkout pages under baseURL.
+func NewFake(baseURL, webhookSecret string) *Fake {
+	return &Fake{
+		BaseURL:       baseURL,
+		WebhookSecret: webhookSecret,
+		checkouts:     map[string]Checkout{},
+		requested:     map[string]Refund{},
+	}
+}
+
+func (f *Fake) Name() string {
+	return "fake"
+}
+
+func (f *Fake) CreateCheckout(ctx context.Context, checkout Checkout) (Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return Session{}, f.Err
+	}
+	id := f.reference("cs")
+	f.checkouts[id] = checkout
+	return Session{ID: id, URL: fmt.Sprintf("%s/checkout/%s", f.BaseURL, id)}, nil
+}
+
+// VerifyWebhook accepts a JSON event signed in the Fake-Signature header.
+func (f *Fake) VerifyWebhook(payload []byte, header http.Header) (Event, error) {
+	expected := sign(f.WebhookSecret, payload)
+	if !hmac.Equal([]byte(header.Get("Fake-Signature")), []byte(expected)) {
+		return Event{}, ErrInvalidSignature
+	}
+	var event fakeEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return Event{}, err
+	}
+	return Event(event), nil
+}
+
+// Refund accepts the refund, returning the refund already accepted with the
+// idempotency key when there is one.
+func (f *Fake) Refund(ctx context.Context, paymentReference string, amount int, idempotencyKey string) (Refund, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return Refund{}, f.Err
+	}
+	if refund, ok := f.requested[idempotencyKey]; ok {
+		return refund, nil
+	}
+	refund := Refund{Reference: f.reference("re"), Amount: amount}
+	f.refunds = append(f.refunds, refund)
+	if idempotencyKey != "" {
+		f.requested[idempotencyKey] = refund
+	}
+	return refund, nil
+}
+
+// Checkout returns the checkout started with the session ID.
+func (f *Fake) Checkout(sessionID string) (Checkout, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	checkout, ok := f.checkouts[sessionID]
+	return checkout, ok
+}
+
+// Complete returns the signed webhook reporting the checkout was paid in full.
+func (f *Fake) Complete(sessionID string) ([]byte, http.Header, error) {
+	checkout, ok := f.Checkout(sessionID)
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown checkout session %s", sessionID)
+	}
+	f.mu.Lock()
+	reference := f.reference("pi")
+	f.mu.Unlock()
+	return f.Sign(Event{
+		ID:             "evt_" + reference,
+		Type:           EventPaymentSucceeded,
+		RegistrationID: checkout.RegistrationID,
+		Reference:      reference,
+		Amount:         checkout.Amount,
+	})
+}
+
+// Refunds returns every refund accepted by the provider.
+func (f *Fake) Refunds() []Refund {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Refund{}, f.refunds...)
+}
+
+// Sign returns the webhook payload and headers reporting event.
+func (f *Fake) Sign(event Event) ([]byte, http.Header, error) {
+	payload, err := json.Marshal(fakeEvent(event))
+	if err != nil {
+		return nil, nil, err
+	}
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("Fake-Signature", sign(f.WebhookSecret, payload))
+	return payload, header, nil
+}
+
+// reference returns a new provider reference with prefix. Callers must hold
+// f.mu.
+func (f *Fake) reference(prefix string) string {
+	f.issued++
+	return fmt.Sprintf("fake_%s_%d", prefix, f.issued)
+}
@@ -0,0 +1,55 @@
+package payments
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeCheckout(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	fake := NewFake("http://localhost", "fake_secret")
+	session, err := fake.CreateCheckout(context.Background(), Checkout{
+		RegistrationID: "6KQJ7ZPR2",
+		Amount:         15200,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "http://localhost/checkout/"+session.ID, session.URL)
+
+	payload, header, err := fake.Complete(session.ID)
+	assert.NoError(t, err)
+	event, err := fake.VerifyWebhook(payload, header)
+	assert.NoError(t, err)
+	assert.Equal(t, EventPaymentSucceeded, event.Type)
+	assert.Equal(t, "6KQJ7ZPR2", event.RegistrationID)
+	assert.Equal(t, 15200, event.Amount)
+
+	// webhooks signed with another secret are rejected
+	other := NewFake("http://localhost", "other_secret")
+	_, err = other.VerifyWebhook(payload, header)
+	assert.Equal(t, ErrInvalidSignature, err)
+
+	_, _, err = fake.Complete("unknown")
+	assert.Error(t, err)
+}
+
+func TestFakeRefund(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	fake := NewFake("http://localhost", "fake_secret")
+	refund, err := fake.Refund(context.Background(), "fake_pi_1", 5000, "withdrawal_1")
+	assert.NoError(t, err)
+	assert.Equal(t, 5000, refund.Amount)
+
+	// refunds requested again with the same key are not made twice
+	repeated, err := fake.Refund(context.Background(), "fake_pi_1", 5000, "withdrawal_1")
+	assert.NoError(t, err)
+	assert.Equal(t, refund, repeated)
+	assert.Len(t, fake.Refunds(), 1)
+
+	_, err = fake.Refund(context.Background(), "fake_pi_1", 5000, "withdrawal_2")
+	assert.NoError(t, err)
+	assert.Len(t, fake.Refunds(), 2)
+}
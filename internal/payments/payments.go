@@ -0,0 +1,67 @@
+// Package payments collects registration fees through external payment
+// providers. Payers complete a provider hosted checkout, and the provider
+// reports completed payments and refunds back through signed webhooks.
+package payments
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Event types reported by providers. Other events are ignored.
+const (
+	EventPaymentSucceeded = "payment.succeeded"
+	EventRefundSucceeded  = "refund.succeeded"
+)
+
+// ErrInvalidSignature is returned when a webhook was not signed by the
+// provider.
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Provider is a payment processor registration fees can be collected through.
+type Provider interface {
+	// Name identifies the provider on the payments it records.
+	Name() string
+	// CreateCheckout starts a hosted checkout the payer is sent to.
+	CreateCheckout(ctx context.Context, checkout Checkout) (Session, error)
+	// VerifyWebhook checks the webhook's signature and returns its event.
+	VerifyWebhook(payload []byte, header http.Header) (Event, error)
+	// Refund returns amount of the payment with the given reference. A
+	// refund requested again with the same idempotency key is not made twice.
+	Refund(ctx context.Context, paymentReference string, amount int, idempotencyKey string) (Refund, error)
+}
+
+// Checkout is a payment towards a registration. Amounts are in cents.
+type Checkout struct {
+	RegistrationID string
+	Amount         int
+	Description    string
+	Email          string
+	SuccessURL     string
+	CancelURL      string
+}
+
+// Session is a hosted checkout.
+type Session struct {
+	ID  string
+	URL string
+}
+
+// Event is a completed payment or refund reported by a provider. Reference
+// identifies the payment or refund with the provider; refunds also carry the
+// reference of the payment they return.
+type Event struct {
+	ID               string
+	Type             string
+	RegistrationID   string
+	Reference        string
+	PaymentReference string
+	Amount           int
+}
+
+// Refund is a refund accepted by a provider.
+type Refund struct {
+	Reference string
+	Amount    int
+}
@@ -0,0 +1,217 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// StripeAPI is the base URL of the Stripe API.
+	StripeAPI = "https://api.stripe.com"
+	// signatureTolerance is how old a signed webhook may be before it is
+	// treated as a replay.
+	signatureTolerance = 5 * time.Minute
+)
+
+// Stripe is a Provider for Stripe and services implementing its checkout
+// session, refund and webhook signing APIs.
+type Stripe struct {
+	BaseURL       string
+	SecretKey     string
+	WebhookSecret string
+	Currency      string
+	HTTPClient    *http.Client
+	// Now returns the current time when checking webhook timestamps.
+	Now func() time.Time
+}
+
+type stripeObject struct {
+	ID                string `json:"id"`
+	Amount            int    `json:"amount"`
+	AmountTotal       int    `json:"amount_total"`
+	ClientReferenceID string `json:"client_reference_id"`
+	PaymentIntent     string `json:"payment_intent"`
+	PaymentStatus     string `json:"payment_status"`
+	Status            string `json:"status"`
+	URL               string `json:"url"`
+}
+
+type stripeEvent struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Object stripeObject `json:"object"`
+	} `json:"data"`
+}
+
+// NewStripe returns a Stripe provider charging in currency.
+func NewStripe(secretKey, webhookSecret, currency string) *Stripe {
+	return &Stripe{
+		BaseURL:       StripeAPI,
+		SecretKey:     secretKey,
+		WebhookSecret: webhookSecret,
+		Currency:      currency,
+		HTTPClient:    &http.Client{Timeout: 10 * time.Second},
+		Now:           time.Now,
+	}
+}
+
+func (s *Stripe) Name() string {
+	return "stripe"
+}
+
+func (s *Stripe) CreateCheckout(ctx context.Context, checkout Checkout) (Session, error) {
+	form := url.Values{}
+	form.Set("mode", "payment")
+	form.Set("client_reference_id", checkout.RegistrationID)
+	form.Set("success_url", checkout.SuccessURL)
+	form.Set("cancel_url", checkout.CancelURL)
+	if checkout.Email != "" {
+		form.Set("customer_email", checkout.Email)
+	}
+	form.Set("line_items[0][quantity]", "1")
+	form.Set("line_items[0][price_data][currency]", s.Currency)
+	form.Set("line_items[0][price_data][unit_amount]", strconv.Itoa(checkout.Amount))
+	form.Set("line_items[0][price_data][product_data][name]", checkout.Description)
+	form.Set("metadata[registration_id]", checkout.RegistrationID)
+	form.Set("payment_intent_data[metadata][registration_id]", checkout.RegistrationID)
+	var session stripeObject
+	if err := s.post(ctx, "/v1/checkout/sessions", form, "", &session); err != nil {
+		return Session{}, err
+	}
+	return Session{ID: session.ID, URL: session.URL}, nil
+}
+
+// VerifyWebhook accepts completed checkout sessions and succeeded refunds.
+// Checkouts paid by delayed payment methods are reported once the payment
+// succeeds.
+func (s *Stripe) VerifyWebhook(payload []byte, header http.Header) (Event, error) {
+	if !s.validSignature(payload, header.Get("Stripe-Signature")) {
+		return Event{}, ErrInvalidSignature
+	}
+	var event stripeEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return Event{}, err
+	}
+	object := event.Data.Object
+	switch event.Type {
+	case "checkout.session.completed", "checkout.session.async_payment_succeeded":
+		if object.PaymentStatus != "paid" {
+			return Event{ID: event.ID, Type: event.Type}, nil
+		}
+		return Event{
+			ID:             event.ID,
+			Type:           EventPaymentSucceeded,
+			RegistrationID: object.ClientReferenceID,
+			Reference:      object.PaymentIntent,
+			Amount:         object.AmountTotal,
+		}, nil
+	case "refund.created", "refund.updated", "charge.refund.updated":
+		if object.Status != "succeeded" {
+			return Event{ID: event.ID, Type: event.Type}, nil
+		}
+		return Event{
+			ID:               event.ID,
+			Type:             EventRefundSucceeded,
+			Reference:        object.ID,
+			PaymentReference: object.PaymentIntent,
+			Amount:           object.Amount,
+		}, nil
+	}
+	return Event{ID: event.ID, Type: event.Type}, nil
+}
+
+func (s *Stripe) Refund(ctx context.Context, paymentReference string, amount int, idempotencyKey string) (Refund, error) {
+	form := url.Values{}
+	form.Set("payment_intent", paymentReference)
+	form.Set("amount", strconv.Itoa(amount))
+	var refund stripeObject
+	if err := s.post(ctx, "/v1/refunds", form, idempotencyKey, &refund); err != nil {
+		return Refund{}, err
+	}
+	if refund.Status == "failed" || refund.Status == "canceled" {
+		return Refund{}, fmt.Errorf("refund %s %s", refund.ID, refund.Status)
+	}
+	return Refund{Reference: refund.ID, Amount: refund.Amount}, nil
+}
+
+// post sends the form to the Stripe API. Stripe replays the response to a
+// request made again with the same idempotency key, when one is given.
+func (s *Stripe) post(ctx context.Context, path string, form url.Values, idempotencyKey string, v any) error {
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, s.BaseURL+path, strings.NewReader(form.Encode()),
+	)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.SecretKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&failure); err != nil || failure.Error.Message == "" {
+			return fmt.Errorf("payment provider returned %s", resp.Status)
+		}
+		return fmt.Errorf("payment provider returned %s: %s", resp.Status, failure.Error.Message)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// validSignature checks a Stripe-Signature header of the form
+// t=<unix time>,v1=<signature>[,v1=<signature>...], where each signature is
+// the hex HMAC-SHA256 of "<unix time>.<payload>".
+func (s *Stripe) validSignature(payload []byte, header string) bool {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return false
+	}
+	age := s.Now().Sub(time.Unix(unix, 0))
+	if age > signatureTolerance || age < -signatureTolerance {
+		return false
+	}
+	expected := sign(s.WebhookSecret, []byte(timestamp+"."+string(payload)))
+	for _, signature := range signatures {
+		if hmac.Equal([]byte(signature), []byte(expected)) {
+			return true
+		}
+	}
+	return false
+}
+
+// sign returns the hex HMAC-SHA256 of payload.
+func sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
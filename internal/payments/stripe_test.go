@@ -0,0 +1,173 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripeCreateCheckout(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/checkout/sessions" || r.Header.Get("Authorization") != "Bearer sk_test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":{"message":"Invalid API Key provided"}}`)
+			return
+		}
+		r.ParseForm()
+		assert.Equal(t, "payment", r.PostForm.Get("mode"))
+		assert.Equal(t, "6KQJ7ZPR2", r.PostForm.Get("client_reference_id"))
+		assert.Equal(t, "usd", r.PostForm.Get("line_items[0][price_data][currency]"))
+		assert.Equal(t, "15200", r.PostForm.Get("line_items[0][price_data][unit_amount]"))
+		fmt.Fprint(w, `{"id":"cs_test_1","url":"https://checkout.stripe.com/c/pay/cs_test_1"}`)
+	}))
+	defer server.Close()
+	testCases := []struct {
+		Description     string
+		SecretKey       string
+		ExpectedSession Session
+		ExpectedError   bool
+	}{
+		{
+			Description: "Valid Checkout",
+			SecretKey:   "sk_test",
+			ExpectedSession: Session{
+				ID:  "cs_test_1",
+				URL: "https://checkout.stripe.com/c/pay/cs_test_1",
+			},
+		},
+		{
+			Description:   "Invalid Secret Key",
+			SecretKey:     "sk_invalid",
+			ExpectedError: true,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		stripe := NewStripe(test.SecretKey, "whsec_test", "usd")
+		stripe.BaseURL = server.URL
+		session, err := stripe.CreateCheckout(context.Background(), Checkout{
+			RegistrationID: "6KQJ7ZPR2",
+			Amount:         15200,
+			Description:    "Registration",
+			SuccessURL:     "http://localhost/success",
+			CancelURL:      "http://localhost/cancel",
+		})
+		if test.ExpectedError {
+			assert.Error(t, err, test.Description)
+			continue
+		}
+		assert.NoError(t, err, test.Description)
+		assert.Equal(t, test.ExpectedSession, session, test.Description)
+	}
+}
+
+func TestStripeRefund(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	var idempotencyKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		idempotencyKey = r.Header.Get("Idempotency-Key")
+		status := "succeeded"
+		if r.PostForm.Get("payment_intent") == "pi_failed" {
+			status = "failed"
+		}
+		fmt.Fprintf(w, `{"id":"re_test_1","amount":%s,"status":"%s"}`, r.PostForm.Get("amount"), status)
+	}))
+	defer server.Close()
+	stripe := NewStripe("sk_test", "whsec_test", "usd")
+	stripe.BaseURL = server.URL
+
+	refund, err := stripe.Refund(context.Background(), "pi_test_1", 5000, "refund_B2KD8RFWQ")
+	assert.NoError(t, err)
+	assert.Equal(t, Refund{Reference: "re_test_1", Amount: 5000}, refund)
+	assert.Equal(t, "refund_B2KD8RFWQ", idempotencyKey)
+
+	_, err = stripe.Refund(context.Background(), "pi_failed", 5000, "refund_7PWXQ2M4K")
+	assert.Error(t, err)
+}
+
+func TestStripeVerifyWebhook(t *testing.T) {
+	// run test in parallel
+	t.Parallel()
+	now := time.Unix(1700000000, 0)
+	stripe := NewStripe("sk_test", "whsec_test", "usd")
+	stripe.Now = func() time.Time { return now }
+	paid := []byte(`{"id":"evt_1","type":"checkout.session.completed","data":{"object":{"id":"cs_test_1","payment_intent":"pi_test_1","amount_total":15200,"client_reference_id":"6KQJ7ZPR2","payment_status":"paid"}}}`)
+	unpaid := []byte(`{"id":"evt_2","type":"checkout.session.completed","data":{"object":{"id":"cs_test_1","payment_intent":"pi_test_1","amount_total":15200,"client_reference_id":"6KQJ7ZPR2","payment_status":"unpaid"}}}`)
+	refunded := []byte(`{"id":"evt_3","type":"refund.created","data":{"object":{"id":"re_test_1","payment_intent":"pi_test_1","amount":5000,"status":"succeeded"}}}`)
+	signature := func(timestamp int64, secret string, payload []byte) string {
+		return fmt.Sprintf("t=%d,v1=%s", timestamp, sign(secret, []byte(fmt.Sprintf("%d.%s", timestamp, payload))))
+	}
+
+	testCases := []struct {
+		Description   string
+		Payload       []byte
+		Signature     string
+		ExpectedEvent Event
+		ExpectedError error
+	}{
+		{
+			Description: "Paid Checkout",
+			Payload:     paid,
+			Signature:   signature(now.Unix(), "whsec_test", paid),
+			ExpectedEvent: Event{
+				ID:             "evt_1",
+				Type:           EventPaymentSucceeded,
+				RegistrationID: "6KQJ7ZPR2",
+				Reference:      "pi_test_1",
+				Amount:         15200,
+			},
+		},
+		{
+			Description:   "Unpaid Checkout",
+			Payload:       unpaid,
+			Signature:     signature(now.Unix(), "whsec_test", unpaid),
+			ExpectedEvent: Event{ID: "evt_2", Type: "checkout.session.completed"},
+		},
+		{
+			Description: "Succeeded Refund",
+			Payload:     refunded,
+			Signature:   signature(now.Unix(), "whsec_test", refunded),
+			ExpectedEvent: Event{
+				ID:               "evt_3",
+				Type:             EventRefundSucceeded,
+				Reference:        "re_test_1",
+				PaymentReference: "pi_test_1",
+				Amount:           5000,
+			},
+		},
+		{
+			Description:   "Incorrect Secret",
+			Payload:       paid,
+			Signature:     signature(now.Unix(), "whsec_incorrect", paid),
+			ExpectedError: ErrInvalidSignature,
+		},
+		{
+			Description:   "Replayed Webhook",
+			Payload:       paid,
+			Signature:     signature(now.Add(-time.Hour).Unix(), "whsec_test", paid),
+			ExpectedError: ErrInvalidSignature,
+		},
+		{
+			Description:   "Missing Signature",
+			Payload:       paid,
+			ExpectedError: ErrInvalidSignature,
+		},
+	}
+	// Execute Test Cases
+	for _, test := range testCases {
+		header := http.Header{}
+		header.Set("Stripe-Signature", test.Signature)
+		event, err := stripe.VerifyWebhook(test.Payload, header)
+		assert.Equal(t, test.ExpectedError, err, test.Description)
+		assert.Equal(t, test.ExpectedEvent, event, test.Description)
+	}
+}
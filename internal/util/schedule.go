@@ -0,0 +1,25 @@
+package util
+
+import (
+	"context"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// RunEvery calls run straight away and then every interval until ctx is
+// cancelled. Errors are reported to Sentry and do not stop the loop.
+func RunEvery(ctx context.Context, interval time.Duration, run func() error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := run(); err != nil {
+			sentry.CaptureException(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
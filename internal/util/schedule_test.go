@@ -0,0 +1,33 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunEvery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	runs := 0
+	done := make(chan struct{})
+	go func() {
+		RunEvery(ctx, time.Millisecond, func() error {
+			runs++
+			if runs == 3 {
+				cancel()
+			}
+			return errors.New("failed runs are retried on the next tick")
+		})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("RunEvery did not return once its context was cancelled")
+	}
+	assert.Equal(t, 3, runs)
+}
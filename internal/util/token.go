@@ -1,10 +1,16 @@
 package util
 
 import (
+	"crypto/rand"
 	"fmt"
-	"math/rand"
 	"strings"
-	"time"
+)
+
+const (
+	// secretPrefix identifies credentials such as API keys in logs and scanners
+	secretPrefix = "lfy_"
+	// secretLength of 52 characters provides 260 bits of entropy
+	secretLength = 52
 )
 
 var (
@@ -27,6 +33,12 @@ func SignedToken(length int) string {
 	return fmt.Sprintf("%s%s", string(bytes), checksumChar)
 }
 
+// SecretToken returns a high-entropy credential such as an API key. Secrets
+// are never used as identifiers and should only be stored hashed.
+func SecretToken() string {
+	return secretPrefix + SignedToken(secretLength+1)
+}
+
 func UnsignedToken(length int) string {
 	if length <= 0 {
 		return ""
@@ -47,6 +59,19 @@ func VerifyToken(signedToken string) bool {
 	return string(submittedChecksum) == checksumChar
 }
 
+func VerifySecret(secret string) bool {
+	token, found := strings.CutPrefix(secret, secretPrefix)
+	if !found || len(token) != secretLength+1 {
+		return false
+	}
+	for _, char := range token[:secretLength] {
+		if !strings.ContainsRune(charSet, char) {
+			return false
+		}
+	}
+	return VerifyToken(token)
+}
+
 func calulateChecksum(bytes []byte) int {
 	var rawSum int
 	for _, i := range bytes {
@@ -60,11 +85,25 @@ func getChecksum(checksumNumber int) string {
 	return checksumCharSet[checksumNumber]
 }
 
+// generateBytes draws characters from charSet using crypto/rand. Random bytes
+// above the largest multiple of len(charSet) are discarded to avoid modulo bias.
 func generateBytes(length int) []byte {
-	rand.NewSource(time.Now().UnixNano())
-	result := make([]byte, length)
-	for i := range result {
-		result[i] = charSet[rand.Intn(len(charSet))]
+	limit := 256 - 256%len(charSet)
+	result := make([]byte, 0, length)
+	buffer := make([]byte, length)
+	for len(result) < length {
+		if _, err := rand.Read(buffer); err != nil {
+			panic(fmt.Sprintf("util: unable to read random bytes: %v", err))
+		}
+		for _, b := range buffer {
+			if int(b) >= limit {
+				continue
+			}
+			result = append(result, charSet[int(b)%len(charSet)])
+			if len(result) == length {
+				break
+			}
+		}
 	}
 	return result
 }
@@ -1,6 +1,7 @@
 package util
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -150,3 +151,159 @@ func TestVerifySignedToken(t *testing.T) {
 		}
 	}
 }
+
+func TestSecretToken(t *testing.T) {
+	secret := SecretToken()
+	if !strings.HasPrefix(secret, secretPrefix) {
+		t.Errorf(`Expected prefix %v but received "%v".`, secretPrefix, secret)
+	}
+	if len(secret) != len(secretPrefix)+secretLength+1 {
+		t.Errorf(
+			`Expected a length of %v but received %v.`,
+			len(secretPrefix)+secretLength+1, len(secret),
+		)
+	}
+	if !VerifySecret(secret) {
+		t.Errorf(`Expected "%v" to be a valid secret.`, secret)
+	}
+}
+
+func TestVerifySecret(t *testing.T) {
+	secret := SecretToken()
+	testCases := []struct {
+		Description    string
+		Secret         string
+		ExpectedResult bool
+	}{
+		{
+			Description:    "Valid Secret",
+			Secret:         secret,
+			ExpectedResult: true,
+		},
+		{
+			Description:    "Missing Prefix",
+			Secret:         strings.TrimPrefix(secret, secretPrefix),
+			ExpectedResult: false,
+		},
+		{
+			Description:    "Truncated Secret",
+			Secret:         secret[:len(secret)-2],
+			ExpectedResult: false,
+		},
+		{
+			Description:    "Signed Token",
+			Secret:         SignedToken(secretLength + 1),
+			ExpectedResult: false,
+		},
+		{
+			Description:    "Invalid Character",
+			Secret:         secretPrefix + "I" + secret[len(secretPrefix)+1:],
+			ExpectedResult: false,
+		},
+		{
+			Description:    "Empty Secret",
+			Secret:         "",
+			ExpectedResult: false,
+		},
+	}
+
+	for _, test := range testCases {
+		result := VerifySecret(test.Secret)
+		if result != test.ExpectedResult {
+			t.Errorf(
+				`%v: Expected %v but received %v with value "%v".`,
+				test.Description, test.ExpectedResult, result, test.Secret,
+			)
+		}
+	}
+}
+
+func TestTokenUniqueness(t *testing.T) {
+	// 10,000 tokens of 50 bits should never collide
+	seen := make(map[string]bool)
+	for i := 0; i < 10000; i++ {
+		token := UnsignedToken(10)
+		if seen[token] {
+			t.Fatalf(`Duplicate token "%v" after %v tokens.`, token, i)
+		}
+		seen[token] = true
+	}
+}
+
+func TestTokenDistribution(t *testing.T) {
+	// chi-squared goodness of fit against a uniform charSet distribution,
+	// 31 degrees of freedom has a critical value of 84 at p = 0.000001
+	const samples = 320000
+	const critical = 84.0
+	counts := make(map[byte]int)
+	for _, char := range generateBytes(samples) {
+		counts[char]++
+	}
+	if len(counts) != len(charSet) {
+		t.Fatalf(`Expected %v distinct characters but received %v.`, len(charSet), len(counts))
+	}
+	expected := float64(samples) / float64(len(charSet))
+	var chiSquared float64
+	for _, count := range counts {
+		diff := float64(count) - expected
+		chiSquared += diff * diff / expected
+	}
+	if chiSquared > critical {
+		t.Errorf(`Expected chi-squared below %v but received %v.`, critical, chiSquared)
+	}
+}
+
+func TestTokenPositionalDistribution(t *testing.T) {
+	// every position of a token should be uniform, not only the aggregate
+	const tokens = 16000
+	const length = 8
+	const critical = 84.0
+	counts := make([]map[byte]int, length)
+	for index := range counts {
+		counts[index] = make(map[byte]int)
+	}
+	for i := 0; i < tokens; i++ {
+		for index, char := range generateBytes(length) {
+			counts[index][char]++
+		}
+	}
+	expected := float64(tokens) / float64(len(charSet))
+	for index, positionCounts := range counts {
+		var chiSquared float64
+		for _, char := range []byte(charSet) {
+			diff := float64(positionCounts[char]) - expected
+			chiSquared += diff * diff / expected
+		}
+		if chiSquared > critical {
+			t.Errorf(
+				`Position %v: Expected chi-squared below %v but received %v.`,
+				index, critical, chiSquared,
+			)
+		}
+	}
+}
+
+func TestTokenSerialCorrelation(t *testing.T) {
+	// consecutive characters should be independent, pairs are checked against
+	// a uniform distribution over 1023 degrees of freedom (critical 1253)
+	const pairs = 204800
+	const critical = 1253.0
+	sample := generateBytes(pairs * 2)
+	index := make(map[byte]int)
+	for i, char := range []byte(charSet) {
+		index[char] = i
+	}
+	counts := make([]int, len(charSet)*len(charSet))
+	for i := 0; i < len(sample); i += 2 {
+		counts[index[sample[i]]*len(charSet)+index[sample[i+1]]]++
+	}
+	expected := float64(pairs) / float64(len(counts))
+	var chiSquared float64
+	for _, count := range counts {
+		diff := float64(count) - expected
+		chiSquared += diff * diff / expected
+	}
+	if chiSquared > critical {
+		t.Errorf(`Expected chi-squared below %v but received %v.`, critical, chiSquared)
+	}
+}
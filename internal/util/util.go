@@ -1,6 +1,8 @@
 package util
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/lib/pq"
@@ -39,6 +41,31 @@ func IsValidDateRange(start, end string) (bool, error) {
 	return true, nil
 }
 
+// IsDateInRange reports whether date falls on or between the start and end
+// dates.
+func IsDateInRange(date, start, end string) (bool, error) {
+	afterStart, err := IsValidDateRange(start, date)
+	if err != nil {
+		return false, err
+	}
+	beforeEnd, err := IsValidDateRange(date, end)
+	if err != nil {
+		return false, err
+	}
+	return afterStart && beforeEnd, nil
+}
+
+// FormatAmount renders an amount in cents in the currency, e.g. 4000 in usd
+// as "40.00 USD".
+func FormatAmount(amount int, currency string) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%s%d.%02d %s", sign, amount/100, amount%100, strings.ToUpper(currency))
+}
+
 func IsInArray(players pq.StringArray, playerID string) bool {
 	for _, player := range players {
 		if playerID == player {
@@ -42,3 +42,51 @@ func TestCalculateAge(t *testing.T) {
 		}
 	}
 }
+
+func TestIsDateInRange(t *testing.T) {
+	testCases := []struct {
+		Description    string
+		Date           string
+		ExpectedResult bool
+		ExpectedError  bool
+	}{
+		{Description: "Before Range", Date: "2024-02-29", ExpectedResult: false},
+		{Description: "Range Start", Date: "2024-03-01", ExpectedResult: true},
+		{Description: "Within Range", Date: "2024-03-15", ExpectedResult: true},
+		{Description: "Range End", Date: "2024-03-31", ExpectedResult: true},
+		{Description: "After Range", Date: "2024-04-01", ExpectedResult: false},
+		{Description: "Invalid Date", Date: "03/15/2024", ExpectedError: true},
+	}
+
+	for _, test := range testCases {
+		result, err := IsDateInRange(test.Date, "2024-03-01", "2024-03-31")
+		if (err != nil) != test.ExpectedError {
+			t.Errorf(`%v: Expected error %v but received %v`, test.Description, test.ExpectedError, err)
+		}
+		if result != test.ExpectedResult {
+			t.Errorf(
+				`%v: Expected %v but received %v for %v`,
+				test.Description, test.ExpectedResult, result, test.Date,
+			)
+		}
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	testCases := []struct {
+		Amount   int
+		Expected string
+	}{
+		{Amount: 0, Expected: "0.00 USD"},
+		{Amount: 5, Expected: "0.05 USD"},
+		{Amount: 4000, Expected: "40.00 USD"},
+		{Amount: 123456, Expected: "1234.56 USD"},
+		{Amount: -2550, Expected: "-25.50 USD"},
+	}
+
+	for _, test := range testCases {
+		if result := FormatAmount(test.Amount, "usd"); result != test.Expected {
+			t.Errorf(`Expected %v but received %v for %v`, test.Expected, result, test.Amount)
+		}
+	}
+}
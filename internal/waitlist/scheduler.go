@@ -0,0 +1,54 @@
+package waitlist
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+)
+
+// DefaultInterval is how often the Scheduler refreshes waitlists, so offers
+// expire at most this long after their deadline.
+const DefaultInterval = 15 * time.Minute
+
+// Store is the persistence the Scheduler refreshes waitlists through and
+// queues offers in.
+type Store interface {
+	Outbox
+	RefreshWaitlists() ([]model.WaitlistOffer, error)
+}
+
+type Scheduler struct {
+	Store    Store
+	BaseURL  string
+	Interval time.Duration
+}
+
+func NewScheduler(store Store, baseURL string) *Scheduler {
+	return &Scheduler{
+		Store:    store,
+		BaseURL:  baseURL,
+		Interval: DefaultInterval,
+	}
+}
+
+// Run refreshes waitlists every Interval until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	util.RunEvery(ctx, s.Interval, func() error {
+		_, err := s.Refresh()
+		return err
+	})
+}
+
+// Refresh withdraws the waitlisted players whose offers have expired, offers
+// the places open in each division to the players next on its waitlist and
+// tells their families, returning how many emails were queued.
+func (s *Scheduler) Refresh() (int, error) {
+	offers, err := s.Store.RefreshWaitlists()
+	// offers made in the divisions refreshed before one failed still hold
+	// places, so their families are told all the same
+	queued, notifyErr := Notify(s.Store, s.BaseURL, offers)
+	return queued, errors.Join(err, notifyErr)
+}
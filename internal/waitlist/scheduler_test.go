@@ -0,0 +1,83 @@
+package waitlist
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Leagueify/api/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeStore struct {
+	offers     []model.WaitlistOffer
+	refreshErr error
+	queued     []model.OutboundEmail
+}
+
+func (s *fakeStore) CreateOutboundEmail(email model.OutboundEmail) error {
+	s.queued = append(s.queued, email)
+	return nil
+}
+
+func (s *fakeStore) RefreshWaitlists() ([]model.WaitlistOffer, error) {
+	return s.offers, s.refreshErr
+}
+
+func TestRefresh(t *testing.T) {
+	offer := model.WaitlistOffer{
+		RegistrationID: "6KQJ7ZPR2",
+		PlayerID:       "DW74MSY5X",
+		PlayerName:     "Test Player",
+		Division:       "U12",
+		SeasonName:     "Spring 2024",
+		Email:          "test@leagueify.org",
+		FirstName:      "Leagueify",
+		ExpiresAt:      time.Date(2024, time.March, 2, 9, 0, 0, 0, time.UTC),
+	}
+	testCases := []struct {
+		Description    string
+		Store          *fakeStore
+		ExpectedQueued int
+		ExpectError    bool
+	}{
+		{
+			Description: "Nothing Offered",
+			Store:       &fakeStore{},
+		},
+		{
+			Description:    "Place Offered",
+			Store:          &fakeStore{offers: []model.WaitlistOffer{offer}},
+			ExpectedQueued: 1,
+		},
+		{
+			Description: "Registration Without Account",
+			Store: &fakeStore{
+				offers: []model.WaitlistOffer{{RegistrationID: "6KQJ7ZPR2", ExpiresAt: offer.ExpiresAt}},
+			},
+		},
+		{
+			Description: "Division Failed After Offers",
+			Store: &fakeStore{
+				offers:     []model.WaitlistOffer{offer},
+				refreshErr: errors.New("connection refused"),
+			},
+			ExpectedQueued: 1,
+			ExpectError:    true,
+		},
+	}
+	for _, test := range testCases {
+		scheduler := NewScheduler(test.Store, "http://localhost")
+
+		queued, err := scheduler.Refresh()
+
+		assert.Equal(t, test.ExpectError, err != nil, test.Description)
+		assert.Equal(t, test.ExpectedQueued, queued, test.Description)
+		assert.Len(t, test.Store.queued, test.ExpectedQueued, test.Description)
+		if test.ExpectedQueued > 0 {
+			assert.Equal(t, "test@leagueify.org", test.Store.queued[0].Recipient, test.Description)
+			assert.Contains(t, test.Store.queued[0].TextBody, "until Saturday, March 2 at 9:00 AM UTC.", test.Description)
+			assert.Contains(t, test.Store.queued[0].TextBody, "http://localhost/registrations/6KQJ7ZPR2", test.Description)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// Package waitlist places players in divisions with a capacity and offers the
+// places that open up to the families waiting for them.
+package waitlist
+
+import (
+	"fmt"
+
+	"github.com/Leagueify/api/internal/mail"
+	"github.com/Leagueify/api/internal/model"
+	"github.com/Leagueify/api/internal/util"
+)
+
+// expiresFormat is how the time an offer expires is written in emails.
+const expiresFormat = "Monday, January 2 at 3:04 PM MST"
+
+// Outbox is where emails about offers are queued for delivery.
+type Outbox interface {
+	CreateOutboundEmail(email model.OutboundEmail) error
+}
+
+// HasRoom reports whether a player registering for the division takes a
+// place. Players are waitlisted once the division is full, and while anyone
+// is waiting for a place so they cannot jump the queue.
+func HasRoom(capacity model.DivisionCapacity) bool {
+	return capacity.Capacity-capacity.Placed-capacity.Offered > 0 &&
+		capacity.Waitlisted == capacity.Offered
+}
+
+// Notify queues an email telling the account holder of each offer about the
+// place held for their player, returning how many were queued. Offers on
+// registrations without an account holder are still made, no one is told.
+func Notify(outbox Outbox, baseURL string, offers []model.WaitlistOffer) (int, error) {
+	var queued int
+	for _, offer := range offers {
+		if offer.Email == "" {
+			continue
+		}
+		email, err := mail.Compose(offer.Email, "waitlist_offer", map[string]any{
+			"FirstName":  offer.FirstName,
+			"PlayerName": offer.PlayerName,
+			"Division":   offer.Division,
+			"Season":     offer.SeasonName,
+			"Expires":    offer.ExpiresAt.Format(expiresFormat),
+			"Link": fmt.Sprintf(
+				"%s/registrations/%s", baseURL, util.ReturnSignedToken(offer.RegistrationID),
+			),
+		})
+		if err != nil {
+			return queued, err
+		}
+		if err := outbox.CreateOutboundEmail(email); err != nil {
+			return queued, err
+		}
+		queued++
+	}
+	return queued, nil
+}
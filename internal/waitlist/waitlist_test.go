@@ -0,0 +1,41 @@
+package waitlist
+
+import (
+	"testing"
+
+	"github.com/Leagueify/api/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHasRoom(t *testing.T) {
+	testCases := []struct {
+		Description string
+		Capacity    model.DivisionCapacity
+		Expected    bool
+	}{
+		{
+			Description: "Places Open",
+			Capacity:    model.DivisionCapacity{Capacity: 12, Placed: 11},
+			Expected:    true,
+		},
+		{
+			Description: "Division Full",
+			Capacity:    model.DivisionCapacity{Capacity: 12, Placed: 12},
+		},
+		{
+			Description: "Last Place Offered",
+			Capacity:    model.DivisionCapacity{Capacity: 12, Placed: 11, Offered: 1, Waitlisted: 1},
+		},
+		{
+			Description: "Players Waiting",
+			Capacity:    model.DivisionCapacity{Capacity: 12, Placed: 10, Offered: 1, Waitlisted: 2},
+		},
+		{
+			Description: "Capacity Lowered",
+			Capacity:    model.DivisionCapacity{Capacity: 10, Placed: 12},
+		},
+	}
+	for _, test := range testCases {
+		assert.Equal(t, test.Expected, HasRoom(test.Capacity), test.Description)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/Leagueify/api/internal/database"
+)
+
+const migrateUsage = "usage: server migrate <up|down [steps]|status>"
+
+func runMigrate(db database.Database, args []string) error {
+	if len(args) < 1 {
+		return errors.New(migrateUsage)
+	}
+	switch args[0] {
+	case "up":
+		applied, err := db.MigrateUp()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Applied %d migration(s)\n", applied)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			parsed, err := strconv.Atoi(args[1])
+			if err != nil || parsed < 1 {
+				return fmt.Errorf("invalid migration steps '%s'", args[1])
+			}
+			steps = parsed
+		}
+		reverted, err := db.MigrateDown(steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Reverted %d migration(s)\n", reverted)
+	case "status":
+		migrations, err := db.MigrationStatus()
+		if err != nil {
+			return err
+		}
+		for _, migration := range migrations {
+			appliedAt := "pending"
+			if migration.IsApplied {
+				appliedAt = migration.AppliedAt
+			}
+			fmt.Printf("%04d_%-32s %s\n", migration.Version, migration.Name, appliedAt)
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+	return nil
+}
+
+func checkMigrations(db database.Database, autoMigrate bool) error {
+	if autoMigrate {
+		if _, err := db.MigrateUp(); err != nil {
+			return fmt.Errorf("ERROR: Database Migration Error '%s'", err)
+		}
+	}
+	pending, err := db.PendingMigrations()
+	if err != nil {
+		return fmt.Errorf("ERROR: Database Migration Error '%s'", err)
+	}
+	if pending > 0 {
+		return fmt.Errorf(
+			"ERROR: %d pending database migration(s), run 'server migrate up'",
+			pending,
+		)
+	}
+	return nil
+}
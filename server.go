@@ -1,11 +1,17 @@
 package main
 
 import (
+	"context"
 	"embed"
 	"fmt"
+	"os"
 
 	"github.com/Leagueify/api/internal/config"
+	"github.com/Leagueify/api/internal/database"
 	"github.com/Leagueify/api/internal/endpoints"
+	"github.com/Leagueify/api/internal/installments"
+	"github.com/Leagueify/api/internal/mail"
+	"github.com/Leagueify/api/internal/waitlist"
 	"github.com/getsentry/sentry-go"
 	sentryecho "github.com/getsentry/sentry-go/echo"
 	"github.com/labstack/echo/v4"
@@ -22,8 +28,30 @@ var (
 func main() {
 	// Configuration
 	cfg := config.LoadConfig()
+	// Database Initialization
+	db, err := database.GetDatabase()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	// Migration Command
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(db, os.Args[2:]); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+	// Pending Migration Check
+	if err := checkMigrations(db, cfg.DBAutoMigrate); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 	// Echo Initialization
 	e := echo.New()
+	// Only trust X-Forwarded-For from proxies on private networks so clients
+	// cannot spoof the address used for login attempt tracking
+	e.IPExtractor = echo.ExtractIPFromXFFHeader()
 	// Middleware Config
 	e.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
 		Format: "${time_rfv3339}::${remote_id}::${status}:${method}:${uri}\n",
@@ -46,7 +74,13 @@ func main() {
 	e.StaticFS("/api", webDocsFS)
 	e.StaticFS("/assets", webAssetFS)
 	// API Routes
-	api.Routes(e)
+	api.Routes(e, db)
+	// Outbound Email Delivery
+	go mail.NewMailer(db, mail.SMTPTransport{}).Run(context.Background())
+	// Overdue Installment Reminders
+	go installments.NewScheduler(db, cfg.BaseURL, cfg.PaymentsCurrency).Run(context.Background())
+	// Waitlist Offers
+	go waitlist.NewScheduler(db, cfg.BaseURL).Run(context.Background())
 	// Start Server
 	e.Logger.Fatal(e.Start(":8888"))
 }